@@ -0,0 +1,268 @@
+// backfill fetches trades from the REST API and writes the ones missing
+// from the trades table, for example after the trade WebSocket was down.
+//
+// Usage:
+//
+//	go run ./cmd/backfill --config configs/gatherer.local.yaml \
+//	    --from 2025-01-15T12:00:00Z --to 2025-01-15T13:00:00Z --tickers KXBTC-25JAN-B100000
+//	go run ./cmd/backfill --config configs/gatherer.local.yaml --from 2025-01-15T12:00:00Z --dry-run
+//
+// Without --tickers every market's trades in the range are fetched. Rows go
+// through the trade writer, whose inserts are ON CONFLICT (trade_id,
+// exchange_ts) DO NOTHING, so trades already written count as conflicts and
+// a range can be backfilled repeatedly. received_at is the time of the
+// backfill, so the deduplicator picks the new rows up.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/api"
+	"github.com/rickgao/kalshi-data/internal/auth"
+	"github.com/rickgao/kalshi-data/internal/config"
+	"github.com/rickgao/kalshi-data/internal/database"
+	"github.com/rickgao/kalshi-data/internal/router"
+	"github.com/rickgao/kalshi-data/internal/writer"
+)
+
+func main() {
+	configPath := flag.String("config", "", "gatherer config with the API settings and target database")
+	from := flag.String("from", "", "backfill trades at or after this RFC3339 time (required)")
+	to := flag.String("to", "", "backfill trades up to this RFC3339 time (default: now)")
+	tickers := flag.String("tickers", "", "comma-separated market tickers (default: all markets)")
+	dryRun := flag.Bool("dry-run", false, "fetch and count trades without writing them")
+	flag.Parse()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+
+	if *configPath == "" || *from == "" {
+		fmt.Fprintln(os.Stderr, "backfill: --config and --from required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fromTime, err := time.Parse(time.RFC3339, *from)
+	if err != nil {
+		logger.Error("invalid --from", "error", err)
+		os.Exit(2)
+	}
+	toTime := time.Now()
+	if *to != "" {
+		toTime, err = time.Parse(time.RFC3339, *to)
+		if err != nil {
+			logger.Error("invalid --to", "error", err)
+			os.Exit(2)
+		}
+	}
+	if !toTime.After(fromTime) {
+		logger.Error("--to must be after --from", "from", fromTime, "to", toTime)
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadAndValidate(*configPath)
+	if err != nil {
+		logger.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigCh
+		logger.Info("received shutdown signal", "signal", sig)
+		cancel()
+	}()
+
+	client, err := newClient(cfg.API, logger)
+	if err != nil {
+		logger.Error("failed to create API client", "error", err)
+		os.Exit(1)
+	}
+
+	// Trades are pushed into a buffer drained by a normal trade writer
+	buf := router.NewGrowableBuffer[router.TradeMsg](cfg.Writers.BufferSize)
+	var tradeWriter *writer.TradeWriter
+	if !*dryRun {
+		pools, err := database.NewPools(ctx, cfg.Database)
+		if err != nil {
+			logger.Error("failed to connect to database", "error", err)
+			os.Exit(1)
+		}
+		defer pools.Close()
+
+		writerCfg := writer.WriterConfig{
+			BatchSize:     cfg.Writers.BatchSize,
+			FlushInterval: cfg.Writers.FlushInterval,
+			InsertMode:    cfg.Writers.InsertMode,
+			// Failed batches are reported, not spilled: rerunning the
+			// backfill is the recovery path.
+			Retry: writer.RetryConfig{
+				MaxAttempts: cfg.Writers.Retry.MaxAttempts,
+				BaseDelay:   cfg.Writers.Retry.BaseDelay,
+				MaxDelay:    cfg.Writers.Retry.MaxDelay,
+			},
+		}
+		tradeWriter = writer.NewTradeWriter(writerCfg, buf, pools.Timescale, logger)
+		if err := tradeWriter.Start(ctx); err != nil {
+			logger.Error("failed to start trade writer", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	targets := []string{""} // "" fetches all markets in one pass
+	if *tickers != "" {
+		targets = targets[:0]
+		for _, t := range strings.Split(*tickers, ",") {
+			targets = append(targets, strings.TrimSpace(t))
+		}
+	}
+
+	logger.Info("backfill starting",
+		"from", fromTime,
+		"to", toTime,
+		"tickers", len(targets),
+		"dry_run", *dryRun,
+	)
+
+	start := time.Now()
+	var fetched int64
+	var fetchErr error
+	for _, ticker := range targets {
+		n, err := backfillTicker(ctx, client, buf, ticker, fromTime, toTime, *dryRun)
+		fetched += n
+		if err != nil {
+			fetchErr = err
+			logger.Error("failed to fetch trades", "ticker", ticker, "fetched", n, "error", err)
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		logger.Info("fetched trades", "ticker", ticker, "count", n)
+	}
+
+	if tradeWriter != nil {
+		// Let the writer take everything before stopping it
+		for buf.Len() > 0 && ctx.Err() == nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer shutdownCancel()
+		tradeWriter.Stop(shutdownCtx)
+
+		stats := tradeWriter.Stats()
+		logger.Info("backfill complete",
+			"duration", time.Since(start),
+			"fetched", fetched,
+			"inserted", stats.Inserts,
+			"already_present", stats.Conflicts,
+			"write_errors", stats.Errors,
+		)
+		if stats.Errors > 0 {
+			fetchErr = fmt.Errorf("%d batches failed to write", stats.Errors)
+		}
+	} else {
+		logger.Info("dry-run complete", "duration", time.Since(start), "fetched", fetched)
+	}
+
+	if fetchErr != nil {
+		os.Exit(1)
+	}
+}
+
+// newClient creates a rate-limited API client from the gatherer's settings.
+func newClient(cfg config.APIConfig, logger *slog.Logger) (*api.Client, error) {
+	opts := []api.ClientOption{
+		api.WithLogger(logger),
+		api.WithTimeout(cfg.Timeout),
+		api.WithRetries(cfg.MaxRetries, time.Second),
+		api.WithRateLimiter(api.NewRateLimiter(api.RateLimits{
+			ReadPerSecond:  cfg.RateLimit.ReadPerSecond,
+			WritePerSecond: cfg.RateLimit.WritePerSecond,
+			Burst:          cfg.RateLimit.Burst,
+		})),
+	}
+
+	if cfg.PrivateKeyPath == "" {
+		return api.NewClient(cfg.RestURL, cfg.APIKey, nil, opts...), nil
+	}
+	creds, err := auth.LoadCredentials(cfg.APIKey, cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	return api.NewClient(cfg.RestURL, cfg.APIKey, creds.PrivateKey, opts...), nil
+}
+
+// backfillTicker pages through one ticker's trades ("" for all markets) and
+// queues them for the writer. Returns the number of trades fetched.
+func backfillTicker(ctx context.Context, client *api.Client, buf *router.GrowableBuffer[router.TradeMsg], ticker string, from, to time.Time, dryRun bool) (int64, error) {
+	opts := api.GetTradesOptions{
+		Limit:  1000,
+		Ticker: ticker,
+		MinTS:  from.Unix(),
+		MaxTS:  to.Unix(),
+	}
+
+	var fetched int64
+	for {
+		resp, err := client.GetTrades(ctx, opts)
+		if err != nil {
+			return fetched, err
+		}
+
+		receivedAt := time.Now()
+		for _, t := range resp.Trades {
+			fetched++
+			if !dryRun {
+				buf.Send(tradeMsg(t, receivedAt))
+			}
+		}
+
+		if resp.Cursor == "" {
+			return fetched, nil
+		}
+		opts.Cursor = resp.Cursor
+	}
+}
+
+// tradeMsg converts a REST trade to the router message the WebSocket
+// produces for it, so both land on the same (trade_id, exchange_ts) key.
+func tradeMsg(t api.APITrade, receivedAt time.Time) router.TradeMsg {
+	yes, no := t.YesPriceDollars, t.NoPriceDollars
+	if yes == "" {
+		yes = fmt.Sprintf("%.2f", float64(t.YesPrice)/100)
+	}
+	if no == "" {
+		no = fmt.Sprintf("%.2f", float64(t.NoPrice)/100)
+	}
+
+	// The WebSocket carries whole seconds
+	exchangeTs := t.Ts * 1_000_000
+	if t.Ts == 0 {
+		exchangeTs = api.ParseTimestamp(t.CreatedTime) / 1_000_000 * 1_000_000
+	}
+
+	return router.TradeMsg{
+		Ticker:          t.Ticker,
+		TradeID:         t.TradeID,
+		Size:            t.Count,
+		YesPriceDollars: yes,
+		NoPriceDollars:  no,
+		TakerSide:       t.TakerSide,
+		ExchangeTs:      exchangeTs,
+		ReceivedAt:      receivedAt,
+	}
+}
@@ -1,25 +1,215 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rickgao/kalshi-data/internal/config"
+	"github.com/rickgao/kalshi-data/internal/database"
+	"github.com/rickgao/kalshi-data/internal/dedup"
+	"github.com/rickgao/kalshi-data/internal/metrics"
+	"github.com/rickgao/kalshi-data/internal/version"
 )
 
 func main() {
 	configPath := flag.String("config", "/etc/kalshi/deduplicator.yaml", "path to config file")
 	flag.Parse()
 
-	fmt.Printf("Starting deduplicator with config: %s\n", *configPath)
+	// Set up structured logging
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
+	slog.SetDefault(logger)
+
+	logger.Info("starting deduplicator",
+		"version", version.Version,
+		"commit", version.Commit,
+		"config", *configPath,
+	)
+
+	// Load configuration
+	cfg, err := config.LoadDeduplicatorAndValidate(*configPath)
+	if err != nil {
+		logger.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	logger.Info("configuration loaded",
+		"instance_id", cfg.Instance.ID,
+		"sources", len(cfg.Sources),
+	)
+
+	// Create context with cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle shutdown signals
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigCh
+		logger.Info("received shutdown signal", "signal", sig)
+		cancel()
+	}()
+
+	// Prometheus metrics (components register as they are created)
+	metricsRegistry := metrics.NewRegistry()
+
+	// Connect to production
+	logger.Info("connecting to production database",
+		"host", cfg.Production.Host,
+		"port", cfg.Production.Port,
+		"database", cfg.Production.Name,
+	)
+	production, err := database.Connect(ctx, cfg.Production)
+	if err != nil {
+		logger.Error("failed to connect to production database", "error", err)
+		os.Exit(1)
+	}
+	defer production.Close()
+	metricsRegistry.RegisterPool("production", production)
+
+	// Connect to every gatherer. All must be reachable at startup; once
+	// running, an unreachable gatherer is skipped until it recovers.
+	sources := make([]dedup.Source, 0, len(cfg.Sources))
+	for _, src := range cfg.Sources {
+		logger.Info("connecting to gatherer database",
+			"gatherer", src.ID,
+			"host", src.Host,
+			"port", src.Port,
+			"database", src.Name,
+		)
+		pool, err := database.Connect(ctx, src.DBConfig)
+		if err != nil {
+			logger.Error("failed to connect to gatherer database", "gatherer", src.ID, "error", err)
+			os.Exit(1)
+		}
+		defer pool.Close()
+		metricsRegistry.RegisterPool(src.ID, pool)
+		sources = append(sources, dedup.Source{ID: src.ID, DB: pool})
+	}
+
+	logger.Info("databases connected")
+
+	if cfg.S3.Enabled {
+		logger.Warn("s3 export is not implemented yet, ignoring s3 config", "bucket", cfg.S3.Bucket)
+	}
+
+	// Create deduplicator
+	dedupCfg := dedup.DefaultConfig()
+	dedupCfg.PollInterval = cfg.Sync.PollInterval
+	dedupCfg.BatchSize = cfg.Sync.BatchSize
+	dedupCfg.Lookback = cfg.Sync.Lookback
+	dedupCfg.QueryTimeout = cfg.Sync.QueryTimeout
+
+	deduplicator := dedup.New(dedupCfg, sources, production, logger)
+	metricsRegistry.RegisterDeduplicator(deduplicator)
+
+	// Start health server
+	healthServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.Metrics.Port),
+		Handler: createHealthHandler(production, deduplicator, metricsRegistry, cfg.Metrics.Path),
+	}
+
+	go func() {
+		logger.Info("starting health server", "port", cfg.Metrics.Port, "metrics_path", cfg.Metrics.Path)
+		if err := healthServer.ListenAndServe(); err != http.ErrServerClosed {
+			logger.Error("health server error", "error", err)
+		}
+	}()
+
+	// Start syncing
+	if err := deduplicator.Start(ctx); err != nil {
+		logger.Error("failed to start deduplicator", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer shutdownCancel()
+		deduplicator.Stop(shutdownCtx)
+	}()
+
+	logger.Info("deduplicator running")
+
+	// Wait for shutdown
+	<-ctx.Done()
+
+	logger.Info("shutting down...")
+
+	// Graceful shutdown of health server
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	healthServer.Shutdown(shutdownCtx)
+
+	logger.Info("deduplicator stopped")
+}
+
+// createHealthHandler creates the HTTP handler for health checks.
+func createHealthHandler(production *pgxpool.Pool, deduplicator *dedup.Deduplicator, metricsRegistry *metrics.Registry, metricsPath string) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.Handle(metricsPath, metricsRegistry.Handler())
+
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		health := struct {
+			Status     string                 `json:"status"`
+			Components map[string]interface{} `json:"components"`
+		}{
+			Status:     "healthy",
+			Components: make(map[string]interface{}),
+		}
+
+		// Check production
+		if err := production.Ping(ctx); err != nil {
+			health.Status = "unhealthy"
+			health.Components["production"] = map[string]string{
+				"status": "disconnected",
+				"error":  err.Error(),
+			}
+		} else {
+			health.Components["production"] = "connected"
+		}
+
+		// Check gatherers: any one of them carries the data, so only
+		// losing all of them is unhealthy
+		stats := deduplicator.Stats()
+		healthy := 0
+		for id, src := range stats.Sources {
+			if src.Healthy {
+				healthy++
+				health.Components[id] = "connected"
+				continue
+			}
+			health.Components[id] = map[string]string{
+				"status": "disconnected",
+				"error":  src.LastError,
+			}
+		}
+		if healthy == 0 {
+			health.Status = "unhealthy"
+		} else if healthy < len(stats.Sources) && health.Status == "healthy" {
+			health.Status = "degraded"
+		}
 
-	// TODO: Initialize components
-	// - Load configuration
-	// - Connect to gatherer databases
-	// - Connect to production RDS
-	// - Start cursor-based sync polling
-	// - Start deduplication workers
-	// - Start S3 export (if configured)
-	// - Start metrics server
+		// Set response
+		w.Header().Set("Content-Type", "application/json")
+		if health.Status == "unhealthy" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(health)
+	})
 
-	os.Exit(0)
+	return mux
 }
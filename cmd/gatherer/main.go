@@ -9,15 +9,21 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
 	"github.com/rickgao/kalshi-data/internal/api"
 	"github.com/rickgao/kalshi-data/internal/auth"
+	"github.com/rickgao/kalshi-data/internal/book"
 	"github.com/rickgao/kalshi-data/internal/config"
 	"github.com/rickgao/kalshi-data/internal/connection"
 	"github.com/rickgao/kalshi-data/internal/database"
+	"github.com/rickgao/kalshi-data/internal/database/migrate"
+	"github.com/rickgao/kalshi-data/internal/journal"
 	"github.com/rickgao/kalshi-data/internal/market"
+	"github.com/rickgao/kalshi-data/internal/metrics"
+	"github.com/rickgao/kalshi-data/internal/poller"
 	"github.com/rickgao/kalshi-data/internal/router"
 	"github.com/rickgao/kalshi-data/internal/version"
 	"github.com/rickgao/kalshi-data/internal/writer"
@@ -80,6 +86,29 @@ func main() {
 
 	logger.Info("database connected")
 
+	// Schema migrations (advisory-locked, so gatherers starting together apply each once)
+	migrations, err := migrate.Migrations()
+	if err != nil {
+		logger.Error("failed to load migrations", "error", err)
+		os.Exit(1)
+	}
+	migrator := migrate.New(pools.Timescale, migrations, logger)
+	if cfg.Database.AutoMigrate {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			logger.Error("failed to migrate database", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("database schema current", "version", migrator.Latest(), "applied", applied)
+	} else if err := migrator.Check(ctx); err != nil {
+		logger.Error("database schema not current", "error", err, "latest", migrator.Latest())
+		os.Exit(1)
+	}
+
+	// Prometheus metrics (components register as they are created)
+	metricsRegistry := metrics.NewRegistry()
+	metricsRegistry.RegisterPool("timescaledb", pools.Timescale)
+
 	// Load API credentials
 	var privateKey *auth.Credentials
 	if cfg.API.PrivateKeyPath != "" {
@@ -92,7 +121,15 @@ func main() {
 		logger.Info("loaded API credentials", "key_id", cfg.API.APIKey)
 	}
 
-	// Create API client
+	// Create API client. The registry, poller and other REST callers share
+	// it, and with it one rate limiter per API key.
+	rateLimiter := api.NewRateLimiter(api.RateLimits{
+		ReadPerSecond:  cfg.API.RateLimit.ReadPerSecond,
+		WritePerSecond: cfg.API.RateLimit.WritePerSecond,
+		Burst:          cfg.API.RateLimit.Burst,
+	})
+	metricsRegistry.RegisterRateLimiter(rateLimiter)
+
 	var apiClient *api.Client
 	if privateKey != nil {
 		apiClient = api.NewClient(
@@ -102,6 +139,7 @@ func main() {
 			api.WithLogger(logger),
 			api.WithTimeout(30*time.Second),
 			api.WithRetries(3, time.Second),
+			api.WithRateLimiter(rateLimiter),
 		)
 	} else {
 		apiClient = api.NewClient(
@@ -111,6 +149,7 @@ func main() {
 			api.WithLogger(logger),
 			api.WithTimeout(30*time.Second),
 			api.WithRetries(3, time.Second),
+			api.WithRateLimiter(rateLimiter),
 		)
 	}
 
@@ -134,19 +173,23 @@ func main() {
 	}
 	registry := market.NewRegistry(registryCfg, apiClient, logger)
 
+	// Subscribe before the initial sync so its markets reach the metadata writer
+	metadataUpdates := registry.SubscribeMetadata()
+
 	// Start health server early so we can monitor sync progress
 	healthPort := 8080
 	if cfg.Metrics.Port > 0 {
 		healthPort = cfg.Metrics.Port
 	}
 
+	healthMux := createHealthHandler(pools, registry, metricsRegistry, cfg.Metrics.Path, logger)
 	healthServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", healthPort),
-		Handler: createHealthHandler(pools, registry, logger),
+		Handler: healthMux,
 	}
 
 	go func() {
-		logger.Info("starting health server", "port", healthPort)
+		logger.Info("starting health server", "port", healthPort, "metrics_path", cfg.Metrics.Path)
 		if err := healthServer.ListenAndServe(); err != http.ErrServerClosed {
 			logger.Error("health server error", "error", err)
 		}
@@ -173,21 +216,62 @@ func main() {
 	connMgrCfg := connection.DefaultManagerConfig()
 	connMgrCfg.WSURL = cfg.API.WSURL
 	connMgrCfg.KeyID = cfg.API.APIKey
+	connMgrCfg.GlobalConns = cfg.Connections.GlobalCount
+	connMgrCfg.OrderbookConns = cfg.Connections.OrderbookCount
+	connMgrCfg.MarketsPerConn = cfg.Connections.MarketsPerConnection
+	connMgrCfg.MaxConnRate = cfg.Connections.MaxMessageRate
+	connMgrCfg.RebalanceInterval = cfg.Connections.RebalanceInterval
+	connMgrCfg.OverflowPolicy = connection.OverflowPolicy(cfg.Connections.OverflowPolicy)
+	connMgrCfg.OverflowTimeout = cfg.Connections.OverflowTimeout
+	connMgrCfg.SpillDir = cfg.Connections.SpillDir
+	connMgrCfg.SpillMaxBytes = int64(cfg.Connections.SpillMaxMB) << 20
 	if privateKey != nil {
 		connMgrCfg.PrivateKey = privateKey.PrivateKey
 	}
 
 	connMgr := connection.NewManager(connMgrCfg, registry, logger)
+	metricsRegistry.RegisterManager(connMgr)
+	healthMux.Handle("/debug/subscriptions", createSubscriptionsHandler(connMgr))
 	defer func() {
 		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer shutdownCancel()
 		connMgr.Stop(shutdownCtx)
 	}()
 
+	// Start Journal (raw messages to local disk, between Connection Manager and Router)
+	routerInput := connMgr.Messages()
+	if cfg.Journal.Enabled {
+		journalCfg := journal.DefaultConfig()
+		journalCfg.Dir = cfg.Journal.Dir
+		journalCfg.Prefix = cfg.Instance.ID
+		journalCfg.MaxSegmentBytes = int64(cfg.Journal.MaxSegmentMB) << 20
+		journalCfg.MaxSegmentAge = cfg.Journal.MaxSegmentAge
+		journalCfg.FlushInterval = cfg.Journal.FlushInterval
+		journalCfg.Retention = cfg.Journal.Retention
+		journalCfg.BufferSize = connMgrCfg.MessageBufferSize
+
+		rawJournal := journal.New(journalCfg, connMgr.Messages(), logger)
+		metricsRegistry.RegisterJournal(rawJournal)
+
+		if err := rawJournal.Start(ctx); err != nil {
+			logger.Error("failed to start journal", "error", err)
+			os.Exit(1)
+		}
+		defer func() {
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer shutdownCancel()
+			rawJournal.Stop(shutdownCtx)
+		}()
+		routerInput = rawJournal.Messages()
+		logger.Info("journal started", "dir", journalCfg.Dir)
+	}
+
 	// Create and Start Message Router BEFORE Connection Manager
 	// (so it's ready to consume messages as soon as connections are established)
 	routerCfg := router.DefaultRouterConfig()
-	msgRouter := router.NewRouter(routerCfg, connMgr.Messages(), logger)
+	routerCfg.BookBufferSize = routerCfg.OrderbookBufferSize // Feed the book engine
+	msgRouter := router.NewRouter(routerCfg, routerInput, logger)
+	metricsRegistry.RegisterRouter(msgRouter)
 
 	logger.Info("starting message router...")
 	if err := msgRouter.Start(ctx); err != nil {
@@ -201,10 +285,34 @@ func main() {
 	}()
 	logger.Info("message router started")
 
+	// Create and Start Book Engine (in-memory L2 books from the orderbook stream)
+	bookEngine := book.NewEngine(msgRouter.Buffers().Book, registry, logger)
+	metricsRegistry.RegisterBookEngine(bookEngine)
+
+	if err := bookEngine.Start(ctx); err != nil {
+		logger.Error("failed to start book engine", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer shutdownCancel()
+		bookEngine.Stop(shutdownCtx)
+	}()
+	logger.Info("book engine started")
+
 	// Create and Start Writers BEFORE Connection Manager
 	writerCfg := writer.WriterConfig{
 		BatchSize:     cfg.Writers.BatchSize,
 		FlushInterval: cfg.Writers.FlushInterval,
+		InsertMode:    cfg.Writers.InsertMode,
+		Retry: writer.RetryConfig{
+			MaxAttempts:    cfg.Writers.Retry.MaxAttempts,
+			BaseDelay:      cfg.Writers.Retry.BaseDelay,
+			MaxDelay:       cfg.Writers.Retry.MaxDelay,
+			DeadLetterDir:  cfg.Writers.Retry.DeadLetterDir,
+			ReplayInterval: cfg.Writers.Retry.ReplayInterval,
+		},
+		Observer: metricsRegistry,
 	}
 	if writerCfg.BatchSize == 0 {
 		writerCfg.BatchSize = 1000
@@ -218,6 +326,16 @@ func main() {
 	tradeWriter := writer.NewTradeWriter(writerCfg, buffers.Trade, pools.Timescale, logger)
 	orderbookWriter := writer.NewOrderbookWriter(writerCfg, buffers.Orderbook, pools.Timescale, logger)
 	tickerWriter := writer.NewTickerWriter(writerCfg, buffers.Ticker, pools.Timescale, logger)
+	snapshotWriter := writer.NewSnapshotWriter(writerCfg, pools.Timescale, logger)
+	gapWriter := writer.NewGapWriter(writerCfg, connMgr.GapEvents(), pools.Timescale, logger)
+	metadataWriter := writer.NewMetadataWriter(writerCfg, metadataUpdates, apiClient, pools.Timescale, logger)
+
+	metricsRegistry.RegisterWriter("trade", tradeWriter)
+	metricsRegistry.RegisterWriter("ticker", tickerWriter)
+	metricsRegistry.RegisterWriter("snapshot", snapshotWriter)
+	metricsRegistry.RegisterWriter("gap", gapWriter)
+	metricsRegistry.RegisterWriter("metadata", metadataWriter)
+	metricsRegistry.RegisterOrderbookWriter(orderbookWriter)
 
 	logger.Info("starting writers...")
 	if err := tradeWriter.Start(ctx); err != nil {
@@ -249,6 +367,36 @@ func main() {
 		defer shutdownCancel()
 		tickerWriter.Stop(shutdownCtx)
 	}()
+
+	if err := snapshotWriter.Start(ctx); err != nil {
+		logger.Error("failed to start snapshot writer", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer shutdownCancel()
+		snapshotWriter.Stop(shutdownCtx)
+	}()
+
+	if err := gapWriter.Start(ctx); err != nil {
+		logger.Error("failed to start gap writer", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer shutdownCancel()
+		gapWriter.Stop(shutdownCtx)
+	}()
+
+	if err := metadataWriter.Start(ctx); err != nil {
+		logger.Error("failed to start metadata writer", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer shutdownCancel()
+		metadataWriter.Stop(shutdownCtx)
+	}()
 	logger.Info("writers started")
 
 	// NOW start Connection Manager (consumers are ready)
@@ -259,7 +407,91 @@ func main() {
 	}
 	logger.Info("connection manager started")
 
-	// TODO: Start Snapshot Poller (optional, for backup data)
+	// Start Snapshot Poller (REST backup snapshots, source='rest'). Each REST
+	// snapshot is cross-checked against the in-memory book before it is
+	// written; divergent markets are resubscribed.
+	crossCheckCfg := book.DefaultCrossCheckConfig()
+	crossCheckCfg.Tolerance = cfg.Poller.DivergenceTolerance
+
+	crossChecker := book.NewCrossChecker(crossCheckCfg, bookEngine, snapshotWriter, connMgr, logger)
+	metricsRegistry.RegisterCrossChecker(crossChecker)
+
+	pollerCfg := poller.DefaultConfig()
+	pollerCfg.Interval = cfg.Poller.Interval
+	pollerCfg.Concurrency = cfg.Poller.Concurrency
+
+	snapshotPoller := poller.New(pollerCfg, apiClient, registry, crossChecker, logger)
+
+	logger.Info("starting snapshot poller...")
+	if err := snapshotPoller.Start(ctx); err != nil {
+		logger.Error("failed to start snapshot poller", "error", err)
+		os.Exit(1)
+	}
+	// Registered after the snapshot writer's defer, so the poller stops first
+	// and the writer's final flush sees every snapshot.
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer shutdownCancel()
+		snapshotPoller.Stop(shutdownCtx)
+	}()
+	logger.Info("snapshot poller started")
+
+	// Start Derived Snapshotter (periodic snapshots from in-memory books, source='derived')
+	if cfg.Book.DerivedSnapshots.Enabled {
+		derivedCfg := book.DefaultDerivedConfig()
+		derivedCfg.Interval = cfg.Book.DerivedSnapshots.Interval
+		derivedCfg.MinChanges = cfg.Book.DerivedSnapshots.MinChanges
+
+		derivedSnapshotter := book.NewDerivedSnapshotter(derivedCfg, bookEngine, snapshotWriter, logger)
+		metricsRegistry.RegisterDerivedSnapshotter(derivedSnapshotter)
+
+		if err := derivedSnapshotter.Start(ctx); err != nil {
+			logger.Error("failed to start derived snapshotter", "error", err)
+			os.Exit(1)
+		}
+		// Stops before the snapshot writer, like the poller.
+		defer func() {
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer shutdownCancel()
+			derivedSnapshotter.Stop(shutdownCtx)
+		}()
+		logger.Info("derived snapshotter started")
+	}
+
+	// Start Candle Fetcher (exchange candlesticks, compared against the
+	// trade_candles_* continuous aggregates)
+	if cfg.Poller.Candles.Enabled {
+		candleWriter := writer.NewCandleWriter(writerCfg, pools.Timescale, logger)
+		metricsRegistry.RegisterWriter("candle", candleWriter)
+
+		if err := candleWriter.Start(ctx); err != nil {
+			logger.Error("failed to start candle writer", "error", err)
+			os.Exit(1)
+		}
+		defer func() {
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer shutdownCancel()
+			candleWriter.Stop(shutdownCtx)
+		}()
+
+		candleCfg := poller.DefaultCandleConfig()
+		candleCfg.Interval = cfg.Poller.Candles.Interval
+		candleCfg.PeriodMinutes = cfg.Poller.Candles.PeriodMinutes
+		candleCfg.Lookback = cfg.Poller.Candles.Lookback
+
+		candleFetcher := poller.NewCandleFetcher(candleCfg, apiClient, registry, candleWriter, logger)
+		if err := candleFetcher.Start(ctx); err != nil {
+			logger.Error("failed to start candle fetcher", "error", err)
+			os.Exit(1)
+		}
+		// Stops before the candle writer so its final flush sees every candle.
+		defer func() {
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer shutdownCancel()
+			candleFetcher.Stop(shutdownCtx)
+		}()
+		logger.Info("candle fetcher started")
+	}
 
 	logger.Info("gatherer running",
 		"instance_id", cfg.Instance.ID,
@@ -280,9 +512,11 @@ func main() {
 }
 
 // createHealthHandler creates the HTTP handler for health checks.
-func createHealthHandler(pools *database.Pools, registry market.Registry, logger *slog.Logger) http.Handler {
+func createHealthHandler(pools *database.Pools, registry market.Registry, metricsRegistry *metrics.Registry, metricsPath string, logger *slog.Logger) *http.ServeMux {
 	mux := http.NewServeMux()
 
+	mux.Handle(metricsPath, metricsRegistry.Handler())
+
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 		defer cancel()
@@ -342,3 +576,34 @@ func createHealthHandler(pools *database.Pools, registry market.Registry, logger
 
 	return mux
 }
+
+// createSubscriptionsHandler serves the Connection Manager's live
+// subscriptions, to audit which markets are actually subscribed. A market
+// query parameter narrows it to the subscription carrying that market.
+func createSubscriptionsHandler(connMgr connection.Manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subs := connMgr.Subscriptions()
+
+		markets := 0
+		for _, sub := range subs {
+			markets += len(sub.Markets)
+		}
+
+		if ticker := r.URL.Query().Get("market"); ticker != "" {
+			filtered := subs[:0]
+			for _, sub := range subs {
+				if slices.Contains(sub.Markets, ticker) {
+					filtered = append(filtered, sub)
+				}
+			}
+			subs = filtered
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"count":         len(subs),
+			"markets":       markets,
+			"subscriptions": subs,
+		})
+	})
+}
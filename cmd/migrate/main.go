@@ -0,0 +1,144 @@
+// migrate applies, rolls back and reports the gatherer's TimescaleDB schema
+// migrations.
+//
+// Usage:
+//
+//	go run ./cmd/migrate --config configs/local/gatherer.yaml up
+//	go run ./cmd/migrate --config configs/local/gatherer.yaml down [N]
+//	go run ./cmd/migrate --config configs/local/gatherer.yaml status
+//
+// down rolls back one migration unless N is given. Migrations run under the
+// same advisory lock the gatherer takes on startup, so running this against
+// a live database is safe.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/config"
+	"github.com/rickgao/kalshi-data/internal/database"
+	"github.com/rickgao/kalshi-data/internal/database/migrate"
+)
+
+func main() {
+	configPath := flag.String("config", "configs/gatherer.local.yaml", "path to gatherer config file")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: migrate [--config path] up | down [N] | status\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command := flag.Arg(0)
+
+	steps := 1
+	if command == "down" && flag.NArg() > 1 {
+		n, err := strconv.Atoi(flag.Arg(1))
+		if err != nil || n < 1 {
+			fmt.Fprintf(os.Stderr, "migrate: invalid step count %q\n", flag.Arg(1))
+			os.Exit(2)
+		}
+		steps = n
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+
+	cfg, err := config.LoadAndValidate(*configPath)
+	if err != nil {
+		logger.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	pools, err := database.NewPools(ctx, cfg.Database)
+	if err != nil {
+		logger.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer pools.Close()
+
+	migrations, err := migrate.Migrations()
+	if err != nil {
+		logger.Error("failed to load migrations", "error", err)
+		os.Exit(1)
+	}
+	migrator := migrate.New(pools.Timescale, migrations, logger)
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			logger.Error("migrate up failed", "error", err, "applied", applied)
+			os.Exit(1)
+		}
+		logger.Info("migrate up complete", "applied", applied, "version", migrator.Latest())
+
+	case "down":
+		rolledBack, err := migrator.Down(ctx, steps)
+		if err != nil {
+			logger.Error("migrate down failed", "error", err, "rolled_back", rolledBack)
+			os.Exit(1)
+		}
+		current, _ := migrator.Version(ctx)
+		logger.Info("migrate down complete", "rolled_back", rolledBack, "version", current)
+
+	case "status":
+		if err := printStatus(ctx, migrator); err != nil {
+			logger.Error("migrate status failed", "error", err)
+			os.Exit(1)
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// printStatus prints one line per known migration and the database version.
+func printStatus(ctx context.Context, m *migrate.Migrator) error {
+	current, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range statuses {
+		applied := "pending"
+		if s.Applied {
+			applied = "applied " + s.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Printf("%04d  %-32s  %s\n", s.Version, s.Name, applied)
+	}
+
+	fmt.Printf("\ndatabase version %d, latest %d", current, m.Latest())
+	if current > m.Latest() {
+		fmt.Print(" (database is newer than this binary)")
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,375 @@
+// replay reads raw WebSocket message journals and pushes them through the
+// Message Router into the writers, for backfilling after a parser or writer fix.
+//
+// Usage:
+//
+//	go run ./cmd/replay --dir /var/lib/kalshi-data/journal --dry-run
+//	go run ./cmd/replay --config configs/gatherer.local.yaml --dir journal/ \
+//	    --from 2025-01-15T12:00:00Z --to 2025-01-15T13:00:00Z --tickers KXBTC-25JAN-B100000
+//	go run ./cmd/replay --dry-run --speed 1 journal/gatherer-1-20250115T120000.000000000Z.jrnl.gz
+//
+// Writes go through the normal writers, whose inserts are ON CONFLICT DO
+// NOTHING, so replaying a range that was already written is safe.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/config"
+	"github.com/rickgao/kalshi-data/internal/connection"
+	"github.com/rickgao/kalshi-data/internal/database"
+	"github.com/rickgao/kalshi-data/internal/journal"
+	"github.com/rickgao/kalshi-data/internal/router"
+	"github.com/rickgao/kalshi-data/internal/writer"
+)
+
+func main() {
+	configPath := flag.String("config", "", "gatherer config with the target database (not needed with --dry-run)")
+	dir := flag.String("dir", "", "journal directory (or pass segment files as arguments)")
+	from := flag.String("from", "", "replay messages received at or after this RFC3339 time")
+	to := flag.String("to", "", "replay messages received before this RFC3339 time")
+	tickers := flag.String("tickers", "", "comma-separated market tickers to replay (default: all)")
+	speed := flag.Float64("speed", 0, "pacing: 0 = full speed, 1 = original wall-clock pacing, N = N times faster")
+	dryRun := flag.Bool("dry-run", false, "route messages but count them instead of writing")
+	verbose := flag.Bool("verbose", false, "print each routed message (dry-run only)")
+	maxPending := flag.Int("max-pending", 100000, "pause reading while this many routed messages await the writers")
+	flag.Parse()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+
+	if *dir == "" && flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "replay: --dir or segment files required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if !*dryRun && *configPath == "" {
+		fmt.Fprintln(os.Stderr, "replay: --config required unless --dry-run")
+		os.Exit(2)
+	}
+
+	fromTime, err := parseTime(*from)
+	if err != nil {
+		logger.Error("invalid --from", "error", err)
+		os.Exit(2)
+	}
+	toTime, err := parseTime(*to)
+	if err != nil {
+		logger.Error("invalid --to", "error", err)
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigCh
+		logger.Info("received shutdown signal", "signal", sig)
+		cancel()
+	}()
+
+	// Open journal
+	var reader *journal.Reader
+	if flag.NArg() > 0 {
+		reader = journal.NewReader(flag.Args()...)
+	} else {
+		reader, err = journal.Open(*dir, fromTime, toTime)
+		if err != nil {
+			logger.Error("failed to open journal", "error", err)
+			os.Exit(1)
+		}
+	}
+	defer reader.Close()
+
+	logger.Info("replay starting",
+		"segments", len(reader.Segments()),
+		"from", *from,
+		"to", *to,
+		"speed", *speed,
+		"dry_run", *dryRun,
+	)
+
+	// Router fed from the journal
+	input := make(chan connection.RawMessage, 1000)
+	msgRouter := router.NewRouter(router.DefaultRouterConfig(), input, logger)
+	if err := msgRouter.Start(ctx); err != nil {
+		logger.Error("failed to start message router", "error", err)
+		os.Exit(1)
+	}
+	buffers := msgRouter.Buffers()
+
+	// Sink: real writers or dry-run counters
+	var sink replaySink
+	if *dryRun {
+		sink = newDrySink(buffers, *verbose)
+	} else {
+		cfg, err := config.LoadAndValidate(*configPath)
+		if err != nil {
+			logger.Error("failed to load config", "error", err)
+			os.Exit(1)
+		}
+
+		pools, err := database.NewPools(ctx, cfg.Database)
+		if err != nil {
+			logger.Error("failed to connect to database", "error", err)
+			os.Exit(1)
+		}
+		defer pools.Close()
+
+		writerCfg := writer.WriterConfig{
+			BatchSize:     cfg.Writers.BatchSize,
+			FlushInterval: cfg.Writers.FlushInterval,
+			InsertMode:    cfg.Writers.InsertMode,
+			// Failed batches are reported, not spilled: rerunning the
+			// replay is the recovery path.
+			Retry: writer.RetryConfig{
+				MaxAttempts: cfg.Writers.Retry.MaxAttempts,
+				BaseDelay:   cfg.Writers.Retry.BaseDelay,
+				MaxDelay:    cfg.Writers.Retry.MaxDelay,
+			},
+		}
+		sink = newWriterSink(writerCfg, buffers, pools, logger)
+	}
+
+	if err := sink.Start(ctx); err != nil {
+		logger.Error("failed to start sink", "error", err)
+		os.Exit(1)
+	}
+
+	opts := journal.ReplayOptions{
+		Speed: *speed,
+		Throttle: func(ctx context.Context) error {
+			for pending(buffers) > *maxPending {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+			return nil
+		},
+	}
+	if *tickers != "" {
+		opts.Tickers = make(map[string]struct{})
+		for _, t := range strings.Split(*tickers, ",") {
+			opts.Tickers[strings.TrimSpace(t)] = struct{}{}
+		}
+	}
+
+	start := time.Now()
+	stats, replayErr := journal.Replay(ctx, reader, input, opts)
+	close(input)
+	if replayErr != nil && ctx.Err() == nil {
+		logger.Error("replay failed", "error", replayErr)
+	}
+
+	// Let the router and sink catch up before stopping them
+	if err := waitDrained(ctx, msgRouter, stats.Sent); err != nil {
+		logger.Warn("stopped before all messages were written", "error", err)
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+	sink.Stop(shutdownCtx)
+	msgRouter.Stop(shutdownCtx)
+
+	routerStats := msgRouter.Stats()
+	logger.Info("replay complete",
+		"duration", time.Since(start),
+		"read", stats.Read,
+		"sent", stats.Sent,
+		"filtered", stats.Filtered,
+		"truncated_segments", reader.Truncated(),
+		"routed", routerStats.MessagesRouted,
+		"parse_errors", routerStats.ParseErrors,
+		"unknown_messages", routerStats.UnknownMessages,
+	)
+	sink.Report(logger)
+
+	if replayErr != nil && ctx.Err() == nil {
+		os.Exit(1)
+	}
+}
+
+// parseTime parses an RFC3339 time; "" is the zero time.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// pending returns routed messages not yet taken by the sink.
+func pending(b router.RouterBuffers) int {
+	return b.Orderbook.Len() + b.Trade.Len() + b.Ticker.Len()
+}
+
+// waitDrained waits until the router has read all sent messages and the
+// sink has taken everything it routed.
+func waitDrained(ctx context.Context, r router.Router, sent int64) error {
+	for {
+		if r.Stats().MessagesReceived >= sent && pending(r.Buffers()) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// replaySink consumes the router's buffers.
+type replaySink interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+	Report(logger *slog.Logger)
+}
+
+// writerSink writes through the gatherer's writers.
+type writerSink struct {
+	trade     *writer.TradeWriter
+	ticker    *writer.TickerWriter
+	orderbook *writer.OrderbookWriter
+}
+
+func newWriterSink(cfg writer.WriterConfig, b router.RouterBuffers, pools *database.Pools, logger *slog.Logger) *writerSink {
+	return &writerSink{
+		trade:     writer.NewTradeWriter(cfg, b.Trade, pools.Timescale, logger),
+		ticker:    writer.NewTickerWriter(cfg, b.Ticker, pools.Timescale, logger),
+		orderbook: writer.NewOrderbookWriter(cfg, b.Orderbook, pools.Timescale, logger),
+	}
+}
+
+func (s *writerSink) Start(ctx context.Context) error {
+	if err := s.trade.Start(ctx); err != nil {
+		return fmt.Errorf("trade writer: %w", err)
+	}
+	if err := s.ticker.Start(ctx); err != nil {
+		return fmt.Errorf("ticker writer: %w", err)
+	}
+	if err := s.orderbook.Start(ctx); err != nil {
+		return fmt.Errorf("orderbook writer: %w", err)
+	}
+	return nil
+}
+
+// Stop stops the writers; each flushes its final batch.
+func (s *writerSink) Stop(ctx context.Context) error {
+	s.trade.Stop(ctx)
+	s.ticker.Stop(ctx)
+	s.orderbook.Stop(ctx)
+	return nil
+}
+
+func (s *writerSink) Report(logger *slog.Logger) {
+	trade := s.trade.Stats()
+	ticker := s.ticker.Stats()
+	ob := s.orderbook.Stats()
+	logger.Info("writer results",
+		"trade_inserts", trade.Inserts,
+		"trade_conflicts", trade.Conflicts,
+		"trade_errors", trade.Errors,
+		"ticker_inserts", ticker.Inserts,
+		"ticker_conflicts", ticker.Conflicts,
+		"ticker_errors", ticker.Errors,
+		"delta_inserts", ob.DeltaInserts,
+		"delta_conflicts", ob.DeltaConflicts,
+		"delta_errors", ob.DeltaErrors,
+		"snapshot_inserts", ob.SnapshotInserts,
+		"snapshot_conflicts", ob.SnapshotConflicts,
+		"snapshot_errors", ob.SnapshotErrors,
+	)
+}
+
+// drySink counts routed messages instead of writing them.
+type drySink struct {
+	buffers router.RouterBuffers
+	verbose bool
+
+	deltas, snapshots, trades, tickers atomic.Int64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newDrySink(b router.RouterBuffers, verbose bool) *drySink {
+	return &drySink{buffers: b, verbose: verbose}
+}
+
+func (s *drySink) Start(ctx context.Context) error {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(3)
+	go drain(ctx, &s.wg, s.buffers.Orderbook, func(m router.OrderbookMsg) {
+		if m.Type == "delta" {
+			s.deltas.Add(1)
+		} else {
+			s.snapshots.Add(1)
+		}
+		if s.verbose {
+			fmt.Printf("[ORDERBOOK %s] ticker=%s sid=%d seq=%d\n", strings.ToUpper(m.Type), m.Ticker, m.SID, m.Seq)
+		}
+	})
+	go drain(ctx, &s.wg, s.buffers.Trade, func(m router.TradeMsg) {
+		s.trades.Add(1)
+		if s.verbose {
+			fmt.Printf("[TRADE] ticker=%s id=%s size=%d yes_price=%s\n", m.Ticker, m.TradeID, m.Size, m.YesPriceDollars)
+		}
+	})
+	go drain(ctx, &s.wg, s.buffers.Ticker, func(m router.TickerMsg) {
+		s.tickers.Add(1)
+		if s.verbose {
+			fmt.Printf("[TICKER] ticker=%s price=%s bid=%s ask=%s\n", m.Ticker, m.PriceDollars, m.YesBidDollars, m.YesAskDollars)
+		}
+	})
+	return nil
+}
+
+func (s *drySink) Stop(ctx context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+func (s *drySink) Report(logger *slog.Logger) {
+	logger.Info("dry-run results",
+		"orderbook_deltas", s.deltas.Load(),
+		"orderbook_snapshots", s.snapshots.Load(),
+		"trades", s.trades.Load(),
+		"tickers", s.tickers.Load(),
+	)
+}
+
+// drain consumes buf until ctx is done.
+func drain[T any](ctx context.Context, wg *sync.WaitGroup, buf *router.GrowableBuffer[T], handle func(T)) {
+	defer wg.Done()
+
+	for {
+		msg, ok := buf.TryReceive()
+		if ok {
+			handle(msg)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -1,6 +1,9 @@
 // streamtest connects to Kalshi WebSocket and streams parsed messages to console.
 // Usage: go run ./cmd/streamtest --config configs/gatherer.local.yaml
 //
+// With --journal DIR the raw messages are also journaled, so a session can be
+// fed back through the writers with cmd/replay.
+//
 // Required environment variables:
 //
 //	KALSHI_API_KEY         - Your API key ID from Kalshi dashboard
@@ -22,6 +25,7 @@ import (
 	"github.com/rickgao/kalshi-data/internal/auth"
 	"github.com/rickgao/kalshi-data/internal/config"
 	"github.com/rickgao/kalshi-data/internal/connection"
+	"github.com/rickgao/kalshi-data/internal/journal"
 	"github.com/rickgao/kalshi-data/internal/market"
 	"github.com/rickgao/kalshi-data/internal/router"
 )
@@ -29,6 +33,7 @@ import (
 func main() {
 	configPath := flag.String("config", "configs/gatherer.example.yaml", "path to config file")
 	verbose := flag.Bool("verbose", false, "print full message JSON")
+	journalDir := flag.String("journal", "", "also journal raw messages to this directory for cmd/replay")
 	flag.Parse()
 
 	// Setup logger
@@ -108,15 +113,36 @@ func main() {
 	connCfg.KeyID = privateKey.KeyID
 	connCfg.PrivateKey = privateKey.PrivateKey
 	connCfg.MessageBufferSize = 10000
+	connCfg.GlobalConns = cfg.Connections.GlobalCount
+	connCfg.OrderbookConns = cfg.Connections.OrderbookCount
+	connCfg.MarketsPerConn = cfg.Connections.MarketsPerConnection
+	connCfg.MaxConnRate = cfg.Connections.MaxMessageRate
+	connCfg.RebalanceInterval = cfg.Connections.RebalanceInterval
+	connCfg.OverflowPolicy = connection.OverflowPolicy(cfg.Connections.OverflowPolicy)
+	connCfg.OverflowTimeout = cfg.Connections.OverflowTimeout
+	connCfg.SpillDir = cfg.Connections.SpillDir
+	connCfg.SpillMaxBytes = int64(cfg.Connections.SpillMaxMB) << 20
 
 	connMgr := connection.NewManager(connCfg, registry, logger)
 
+	// Optionally journal raw messages between Connection Manager and Router
+	routerInput := connMgr.Messages()
+	var rawJournal *journal.Journal
+	if *journalDir != "" {
+		journalCfg := journal.DefaultConfig()
+		journalCfg.Dir = *journalDir
+		journalCfg.Prefix = "streamtest"
+		journalCfg.BufferSize = connCfg.MessageBufferSize
+		rawJournal = journal.New(journalCfg, routerInput, logger)
+		routerInput = rawJournal.Messages()
+	}
+
 	// Create Router using Connection Manager's message channel
 	rtr := router.NewRouter(router.RouterConfig{
 		OrderbookBufferSize: 1000,
 		TradeBufferSize:     1000,
 		TickerBufferSize:    1000,
-	}, connMgr.Messages(), logger)
+	}, routerInput, logger)
 
 	// Start Connection Manager (will auto-subscribe to active markets)
 	logger.Info("starting connection manager")
@@ -125,6 +151,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	if rawJournal != nil {
+		if err := rawJournal.Start(ctx); err != nil {
+			logger.Error("failed to start journal", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	// Start Router
 	logger.Info("starting router")
 	if err := rtr.Start(ctx); err != nil {
@@ -177,6 +210,9 @@ func main() {
 	logger.Info("shutting down...")
 	rtr.Stop(shutdownCtx)
 	connMgr.Stop(shutdownCtx)
+	if rawJournal != nil {
+		rawJournal.Stop(shutdownCtx)
+	}
 	registry.Stop(shutdownCtx)
 
 	logger.Info("shutdown complete")
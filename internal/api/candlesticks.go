@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Batch candlestick request limits.
+const (
+	MaxBatchCandlestickTickers = 100   // Tickers per request
+	MaxBatchCandlesticks       = 10000 // Candlesticks per response, across all tickers
+)
+
+// GetCandlesticks fetches a page of candlesticks for one market.
+func (c *Client) GetCandlesticks(ctx context.Context, seriesTicker, ticker string, opts GetCandlesticksOptions) (*CandlesticksResponse, error) {
+	query := url.Values{}
+	query.Set("period_interval", strconv.Itoa(opts.PeriodInterval))
+
+	if opts.StartTS > 0 {
+		query.Set("start_ts", strconv.FormatInt(opts.StartTS, 10))
+	}
+	if opts.EndTS > 0 {
+		query.Set("end_ts", strconv.FormatInt(opts.EndTS, 10))
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
+	}
+
+	var resp CandlesticksResponse
+	path := "/series/" + seriesTicker + "/markets/" + ticker + "/candlesticks"
+	if err := c.get(ctx, path, query, &resp); err != nil {
+		return nil, fmt.Errorf("get candlesticks %s: %w", ticker, err)
+	}
+
+	return &resp, nil
+}
+
+// BatchGetCandlesticks fetches candlesticks for up to
+// MaxBatchCandlestickTickers markets in one request.
+func (c *Client) BatchGetCandlesticks(ctx context.Context, opts BatchCandlesticksOptions) (*BatchCandlesticksResponse, error) {
+	if len(opts.Tickers) == 0 || len(opts.Tickers) > MaxBatchCandlestickTickers {
+		return nil, fmt.Errorf("batch get candlesticks: %d tickers, want 1-%d", len(opts.Tickers), MaxBatchCandlestickTickers)
+	}
+
+	query := url.Values{}
+	query.Set("tickers", strings.Join(opts.Tickers, ","))
+	query.Set("period_interval", strconv.Itoa(opts.PeriodInterval))
+
+	if opts.StartTS > 0 {
+		query.Set("start_ts", strconv.FormatInt(opts.StartTS, 10))
+	}
+	if opts.EndTS > 0 {
+		query.Set("end_ts", strconv.FormatInt(opts.EndTS, 10))
+	}
+	if opts.IncludeLatestBeforeStart {
+		query.Set("include_latest_before_start", "true")
+	}
+
+	var resp BatchCandlesticksResponse
+	if err := c.get(ctx, "/markets/candlesticks", query, &resp); err != nil {
+		return nil, fmt.Errorf("batch get candlesticks: %w", err)
+	}
+
+	return &resp, nil
+}
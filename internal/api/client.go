@@ -17,6 +17,7 @@ type Client struct {
 
 	maxRetries   int
 	retryBackoff time.Duration
+	limiter      *RateLimiter // nil means unlimited
 }
 
 // ClientOption configures a Client.
@@ -60,6 +61,14 @@ func WithRetries(max int, backoff time.Duration) ClientOption {
 	}
 }
 
+// WithRateLimiter throttles requests through l. Pass the same limiter to
+// every client that shares an API key.
+func WithRateLimiter(l *RateLimiter) ClientOption {
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
 // WithLogger sets the logger.
 func WithLogger(logger *slog.Logger) ClientOption {
 	return func(c *Client) {
@@ -17,13 +17,13 @@ import (
 // TestNewClient tests client construction with various options.
 func TestNewClient(t *testing.T) {
 	t.Run("default values", func(t *testing.T) {
-		c := NewClient("https://api.example.com", "test-key")
+		c := NewClient("https://api.example.com", "test-key", nil)
 
 		if c.baseURL != "https://api.example.com" {
 			t.Errorf("baseURL = %q, want %q", c.baseURL, "https://api.example.com")
 		}
-		if c.apiKey != "test-key" {
-			t.Errorf("apiKey = %q, want %q", c.apiKey, "test-key")
+		if c.keyID != "test-key" {
+			t.Errorf("keyID = %q, want %q", c.keyID, "test-key")
 		}
 		if c.httpClient.Timeout != 30*time.Second {
 			t.Errorf("Timeout = %v, want %v", c.httpClient.Timeout, 30*time.Second)
@@ -40,14 +40,14 @@ func TestNewClient(t *testing.T) {
 	})
 
 	t.Run("with timeout option", func(t *testing.T) {
-		c := NewClient("https://api.example.com", "", WithTimeout(5*time.Second))
+		c := NewClient("https://api.example.com", "", nil, WithTimeout(5*time.Second))
 		if c.httpClient.Timeout != 5*time.Second {
 			t.Errorf("Timeout = %v, want %v", c.httpClient.Timeout, 5*time.Second)
 		}
 	})
 
 	t.Run("with retries option", func(t *testing.T) {
-		c := NewClient("https://api.example.com", "", WithRetries(5, 2*time.Second))
+		c := NewClient("https://api.example.com", "", nil, WithRetries(5, 2*time.Second))
 		if c.maxRetries != 5 {
 			t.Errorf("maxRetries = %d, want %d", c.maxRetries, 5)
 		}
@@ -58,7 +58,7 @@ func TestNewClient(t *testing.T) {
 
 	t.Run("with logger option", func(t *testing.T) {
 		logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
-		c := NewClient("https://api.example.com", "", WithLogger(logger))
+		c := NewClient("https://api.example.com", "", nil, WithLogger(logger))
 		if c.logger != logger {
 			t.Error("logger not set correctly")
 		}
@@ -66,7 +66,7 @@ func TestNewClient(t *testing.T) {
 
 	t.Run("with custom HTTP client", func(t *testing.T) {
 		customClient := &http.Client{Timeout: 10 * time.Second}
-		c := NewClient("https://api.example.com", "", WithHTTPClient(customClient))
+		c := NewClient("https://api.example.com", "", nil, WithHTTPClient(customClient))
 		if c.httpClient != customClient {
 			t.Error("custom HTTP client not set")
 		}
@@ -74,7 +74,7 @@ func TestNewClient(t *testing.T) {
 
 	t.Run("with multiple options", func(t *testing.T) {
 		logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
-		c := NewClient("https://api.example.com", "key",
+		c := NewClient("https://api.example.com", "key", nil,
 			WithTimeout(15*time.Second),
 			WithRetries(10, 500*time.Millisecond),
 			WithLogger(logger),
@@ -94,9 +94,9 @@ func TestNewClient(t *testing.T) {
 	})
 
 	t.Run("empty API key", func(t *testing.T) {
-		c := NewClient("https://api.example.com", "")
-		if c.apiKey != "" {
-			t.Errorf("apiKey = %q, want empty", c.apiKey)
+		c := NewClient("https://api.example.com", "", nil)
+		if c.keyID != "" {
+			t.Errorf("keyID = %q, want empty", c.keyID)
 		}
 	})
 }
@@ -149,15 +149,16 @@ func TestDoRequest(t *testing.T) {
 			if r.Header.Get("Accept") != "application/json" {
 				t.Errorf("Accept header = %q, want %q", r.Header.Get("Accept"), "application/json")
 			}
-			if r.Header.Get("Authorization") != "Bearer test-key" {
-				t.Errorf("Authorization header = %q, want %q", r.Header.Get("Authorization"), "Bearer test-key")
+			// No private key configured, so no signed auth headers.
+			if r.Header.Get("KALSHI-ACCESS-KEY") != "" {
+				t.Errorf("KALSHI-ACCESS-KEY header = %q, want empty", r.Header.Get("KALSHI-ACCESS-KEY"))
 			}
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte(`{"status": "ok"}`))
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "test-key")
+		c := NewClient(server.URL, "test-key", nil)
 		body, err := c.doRequest(context.Background(), http.MethodGet, "/test", nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -177,7 +178,7 @@ func TestDoRequest(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "")
+		c := NewClient(server.URL, "", nil)
 		_, err := c.doRequest(context.Background(), http.MethodGet, "/test", nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -197,7 +198,7 @@ func TestDoRequest(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		query := make(map[string][]string)
 		query["limit"] = []string{"10"}
 		query["cursor"] = []string{"abc123"}
@@ -214,7 +215,7 @@ func TestDoRequest(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		_, err := c.doRequest(context.Background(), http.MethodGet, "/test", nil)
 		if err == nil {
 			t.Fatal("expected error, got nil")
@@ -239,7 +240,7 @@ func TestDoRequest(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		_, err := c.doRequest(context.Background(), http.MethodGet, "/test", nil)
 		if err == nil {
 			t.Fatal("expected error, got nil")
@@ -261,7 +262,7 @@ func TestDoRequest(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel() // Cancel immediately
 
@@ -286,7 +287,7 @@ func TestDoWithRetry(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key", WithRetries(3, 10*time.Millisecond))
+		c := NewClient(server.URL, "key", nil, WithRetries(3, 10*time.Millisecond))
 		body, err := c.doWithRetry(context.Background(), http.MethodGet, "/test", nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -313,7 +314,7 @@ func TestDoWithRetry(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key", WithRetries(3, 10*time.Millisecond))
+		c := NewClient(server.URL, "key", nil, WithRetries(3, 10*time.Millisecond))
 		body, err := c.doWithRetry(context.Background(), http.MethodGet, "/test", nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -340,7 +341,7 @@ func TestDoWithRetry(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key", WithRetries(3, 10*time.Millisecond))
+		c := NewClient(server.URL, "key", nil, WithRetries(3, 10*time.Millisecond))
 		_, err := c.doWithRetry(context.Background(), http.MethodGet, "/test", nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -350,6 +351,37 @@ func TestDoWithRetry(t *testing.T) {
 		}
 	})
 
+	t.Run("honours Retry-After and pauses the limiter", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&attempts, 1)
+			if n == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"ok": true}`))
+		}))
+		defer server.Close()
+
+		limiter := NewRateLimiter(DefaultRateLimits())
+		c := NewClient(server.URL, "key", nil, WithRetries(3, 10*time.Millisecond), WithRateLimiter(limiter))
+
+		start := time.Now()
+		if _, err := c.doWithRetry(context.Background(), http.MethodGet, "/test", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < time.Second {
+			t.Errorf("retried after %v, want >= 1s", elapsed)
+		}
+
+		s := limiter.Stats()
+		if s.RateLimited != 1 || s.Pauses != 1 {
+			t.Errorf("RateLimited, Pauses = %d, %d, want 1, 1", s.RateLimited, s.Pauses)
+		}
+	})
+
 	t.Run("does not retry on 4xx (except 429)", func(t *testing.T) {
 		var attempts int32
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -359,7 +391,7 @@ func TestDoWithRetry(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key", WithRetries(3, 10*time.Millisecond))
+		c := NewClient(server.URL, "key", nil, WithRetries(3, 10*time.Millisecond))
 		_, err := c.doWithRetry(context.Background(), http.MethodGet, "/test", nil)
 		if err == nil {
 			t.Fatal("expected error, got nil")
@@ -378,7 +410,7 @@ func TestDoWithRetry(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key", WithRetries(2, 10*time.Millisecond))
+		c := NewClient(server.URL, "key", nil, WithRetries(2, 10*time.Millisecond))
 		_, err := c.doWithRetry(context.Background(), http.MethodGet, "/test", nil)
 		if err == nil {
 			t.Fatal("expected error, got nil")
@@ -400,7 +432,7 @@ func TestDoWithRetry(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key", WithRetries(5, 50*time.Millisecond))
+		c := NewClient(server.URL, "key", nil, WithRetries(5, 50*time.Millisecond))
 		ctx, cancel := context.WithTimeout(context.Background(), 80*time.Millisecond)
 		defer cancel()
 
@@ -429,7 +461,7 @@ func TestGetExchangeStatus(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		status, err := c.GetExchangeStatus(context.Background())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -453,7 +485,7 @@ func TestGetExchangeStatus(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		status, err := c.GetExchangeStatus(context.Background())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -472,7 +504,7 @@ func TestGetExchangeStatus(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key", WithRetries(0, time.Millisecond))
+		c := NewClient(server.URL, "key", nil, WithRetries(0, time.Millisecond))
 		_, err := c.GetExchangeStatus(context.Background())
 		if err == nil {
 			t.Fatal("expected error, got nil")
@@ -480,6 +512,63 @@ func TestGetExchangeStatus(t *testing.T) {
 	})
 }
 
+func TestGetExchangeSchedule(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/exchange/schedule" {
+			t.Errorf("path = %q, want /exchange/schedule", r.URL.Path)
+		}
+		w.Write([]byte(`{"schedule": {
+			"standard_hours": {"timezone": "America/New_York", "days": {"monday": {"open": "00:00", "close": "23:59"}}},
+			"closures": [],
+			"maintenance_windows": [{"start_datetime": "2024-01-18T08:00:00Z", "end_datetime": "2024-01-18T10:00:00Z"}]
+		}}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "key", nil)
+	resp, err := c.GetExchangeSchedule(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Schedule.StandardHours.Days["monday"].Close != "23:59" {
+		t.Errorf("standard hours = %+v", resp.Schedule.StandardHours)
+	}
+	if len(resp.Schedule.MaintenanceWindows) != 1 || resp.Schedule.MaintenanceWindows[0].EndDatetime != "2024-01-18T10:00:00Z" {
+		t.Errorf("maintenance windows = %+v", resp.Schedule.MaintenanceWindows)
+	}
+}
+
+func TestGetAnnouncements(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/exchange/announcements" {
+			t.Errorf("path = %q, want /exchange/announcements", r.URL.Path)
+		}
+		if q := r.URL.Query(); q.Get("limit") != "100" || q.Get("cursor") != "abc" {
+			t.Errorf("query = %v, want limit and cursor", q)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"announcements": []map[string]any{{
+				"id":            "ann-1",
+				"title":         "Scheduled maintenance",
+				"type":          "maintenance",
+				"status":        "active",
+				"delivery_time": "2024-01-15T12:00:00Z",
+			}},
+			"cursor": "",
+		})
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "key", nil)
+	resp, err := c.GetAnnouncements(context.Background(), GetAnnouncementsOptions{Limit: 100, Cursor: "abc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Announcements) != 1 || resp.Announcements[0].Type != "maintenance" {
+		t.Errorf("announcements = %+v", resp.Announcements)
+	}
+}
+
 // TestGetMarkets tests the GetMarkets method.
 func TestGetMarkets(t *testing.T) {
 	t.Run("basic request", func(t *testing.T) {
@@ -498,7 +587,7 @@ func TestGetMarkets(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		resp, err := c.GetMarkets(context.Background(), GetMarketsOptions{})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -537,7 +626,7 @@ func TestGetMarkets(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		_, err := c.GetMarkets(context.Background(), GetMarketsOptions{
 			Limit:        100,
 			Cursor:       "cursor123",
@@ -561,7 +650,7 @@ func TestGetMarkets(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		resp, err := c.GetMarkets(context.Background(), GetMarketsOptions{})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -584,7 +673,7 @@ func TestGetAllMarkets(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		markets, err := c.GetAllMarkets(context.Background())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -622,7 +711,7 @@ func TestGetAllMarkets(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		markets, err := c.GetAllMarkets(context.Background())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -641,7 +730,7 @@ func TestGetAllMarkets(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		// Pass context without deadline - should apply DefaultPaginationTimeout
 		ctx := context.Background()
 		_, err := c.GetAllMarkets(ctx)
@@ -659,7 +748,7 @@ func TestGetAllMarkets(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
 		defer cancel()
 
@@ -671,7 +760,191 @@ func TestGetAllMarkets(t *testing.T) {
 	})
 }
 
+// TestGetTrades tests the GetTrades method.
+func TestGetTrades(t *testing.T) {
+	t.Run("with filters", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/markets/trades" {
+				t.Errorf("path = %q, want /markets/trades", r.URL.Path)
+			}
+			q := r.URL.Query()
+			if q.Get("ticker") != "MKT1" || q.Get("min_ts") != "1705320000" || q.Get("max_ts") != "1705323600" {
+				t.Errorf("query = %v, want ticker and min/max_ts", q)
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"trades": []map[string]any{{
+					"trade_id":          "d91bc706-ee49-470d-82d8-11418bda6fed",
+					"ticker":            "MKT1",
+					"count":             10,
+					"yes_price":         52,
+					"yes_price_dollars": "0.5200",
+					"taker_side":        "yes",
+					"ts":                1705320500,
+				}},
+				"cursor": "next",
+			})
+		}))
+		defer server.Close()
+
+		c := NewClient(server.URL, "key", nil)
+		resp, err := c.GetTrades(context.Background(), GetTradesOptions{
+			Ticker: "MKT1",
+			MinTS:  1705320000,
+			MaxTS:  1705323600,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Trades) != 1 || resp.Cursor != "next" {
+			t.Fatalf("resp = %+v, want one trade and cursor", resp)
+		}
+		tr := resp.Trades[0]
+		if tr.Count != 10 || tr.YesPriceDollars != "0.5200" || tr.TakerSide != "yes" || tr.Ts != 1705320500 {
+			t.Errorf("trade = %+v", tr)
+		}
+	})
+
+	t.Run("omits open bounds", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			if q.Has("ticker") || q.Has("min_ts") || q.Has("max_ts") {
+				t.Errorf("query = %v, want no filters", q)
+			}
+			json.NewEncoder(w).Encode(TradesResponse{})
+		}))
+		defer server.Close()
+
+		c := NewClient(server.URL, "key", nil)
+		if _, err := c.GetTrades(context.Background(), GetTradesOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+// TestGetAllTrades tests pagination through trades.
+func TestGetAllTrades(t *testing.T) {
+	var requestCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requestCount, 1)
+		q := r.URL.Query()
+		if q.Get("ticker") != "MKT1" || q.Get("limit") != "1000" {
+			t.Errorf("query = %v, want ticker filter and max page size", q)
+		}
+		if q.Get("cursor") == "" {
+			json.NewEncoder(w).Encode(TradesResponse{
+				Trades: []APITrade{{TradeID: "a"}, {TradeID: "b"}},
+				Cursor: "page2",
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(TradesResponse{Trades: []APITrade{{TradeID: "c"}}})
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "key", nil)
+	trades, err := c.GetAllTrades(context.Background(), GetTradesOptions{Ticker: "MKT1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trades) != 3 {
+		t.Errorf("len(trades) = %d, want 3", len(trades))
+	}
+	if requestCount != 2 {
+		t.Errorf("requestCount = %d, want 2", requestCount)
+	}
+}
+
 // TestGetAllMarketsWithOptions tests filtered pagination.
+func TestGetCandlesticks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/series/KXBTC/markets/MKT1/candlesticks" {
+			t.Errorf("path = %q, want /series/KXBTC/markets/MKT1/candlesticks", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("period_interval") != "60" || q.Get("start_ts") != "1705320000" || q.Has("end_ts") {
+			t.Errorf("query = %v, want period_interval and start_ts only", q)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"candlesticks": []map[string]any{{
+				"ticker":          "MKT1",
+				"period_interval": 60,
+				"open_ts":         1705320000,
+				"close_ts":        1705323600,
+				"open":            52,
+				"close_dollars":   "0.5525",
+				"volume":          1200,
+			}},
+			"cursor": "next",
+		})
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "key", nil)
+	resp, err := c.GetCandlesticks(context.Background(), "KXBTC", "MKT1", GetCandlesticksOptions{
+		PeriodInterval: PeriodHour,
+		StartTS:        1705320000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Candlesticks) != 1 || resp.Cursor != "next" {
+		t.Fatalf("resp = %+v, want one candlestick and cursor", resp)
+	}
+	cs := resp.Candlesticks[0]
+	if cs.Open != 52 || cs.CloseDollars != "0.5525" || cs.Volume != 1200 || cs.CloseTS != 1705323600 {
+		t.Errorf("candlestick = %+v", cs)
+	}
+}
+
+func TestBatchGetCandlesticks(t *testing.T) {
+	t.Run("groups by ticker", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/markets/candlesticks" {
+				t.Errorf("path = %q, want /markets/candlesticks", r.URL.Path)
+			}
+			q := r.URL.Query()
+			if q.Get("tickers") != "MKT1,MKT2" || q.Get("period_interval") != "1" || q.Get("include_latest_before_start") != "true" {
+				t.Errorf("query = %v", q)
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"candlesticks": map[string]any{
+					"MKT1": []map[string]any{{"close_ts": 1705320060, "close": 52}},
+					"MKT2": []map[string]any{},
+				},
+			})
+		}))
+		defer server.Close()
+
+		c := NewClient(server.URL, "key", nil)
+		resp, err := c.BatchGetCandlesticks(context.Background(), BatchCandlesticksOptions{
+			Tickers:                  []string{"MKT1", "MKT2"},
+			PeriodInterval:           PeriodMinute,
+			StartTS:                  1705320000,
+			IncludeLatestBeforeStart: true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resp.Candlesticks["MKT1"]) != 1 || resp.Candlesticks["MKT1"][0].Close != 52 {
+			t.Errorf("MKT1 = %+v, want one candlestick", resp.Candlesticks["MKT1"])
+		}
+		if got, ok := resp.Candlesticks["MKT2"]; !ok || len(got) != 0 {
+			t.Errorf("MKT2 = %+v, want empty", got)
+		}
+	})
+
+	t.Run("rejects too many tickers", func(t *testing.T) {
+		c := NewClient("http://unused", "key", nil)
+		tickers := make([]string, MaxBatchCandlestickTickers+1)
+		if _, err := c.BatchGetCandlesticks(context.Background(), BatchCandlesticksOptions{Tickers: tickers}); err == nil {
+			t.Error("expected error for too many tickers")
+		}
+		if _, err := c.BatchGetCandlesticks(context.Background(), BatchCandlesticksOptions{}); err == nil {
+			t.Error("expected error for no tickers")
+		}
+	})
+}
+
 func TestGetAllMarketsWithOptions(t *testing.T) {
 	t.Run("with event ticker filter", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -688,7 +961,7 @@ func TestGetAllMarketsWithOptions(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		markets, err := c.GetAllMarketsWithOptions(context.Background(), GetMarketsOptions{
 			EventTicker: "EVENT1",
 		})
@@ -720,7 +993,7 @@ func TestGetMarket(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		market, err := c.GetMarket(context.Background(), "TEST-MARKET")
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -740,7 +1013,7 @@ func TestGetMarket(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key", WithRetries(0, time.Millisecond))
+		c := NewClient(server.URL, "key", nil, WithRetries(0, time.Millisecond))
 		_, err := c.GetMarket(context.Background(), "NONEXISTENT")
 		if err == nil {
 			t.Fatal("expected error, got nil")
@@ -771,7 +1044,7 @@ func TestGetOrderbook(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		ob, err := c.GetOrderbook(context.Background(), "TEST-MARKET", 0)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -793,7 +1066,7 @@ func TestGetOrderbook(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		_, err := c.GetOrderbook(context.Background(), "TEST-MARKET", 5)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -809,7 +1082,7 @@ func TestGetOrderbook(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		_, err := c.GetOrderbook(context.Background(), "TEST-MARKET", 0)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -834,7 +1107,7 @@ func TestGetEvents(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		resp, err := c.GetEvents(context.Background(), GetEventsOptions{})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -863,7 +1136,7 @@ func TestGetEvents(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		_, err := c.GetEvents(context.Background(), GetEventsOptions{
 			Limit:        50,
 			Cursor:       "cursor456",
@@ -898,7 +1171,7 @@ func TestGetAllEvents(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		events, err := c.GetAllEvents(context.Background())
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -929,7 +1202,7 @@ func TestGetEvent(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		event, err := c.GetEvent(context.Background(), "TEST-EVENT")
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -958,7 +1231,7 @@ func TestGetSeries(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		series, err := c.GetSeries(context.Background(), "TEST-SERIES")
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -981,7 +1254,7 @@ func TestJSONUnmarshalErrors(t *testing.T) {
 		}))
 		defer server.Close()
 
-		c := NewClient(server.URL, "key")
+		c := NewClient(server.URL, "key", nil)
 		_, err := c.GetExchangeStatus(context.Background())
 		if err == nil {
 			t.Fatal("expected error, got nil")
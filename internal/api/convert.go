@@ -80,9 +80,26 @@ func (m *APIMarket) ToModel() model.Market {
 		ExpirationTS:  ParseTimestamp(m.ExpirationTime),
 		CreatedTS:     ParseTimestamp(m.CreatedTime),
 		UpdatedAt:     NowMicro(),
+
+		SettlementValue: m.settlementValue(),
 	}
 }
 
+// settlementValue returns the YES payout in internal units, preferring the
+// sub-penny dollar string. Returns nil if the market has not settled.
+func (m *APIMarket) settlementValue() *int {
+	var v int
+	switch {
+	case m.SettlementValueDollars != nil && *m.SettlementValueDollars != "":
+		v = DollarsToInternal(*m.SettlementValueDollars)
+	case m.SettlementValue != nil:
+		v = CentsToInternal(*m.SettlementValue)
+	default:
+		return nil
+	}
+	return &v
+}
+
 // ToModel converts an APIEvent to model.Event.
 func (e *APIEvent) ToModel() model.Event {
 	return model.Event{
@@ -113,6 +130,60 @@ func (s *APISeries) ToModel() model.Series {
 	}
 }
 
+// ToModel converts an APICandlestick to model.Candle. Sub-penny dollar
+// prices are used when present, falling back to cents.
+func (c *APICandlestick) ToModel() model.Candle {
+	return model.Candle{
+		Ticker:        c.Ticker,
+		PeriodMinutes: c.PeriodInterval,
+		OpenTS:        c.OpenTS * 1_000_000,
+		CloseTS:       c.CloseTS * 1_000_000,
+		Open:          candlePrice(c.OpenDollars, c.Open),
+		High:          candlePrice(c.HighDollars, c.High),
+		Low:           candlePrice(c.LowDollars, c.Low),
+		Close:         candlePrice(c.CloseDollars, c.Close),
+		Volume:        c.Volume,
+		OpenInterest:  c.OpenInterest,
+	}
+}
+
+// candlePrice prefers the dollar string over cents.
+func candlePrice(dollars string, cents int) int {
+	if dollars != "" {
+		return DollarsToInternal(dollars)
+	}
+	return CentsToInternal(cents)
+}
+
+// Windows returns the schedule's closures and maintenance windows.
+// Windows without a parseable start and end are skipped.
+func (s *APISchedule) Windows() []model.MaintenanceWindow {
+	windows := make([]model.MaintenanceWindow, 0, len(s.Closures)+len(s.MaintenanceWindows))
+	for _, list := range [][]APIScheduleWindow{s.Closures, s.MaintenanceWindows} {
+		for _, w := range list {
+			start, end := ParseTimestamp(w.StartDatetime), ParseTimestamp(w.EndDatetime)
+			if start == 0 || end <= start {
+				continue
+			}
+			windows = append(windows, model.MaintenanceWindow{Start: start, End: end})
+		}
+	}
+	return windows
+}
+
+// ToModel converts an APIAnnouncement to model.Announcement.
+func (a *APIAnnouncement) ToModel() model.Announcement {
+	return model.Announcement{
+		ID:         a.ID,
+		Title:      a.Title,
+		Message:    a.Message,
+		Type:       a.Type,
+		Status:     a.Status,
+		CreatedTS:  ParseTimestamp(a.CreatedTime),
+		DeliveryTS: ParseTimestamp(a.DeliveryTime),
+	}
+}
+
 // ToOrderbookSnapshot converts an OrderbookResponse to model.OrderbookSnapshot.
 func (o *OrderbookResponse) ToOrderbookSnapshot(ticker string, source string) model.OrderbookSnapshot {
 	now := NowMicro()
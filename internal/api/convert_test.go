@@ -220,6 +220,44 @@ func TestAPIMarketToModel(t *testing.T) {
 			t.Errorf("LastPrice = %d, want 53001", model.LastPrice)
 		}
 	})
+
+	t.Run("settlement value", func(t *testing.T) {
+		cents := 100
+		dollars := "0.99995"
+		empty := ""
+
+		tests := []struct {
+			name    string
+			cents   *int
+			dollars *string
+			want    *int
+		}{
+			{"unsettled", nil, nil, nil},
+			{"cents only", &cents, nil, intPtr(100000)},
+			{"dollars preferred", &cents, &dollars, intPtr(99995)},
+			{"empty dollars falls back to cents", &cents, &empty, intPtr(100000)},
+		}
+
+		for _, tt := range tests {
+			m := APIMarket{SettlementValue: tt.cents, SettlementValueDollars: tt.dollars}
+			got := m.ToModel().SettlementValue
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || *got != *tt.want:
+				t.Errorf("%s: SettlementValue = %v, want %v", tt.name, deref(got), deref(tt.want))
+			}
+		}
+	})
+}
+
+func intPtr(v int) *int { return &v }
+
+// deref formats an optional int for error messages.
+func deref(v *int) any {
+	if v == nil {
+		return nil
+	}
+	return *v
 }
 
 func TestAPIEventToModel(t *testing.T) {
@@ -341,6 +379,98 @@ func TestAPISeriesToModel(t *testing.T) {
 	})
 }
 
+func TestAPICandlestickToModel(t *testing.T) {
+	t.Run("dollar prices preferred", func(t *testing.T) {
+		c := APICandlestick{
+			Ticker:         "MKT1",
+			PeriodInterval: PeriodHour,
+			OpenTS:         1705320000,
+			CloseTS:        1705323600,
+			Open:           52,
+			High:           56,
+			Low:            50,
+			Close:          55,
+			OpenDollars:    "0.5250",
+			HighDollars:    "0.5600",
+			LowDollars:     "0.5000",
+			CloseDollars:   "0.5525",
+			Volume:         1200,
+			OpenInterest:   5000,
+		}
+
+		model := c.ToModel()
+
+		if model.Ticker != "MKT1" || model.PeriodMinutes != 60 {
+			t.Errorf("Ticker, PeriodMinutes = %q, %d, want MKT1, 60", model.Ticker, model.PeriodMinutes)
+		}
+		if model.OpenTS != 1705320000000000 || model.CloseTS != 1705323600000000 {
+			t.Errorf("OpenTS, CloseTS = %d, %d, want µs", model.OpenTS, model.CloseTS)
+		}
+		if model.Open != 52500 || model.High != 56000 || model.Low != 50000 || model.Close != 55250 {
+			t.Errorf("OHLC = %d/%d/%d/%d, want 52500/56000/50000/55250", model.Open, model.High, model.Low, model.Close)
+		}
+		if model.Volume != 1200 || model.OpenInterest != 5000 {
+			t.Errorf("Volume, OpenInterest = %d, %d, want 1200, 5000", model.Volume, model.OpenInterest)
+		}
+	})
+
+	t.Run("cents fallback", func(t *testing.T) {
+		c := APICandlestick{Open: 52, High: 56, Low: 50, Close: 55}
+
+		model := c.ToModel()
+
+		if model.Open != 52000 || model.High != 56000 || model.Low != 50000 || model.Close != 55000 {
+			t.Errorf("OHLC = %d/%d/%d/%d, want 52000/56000/50000/55000", model.Open, model.High, model.Low, model.Close)
+		}
+	})
+}
+
+func TestAPIScheduleWindows(t *testing.T) {
+	s := APISchedule{
+		Closures: []APIScheduleWindow{
+			{StartDatetime: "2024-12-25T00:00:00Z", EndDatetime: "2024-12-26T00:00:00Z"},
+		},
+		MaintenanceWindows: []APIScheduleWindow{
+			{StartDatetime: "2024-01-18T08:00:00Z", EndDatetime: "2024-01-18T10:00:00Z"},
+			{StartDatetime: "invalid", EndDatetime: "2024-01-18T10:00:00Z"},
+			{StartDatetime: "2024-01-18T10:00:00Z", EndDatetime: "2024-01-18T08:00:00Z"},
+		},
+	}
+
+	windows := s.Windows()
+
+	if len(windows) != 2 {
+		t.Fatalf("len(Windows()) = %d, want 2 (invalid windows skipped)", len(windows))
+	}
+	if windows[0].Start != 1735084800000000 || windows[0].End != 1735171200000000 {
+		t.Errorf("closure = %+v", windows[0])
+	}
+	if windows[1].Start != 1705564800000000 || windows[1].End != 1705572000000000 {
+		t.Errorf("maintenance = %+v", windows[1])
+	}
+}
+
+func TestAPIAnnouncementToModel(t *testing.T) {
+	a := APIAnnouncement{
+		ID:           "ann-1",
+		Title:        "Scheduled maintenance",
+		Message:      "The exchange will be unavailable.",
+		Type:         "maintenance",
+		Status:       "active",
+		CreatedTime:  "2024-01-15T12:00:00Z",
+		DeliveryTime: "2024-01-15T12:30:00Z",
+	}
+
+	model := a.ToModel()
+
+	if model.ID != "ann-1" || model.Type != "maintenance" || model.Status != "active" {
+		t.Errorf("model = %+v", model)
+	}
+	if model.CreatedTS != 1705320000000000 || model.DeliveryTS != 1705321800000000 {
+		t.Errorf("CreatedTS, DeliveryTS = %d, %d", model.CreatedTS, model.DeliveryTS)
+	}
+}
+
 func TestOrderbookResponseToOrderbookSnapshot(t *testing.T) {
 	t.Run("full orderbook", func(t *testing.T) {
 		ob := &OrderbookResponse{
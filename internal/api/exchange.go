@@ -3,6 +3,8 @@ package api
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strconv"
 )
 
 // GetExchangeStatus fetches the current exchange status.
@@ -13,3 +15,31 @@ func (c *Client) GetExchangeStatus(ctx context.Context) (*ExchangeStatusResponse
 	}
 	return &resp, nil
 }
+
+// GetExchangeSchedule fetches the exchange's trading hours and planned
+// closures.
+func (c *Client) GetExchangeSchedule(ctx context.Context) (*ScheduleResponse, error) {
+	var resp ScheduleResponse
+	if err := c.get(ctx, "/exchange/schedule", nil, &resp); err != nil {
+		return nil, fmt.Errorf("get exchange schedule: %w", err)
+	}
+	return &resp, nil
+}
+
+// GetAnnouncements fetches a page of exchange announcements.
+func (c *Client) GetAnnouncements(ctx context.Context, opts GetAnnouncementsOptions) (*AnnouncementsResponse, error) {
+	query := url.Values{}
+
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
+	}
+
+	var resp AnnouncementsResponse
+	if err := c.get(ctx, "/exchange/announcements", query, &resp); err != nil {
+		return nil, fmt.Errorf("get announcements: %w", err)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Tier is a Kalshi rate limit class. GET requests count against TierRead,
+// everything else against TierWrite.
+type Tier int
+
+const (
+	TierRead Tier = iota
+	TierWrite
+)
+
+// String returns the tier name used in metric labels.
+func (t Tier) String() string {
+	if t == TierWrite {
+		return "write"
+	}
+	return "read"
+}
+
+// tierFor returns the rate limit tier for an HTTP method.
+func tierFor(method string) Tier {
+	if method == http.MethodGet || method == http.MethodHead {
+		return TierRead
+	}
+	return TierWrite
+}
+
+// RateLimits configures per-tier request rates.
+type RateLimits struct {
+	ReadPerSecond  float64
+	WritePerSecond float64
+	Burst          int // Requests per tier that may be issued back to back
+}
+
+// DefaultRateLimits returns Kalshi's Basic tier limits.
+func DefaultRateLimits() RateLimits {
+	return RateLimits{
+		ReadPerSecond:  20,
+		WritePerSecond: 10,
+		Burst:          5,
+	}
+}
+
+// RateLimitStats holds cumulative rate limiter statistics.
+type RateLimitStats struct {
+	ReadWaits     int64         // Read requests delayed by the limiter
+	WriteWaits    int64         // Write requests delayed by the limiter
+	ReadWaitTime  time.Duration // Total time read requests were delayed
+	WriteWaitTime time.Duration // Total time write requests were delayed
+	RateLimited   int64         // 429 responses received
+	Pauses        int64         // 429 responses that paused all requests (Retry-After)
+}
+
+// RateLimiter is a token bucket per tier, shared by every caller of a Client.
+// A 429 with Retry-After pauses both tiers until it expires.
+type RateLimiter struct {
+	mu          sync.Mutex
+	buckets     [2]bucket
+	pausedUntil time.Time
+	stats       RateLimitStats
+
+	now func() time.Time // Overridden in tests
+}
+
+// bucket is a token bucket. tokens goes negative while requests are queued.
+type bucket struct {
+	rate   float64 // Tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter. Rates must be > 0; Burst < 1 means 1.
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	burst := float64(max(limits.Burst, 1))
+	now := time.Now()
+	return &RateLimiter{
+		buckets: [2]bucket{
+			TierRead:  {rate: limits.ReadPerSecond, burst: burst, tokens: burst, last: now},
+			TierWrite: {rate: limits.WritePerSecond, burst: burst, tokens: burst, last: now},
+		},
+		now: time.Now,
+	}
+}
+
+// Wait blocks until a request in the given tier may be sent.
+func (l *RateLimiter) Wait(ctx context.Context, tier Tier) error {
+	delay := l.reserve(tier)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.cancel(tier)
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait for it.
+func (l *RateLimiter) reserve(tier Tier) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b := &l.buckets[tier]
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	if paused := l.pausedUntil.Sub(now); paused > delay {
+		delay = paused
+	}
+
+	if delay > 0 {
+		if tier == TierWrite {
+			l.stats.WriteWaits++
+			l.stats.WriteWaitTime += delay
+		} else {
+			l.stats.ReadWaits++
+			l.stats.ReadWaitTime += delay
+		}
+	}
+	return delay
+}
+
+// cancel returns the token of a request abandoned while waiting.
+func (l *RateLimiter) cancel(tier Tier) {
+	l.mu.Lock()
+	l.buckets[tier].tokens++
+	l.mu.Unlock()
+}
+
+// throttled records a 429 response. A positive retryAfter pauses all
+// requests until it has passed.
+func (l *RateLimiter) throttled(retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stats.RateLimited++
+	if retryAfter <= 0 {
+		return
+	}
+	l.stats.Pauses++
+	if until := l.now().Add(retryAfter); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+// Stats returns a snapshot of limiter statistics.
+func (l *RateLimiter) Stats() RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a limiter whose clock only moves when advance is called.
+func fakeClock(l *RateLimiter) (advance func(time.Duration)) {
+	now := time.Unix(1705328200, 0)
+	l.now = func() time.Time { return now }
+	for i := range l.buckets {
+		l.buckets[i].last = now
+	}
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestTierFor(t *testing.T) {
+	tests := []struct {
+		method string
+		want   Tier
+	}{
+		{http.MethodGet, TierRead},
+		{http.MethodHead, TierRead},
+		{http.MethodPost, TierWrite},
+		{http.MethodDelete, TierWrite},
+	}
+
+	for _, tt := range tests {
+		if got := tierFor(tt.method); got != tt.want {
+			t.Errorf("tierFor(%s) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimiter_Reserve(t *testing.T) {
+	l := NewRateLimiter(RateLimits{ReadPerSecond: 10, WritePerSecond: 2, Burst: 2})
+	advance := fakeClock(l)
+
+	// Burst is free
+	for i := 0; i < 2; i++ {
+		if d := l.reserve(TierRead); d != 0 {
+			t.Fatalf("reserve #%d = %v, want 0", i, d)
+		}
+	}
+
+	// Then one token per 100ms, queued behind each other
+	if d := l.reserve(TierRead); d != 100*time.Millisecond {
+		t.Errorf("reserve = %v, want 100ms", d)
+	}
+	if d := l.reserve(TierRead); d != 200*time.Millisecond {
+		t.Errorf("reserve = %v, want 200ms", d)
+	}
+
+	// Writes have their own bucket
+	if d := l.reserve(TierWrite); d != 0 {
+		t.Errorf("write reserve = %v, want 0", d)
+	}
+
+	// Refill never exceeds the burst
+	advance(10 * time.Second)
+	l.reserve(TierRead)
+	l.reserve(TierRead)
+	if d := l.reserve(TierRead); d != 100*time.Millisecond {
+		t.Errorf("reserve after refill = %v, want 100ms", d)
+	}
+
+	s := l.Stats()
+	if s.ReadWaits != 3 || s.ReadWaitTime != 400*time.Millisecond {
+		t.Errorf("read waits = %d (%v), want 3 (400ms)", s.ReadWaits, s.ReadWaitTime)
+	}
+	if s.WriteWaits != 0 {
+		t.Errorf("WriteWaits = %d, want 0", s.WriteWaits)
+	}
+}
+
+func TestRateLimiter_Throttled(t *testing.T) {
+	l := NewRateLimiter(RateLimits{ReadPerSecond: 100, WritePerSecond: 100, Burst: 10})
+	advance := fakeClock(l)
+
+	l.throttled(0)
+	if d := l.reserve(TierRead); d != 0 {
+		t.Errorf("reserve after 429 without Retry-After = %v, want 0", d)
+	}
+
+	// Retry-After pauses both tiers; a shorter one does not shorten it
+	l.throttled(2 * time.Second)
+	l.throttled(time.Second)
+	if d := l.reserve(TierWrite); d != 2*time.Second {
+		t.Errorf("write reserve = %v, want 2s", d)
+	}
+	advance(1500 * time.Millisecond)
+	if d := l.reserve(TierRead); d != 500*time.Millisecond {
+		t.Errorf("read reserve = %v, want 500ms", d)
+	}
+
+	s := l.Stats()
+	if s.RateLimited != 3 || s.Pauses != 2 {
+		t.Errorf("RateLimited, Pauses = %d, %d, want 3, 2", s.RateLimited, s.Pauses)
+	}
+}
+
+func TestRateLimiter_WaitCancelled(t *testing.T) {
+	l := NewRateLimiter(RateLimits{ReadPerSecond: 1, WritePerSecond: 1, Burst: 1})
+	l.Wait(context.Background(), TierRead)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx, TierRead); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() = %v, want context.Canceled", err)
+	}
+
+	// The abandoned token is returned: the next caller waits ~1s, not ~2s
+	if d := l.reserve(TierRead); d > time.Second {
+		t.Errorf("reserve after cancel = %v, want <= 1s", d)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header string
+		body   string
+		want   time.Duration
+	}{
+		{"seconds", "3", "", 3 * time.Second},
+		{"http date", now.Add(5 * time.Second).Format(http.TimeFormat), "", 5 * time.Second},
+		{"date in past", now.Add(-time.Minute).Format(http.TimeFormat), "", 0},
+		{"body", "", `{"code":"RATE_LIMITED","details":{"retry_after_ms":1500}}`, 1500 * time.Millisecond},
+		{"header wins", "1", `{"details":{"retry_after_ms":9000}}`, time.Second},
+		{"invalid header falls back to body", "soon", `{"details":{"retry_after_ms":250}}`, 250 * time.Millisecond},
+		{"absent", "", "rate limited", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.header, []byte(tt.body), now); got != tt.want {
+			t.Errorf("%s: parseRetryAfter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"math/rand/v2"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -21,6 +22,7 @@ type APIError struct {
 	StatusCode int
 	Message    string
 	Body       []byte
+	RetryAfter time.Duration // From a 429's Retry-After header or body, 0 if absent
 }
 
 func (e *APIError) Error() string {
@@ -81,16 +83,44 @@ func (c *Client) doRequest(ctx context.Context, method, path string, query url.V
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, &APIError{
+		apiErr := &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    http.StatusText(resp.StatusCode),
 			Body:       body,
 		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), body, time.Now())
+		}
+		return nil, apiErr
 	}
 
 	return body, nil
 }
 
+// parseRetryAfter returns the wait requested by a 429 response. The
+// Retry-After header (seconds or HTTP date) takes precedence over the
+// body's details.retry_after_ms. Returns 0 if neither is present.
+func parseRetryAfter(header string, body []byte, now time.Time) time.Duration {
+	if header != "" {
+		if secs, err := strconv.Atoi(header); err == nil {
+			return time.Duration(max(secs, 0)) * time.Second
+		}
+		if t, err := http.ParseTime(header); err == nil {
+			return max(t.Sub(now), 0)
+		}
+	}
+
+	var rateLimited struct {
+		Details struct {
+			RetryAfterMs int64 `json:"retry_after_ms"`
+		} `json:"details"`
+	}
+	if json.Unmarshal(body, &rateLimited) == nil && rateLimited.Details.RetryAfterMs > 0 {
+		return time.Duration(rateLimited.Details.RetryAfterMs) * time.Millisecond
+	}
+	return 0
+}
+
 // generateSignature creates an RSA-PSS signature for Kalshi API authentication.
 // Message format: timestamp_ms + method + path
 func (c *Client) generateSignature(timestampMs int64, method, path string) (string, error) {
@@ -115,30 +145,42 @@ func (c *Client) generateSignature(timestampMs int64, method, path string) (stri
 	return base64.StdEncoding.EncodeToString(signature), nil
 }
 
-// doWithRetry performs a request with exponential backoff retry.
+// doWithRetry performs a request with exponential backoff retry. Each
+// attempt waits for the rate limiter; a 429's Retry-After replaces the
+// backoff if it is longer.
 func (c *Client) doWithRetry(ctx context.Context, method, path string, query url.Values) ([]byte, error) {
 	var lastErr error
+	var retryAfter time.Duration
 	backoff := c.retryBackoff
 
 	for attempt := 0; attempt <= c.maxRetries; attempt++ {
 		if attempt > 0 {
 			// Add jitter: backoff * (0.5 to 1.5)
-			jitter := backoff/2 + time.Duration(rand.Int64N(int64(backoff)))
+			wait := backoff/2 + time.Duration(rand.Int64N(int64(backoff)))
+			if retryAfter > wait {
+				wait = retryAfter
+			}
 			c.logger.Debug("retrying request",
 				"attempt", attempt,
-				"backoff", jitter,
+				"backoff", wait,
 				"path", path,
 			)
 
 			select {
 			case <-ctx.Done():
 				return nil, ctx.Err()
-			case <-time.After(jitter):
+			case <-time.After(wait):
 			}
 
 			backoff *= 2
 		}
 
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx, tierFor(method)); err != nil {
+				return nil, err
+			}
+		}
+
 		body, err := c.doRequest(ctx, method, path, query)
 		if err == nil {
 			return body, nil
@@ -151,6 +193,14 @@ func (c *Client) doWithRetry(ctx context.Context, method, path string, query url
 		if !ok || !apiErr.IsRetryable() {
 			return nil, err
 		}
+
+		retryAfter = apiErr.RetryAfter
+		if apiErr.StatusCode == http.StatusTooManyRequests {
+			c.logger.Warn("rate limited", "path", path, "retry_after", retryAfter)
+			if c.limiter != nil {
+				c.limiter.throttled(retryAfter)
+			}
+		}
 	}
 
 	return nil, fmt.Errorf("max retries exceeded: %w", lastErr)
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// GetTrades fetches a page of trades, newest first.
+func (c *Client) GetTrades(ctx context.Context, opts GetTradesOptions) (*TradesResponse, error) {
+	query := url.Values{}
+
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
+	}
+	if opts.Ticker != "" {
+		query.Set("ticker", opts.Ticker)
+	}
+	if opts.MinTS > 0 {
+		query.Set("min_ts", strconv.FormatInt(opts.MinTS, 10))
+	}
+	if opts.MaxTS > 0 {
+		query.Set("max_ts", strconv.FormatInt(opts.MaxTS, 10))
+	}
+
+	var resp TradesResponse
+	if err := c.get(ctx, "/markets/trades", query, &resp); err != nil {
+		return nil, fmt.Errorf("get trades: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// GetAllTrades fetches all trades matching the given options.
+// Uses DefaultPaginationTimeout (30m) if the context has no deadline.
+func (c *Client) GetAllTrades(ctx context.Context, opts GetTradesOptions) ([]APITrade, error) {
+	// Apply default timeout if context has no deadline.
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultPaginationTimeout)
+		defer cancel()
+	}
+
+	var allTrades []APITrade
+	opts.Limit = 1000 // Max page size
+
+	for {
+		resp, err := c.GetTrades(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		allTrades = append(allTrades, resp.Trades...)
+
+		if resp.Cursor == "" {
+			break
+		}
+		opts.Cursor = resp.Cursor
+	}
+
+	return allTrades, nil
+}
@@ -7,6 +7,53 @@ type ExchangeStatusResponse struct {
 	EstimatedResumeTime string `json:"exchange_estimated_resume_time,omitempty"`
 }
 
+// ScheduleResponse from GET /exchange/schedule
+type ScheduleResponse struct {
+	Schedule APISchedule `json:"schedule"`
+}
+
+// APISchedule is the exchange's trading hours and planned closures.
+type APISchedule struct {
+	StandardHours      APIStandardHours    `json:"standard_hours"`
+	Closures           []APIScheduleWindow `json:"closures"`
+	MaintenanceWindows []APIScheduleWindow `json:"maintenance_windows"`
+}
+
+// APIStandardHours holds regular trading hours by lowercase weekday.
+type APIStandardHours struct {
+	Timezone string                 `json:"timezone"`
+	Days     map[string]APIDayHours `json:"days"`
+}
+
+// APIDayHours is one day's trading hours ("HH:MM" in the schedule timezone).
+type APIDayHours struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// APIScheduleWindow is a scheduled closure or maintenance window.
+type APIScheduleWindow struct {
+	StartDatetime string `json:"start_datetime"` // ISO 8601
+	EndDatetime   string `json:"end_datetime"`   // ISO 8601
+}
+
+// AnnouncementsResponse from GET /exchange/announcements
+type AnnouncementsResponse struct {
+	Announcements []APIAnnouncement `json:"announcements"`
+	Cursor        string            `json:"cursor"`
+}
+
+// APIAnnouncement represents an exchange announcement from the Kalshi API.
+type APIAnnouncement struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	Message      string `json:"message"`
+	Type         string `json:"type"` // maintenance, market, feature, general
+	Status       string `json:"status"`
+	CreatedTime  string `json:"created_time"`
+	DeliveryTime string `json:"delivery_time"`
+}
+
 // MarketsResponse from GET /markets
 type MarketsResponse struct {
 	Markets []APIMarket `json:"markets"`
@@ -107,6 +154,60 @@ type APIOrderbook struct {
 	No  [][]int `json:"no"`
 }
 
+// TradesResponse from GET /markets/trades
+type TradesResponse struct {
+	Trades []APITrade `json:"trades"`
+	Cursor string     `json:"cursor"`
+}
+
+// APITrade represents an executed trade from the Kalshi API.
+type APITrade struct {
+	TradeID         string `json:"trade_id"`
+	Ticker          string `json:"ticker"`
+	Count           int    `json:"count"`
+	YesPrice        int    `json:"yes_price"` // Cents
+	NoPrice         int    `json:"no_price"`  // Cents
+	YesPriceDollars string `json:"yes_price_dollars"`
+	NoPriceDollars  string `json:"no_price_dollars"`
+	TakerSide       string `json:"taker_side"`
+	CreatedTime     string `json:"created_time"` // ISO 8601
+	Ts              int64  `json:"ts"`           // Unix seconds
+}
+
+// CandlesticksResponse from GET /series/{series_ticker}/markets/{ticker}/candlesticks
+type CandlesticksResponse struct {
+	Candlesticks []APICandlestick `json:"candlesticks"`
+	Cursor       string           `json:"cursor"`
+}
+
+// BatchCandlesticksResponse from GET /markets/candlesticks, keyed by ticker.
+type BatchCandlesticksResponse struct {
+	Candlesticks map[string][]APICandlestick `json:"candlesticks"`
+}
+
+// APICandlestick represents an OHLC candlestick from the Kalshi API.
+type APICandlestick struct {
+	Ticker         string `json:"ticker"`
+	PeriodInterval int    `json:"period_interval"` // Minutes
+	OpenTS         int64  `json:"open_ts"`         // Unix seconds
+	CloseTS        int64  `json:"close_ts"`        // Unix seconds
+
+	// Prices in cents
+	Open  int `json:"open"`
+	High  int `json:"high"`
+	Low   int `json:"low"`
+	Close int `json:"close"`
+
+	// Prices as strings (sub-penny)
+	OpenDollars  string `json:"open_dollars"`
+	HighDollars  string `json:"high_dollars"`
+	LowDollars   string `json:"low_dollars"`
+	CloseDollars string `json:"close_dollars"`
+
+	Volume       int64 `json:"volume"`
+	OpenInterest int64 `json:"open_interest"`
+}
+
 // GetMarketsOptions configures a GetMarkets request.
 type GetMarketsOptions struct {
 	Limit        int
@@ -117,6 +218,48 @@ type GetMarketsOptions struct {
 	Status       string
 }
 
+// GetTradesOptions configures a GetTrades request. MinTS and MaxTS are
+// Unix seconds; zero leaves the bound open.
+type GetTradesOptions struct {
+	Limit  int
+	Cursor string
+	Ticker string
+	MinTS  int64
+	MaxTS  int64
+}
+
+// Candlestick period intervals, in minutes.
+const (
+	PeriodMinute = 1
+	PeriodHour   = 60
+	PeriodDay    = 1440
+)
+
+// GetCandlesticksOptions configures a GetCandlesticks request. StartTS and
+// EndTS are Unix seconds; zero leaves the bound open.
+type GetCandlesticksOptions struct {
+	PeriodInterval int // PeriodMinute, PeriodHour or PeriodDay
+	StartTS        int64
+	EndTS          int64
+	Limit          int
+	Cursor         string
+}
+
+// BatchCandlesticksOptions configures a BatchGetCandlesticks request.
+type BatchCandlesticksOptions struct {
+	Tickers                  []string // At most MaxBatchCandlestickTickers
+	PeriodInterval           int
+	StartTS                  int64
+	EndTS                    int64
+	IncludeLatestBeforeStart bool
+}
+
+// GetAnnouncementsOptions configures a GetAnnouncements request.
+type GetAnnouncementsOptions struct {
+	Limit  int
+	Cursor string
+}
+
 // GetEventsOptions configures a GetEvents request.
 type GetEventsOptions struct {
 	Limit        int
@@ -0,0 +1,227 @@
+package book
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/api"
+	"github.com/rickgao/kalshi-data/internal/model"
+	"github.com/rickgao/kalshi-data/internal/router"
+)
+
+// SourceDerived marks snapshots built from the in-memory book.
+const SourceDerived = "derived"
+
+// maxPrice is $1.00 in hundred-thousandths. A YES bid at X is a NO ask at maxPrice - X.
+const maxPrice = 100000
+
+// Errors returned by Apply. Books that hit ErrSequenceGap, ErrNegativeSize
+// or ErrCrossedBook are invalid until the next snapshot.
+var (
+	ErrNoSnapshot   = errors.New("delta before snapshot")
+	ErrStaleSID     = errors.New("delta for replaced subscription")
+	ErrDuplicateSeq = errors.New("duplicate or out-of-order seq")
+	ErrSequenceGap  = errors.New("sequence gap") // Invalidates every book on the SID
+	ErrInvalidBook  = errors.New("book invalid until next snapshot")
+	ErrNegativeSize = errors.New("negative size")
+	ErrCrossedBook  = errors.New("crossed book")
+	ErrUnknownSide  = errors.New("unknown side")
+	ErrUnknownType  = errors.New("unknown message type")
+)
+
+// Quote is the top of a book.
+type Quote struct {
+	YesBid model.PriceLevel // Best YES bid (zero if none)
+	YesAsk model.PriceLevel // Best YES ask, derived from the best NO bid (zero if none)
+	Spread int              // YesAsk.Price - YesBid.Price, 0 if either side is empty
+}
+
+// Depth is the top N levels of each side, best first.
+type Depth struct {
+	YesBids []model.PriceLevel
+	NoBids  []model.PriceLevel
+}
+
+// book is the L2 state of one market: price → size for each side.
+// Kalshi books only carry bids; asks are derived from the opposite side.
+type book struct {
+	ticker string
+	sid    int64
+	seq    int64
+	valid  bool
+
+	yes map[int]int
+	no  map[int]int
+
+	updatedAt  time.Time // ReceivedAt of the last applied message
+	exchangeTs int64     // Exchange timestamp of the last applied delta (µs)
+
+	changes int // Messages applied since the last derived snapshot
+}
+
+func newBook(ticker string) *book {
+	return &book{
+		ticker: ticker,
+		yes:    make(map[int]int),
+		no:     make(map[int]int),
+	}
+}
+
+// applySnapshot replaces the book with a snapshot and resets sequencing.
+func (b *book) applySnapshot(msg router.OrderbookMsg) error {
+	b.sid = msg.SID
+	b.seq = msg.Seq
+	b.valid = true
+	b.updatedAt = msg.ReceivedAt
+	b.exchangeTs = 0
+	b.yes = levelsToMap(msg.Yes)
+	b.no = levelsToMap(msg.No)
+	b.changes++
+
+	return b.checkCrossed()
+}
+
+// applyDelta applies a delta in seq order. Deltas from older subscriptions
+// and duplicates are ignored. Seq runs across every market on the SID, so
+// gaps are detected by the Engine, not here.
+func (b *book) applyDelta(msg router.OrderbookMsg) error {
+	if msg.SID != b.sid {
+		return ErrStaleSID
+	}
+	if msg.Seq <= b.seq {
+		return ErrDuplicateSeq
+	}
+	if !b.valid {
+		return ErrInvalidBook
+	}
+
+	var side map[int]int
+	switch msg.Side {
+	case "yes":
+		side = b.yes
+	case "no":
+		side = b.no
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownSide, msg.Side)
+	}
+
+	b.seq = msg.Seq
+	b.updatedAt = msg.ReceivedAt
+	b.exchangeTs = msg.ExchangeTs
+
+	price := api.DollarsToInternal(msg.PriceDollars)
+	size := side[price] + msg.Delta
+	switch {
+	case size < 0:
+		b.valid = false
+		return fmt.Errorf("%w: %s %d at %d", ErrNegativeSize, msg.Side, size, price)
+	case size == 0:
+		delete(side, price)
+	default:
+		side[price] = size
+	}
+	b.changes++
+
+	return b.checkCrossed()
+}
+
+// checkCrossed invalidates the book if the best YES bid reaches the best YES ask.
+func (b *book) checkCrossed() error {
+	yesBid, okYes := bestPrice(b.yes)
+	noBid, okNo := bestPrice(b.no)
+	if okYes && okNo && yesBid+noBid >= maxPrice {
+		b.valid = false
+		return fmt.Errorf("%w: yes bid %d, yes ask %d", ErrCrossedBook, yesBid, maxPrice-noBid)
+	}
+	return nil
+}
+
+// quote returns the top of the book.
+func (b *book) quote() Quote {
+	var q Quote
+	if p, ok := bestPrice(b.yes); ok {
+		q.YesBid = model.PriceLevel{Price: p, Size: b.yes[p]}
+	}
+	if p, ok := bestPrice(b.no); ok {
+		q.YesAsk = model.PriceLevel{Price: maxPrice - p, Size: b.no[p]}
+	}
+	if q.YesBid.Price > 0 && q.YesAsk.Price > 0 {
+		q.Spread = q.YesAsk.Price - q.YesBid.Price
+	}
+	return q
+}
+
+// depth returns up to n levels per side, best first. n <= 0 returns all levels.
+func (b *book) depth(n int) Depth {
+	return Depth{
+		YesBids: sortedLevels(b.yes, n),
+		NoBids:  sortedLevels(b.no, n),
+	}
+}
+
+// snapshot returns the full book as a model.OrderbookSnapshot taken at ts.
+func (b *book) snapshot(ts time.Time) model.OrderbookSnapshot {
+	yesBids := sortedLevels(b.yes, 0)
+	noBids := sortedLevels(b.no, 0)
+	q := b.quote()
+
+	return model.OrderbookSnapshot{
+		SnapshotTS: ts.UnixMicro(),
+		ExchangeTS: b.exchangeTs,
+		Ticker:     b.ticker,
+		Source:     SourceDerived,
+		YesBids:    yesBids,
+		YesAsks:    asksFromBids(noBids),
+		NoBids:     noBids,
+		NoAsks:     asksFromBids(yesBids),
+		BestYesBid: q.YesBid.Price,
+		BestYesAsk: q.YesAsk.Price,
+		Spread:     q.Spread,
+	}
+}
+
+// levelsToMap converts router price levels to price → size, dropping empty levels.
+func levelsToMap(levels []router.PriceLevel) map[int]int {
+	m := make(map[int]int, len(levels))
+	for _, l := range levels {
+		if l.Quantity > 0 {
+			m[api.DollarsToInternal(l.Dollars)] += l.Quantity
+		}
+	}
+	return m
+}
+
+// bestPrice returns the highest bid price on a side.
+func bestPrice(side map[int]int) (int, bool) {
+	best, ok := 0, false
+	for p := range side {
+		if !ok || p > best {
+			best, ok = p, true
+		}
+	}
+	return best, ok
+}
+
+// sortedLevels returns levels by descending price, truncated to n if n > 0.
+func sortedLevels(side map[int]int, n int) []model.PriceLevel {
+	levels := make([]model.PriceLevel, 0, len(side))
+	for p, s := range side {
+		levels = append(levels, model.PriceLevel{Price: p, Size: s})
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Price > levels[j].Price })
+	if n > 0 && len(levels) > n {
+		levels = levels[:n]
+	}
+	return levels
+}
+
+// asksFromBids derives one side's asks from the opposite side's bids, best (lowest) first.
+func asksFromBids(bids []model.PriceLevel) []model.PriceLevel {
+	asks := make([]model.PriceLevel, len(bids))
+	for i, bid := range bids {
+		asks[i] = model.PriceLevel{Price: maxPrice - bid.Price, Size: bid.Size}
+	}
+	return asks
+}
@@ -0,0 +1,200 @@
+package book
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/model"
+	"github.com/rickgao/kalshi-data/internal/router"
+)
+
+func snapshotMsg(sid, seq int64) router.OrderbookMsg {
+	return router.OrderbookMsg{
+		Type:   "snapshot",
+		Ticker: "MKT",
+		SID:    sid,
+		Seq:    seq,
+		Yes:    []router.PriceLevel{{Dollars: "0.50", Quantity: 100}, {Dollars: "0.52", Quantity: 50}},
+		No:     []router.PriceLevel{{Dollars: "0.45", Quantity: 70}, {Dollars: "0.40", Quantity: 30}},
+	}
+}
+
+func deltaMsg(sid, seq int64, side, price string, delta int) router.OrderbookMsg {
+	return router.OrderbookMsg{
+		Type:         "delta",
+		Ticker:       "MKT",
+		SID:          sid,
+		Seq:          seq,
+		Side:         side,
+		PriceDollars: price,
+		Delta:        delta,
+		ExchangeTs:   1705320000000000 + seq,
+	}
+}
+
+func TestBook_ApplyDelta(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       router.OrderbookMsg
+		wantErr   error
+		wantValid bool
+	}{
+		{"next seq adds level", deltaMsg(1, 11, "yes", "0.51", 10), nil, true},
+		{"removes level", deltaMsg(1, 11, "yes", "0.52", -50), nil, true},
+		{"duplicate seq", deltaMsg(1, 10, "yes", "0.51", 10), ErrDuplicateSeq, true},
+		{"old subscription", deltaMsg(9, 11, "yes", "0.51", 10), ErrStaleSID, true},
+		{"seq skips ahead", deltaMsg(1, 13, "yes", "0.51", 10), nil, true}, // Other markets on the SID
+		{"negative size", deltaMsg(1, 11, "no", "0.45", -71), ErrNegativeSize, false},
+		{"crossed", deltaMsg(1, 11, "no", "0.48", 5), ErrCrossedBook, false},
+		{"unknown side", deltaMsg(1, 11, "maybe", "0.48", 5), ErrUnknownSide, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBook("MKT")
+			if err := b.applySnapshot(snapshotMsg(1, 10)); err != nil {
+				t.Fatalf("applySnapshot() error = %v", err)
+			}
+
+			err := b.applyDelta(tt.msg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("applyDelta() error = %v, want %v", err, tt.wantErr)
+			}
+			if b.valid != tt.wantValid {
+				t.Errorf("valid = %v, want %v", b.valid, tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestBook_InvalidUntilSnapshot(t *testing.T) {
+	b := newBook("MKT")
+	b.applySnapshot(snapshotMsg(1, 10))
+	b.applyDelta(deltaMsg(1, 12, "no", "0.45", -71)) // Negative size
+
+	if err := b.applyDelta(deltaMsg(1, 13, "yes", "0.51", 10)); !errors.Is(err, ErrInvalidBook) {
+		t.Errorf("applyDelta() after gap error = %v, want %v", err, ErrInvalidBook)
+	}
+
+	// Resubscribe delivers a new snapshot under a new SID
+	if err := b.applySnapshot(snapshotMsg(2, 1)); err != nil {
+		t.Fatalf("applySnapshot() error = %v", err)
+	}
+	if err := b.applyDelta(deltaMsg(2, 2, "yes", "0.51", 10)); err != nil {
+		t.Errorf("applyDelta() after snapshot error = %v", err)
+	}
+	if !b.valid {
+		t.Error("valid = false after snapshot, want true")
+	}
+}
+
+func TestBook_Quote(t *testing.T) {
+	b := newBook("MKT")
+	b.applySnapshot(snapshotMsg(1, 10))
+
+	got := b.quote()
+	want := Quote{
+		YesBid: model.PriceLevel{Price: 52000, Size: 50},
+		YesAsk: model.PriceLevel{Price: 55000, Size: 70}, // 1.00 - 0.45
+		Spread: 3000,
+	}
+	if got != want {
+		t.Errorf("quote() = %+v, want %+v", got, want)
+	}
+
+	empty := newBook("EMPTY").quote()
+	if empty != (Quote{}) {
+		t.Errorf("empty quote() = %+v, want zero", empty)
+	}
+}
+
+func TestBook_Depth(t *testing.T) {
+	b := newBook("MKT")
+	b.applySnapshot(snapshotMsg(1, 10))
+	b.applyDelta(deltaMsg(1, 11, "yes", "0.48", 5))
+
+	tests := []struct {
+		n           int
+		wantYesBids []model.PriceLevel
+		wantNoBids  []model.PriceLevel
+	}{
+		{1, []model.PriceLevel{{Price: 52000, Size: 50}}, []model.PriceLevel{{Price: 45000, Size: 70}}},
+		{2, []model.PriceLevel{{Price: 52000, Size: 50}, {Price: 50000, Size: 100}}, []model.PriceLevel{{Price: 45000, Size: 70}, {Price: 40000, Size: 30}}},
+		{0, []model.PriceLevel{{Price: 52000, Size: 50}, {Price: 50000, Size: 100}, {Price: 48000, Size: 5}}, []model.PriceLevel{{Price: 45000, Size: 70}, {Price: 40000, Size: 30}}},
+	}
+
+	for _, tt := range tests {
+		d := b.depth(tt.n)
+		if !equalLevels(d.YesBids, tt.wantYesBids) {
+			t.Errorf("depth(%d).YesBids = %v, want %v", tt.n, d.YesBids, tt.wantYesBids)
+		}
+		if !equalLevels(d.NoBids, tt.wantNoBids) {
+			t.Errorf("depth(%d).NoBids = %v, want %v", tt.n, d.NoBids, tt.wantNoBids)
+		}
+	}
+}
+
+func TestBook_Snapshot(t *testing.T) {
+	b := newBook("MKT")
+	b.applySnapshot(snapshotMsg(1, 10))
+	b.applyDelta(deltaMsg(1, 11, "no", "0.40", -30))
+
+	ts := time.UnixMicro(1705320001000000)
+	s := b.snapshot(ts)
+
+	if s.SnapshotTS != 1705320001000000 {
+		t.Errorf("SnapshotTS = %d, want 1705320001000000", s.SnapshotTS)
+	}
+	if s.ExchangeTS != 1705320000000011 {
+		t.Errorf("ExchangeTS = %d, want 1705320000000011", s.ExchangeTS)
+	}
+	if s.Source != SourceDerived {
+		t.Errorf("Source = %s, want %s", s.Source, SourceDerived)
+	}
+	if s.BestYesBid != 52000 || s.BestYesAsk != 55000 || s.Spread != 3000 {
+		t.Errorf("best/spread = %d/%d/%d, want 52000/55000/3000", s.BestYesBid, s.BestYesAsk, s.Spread)
+	}
+
+	tests := []struct {
+		name string
+		got  []model.PriceLevel
+		want []model.PriceLevel
+	}{
+		{"YesBids", s.YesBids, []model.PriceLevel{{Price: 52000, Size: 50}, {Price: 50000, Size: 100}}},
+		{"YesAsks", s.YesAsks, []model.PriceLevel{{Price: 55000, Size: 70}}},
+		{"NoBids", s.NoBids, []model.PriceLevel{{Price: 45000, Size: 70}}},
+		{"NoAsks", s.NoAsks, []model.PriceLevel{{Price: 48000, Size: 50}, {Price: 50000, Size: 100}}},
+	}
+
+	for _, tt := range tests {
+		if !equalLevels(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestBook_CrossedSnapshot(t *testing.T) {
+	b := newBook("MKT")
+	msg := snapshotMsg(1, 1)
+	msg.No = []router.PriceLevel{{Dollars: "0.48", Quantity: 10}}
+
+	if err := b.applySnapshot(msg); !errors.Is(err, ErrCrossedBook) {
+		t.Errorf("applySnapshot() error = %v, want %v", err, ErrCrossedBook)
+	}
+	if b.valid {
+		t.Error("valid = true, want false")
+	}
+}
+
+func equalLevels(a, b []model.PriceLevel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
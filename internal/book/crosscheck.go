@@ -0,0 +1,225 @@
+package book
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/rickgao/kalshi-data/internal/model"
+)
+
+// Resyncer requests a fresh snapshot for a market. connection.Manager
+// implements it.
+type Resyncer interface {
+	ResyncOrderbook(ticker string) error
+}
+
+// Divergence summarises how a REST snapshot differs from an in-memory book.
+type Divergence struct {
+	Levels       int // Price levels whose size differs by more than the tolerance
+	MaxLevelDiff int // Largest absolute size difference at any level
+}
+
+// CrossCheckConfig configures REST cross-checks.
+type CrossCheckConfig struct {
+	// Tolerance is the size difference per level ignored as in-flight
+	// deltas between the REST fetch and the comparison (default: 0).
+	Tolerance int
+
+	// Confirmations is how many consecutive checks of a market must diverge
+	// before it is resynced (default: 2). Deltas applied between the REST
+	// fetch and the comparison make a single check diverge; a book that is
+	// really wrong stays wrong on the next poll.
+	Confirmations int
+}
+
+// DefaultCrossCheckConfig returns sensible defaults.
+func DefaultCrossCheckConfig() CrossCheckConfig {
+	return CrossCheckConfig{
+		Confirmations: 2,
+	}
+}
+
+// CrossCheckStats holds cross-check counters.
+type CrossCheckStats struct {
+	Checks          int64 // REST snapshots compared with a valid book
+	Unchecked       int64 // REST snapshots with no valid book to compare
+	Divergences     int64 // Checks with at least one divergent level
+	Unconfirmed     int64 // Divergences not (yet) repeated on consecutive checks
+	DivergentLevels int64 // Divergent levels across all checks
+	MaxLevelDiff    int   // Largest level difference seen
+	Resyncs         int64 // Resubscribes requested after a divergence
+	ResyncErrors    int64 // Resubscribe requests refused (rate limited, not subscribed)
+}
+
+// CrossChecker is a SnapshotHandler that compares each REST snapshot with
+// the Engine's book for the same market before passing it on. A divergence
+// means the delta stream lost or misapplied an update, so the market is
+// resubscribed for a fresh WebSocket snapshot.
+type CrossChecker struct {
+	cfg      CrossCheckConfig
+	engine   *Engine
+	next     SnapshotHandler
+	resyncer Resyncer
+	logger   *slog.Logger
+
+	mu        sync.Mutex
+	divergent map[string]int // Ticker → consecutive divergent checks
+	stats     CrossCheckStats
+}
+
+// NewCrossChecker creates a CrossChecker that forwards snapshots to next.
+// next and resyncer may be nil.
+func NewCrossChecker(cfg CrossCheckConfig, engine *Engine, next SnapshotHandler, resyncer Resyncer, logger *slog.Logger) *CrossChecker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.Confirmations < 1 {
+		cfg.Confirmations = 1
+	}
+	return &CrossChecker{
+		cfg:       cfg,
+		engine:    engine,
+		next:      next,
+		resyncer:  resyncer,
+		logger:    logger,
+		divergent: make(map[string]int),
+	}
+}
+
+// HandleSnapshot compares snapshot with the in-memory book, then forwards it.
+// Safe for concurrent use by the poller's workers.
+func (c *CrossChecker) HandleSnapshot(snapshot model.OrderbookSnapshot) error {
+	c.check(snapshot)
+
+	if c.next != nil {
+		return c.next.HandleSnapshot(snapshot)
+	}
+	return nil
+}
+
+// Stats returns current statistics.
+func (c *CrossChecker) Stats() CrossCheckStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// check compares and resyncs once a divergence is confirmed.
+func (c *CrossChecker) check(snapshot model.OrderbookSnapshot) {
+	d, ok := c.engine.Compare(snapshot, c.cfg.Tolerance)
+
+	c.mu.Lock()
+	if !ok {
+		c.stats.Unchecked++
+		delete(c.divergent, snapshot.Ticker)
+		c.mu.Unlock()
+		return
+	}
+	c.stats.Checks++
+	if d.MaxLevelDiff > c.stats.MaxLevelDiff {
+		c.stats.MaxLevelDiff = d.MaxLevelDiff
+	}
+	if d.Levels == 0 {
+		delete(c.divergent, snapshot.Ticker)
+		c.mu.Unlock()
+		return
+	}
+	c.stats.Divergences++
+	c.stats.DivergentLevels += int64(d.Levels)
+
+	n := c.divergent[snapshot.Ticker] + 1
+	confirmed := n >= c.cfg.Confirmations
+	if confirmed {
+		delete(c.divergent, snapshot.Ticker)
+	} else {
+		c.divergent[snapshot.Ticker] = n
+		c.stats.Unconfirmed++
+	}
+	c.mu.Unlock()
+
+	if !confirmed {
+		c.logger.Debug("book diverged from REST snapshot, awaiting confirmation",
+			"ticker", snapshot.Ticker,
+			"levels", d.Levels,
+			"max_level_diff", d.MaxLevelDiff,
+			"checks", n,
+		)
+		return
+	}
+
+	c.logger.Warn("book diverged from REST snapshot",
+		"ticker", snapshot.Ticker,
+		"levels", d.Levels,
+		"max_level_diff", d.MaxLevelDiff,
+		"checks", n,
+	)
+
+	if c.resyncer == nil {
+		return
+	}
+
+	err := c.resyncer.ResyncOrderbook(snapshot.Ticker)
+
+	c.mu.Lock()
+	if err != nil {
+		c.stats.ResyncErrors++
+	} else {
+		c.stats.Resyncs++
+	}
+	c.mu.Unlock()
+
+	if err != nil {
+		c.logger.Debug("resync not requested",
+			"ticker", snapshot.Ticker,
+			"error", err,
+		)
+	}
+}
+
+// Compare compares snapshot's YES and NO bids with ticker's book. Asks are
+// derived from bids on both sides, so bids cover the whole book. Returns
+// ok=false if there is no valid book for the snapshot's ticker.
+func (e *Engine) Compare(snapshot model.OrderbookSnapshot, tolerance int) (Divergence, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	b, ok := e.validBook(snapshot.Ticker)
+	if !ok {
+		return Divergence{}, false
+	}
+
+	var d Divergence
+	compareSide(&d, b.yes, snapshot.YesBids, tolerance)
+	compareSide(&d, b.no, snapshot.NoBids, tolerance)
+	return d, true
+}
+
+// compareSide adds the level differences between a book side and snapshot
+// levels to d.
+func compareSide(d *Divergence, side map[int]int, levels []model.PriceLevel, tolerance int) {
+	other := make(map[int]int, len(levels))
+	for _, l := range levels {
+		other[l.Price] += l.Size
+	}
+
+	add := func(diff int) {
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > d.MaxLevelDiff {
+			d.MaxLevelDiff = diff
+		}
+		if diff > tolerance {
+			d.Levels++
+		}
+	}
+
+	for p, s := range side {
+		add(s - other[p])
+	}
+	for p, s := range other {
+		if _, ok := side[p]; !ok {
+			add(s)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package book
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rickgao/kalshi-data/internal/model"
+)
+
+type fakeResyncer struct {
+	tickers []string
+	err     error
+}
+
+func (f *fakeResyncer) ResyncOrderbook(ticker string) error {
+	f.tickers = append(f.tickers, ticker)
+	return f.err
+}
+
+// restSnapshot matches snapshotMsg(1, 10).
+func restSnapshot() model.OrderbookSnapshot {
+	return model.OrderbookSnapshot{
+		Ticker:  "MKT",
+		Source:  "rest",
+		YesBids: []model.PriceLevel{{Price: 52000, Size: 50}, {Price: 50000, Size: 100}},
+		NoBids:  []model.PriceLevel{{Price: 45000, Size: 70}, {Price: 40000, Size: 30}},
+	}
+}
+
+func TestEngine_Compare(t *testing.T) {
+	e := NewEngine(nil, nil, nil)
+	e.Apply(snapshotMsg(1, 10))
+
+	tests := []struct {
+		name      string
+		modify    func(*model.OrderbookSnapshot)
+		tolerance int
+		want      Divergence
+	}{
+		{"identical", func(*model.OrderbookSnapshot) {}, 0, Divergence{}},
+		{"size differs", func(s *model.OrderbookSnapshot) { s.YesBids[1].Size = 90 }, 0, Divergence{Levels: 1, MaxLevelDiff: 10}},
+		{"within tolerance", func(s *model.OrderbookSnapshot) { s.YesBids[1].Size = 90 }, 10, Divergence{MaxLevelDiff: 10}},
+		{"missing from REST", func(s *model.OrderbookSnapshot) { s.NoBids = s.NoBids[:1] }, 0, Divergence{Levels: 1, MaxLevelDiff: 30}},
+		{"extra in REST", func(s *model.OrderbookSnapshot) {
+			s.NoBids = append(s.NoBids, model.PriceLevel{Price: 35000, Size: 5})
+		}, 0, Divergence{Levels: 1, MaxLevelDiff: 5}},
+		{"both sides", func(s *model.OrderbookSnapshot) {
+			s.YesBids[0].Size = 20
+			s.NoBids[0].Size = 75
+		}, 0, Divergence{Levels: 2, MaxLevelDiff: 30}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := restSnapshot()
+			tt.modify(&s)
+
+			got, ok := e.Compare(s, tt.tolerance)
+			if !ok {
+				t.Fatal("Compare() ok = false")
+			}
+			if got != tt.want {
+				t.Errorf("Compare() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := e.Compare(model.OrderbookSnapshot{Ticker: "OTHER"}, 0); ok {
+		t.Error("Compare() ok = true for unknown ticker")
+	}
+}
+
+func TestCrossChecker_HandleSnapshot(t *testing.T) {
+	e := NewEngine(nil, nil, nil)
+	e.Apply(snapshotMsg(1, 10))
+
+	next := &fakeHandler{}
+	resyncer := &fakeResyncer{}
+	c := NewCrossChecker(DefaultCrossCheckConfig(), e, next, resyncer, nil)
+
+	diverged := restSnapshot()
+	diverged.YesBids[0].Size = 10
+
+	// The first divergence awaits confirmation, the second resyncs
+	for _, s := range []model.OrderbookSnapshot{restSnapshot(), diverged, diverged, {Ticker: "OTHER"}} {
+		if err := c.HandleSnapshot(s); err != nil {
+			t.Errorf("HandleSnapshot(%s) error = %v", s.Ticker, err)
+		}
+	}
+
+	if got := next.count(); got != 4 {
+		t.Errorf("forwarded = %d, want 4", got)
+	}
+	if len(resyncer.tickers) != 1 || resyncer.tickers[0] != "MKT" {
+		t.Errorf("resynced = %v, want [MKT]", resyncer.tickers)
+	}
+
+	want := CrossCheckStats{
+		Checks:          3,
+		Unchecked:       1,
+		Divergences:     2,
+		Unconfirmed:     1,
+		DivergentLevels: 2,
+		MaxLevelDiff:    40,
+		Resyncs:         1,
+	}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCrossChecker_ResyncRefused(t *testing.T) {
+	e := NewEngine(nil, nil, nil)
+	e.Apply(snapshotMsg(1, 10))
+
+	next := &fakeHandler{err: errors.New("closed")}
+	resyncer := &fakeResyncer{err: errors.New("rate limited")}
+	c := NewCrossChecker(DefaultCrossCheckConfig(), e, next, resyncer, nil)
+
+	s := restSnapshot()
+	s.NoBids = nil
+
+	for i := 0; i < 2; i++ {
+		if err := c.HandleSnapshot(s); err == nil {
+			t.Error("HandleSnapshot() error = nil, want handler error")
+		}
+	}
+	if got := c.Stats(); got.Divergences != 2 || got.Resyncs != 0 || got.ResyncErrors != 1 {
+		t.Errorf("Stats() = %+v, want 2 divergences, 1 resync error", got)
+	}
+}
+
+func TestCrossChecker_InFlightDivergence(t *testing.T) {
+	e := NewEngine(nil, nil, nil)
+	e.Apply(snapshotMsg(1, 10))
+
+	resyncer := &fakeResyncer{}
+	c := NewCrossChecker(DefaultCrossCheckConfig(), e, nil, resyncer, nil)
+
+	diverged := restSnapshot()
+	diverged.YesBids[0].Size = 10
+
+	// A divergence that clears by the next poll was deltas in flight, and
+	// does not count towards a later one
+	for _, s := range []model.OrderbookSnapshot{diverged, restSnapshot(), diverged} {
+		c.HandleSnapshot(s)
+	}
+	if len(resyncer.tickers) != 0 {
+		t.Errorf("resynced = %v, want none", resyncer.tickers)
+	}
+	if got := c.Stats(); got.Divergences != 2 || got.Unconfirmed != 2 {
+		t.Errorf("Stats() = %+v, want 2 divergences, 2 unconfirmed", got)
+	}
+
+	// Confirmations of 1 resyncs on the first divergence
+	cfg := DefaultCrossCheckConfig()
+	cfg.Confirmations = 1
+	c = NewCrossChecker(cfg, e, nil, resyncer, nil)
+	c.HandleSnapshot(diverged)
+	if len(resyncer.tickers) != 1 {
+		t.Errorf("resynced = %v, want [MKT]", resyncer.tickers)
+	}
+}
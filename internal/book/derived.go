@@ -0,0 +1,163 @@
+package book
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/model"
+)
+
+// SnapshotHandler receives derived snapshots. writer.SnapshotWriter
+// implements it (as it does poller.SnapshotHandler).
+type SnapshotHandler interface {
+	HandleSnapshot(snapshot model.OrderbookSnapshot) error
+}
+
+// DerivedConfig configures periodic derived snapshots.
+type DerivedConfig struct {
+	Interval   time.Duration // How often to snapshot books (default: 1m)
+	MinChanges int           // Messages a book must apply between snapshots (default: 1)
+}
+
+// DefaultDerivedConfig returns sensible defaults.
+func DefaultDerivedConfig() DerivedConfig {
+	return DerivedConfig{
+		Interval:   time.Minute,
+		MinChanges: 1,
+	}
+}
+
+// DerivedStats holds derived snapshot counters.
+type DerivedStats struct {
+	Cycles  int64 // Snapshot cycles run
+	Emitted int64 // Snapshots passed to the handler
+	Idle    int64 // Book-cycles skipped below MinChanges
+	Errors  int64 // Handler errors
+}
+
+// DerivedSnapshotter periodically emits model.OrderbookSnapshot rows with
+// Source "derived" from the Engine's books, so point-in-time queries start
+// from a recent snapshot instead of replaying hours of deltas. Books that
+// applied fewer than MinChanges messages since their last derived snapshot
+// are skipped.
+type DerivedSnapshotter struct {
+	cfg     DerivedConfig
+	engine  *Engine
+	handler SnapshotHandler
+	logger  *slog.Logger
+
+	mu    sync.Mutex
+	stats DerivedStats
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDerivedSnapshotter creates a new DerivedSnapshotter.
+func NewDerivedSnapshotter(cfg DerivedConfig, engine *Engine, handler SnapshotHandler, logger *slog.Logger) *DerivedSnapshotter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &DerivedSnapshotter{
+		cfg:     cfg,
+		engine:  engine,
+		handler: handler,
+		logger:  logger,
+	}
+}
+
+// Start begins the snapshot loop.
+func (d *DerivedSnapshotter) Start(ctx context.Context) error {
+	d.ctx, d.cancel = context.WithCancel(ctx)
+
+	d.wg.Add(1)
+	go d.run()
+
+	d.logger.Info("derived snapshotter started",
+		"interval", d.cfg.Interval,
+		"min_changes", d.cfg.MinChanges,
+	)
+	return nil
+}
+
+// Stop gracefully shuts down the snapshotter.
+func (d *DerivedSnapshotter) Stop(ctx context.Context) error {
+	d.logger.Info("stopping derived snapshotter")
+
+	if d.cancel != nil {
+		d.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.logger.Info("derived snapshotter stopped")
+	case <-ctx.Done():
+		d.logger.Warn("derived snapshotter stop timed out")
+	}
+
+	return nil
+}
+
+// Stats returns current statistics.
+func (d *DerivedSnapshotter) Stats() DerivedStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stats
+}
+
+// run snapshots on every interval tick.
+func (d *DerivedSnapshotter) run() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			d.snapshotAll()
+		}
+	}
+}
+
+// snapshotAll emits one derived snapshot per changed book.
+func (d *DerivedSnapshotter) snapshotAll() {
+	start := time.Now()
+
+	snapshots, idle := d.engine.ChangedSnapshots(d.cfg.MinChanges)
+
+	var errCount int64
+	for _, s := range snapshots {
+		if err := d.handler.HandleSnapshot(s); err != nil {
+			errCount++
+			d.logger.Warn("derived snapshot handler failed",
+				"ticker", s.Ticker,
+				"error", err,
+			)
+		}
+	}
+
+	d.mu.Lock()
+	d.stats.Cycles++
+	d.stats.Emitted += int64(len(snapshots)) - errCount
+	d.stats.Idle += int64(idle)
+	d.stats.Errors += errCount
+	d.mu.Unlock()
+
+	d.logger.Debug("derived snapshots emitted",
+		"emitted", len(snapshots),
+		"idle", idle,
+		"duration", time.Since(start),
+	)
+}
@@ -0,0 +1,132 @@
+package book
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/model"
+)
+
+type fakeHandler struct {
+	mu        sync.Mutex
+	snapshots []model.OrderbookSnapshot
+	err       error
+}
+
+func (f *fakeHandler) HandleSnapshot(s model.OrderbookSnapshot) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.snapshots = append(f.snapshots, s)
+	return nil
+}
+
+func (f *fakeHandler) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.snapshots)
+}
+
+func TestEngine_ChangedSnapshots(t *testing.T) {
+	e := NewEngine(nil, nil, nil)
+	e.Apply(snapshotMsg(1, 10)) // 1 change
+
+	tests := []struct {
+		name        string
+		apply       int // Deltas applied before the call
+		minChanges  int
+		wantEmitted int
+		wantIdle    int
+	}{
+		{"snapshot counts as a change", 0, 1, 1, 0},
+		{"no changes since last", 0, 1, 0, 1},
+		{"below threshold", 2, 3, 0, 1},
+		{"threshold reached across calls", 1, 3, 1, 0},
+		{"counter reset", 0, 1, 0, 1},
+	}
+
+	seq := int64(10)
+	for _, tt := range tests {
+		for i := 0; i < tt.apply; i++ {
+			seq++
+			if err := e.Apply(deltaMsg(1, seq, "yes", "0.30", 1)); err != nil {
+				t.Fatalf("%s: Apply() error = %v", tt.name, err)
+			}
+		}
+
+		snaps, idle := e.ChangedSnapshots(tt.minChanges)
+		if len(snaps) != tt.wantEmitted || idle != tt.wantIdle {
+			t.Errorf("%s: ChangedSnapshots(%d) = %d snapshots, %d idle, want %d, %d",
+				tt.name, tt.minChanges, len(snaps), idle, tt.wantEmitted, tt.wantIdle)
+		}
+		for _, s := range snaps {
+			if s.Source != SourceDerived {
+				t.Errorf("%s: Source = %s, want %s", tt.name, s.Source, SourceDerived)
+			}
+		}
+	}
+}
+
+func TestEngine_ChangedSnapshotsSkipsInvalid(t *testing.T) {
+	e := NewEngine(nil, nil, nil)
+	e.Apply(snapshotMsg(1, 10))
+	e.Apply(deltaMsg(1, 20, "yes", "0.51", 10)) // Gap
+
+	snaps, idle := e.ChangedSnapshots(1)
+	if len(snaps) != 0 || idle != 0 {
+		t.Errorf("ChangedSnapshots(1) = %d snapshots, %d idle, want 0, 0", len(snaps), idle)
+	}
+}
+
+func TestDerivedSnapshotter_Run(t *testing.T) {
+	e := NewEngine(nil, nil, nil)
+	e.Apply(snapshotMsg(1, 10))
+
+	h := &fakeHandler{}
+	d := NewDerivedSnapshotter(DerivedConfig{Interval: 10 * time.Millisecond, MinChanges: 1}, e, h, nil)
+
+	if err := d.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for d.Stats().Idle < 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Stats() = %+v, want an idle cycle", d.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := d.Stop(stopCtx); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+
+	// The unchanged book is written once, then skipped every cycle
+	if got := h.count(); got != 1 {
+		t.Errorf("handled = %d, want 1", got)
+	}
+	if s := d.Stats(); s.Emitted != 1 || s.Errors != 0 {
+		t.Errorf("Stats() = %+v, want Emitted 1, Errors 0", s)
+	}
+}
+
+func TestDerivedSnapshotter_HandlerError(t *testing.T) {
+	e := NewEngine(nil, nil, nil)
+	e.Apply(snapshotMsg(1, 10))
+
+	h := &fakeHandler{err: errors.New("closed")}
+	d := NewDerivedSnapshotter(DefaultDerivedConfig(), e, h, nil)
+	d.snapshotAll()
+
+	want := DerivedStats{Cycles: 1, Errors: 1}
+	if got := d.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
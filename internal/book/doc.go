@@ -0,0 +1,9 @@
+// Package book implements the in-memory L2 orderbook engine.
+//
+// The Book Engine:
+//   - Consumes orderbook snapshots and deltas from the Message Router
+//   - Keeps per-market YES/NO price-level maps, applied in seq order
+//   - Detects impossible states (gaps, negative sizes, crossed books)
+//   - Exposes best bid/ask, depth-N and full model.OrderbookSnapshot
+//   - Optionally writes periodic derived snapshots (DerivedSnapshotter)
+package book
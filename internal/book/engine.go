@@ -0,0 +1,463 @@
+package book
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/model"
+	"github.com/rickgao/kalshi-data/internal/router"
+)
+
+// EngineStats holds cumulative counters and current book counts.
+type EngineStats struct {
+	Books        int // Books tracked
+	InvalidBooks int // Books waiting for a snapshot after an impossible state
+
+	Snapshots     int64 // Snapshots applied
+	Deltas        int64 // Deltas applied
+	Ignored       int64 // Stale-SID, duplicate, pre-snapshot or invalid-book deltas
+	SequenceGaps  int64 // Gaps that invalidated a book
+	NegativeSizes int64 // Deltas that would take a level below zero
+	CrossedBooks  int64 // Updates that left YES bid >= YES ask
+	Errors        int64 // Malformed messages
+	Pruned        int64 // Books dropped because their market stopped trading
+}
+
+// MarketSource looks up markets. market.Registry implements it.
+type MarketSource interface {
+	GetMarket(ticker string) (model.Market, bool)
+}
+
+// pruneInterval is how often books of markets that stopped trading (settled,
+// closed or unsubscribed) are dropped.
+const pruneInterval = time.Minute
+
+// Engine maintains in-memory L2 books for every subscribed market.
+//
+// Messages are applied by a single consumer; queries are safe from any
+// goroutine and return copies. Books that reach an impossible state stay
+// invalid, and are hidden from queries, until the next snapshot. Books of
+// markets that stop trading are dropped within pruneInterval.
+type Engine struct {
+	logger *slog.Logger
+
+	// Input from Message Router
+	input *router.GrowableBuffer[router.OrderbookMsg]
+
+	// Market status, to drop books of markets no longer trading (nil keeps
+	// every book)
+	markets MarketSource
+
+	mu    sync.RWMutex
+	books map[string]*book
+	stats EngineStats
+
+	// Seq runs per subscription across all of its markets
+	seqs     map[int64]int64               // SID → last seq
+	sidBooks map[int64]map[string]struct{} // SID → tickers of its books
+
+	// Lifecycle
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewEngine creates an Engine fed from input, normally RouterBuffers.Book.
+// input may be nil if messages are passed to Apply directly, and markets nil
+// if books are never to be pruned.
+func NewEngine(input *router.GrowableBuffer[router.OrderbookMsg], markets MarketSource, logger *slog.Logger) *Engine {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Engine{
+		logger:   logger,
+		input:    input,
+		markets:  markets,
+		books:    make(map[string]*book),
+		seqs:     make(map[int64]int64),
+		sidBooks: make(map[int64]map[string]struct{}),
+	}
+}
+
+// Start begins consuming the input buffer.
+func (e *Engine) Start(ctx context.Context) error {
+	e.ctx, e.cancel = context.WithCancel(ctx)
+
+	e.wg.Add(1)
+	go e.consumeLoop()
+
+	if e.markets != nil {
+		e.wg.Add(1)
+		go e.pruneLoop()
+	}
+
+	e.logger.Info("book engine started")
+	return nil
+}
+
+// Stop stops consuming. Books remain queryable.
+func (e *Engine) Stop(ctx context.Context) error {
+	e.logger.Info("stopping book engine")
+
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		e.logger.Info("book engine stopped")
+	case <-ctx.Done():
+		e.logger.Warn("book engine stop timed out")
+	}
+
+	return nil
+}
+
+// consumeLoop reads from the input buffer and applies messages.
+func (e *Engine) consumeLoop() {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		default:
+			msg, ok := e.input.TryReceive()
+			if !ok {
+				select {
+				case <-e.ctx.Done():
+					return
+				case <-time.After(10 * time.Millisecond):
+					continue
+				}
+			}
+
+			if err := e.Apply(msg); err != nil {
+				e.logApplyError(msg, err)
+			}
+		}
+	}
+}
+
+// pruneLoop drops books of markets no longer trading every pruneInterval.
+func (e *Engine) pruneLoop() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+			if n := e.prune(); n > 0 {
+				e.logger.Debug("pruned books", "count", n)
+			}
+		}
+	}
+}
+
+// prune drops the books of markets that are unknown or no longer open for
+// trading; the Connection Manager has unsubscribed them, so they would only
+// go stale. Returns the number of books dropped.
+func (e *Engine) prune() int {
+	e.mu.RLock()
+	tickers := make([]string, 0, len(e.books))
+	for t := range e.books {
+		tickers = append(tickers, t)
+	}
+	e.mu.RUnlock()
+
+	var stale []string
+	for _, t := range tickers {
+		if m, ok := e.markets.GetMarket(t); !ok || !trading(m.MarketStatus) {
+			stale = append(stale, t)
+		}
+	}
+	if len(stale) == 0 {
+		return 0
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	pruned := 0
+	for _, t := range stale {
+		if b, ok := e.books[t]; ok {
+			e.removeBook(b)
+			pruned++
+		}
+	}
+	e.stats.Pruned += int64(pruned)
+	return pruned
+}
+
+// trading reports whether a market status means the market is subscribed,
+// matching the Market Registry's active set.
+func trading(status string) bool {
+	return status == "active" || status == "open"
+}
+
+// Apply applies a snapshot or delta to its market's book. A subscription
+// covers many markets and its seq runs across all of them, so a gap
+// invalidates every book on the SID and is returned as ErrSequenceGap.
+func (e *Engine) Apply(msg router.OrderbookMsg) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if msg.Type == "snapshot" || msg.Type == "delta" {
+		if err := e.sequence(msg); err != nil {
+			e.count(err)
+			return fmt.Errorf("%s: %w", msg.Ticker, err)
+		}
+	}
+
+	b, exists := e.books[msg.Ticker]
+
+	var err error
+	switch msg.Type {
+	case "snapshot":
+		if !exists {
+			b = newBook(msg.Ticker)
+			e.books[msg.Ticker] = b
+		}
+		if !exists || b.sid != msg.SID {
+			e.moveBook(b, msg.SID)
+		}
+		err = b.applySnapshot(msg)
+		e.stats.Snapshots++
+
+	case "delta":
+		if !exists {
+			err = ErrNoSnapshot
+		} else {
+			err = b.applyDelta(msg)
+		}
+		if err == nil {
+			e.stats.Deltas++
+		}
+
+	default:
+		err = fmt.Errorf("%w %q", ErrUnknownType, msg.Type)
+	}
+
+	if err != nil {
+		e.count(err)
+		return fmt.Errorf("%s: %w", msg.Ticker, err)
+	}
+	return nil
+}
+
+// sequence checks msg against the last seq on its SID. Duplicates return
+// ErrDuplicateSeq. A gap invalidates every book on the SID; a snapshot is
+// still applied after it, a delta returns ErrSequenceGap. Caller holds e.mu.
+func (e *Engine) sequence(msg router.OrderbookMsg) error {
+	if msg.Seq == 0 {
+		return nil
+	}
+
+	last, seen := e.seqs[msg.SID]
+	if seen && msg.Seq <= last {
+		return ErrDuplicateSeq
+	}
+	e.seqs[msg.SID] = msg.Seq
+	if !seen || msg.Seq == last+1 {
+		return nil
+	}
+
+	invalidated := 0
+	for t := range e.sidBooks[msg.SID] {
+		if b := e.books[t]; b.valid {
+			b.valid = false
+			invalidated++
+		}
+	}
+
+	gapErr := fmt.Errorf("%w: sid %d expected %d, got %d", ErrSequenceGap, msg.SID, last+1, msg.Seq)
+	e.logger.Warn("books invalidated by sequence gap",
+		"sid", msg.SID,
+		"expected", last+1,
+		"got", msg.Seq,
+		"books", invalidated,
+	)
+	if msg.Type == "delta" {
+		return gapErr
+	}
+	e.stats.SequenceGaps++
+	return nil
+}
+
+// moveBook records b moving to sid. Caller holds e.mu.
+func (e *Engine) moveBook(b *book, sid int64) {
+	e.leaveSID(b)
+	if e.sidBooks[sid] == nil {
+		e.sidBooks[sid] = make(map[string]struct{})
+	}
+	e.sidBooks[sid][b.ticker] = struct{}{}
+}
+
+// removeBook drops b. Caller holds e.mu.
+func (e *Engine) removeBook(b *book) {
+	e.leaveSID(b)
+	delete(e.books, b.ticker)
+}
+
+// leaveSID removes b from its SID's books, dropping the seq of a SID left
+// without books. Caller holds e.mu.
+func (e *Engine) leaveSID(b *book) {
+	books, ok := e.sidBooks[b.sid]
+	if !ok {
+		return
+	}
+	delete(books, b.ticker)
+	if len(books) == 0 {
+		delete(e.sidBooks, b.sid)
+		delete(e.seqs, b.sid)
+	}
+}
+
+// count records an Apply error in stats. Caller holds e.mu.
+func (e *Engine) count(err error) {
+	switch {
+	case errors.Is(err, ErrSequenceGap):
+		e.stats.SequenceGaps++
+	case errors.Is(err, ErrNegativeSize):
+		e.stats.NegativeSizes++
+	case errors.Is(err, ErrCrossedBook):
+		e.stats.CrossedBooks++
+	case errors.Is(err, ErrNoSnapshot), errors.Is(err, ErrStaleSID),
+		errors.Is(err, ErrDuplicateSeq), errors.Is(err, ErrInvalidBook):
+		e.stats.Ignored++
+	default:
+		e.stats.Errors++
+	}
+}
+
+// logApplyError logs impossible states loudly and expected skips quietly.
+func (e *Engine) logApplyError(msg router.OrderbookMsg, err error) {
+	switch {
+	case errors.Is(err, ErrSequenceGap), errors.Is(err, ErrNegativeSize), errors.Is(err, ErrCrossedBook):
+		e.logger.Warn("book invalidated",
+			"ticker", msg.Ticker,
+			"sid", msg.SID,
+			"seq", msg.Seq,
+			"error", err,
+		)
+	default:
+		e.logger.Debug("orderbook message not applied",
+			"ticker", msg.Ticker,
+			"sid", msg.SID,
+			"seq", msg.Seq,
+			"error", err,
+		)
+	}
+}
+
+// validBook returns the book for ticker if it exists and is valid. Caller holds e.mu.
+func (e *Engine) validBook(ticker string) (*book, bool) {
+	b, ok := e.books[ticker]
+	if !ok || !b.valid {
+		return nil, false
+	}
+	return b, true
+}
+
+// Quote returns the best YES bid and ask for ticker.
+func (e *Engine) Quote(ticker string) (Quote, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	b, ok := e.validBook(ticker)
+	if !ok {
+		return Quote{}, false
+	}
+	return b.quote(), true
+}
+
+// Depth returns the top n levels per side for ticker. n <= 0 returns all levels.
+func (e *Engine) Depth(ticker string, n int) (Depth, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	b, ok := e.validBook(ticker)
+	if !ok {
+		return Depth{}, false
+	}
+	return b.depth(n), true
+}
+
+// Snapshot returns the full book for ticker with Source "derived".
+func (e *Engine) Snapshot(ticker string) (model.OrderbookSnapshot, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	b, ok := e.validBook(ticker)
+	if !ok {
+		return model.OrderbookSnapshot{}, false
+	}
+	return b.snapshot(time.Now()), true
+}
+
+// ChangedSnapshots returns snapshots of valid books that applied at least
+// minChanges messages since their last ChangedSnapshots, and resets their
+// change counts. idle counts valid books below the threshold.
+func (e *Engine) ChangedSnapshots(minChanges int) (snapshots []model.OrderbookSnapshot, idle int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	for _, b := range e.books {
+		if !b.valid {
+			continue
+		}
+		if b.changes < minChanges {
+			idle++
+			continue
+		}
+		snapshots = append(snapshots, b.snapshot(now))
+		b.changes = 0
+	}
+	return snapshots, idle
+}
+
+// Tickers returns the markets with a valid book, sorted.
+func (e *Engine) Tickers() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	tickers := make([]string, 0, len(e.books))
+	for t, b := range e.books {
+		if b.valid {
+			tickers = append(tickers, t)
+		}
+	}
+	sort.Strings(tickers)
+	return tickers
+}
+
+// Stats returns current statistics.
+func (e *Engine) Stats() EngineStats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	s := e.stats
+	s.Books = len(e.books)
+	for _, b := range e.books {
+		if !b.valid {
+			s.InvalidBooks++
+		}
+	}
+	return s
+}
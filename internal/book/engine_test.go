@@ -0,0 +1,203 @@
+package book
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/model"
+	"github.com/rickgao/kalshi-data/internal/router"
+)
+
+// fakeMarkets serves market statuses from a map.
+type fakeMarkets map[string]string
+
+func (f fakeMarkets) GetMarket(ticker string) (model.Market, bool) {
+	status, ok := f[ticker]
+	return model.Market{Ticker: ticker, MarketStatus: status}, ok
+}
+
+func TestEngine_Apply(t *testing.T) {
+	e := NewEngine(nil, nil, nil)
+
+	msgs := []struct {
+		msg     router.OrderbookMsg
+		wantErr error
+	}{
+		{deltaMsg(1, 9, "yes", "0.50", 1), ErrNoSnapshot},
+		{snapshotMsg(1, 10), nil},
+		{deltaMsg(1, 11, "yes", "0.51", 10), nil},
+		{deltaMsg(1, 11, "yes", "0.51", 10), ErrDuplicateSeq},
+		{deltaMsg(1, 12, "no", "0.45", -100), ErrNegativeSize},
+		{deltaMsg(1, 13, "yes", "0.51", 10), ErrInvalidBook},
+		{router.OrderbookMsg{Type: "other", Ticker: "MKT"}, ErrUnknownType},
+	}
+
+	for i, m := range msgs {
+		if err := e.Apply(m.msg); !errors.Is(err, m.wantErr) {
+			t.Errorf("Apply #%d error = %v, want %v", i, err, m.wantErr)
+		}
+	}
+
+	want := EngineStats{
+		Books:         1,
+		InvalidBooks:  1,
+		Snapshots:     1,
+		Deltas:        1,
+		Ignored:       3,
+		NegativeSizes: 1,
+		Errors:        1,
+	}
+	if got := e.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestEngine_SharedSIDGap(t *testing.T) {
+	e := NewEngine(nil, nil, nil)
+
+	other := snapshotMsg(1, 11)
+	other.Ticker = "OTHER"
+	msgs := []struct {
+		msg     router.OrderbookMsg
+		wantErr error
+	}{
+		{snapshotMsg(1, 10), nil},
+		{other, nil},
+		{deltaMsg(1, 12, "yes", "0.51", 10), nil}, // MKT skips OTHER's seq
+		{deltaMsg(1, 12, "yes", "0.51", 10), ErrDuplicateSeq},
+		{deltaMsg(1, 15, "yes", "0.51", 10), ErrSequenceGap},
+	}
+
+	for i, m := range msgs {
+		if err := e.Apply(m.msg); !errors.Is(err, m.wantErr) {
+			t.Errorf("Apply #%d error = %v, want %v", i, err, m.wantErr)
+		}
+	}
+
+	// The gap may have hit either market
+	for _, ticker := range []string{"MKT", "OTHER"} {
+		if _, ok := e.Quote(ticker); ok {
+			t.Errorf("Quote(%s) ok = true after gap on its SID", ticker)
+		}
+	}
+
+	// A snapshot under a new SID restores that book only
+	resub := snapshotMsg(2, 1)
+	resub.Ticker = "OTHER"
+	if err := e.Apply(resub); err != nil {
+		t.Fatalf("Apply(snapshot) error = %v", err)
+	}
+	if _, ok := e.Quote("OTHER"); !ok {
+		t.Error("Quote(OTHER) ok = false after snapshot")
+	}
+	if got := e.Stats().SequenceGaps; got != 1 {
+		t.Errorf("SequenceGaps = %d, want 1", got)
+	}
+}
+
+func TestEngine_Prune(t *testing.T) {
+	markets := fakeMarkets{"MKT": "open", "SETTLED": "settled"}
+	e := NewEngine(nil, markets, nil)
+
+	for i, ticker := range []string{"MKT", "SETTLED", "GONE"} {
+		msg := snapshotMsg(1, int64(i+1))
+		msg.Ticker = ticker
+		e.Apply(msg)
+	}
+	other := snapshotMsg(2, 1)
+	other.Ticker = "ALONE"
+	e.Apply(other)
+
+	if n := e.prune(); n != 3 {
+		t.Errorf("prune() = %d, want 3", n)
+	}
+	if got := e.Tickers(); len(got) != 1 || got[0] != "MKT" {
+		t.Errorf("Tickers() = %v, want [MKT]", got)
+	}
+	if got := e.Stats(); got.Books != 1 || got.Pruned != 3 {
+		t.Errorf("Books, Pruned = %d, %d, want 1, 3", got.Books, got.Pruned)
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if len(e.sidBooks) != 1 || len(e.sidBooks[1]) != 1 {
+		t.Errorf("sidBooks = %v, want only MKT on SID 1", e.sidBooks)
+	}
+	if _, ok := e.seqs[2]; ok {
+		t.Error("seq of SID 2 kept after its last book was pruned")
+	}
+}
+
+func TestEngine_QueriesHideInvalidBooks(t *testing.T) {
+	e := NewEngine(nil, nil, nil)
+	e.Apply(snapshotMsg(1, 10))
+
+	if _, ok := e.Quote("MKT"); !ok {
+		t.Fatal("Quote() ok = false for valid book")
+	}
+	if got := e.Tickers(); len(got) != 1 || got[0] != "MKT" {
+		t.Errorf("Tickers() = %v, want [MKT]", got)
+	}
+
+	e.Apply(deltaMsg(1, 20, "yes", "0.51", 10)) // Gap
+
+	if _, ok := e.Quote("MKT"); ok {
+		t.Error("Quote() ok = true for invalid book")
+	}
+	if _, ok := e.Depth("MKT", 5); ok {
+		t.Error("Depth() ok = true for invalid book")
+	}
+	if _, ok := e.Snapshot("MKT"); ok {
+		t.Error("Snapshot() ok = true for invalid book")
+	}
+	if got := e.Tickers(); len(got) != 0 {
+		t.Errorf("Tickers() = %v, want []", got)
+	}
+	if got := e.Stats().SequenceGaps; got != 1 {
+		t.Errorf("SequenceGaps = %d, want 1", got)
+	}
+}
+
+func TestEngine_ConsumesBuffer(t *testing.T) {
+	buf := router.NewGrowableBuffer[router.OrderbookMsg](10)
+	e := NewEngine(buf, nil, nil)
+
+	if err := e.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	buf.Send(snapshotMsg(1, 10))
+	buf.Send(deltaMsg(1, 11, "yes", "0.53", 20))
+
+	deadline := time.Now().Add(time.Second)
+	for e.Stats().Deltas < 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Stats() = %+v, want 1 delta applied", e.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := e.Stop(stopCtx); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+
+	q, ok := e.Quote("MKT")
+	if !ok {
+		t.Fatal("Quote() ok = false")
+	}
+	if q.YesBid.Price != 53000 || q.YesBid.Size != 20 {
+		t.Errorf("YesBid = %+v, want {53000 20}", q.YesBid)
+	}
+
+	snap, ok := e.Snapshot("MKT")
+	if !ok {
+		t.Fatal("Snapshot() ok = false")
+	}
+	if snap.Ticker != "MKT" || len(snap.YesBids) != 3 {
+		t.Errorf("Snapshot() = %s with %d YES bids, want MKT with 3", snap.Ticker, len(snap.YesBids))
+	}
+}
@@ -10,6 +10,8 @@ type GathererConfig struct {
 	Connections ConnectionsConfig `yaml:"connections"`
 	Writers     WritersConfig     `yaml:"writers"`
 	Poller      PollerConfig      `yaml:"poller"`
+	Book        BookConfig        `yaml:"book"`
+	Journal     JournalConfig     `yaml:"journal"`
 	Metrics     MetricsConfig     `yaml:"metrics"`
 }
 
@@ -21,18 +23,31 @@ type InstanceConfig struct {
 
 // APIConfig holds Kalshi API settings.
 type APIConfig struct {
-	RestURL        string        `yaml:"rest_url"`
-	WSURL          string        `yaml:"ws_url"`
-	APIKey         string        `yaml:"api_key"`          // API key ID (for KALSHI-ACCESS-KEY header)
-	PrivateKeyPath string        `yaml:"private_key_path"` // Path to RSA private key PEM file
-	Timeout        time.Duration `yaml:"timeout"`
-	MaxRetries     int           `yaml:"max_retries"`
+	RestURL        string          `yaml:"rest_url"`
+	WSURL          string          `yaml:"ws_url"`
+	APIKey         string          `yaml:"api_key"`          // API key ID (for KALSHI-ACCESS-KEY header)
+	PrivateKeyPath string          `yaml:"private_key_path"` // Path to RSA private key PEM file
+	Timeout        time.Duration   `yaml:"timeout"`
+	MaxRetries     int             `yaml:"max_retries"`
+	RateLimit      RateLimitConfig `yaml:"rate_limit"`
+}
+
+// RateLimitConfig holds the client-side REST rate limits, shared by every
+// caller of the API client. Set them to the account's Kalshi tier.
+type RateLimitConfig struct {
+	ReadPerSecond  float64 `yaml:"read_per_second"`
+	WritePerSecond float64 `yaml:"write_per_second"`
+	Burst          int     `yaml:"burst"`
 }
 
 // DatabaseConfig holds the TimescaleDB connection for time-series data.
 // Note: Gatherers only use TimescaleDB. Market metadata lives in-memory (Market Registry).
 type DatabaseConfig struct {
 	Timescale DBConfig `yaml:"timescale"`
+
+	// AutoMigrate applies pending schema migrations on startup. When false
+	// the gatherer only checks that the schema is current.
+	AutoMigrate bool `yaml:"auto_migrate"`
 }
 
 // DBConfig holds a single database connection.
@@ -52,6 +67,12 @@ type ConnectionsConfig struct {
 	OrderbookCount       int           `yaml:"orderbook_count"`
 	MarketsPerConnection int           `yaml:"markets_per_connection"`
 	GlobalCount          int           `yaml:"global_count"`
+	MaxMessageRate       int           `yaml:"max_message_rate"`
+	RebalanceInterval    time.Duration `yaml:"rebalance_interval"`
+	OverflowPolicy       string        `yaml:"overflow_policy"` // "block", "spill" or "drop"
+	OverflowTimeout      time.Duration `yaml:"overflow_timeout"`
+	SpillDir             string        `yaml:"spill_dir"`
+	SpillMaxMB           int           `yaml:"spill_max_mb"`
 	ReconnectBaseDelay   time.Duration `yaml:"reconnect_base_delay"`
 	ReconnectMaxDelay    time.Duration `yaml:"reconnect_max_delay"`
 	PingInterval         time.Duration `yaml:"ping_interval"`
@@ -63,12 +84,65 @@ type WritersConfig struct {
 	BatchSize     int           `yaml:"batch_size"`
 	FlushInterval time.Duration `yaml:"flush_interval"`
 	BufferSize    int           `yaml:"buffer_size"`
+	InsertMode    string        `yaml:"insert_mode"` // "copy" or "batch"
+	Retry         RetryConfig   `yaml:"retry"`
+}
+
+// RetryConfig holds retry and dead-letter settings for failed writer flushes.
+// Batches that still fail after MaxAttempts are spilled to DeadLetterDir and
+// replayed in order once the database recovers; an empty dir drops them.
+type RetryConfig struct {
+	MaxAttempts    int           `yaml:"max_attempts"`
+	BaseDelay      time.Duration `yaml:"base_delay"`
+	MaxDelay       time.Duration `yaml:"max_delay"`
+	DeadLetterDir  string        `yaml:"dead_letter_dir"`
+	ReplayInterval time.Duration `yaml:"replay_interval"`
 }
 
 // PollerConfig holds snapshot poller settings.
 type PollerConfig struct {
 	Interval    time.Duration `yaml:"interval"`
 	Concurrency int           `yaml:"concurrency"`
+
+	// DivergenceTolerance is the per-level size difference between a REST
+	// snapshot and the in-memory book that is not treated as divergence.
+	DivergenceTolerance int `yaml:"divergence_tolerance"`
+
+	Candles CandlesConfig `yaml:"candles"`
+}
+
+// CandlesConfig holds exchange candlestick fetcher settings. Each cycle
+// fetches the Lookback window ending now, so Lookback should exceed
+// Interval by at least one period.
+type CandlesConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	Interval      time.Duration `yaml:"interval"`
+	PeriodMinutes int           `yaml:"period_minutes"`
+	Lookback      time.Duration `yaml:"lookback"`
+}
+
+// BookConfig holds in-memory book settings.
+type BookConfig struct {
+	DerivedSnapshots DerivedSnapshotsConfig `yaml:"derived_snapshots"`
+}
+
+// DerivedSnapshotsConfig holds periodic derived snapshot settings.
+// Books that applied fewer than MinChanges messages since their last
+// derived snapshot are skipped.
+type DerivedSnapshotsConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	Interval   time.Duration `yaml:"interval"`
+	MinChanges int           `yaml:"min_changes"`
+}
+
+// JournalConfig holds raw WebSocket message journal settings.
+type JournalConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	Dir           string        `yaml:"dir"`
+	MaxSegmentMB  int           `yaml:"max_segment_mb"`
+	MaxSegmentAge time.Duration `yaml:"max_segment_age"`
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	Retention     time.Duration `yaml:"retention"` // 0 keeps all segments
 }
 
 // MetricsConfig holds Prometheus metrics settings.
@@ -76,3 +150,37 @@ type MetricsConfig struct {
 	Port int    `yaml:"port"`
 	Path string `yaml:"path"`
 }
+
+// DeduplicatorConfig is the root configuration for the deduplicator.
+type DeduplicatorConfig struct {
+	Instance   InstanceConfig `yaml:"instance"`
+	Sources    []SourceConfig `yaml:"sources"`
+	Production DBConfig       `yaml:"production"`
+	Sync       SyncConfig     `yaml:"sync"`
+	S3         S3Config       `yaml:"s3"`
+	Metrics    MetricsConfig  `yaml:"metrics"`
+}
+
+// SourceConfig identifies a gatherer TimescaleDB to sync from.
+// The ID is stored as sync_cursors.gatherer_id and must be stable.
+type SourceConfig struct {
+	ID       string `yaml:"id"`
+	DBConfig `yaml:",inline"`
+}
+
+// SyncConfig holds cursor-based sync settings.
+type SyncConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+	Lookback     time.Duration `yaml:"lookback"`
+	QueryTimeout time.Duration `yaml:"query_timeout"`
+}
+
+// S3Config holds S3 export settings.
+type S3Config struct {
+	Enabled        bool          `yaml:"enabled"`
+	Bucket         string        `yaml:"bucket"`
+	Prefix         string        `yaml:"prefix"`
+	Region         string        `yaml:"region"`
+	ExportInterval time.Duration `yaml:"export_interval"`
+}
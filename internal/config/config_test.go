@@ -23,6 +23,7 @@ database:
     name: test_ts
     user: testuser
     password: testpass
+  auto_migrate: true
 `
 		path := writeTempFile(t, yaml)
 
@@ -43,6 +44,9 @@ database:
 		if cfg.Database.Timescale.Host != "localhost" {
 			t.Errorf("Database.Timescale.Host = %q, want %q", cfg.Database.Timescale.Host, "localhost")
 		}
+		if !cfg.Database.AutoMigrate {
+			t.Error("Database.AutoMigrate = false, want true")
+		}
 	})
 
 	t.Run("file not found", func(t *testing.T) {
@@ -197,6 +201,12 @@ database:
 	if cfg.API.MaxRetries != DefaultMaxRetries {
 		t.Errorf("API.MaxRetries = %d, want default %d", cfg.API.MaxRetries, DefaultMaxRetries)
 	}
+	if cfg.API.RateLimit.ReadPerSecond != DefaultReadPerSecond || cfg.API.RateLimit.WritePerSecond != DefaultWritePerSecond {
+		t.Errorf("API.RateLimit = %+v, want default read %v, write %v", cfg.API.RateLimit, DefaultReadPerSecond, DefaultWritePerSecond)
+	}
+	if cfg.API.RateLimit.Burst != DefaultRateLimitBurst {
+		t.Errorf("API.RateLimit.Burst = %d, want default %d", cfg.API.RateLimit.Burst, DefaultRateLimitBurst)
+	}
 
 	// Check database defaults
 	if cfg.Database.Timescale.Port != DefaultDBPort {
@@ -222,6 +232,21 @@ database:
 	if cfg.Connections.GlobalCount != DefaultGlobalCount {
 		t.Errorf("Connections.GlobalCount = %d, want default %d", cfg.Connections.GlobalCount, DefaultGlobalCount)
 	}
+	if cfg.Connections.MaxMessageRate != DefaultMaxMessageRate {
+		t.Errorf("Connections.MaxMessageRate = %d, want default %d", cfg.Connections.MaxMessageRate, DefaultMaxMessageRate)
+	}
+	if cfg.Connections.RebalanceInterval != DefaultRebalanceInterval {
+		t.Errorf("Connections.RebalanceInterval = %v, want default %v", cfg.Connections.RebalanceInterval, DefaultRebalanceInterval)
+	}
+	if cfg.Connections.OverflowPolicy != DefaultOverflowPolicy {
+		t.Errorf("Connections.OverflowPolicy = %q, want default %q", cfg.Connections.OverflowPolicy, DefaultOverflowPolicy)
+	}
+	if cfg.Connections.OverflowTimeout != DefaultOverflowTimeout {
+		t.Errorf("Connections.OverflowTimeout = %v, want default %v", cfg.Connections.OverflowTimeout, DefaultOverflowTimeout)
+	}
+	if cfg.Connections.SpillMaxMB != DefaultSpillMaxMB {
+		t.Errorf("Connections.SpillMaxMB = %d, want default %d", cfg.Connections.SpillMaxMB, DefaultSpillMaxMB)
+	}
 	if cfg.Connections.ReconnectBaseDelay != DefaultReconnectBaseDelay {
 		t.Errorf("Connections.ReconnectBaseDelay = %v, want default %v", cfg.Connections.ReconnectBaseDelay, DefaultReconnectBaseDelay)
 	}
@@ -245,6 +270,18 @@ database:
 	if cfg.Writers.BufferSize != DefaultBufferSize {
 		t.Errorf("Writers.BufferSize = %d, want default %d", cfg.Writers.BufferSize, DefaultBufferSize)
 	}
+	if cfg.Writers.InsertMode != DefaultInsertMode {
+		t.Errorf("Writers.InsertMode = %q, want default %q", cfg.Writers.InsertMode, DefaultInsertMode)
+	}
+	if cfg.Writers.Retry.MaxAttempts != DefaultRetryAttempts {
+		t.Errorf("Writers.Retry.MaxAttempts = %d, want default %d", cfg.Writers.Retry.MaxAttempts, DefaultRetryAttempts)
+	}
+	if cfg.Writers.Retry.ReplayInterval != DefaultReplayInterval {
+		t.Errorf("Writers.Retry.ReplayInterval = %v, want default %v", cfg.Writers.Retry.ReplayInterval, DefaultReplayInterval)
+	}
+	if cfg.Writers.Retry.DeadLetterDir != "" {
+		t.Errorf("Writers.Retry.DeadLetterDir = %q, want empty", cfg.Writers.Retry.DeadLetterDir)
+	}
 
 	// Check poller defaults
 	if cfg.Poller.Interval != DefaultPollInterval {
@@ -253,6 +290,40 @@ database:
 	if cfg.Poller.Concurrency != DefaultPollConcurrency {
 		t.Errorf("Poller.Concurrency = %d, want default %d", cfg.Poller.Concurrency, DefaultPollConcurrency)
 	}
+	if cfg.Poller.Candles.Enabled {
+		t.Error("Poller.Candles.Enabled = true, want default false")
+	}
+	if cfg.Poller.Candles.Interval != DefaultCandleInterval {
+		t.Errorf("Poller.Candles.Interval = %v, want default %v", cfg.Poller.Candles.Interval, DefaultCandleInterval)
+	}
+	if cfg.Poller.Candles.PeriodMinutes != DefaultCandlePeriodMinutes {
+		t.Errorf("Poller.Candles.PeriodMinutes = %d, want default %d", cfg.Poller.Candles.PeriodMinutes, DefaultCandlePeriodMinutes)
+	}
+	if cfg.Poller.Candles.Lookback != DefaultCandleLookback {
+		t.Errorf("Poller.Candles.Lookback = %v, want default %v", cfg.Poller.Candles.Lookback, DefaultCandleLookback)
+	}
+
+	// Check book defaults
+	if cfg.Book.DerivedSnapshots.Enabled {
+		t.Error("Book.DerivedSnapshots.Enabled = true, want default false")
+	}
+	if cfg.Book.DerivedSnapshots.Interval != DefaultDerivedInterval {
+		t.Errorf("Book.DerivedSnapshots.Interval = %v, want default %v", cfg.Book.DerivedSnapshots.Interval, DefaultDerivedInterval)
+	}
+	if cfg.Book.DerivedSnapshots.MinChanges != DefaultDerivedMinChanges {
+		t.Errorf("Book.DerivedSnapshots.MinChanges = %d, want default %d", cfg.Book.DerivedSnapshots.MinChanges, DefaultDerivedMinChanges)
+	}
+
+	// Check journal defaults
+	if cfg.Journal.MaxSegmentMB != DefaultJournalSegmentMB {
+		t.Errorf("Journal.MaxSegmentMB = %d, want default %d", cfg.Journal.MaxSegmentMB, DefaultJournalSegmentMB)
+	}
+	if cfg.Journal.MaxSegmentAge != DefaultJournalSegmentAge {
+		t.Errorf("Journal.MaxSegmentAge = %v, want default %v", cfg.Journal.MaxSegmentAge, DefaultJournalSegmentAge)
+	}
+	if cfg.Journal.FlushInterval != DefaultJournalFlush {
+		t.Errorf("Journal.FlushInterval = %v, want default %v", cfg.Journal.FlushInterval, DefaultJournalFlush)
+	}
 
 	// Check metrics defaults
 	if cfg.Metrics.Port != DefaultMetricsPort {
@@ -271,6 +342,10 @@ api:
   rest_url: https://custom.api.com
   timeout: 60s
   max_retries: 5
+  rate_limit:
+    read_per_second: 100
+    write_per_second: 100
+    burst: 20
 database:
   timescale:
     host: customhost
@@ -288,6 +363,10 @@ writers:
 poller:
   interval: 10m
   concurrency: 50
+  candles:
+    enabled: true
+    period_minutes: 60
+    lookback: 6h
 metrics:
   port: 8080
   path: /health
@@ -309,6 +388,9 @@ metrics:
 	if cfg.API.MaxRetries != 5 {
 		t.Errorf("API.MaxRetries = %d, want 5", cfg.API.MaxRetries)
 	}
+	if cfg.API.RateLimit != (RateLimitConfig{ReadPerSecond: 100, WritePerSecond: 100, Burst: 20}) {
+		t.Errorf("API.RateLimit = %+v, want Premier tier", cfg.API.RateLimit)
+	}
 	if cfg.Database.Timescale.Port != 5433 {
 		t.Errorf("Database.Timescale.Port = %d, want 5433", cfg.Database.Timescale.Port)
 	}
@@ -324,6 +406,9 @@ metrics:
 	if cfg.Poller.Interval != 10*time.Minute {
 		t.Errorf("Poller.Interval = %v, want 10m", cfg.Poller.Interval)
 	}
+	if !cfg.Poller.Candles.Enabled || cfg.Poller.Candles.PeriodMinutes != 60 || cfg.Poller.Candles.Lookback != 6*time.Hour {
+		t.Errorf("Poller.Candles = %+v, want enabled hourly with 6h lookback", cfg.Poller.Candles)
+	}
 	if cfg.Metrics.Port != 8080 {
 		t.Errorf("Metrics.Port = %d, want 8080", cfg.Metrics.Port)
 	}
@@ -482,6 +567,66 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: "connections.markets_per_connection must be >= 1",
 		},
+		{
+			name: "connections global_count not a multiple of 3",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+					GlobalCount:          4,
+				},
+			},
+			wantErr: "connections.global_count must be a multiple of 3, got 4",
+		},
+		{
+			name: "connections max_message_rate < 0",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+					MaxMessageRate:       -1,
+				},
+			},
+			wantErr: "connections.max_message_rate must be >= 0, got -1",
+		},
+		{
+			name: "unknown connections overflow_policy",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+					OverflowPolicy:       "discard",
+				},
+			},
+			wantErr: `connections.overflow_policy must be "block", "spill" or "drop", got "discard"`,
+		},
+		{
+			name: "connections overflow_policy spill without spill_dir",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+					OverflowPolicy:       "spill",
+				},
+			},
+			wantErr: `connections.spill_dir is required with overflow_policy "spill"`,
+		},
 		{
 			name: "writers batch_size < 1",
 			cfg: GathererConfig{
@@ -538,6 +683,259 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: "poller.concurrency must be >= 1",
 		},
+		{
+			name: "unknown writers insert_mode",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+				},
+				Writers: WritersConfig{
+					BatchSize:  1000,
+					BufferSize: 10000,
+					InsertMode: "bulk",
+				},
+			},
+			wantErr: `writers.insert_mode must be "copy" or "batch", got "bulk"`,
+		},
+		{
+			name: "writers retry base_delay > max_delay",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+				},
+				Writers: WritersConfig{
+					BatchSize:  1000,
+					BufferSize: 10000,
+					Retry:      RetryConfig{BaseDelay: time.Minute, MaxDelay: time.Second},
+				},
+			},
+			wantErr: "writers.retry.base_delay (1m0s) must not exceed max_delay (1s)",
+		},
+		{
+			name: "api rate_limit read_per_second < 0",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				API: APIConfig{
+					RateLimit: RateLimitConfig{ReadPerSecond: -1, WritePerSecond: 10, Burst: 5},
+				},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+			},
+			wantErr: "api.rate_limit.read_per_second and write_per_second must be > 0",
+		},
+		{
+			name: "poller divergence_tolerance < 0",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+				},
+				Writers: WritersConfig{
+					BatchSize:  1000,
+					BufferSize: 10000,
+				},
+				Poller: PollerConfig{
+					Concurrency:         10,
+					DivergenceTolerance: -1,
+				},
+			},
+			wantErr: "poller.divergence_tolerance must be >= 0, got -1",
+		},
+		{
+			name: "candles period_minutes invalid",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+				},
+				Writers: WritersConfig{
+					BatchSize:  1000,
+					BufferSize: 10000,
+				},
+				Poller: PollerConfig{
+					Concurrency: 10,
+					Candles:     CandlesConfig{Enabled: true, Interval: time.Minute, PeriodMinutes: 5, Lookback: time.Hour},
+				},
+			},
+			wantErr: "poller.candles.period_minutes must be 1, 60 or 1440, got 5",
+		},
+		{
+			name: "candles interval <= 0",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+				},
+				Writers: WritersConfig{
+					BatchSize:  1000,
+					BufferSize: 10000,
+				},
+				Poller: PollerConfig{
+					Concurrency: 10,
+					Candles:     CandlesConfig{Enabled: true, PeriodMinutes: 1, Lookback: time.Hour},
+				},
+			},
+			wantErr: "poller.candles.interval must be > 0, got 0s",
+		},
+		{
+			name: "candles lookback shorter than period",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+				},
+				Writers: WritersConfig{
+					BatchSize:  1000,
+					BufferSize: 10000,
+				},
+				Poller: PollerConfig{
+					Concurrency: 10,
+					Candles:     CandlesConfig{Enabled: true, Interval: time.Minute, PeriodMinutes: 60, Lookback: 30 * time.Minute},
+				},
+			},
+			wantErr: "poller.candles.lookback must be at least one period, got 30m0s",
+		},
+		{
+			name: "derived snapshots interval <= 0",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+				},
+				Writers: WritersConfig{
+					BatchSize:  1000,
+					BufferSize: 10000,
+				},
+				Poller: PollerConfig{
+					Concurrency: 10,
+				},
+				Book: BookConfig{
+					DerivedSnapshots: DerivedSnapshotsConfig{Enabled: true, Interval: 0, MinChanges: 1},
+				},
+			},
+			wantErr: "book.derived_snapshots.interval must be > 0, got 0s",
+		},
+		{
+			name: "derived snapshots min_changes < 1",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+				},
+				Writers: WritersConfig{
+					BatchSize:  1000,
+					BufferSize: 10000,
+				},
+				Poller: PollerConfig{
+					Concurrency: 10,
+				},
+				Book: BookConfig{
+					DerivedSnapshots: DerivedSnapshotsConfig{Enabled: true, Interval: time.Minute, MinChanges: 0},
+				},
+			},
+			wantErr: "book.derived_snapshots.min_changes must be >= 1",
+		},
+		{
+			name: "journal dir missing",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+				},
+				Writers: WritersConfig{
+					BatchSize:  1000,
+					BufferSize: 10000,
+				},
+				Poller: PollerConfig{
+					Concurrency: 10,
+				},
+				Journal: JournalConfig{Enabled: true, MaxSegmentMB: 256, MaxSegmentAge: time.Hour, FlushInterval: time.Second},
+			},
+			wantErr: "journal.dir is required when journal.enabled is true",
+		},
+		{
+			name: "journal max_segment_mb < 1",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+				},
+				Writers: WritersConfig{
+					BatchSize:  1000,
+					BufferSize: 10000,
+				},
+				Poller: PollerConfig{
+					Concurrency: 10,
+				},
+				Journal: JournalConfig{Enabled: true, Dir: "/tmp/j", MaxSegmentMB: 0, MaxSegmentAge: time.Hour, FlushInterval: time.Second},
+			},
+			wantErr: "journal.max_segment_mb must be >= 1",
+		},
+		{
+			name: "journal retention < 0",
+			cfg: GathererConfig{
+				Instance: InstanceConfig{ID: "test"},
+				Database: DatabaseConfig{
+					Timescale: DBConfig{Host: "localhost", Name: "db", User: "user", Password: "pass", MaxConns: 5},
+				},
+				Connections: ConnectionsConfig{
+					OrderbookCount:       100,
+					MarketsPerConnection: 250,
+				},
+				Writers: WritersConfig{
+					BatchSize:  1000,
+					BufferSize: 10000,
+				},
+				Poller: PollerConfig{
+					Concurrency: 10,
+				},
+				Journal: JournalConfig{Enabled: true, Dir: "/tmp/j", MaxSegmentMB: 256, MaxSegmentAge: time.Hour, FlushInterval: time.Second, Retention: -time.Hour},
+			},
+			wantErr: "journal.retention must be >= 0, got -1h0m0s",
+		},
 		{
 			name: "metrics port < 1",
 			cfg: GathererConfig{
@@ -693,6 +1091,9 @@ func TestDefaultConstants(t *testing.T) {
 	if DefaultMaxRetries != 3 {
 		t.Errorf("DefaultMaxRetries = %d, want 3", DefaultMaxRetries)
 	}
+	if DefaultReadPerSecond != 20 || DefaultWritePerSecond != 10 {
+		t.Errorf("DefaultReadPerSecond, DefaultWritePerSecond = %v, %v, want Basic tier 20, 10", DefaultReadPerSecond, DefaultWritePerSecond)
+	}
 	if DefaultDBPort != 5432 {
 		t.Errorf("DefaultDBPort = %d, want 5432", DefaultDBPort)
 	}
@@ -729,12 +1130,296 @@ func TestDefaultConstants(t *testing.T) {
 	if DefaultPollConcurrency != 10 {
 		t.Errorf("DefaultPollConcurrency = %d, want 10", DefaultPollConcurrency)
 	}
+	if DefaultCandleInterval != 5*time.Minute {
+		t.Errorf("DefaultCandleInterval = %v, want 5m", DefaultCandleInterval)
+	}
+	if DefaultCandlePeriodMinutes != 1 {
+		t.Errorf("DefaultCandlePeriodMinutes = %d, want 1", DefaultCandlePeriodMinutes)
+	}
+	if DefaultCandleLookback != time.Hour {
+		t.Errorf("DefaultCandleLookback = %v, want 1h", DefaultCandleLookback)
+	}
 	if DefaultMetricsPort != 9090 {
 		t.Errorf("DefaultMetricsPort = %d, want 9090", DefaultMetricsPort)
 	}
 	if DefaultMetricsPath != "/metrics" {
 		t.Errorf("DefaultMetricsPath = %q, want '/metrics'", DefaultMetricsPath)
 	}
+	if DefaultSyncPollInterval != 1*time.Second {
+		t.Errorf("DefaultSyncPollInterval = %v, want 1s", DefaultSyncPollInterval)
+	}
+	if DefaultSyncBatchSize != 5000 {
+		t.Errorf("DefaultSyncBatchSize = %d, want 5000", DefaultSyncBatchSize)
+	}
+	if DefaultSyncLookback != 10*time.Second {
+		t.Errorf("DefaultSyncLookback = %v, want 10s", DefaultSyncLookback)
+	}
+	if DefaultDedupMetricsPort != 9091 {
+		t.Errorf("DefaultDedupMetricsPort = %d, want 9091", DefaultDedupMetricsPort)
+	}
+}
+
+func TestLoadDeduplicatorWithDefaults(t *testing.T) {
+	yaml := `
+instance:
+  id: dedup-1
+sources:
+  - id: gatherer-1
+    host: gatherer-1.internal
+    name: kalshi_ts
+    user: ${TEST_DEDUP_USER}
+    password: secret
+production:
+  host: prod.internal
+  name: kalshi_prod
+  user: prod
+  password: secret
+  max_conns: 20
+s3:
+  enabled: true
+  bucket: kalshi-archive
+`
+	t.Setenv("TEST_DEDUP_USER", "gatherer")
+	path := writeTempFile(t, yaml)
+
+	cfg, err := LoadDeduplicatorWithDefaults(path)
+	if err != nil {
+		t.Fatalf("LoadDeduplicatorWithDefaults failed: %v", err)
+	}
+
+	if len(cfg.Sources) != 1 {
+		t.Fatalf("len(Sources) = %d, want 1", len(cfg.Sources))
+	}
+	src := cfg.Sources[0]
+	if src.ID != "gatherer-1" {
+		t.Errorf("Sources[0].ID = %q, want %q", src.ID, "gatherer-1")
+	}
+	if src.Host != "gatherer-1.internal" {
+		t.Errorf("Sources[0].Host = %q, want %q", src.Host, "gatherer-1.internal")
+	}
+	if src.User != "gatherer" {
+		t.Errorf("Sources[0].User = %q, want %q", src.User, "gatherer")
+	}
+	if src.Port != DefaultDBPort {
+		t.Errorf("Sources[0].Port = %d, want %d", src.Port, DefaultDBPort)
+	}
+	if src.MaxConns != DefaultMaxConns {
+		t.Errorf("Sources[0].MaxConns = %d, want %d", src.MaxConns, DefaultMaxConns)
+	}
+	if cfg.Production.MaxConns != 20 {
+		t.Errorf("Production.MaxConns = %d, want 20", cfg.Production.MaxConns)
+	}
+	if cfg.Production.SSLMode != DefaultDBSSLMode {
+		t.Errorf("Production.SSLMode = %q, want %q", cfg.Production.SSLMode, DefaultDBSSLMode)
+	}
+	if cfg.Sync.PollInterval != DefaultSyncPollInterval {
+		t.Errorf("Sync.PollInterval = %v, want %v", cfg.Sync.PollInterval, DefaultSyncPollInterval)
+	}
+	if cfg.Sync.BatchSize != DefaultSyncBatchSize {
+		t.Errorf("Sync.BatchSize = %d, want %d", cfg.Sync.BatchSize, DefaultSyncBatchSize)
+	}
+	if cfg.Sync.Lookback != DefaultSyncLookback {
+		t.Errorf("Sync.Lookback = %v, want %v", cfg.Sync.Lookback, DefaultSyncLookback)
+	}
+	if cfg.Sync.QueryTimeout != DefaultSyncQueryTimeout {
+		t.Errorf("Sync.QueryTimeout = %v, want %v", cfg.Sync.QueryTimeout, DefaultSyncQueryTimeout)
+	}
+	if !cfg.S3.Enabled || cfg.S3.Bucket != "kalshi-archive" {
+		t.Errorf("S3 = %+v, want enabled with bucket kalshi-archive", cfg.S3)
+	}
+	if cfg.S3.Region != DefaultS3Region {
+		t.Errorf("S3.Region = %q, want %q", cfg.S3.Region, DefaultS3Region)
+	}
+	if cfg.S3.ExportInterval != DefaultS3ExportInterval {
+		t.Errorf("S3.ExportInterval = %v, want %v", cfg.S3.ExportInterval, DefaultS3ExportInterval)
+	}
+	if cfg.Metrics.Port != DefaultDedupMetricsPort {
+		t.Errorf("Metrics.Port = %d, want %d", cfg.Metrics.Port, DefaultDedupMetricsPort)
+	}
+	if cfg.Metrics.Path != DefaultMetricsPath {
+		t.Errorf("Metrics.Path = %q, want %q", cfg.Metrics.Path, DefaultMetricsPath)
+	}
+}
+
+func TestLoadDeduplicatorAndValidate(t *testing.T) {
+	t.Run("example config", func(t *testing.T) {
+		for _, v := range []string{
+			"GATHERER_1_USER", "GATHERER_1_PASSWORD",
+			"GATHERER_2_USER", "GATHERER_2_PASSWORD",
+			"GATHERER_3_USER", "GATHERER_3_PASSWORD",
+			"PROD_RDS_HOST", "PROD_RDS_USER", "PROD_RDS_PASSWORD",
+		} {
+			t.Setenv(v, "x")
+		}
+
+		cfg, err := LoadDeduplicatorAndValidate("../../configs/deduplicator.example.yaml")
+		if err != nil {
+			t.Fatalf("LoadDeduplicatorAndValidate failed: %v", err)
+		}
+		if len(cfg.Sources) != 3 {
+			t.Errorf("len(Sources) = %d, want 3", len(cfg.Sources))
+		}
+	})
+
+	t.Run("invalid config returns validation error", func(t *testing.T) {
+		path := writeTempFile(t, "instance:\n  id: dedup-1\n")
+
+		_, err := LoadDeduplicatorAndValidate(path)
+		if err == nil {
+			t.Fatal("expected validation error")
+		}
+		if !strings.Contains(err.Error(), "validate config") {
+			t.Errorf("error should mention 'validate config', got %v", err)
+		}
+	})
+
+	t.Run("load error propagates", func(t *testing.T) {
+		_, err := LoadDeduplicatorAndValidate("/nonexistent/path/config.yaml")
+		if err == nil {
+			t.Fatal("expected load error")
+		}
+	})
+}
+
+func TestDeduplicatorValidate(t *testing.T) {
+	validDB := func(host string) DBConfig {
+		return DBConfig{Host: host, Name: "db", User: "u", Password: "p", MaxConns: 10, MinConns: 2}
+	}
+	valid := func() DeduplicatorConfig {
+		return DeduplicatorConfig{
+			Instance: InstanceConfig{ID: "dedup-1"},
+			Sources: []SourceConfig{
+				{ID: "gatherer-1", DBConfig: validDB("g1")},
+				{ID: "gatherer-2", DBConfig: validDB("g2")},
+			},
+			Production: validDB("prod"),
+			Sync: SyncConfig{
+				PollInterval: time.Second,
+				BatchSize:    5000,
+				Lookback:     10 * time.Second,
+				QueryTimeout: 30 * time.Second,
+			},
+			Metrics: MetricsConfig{Port: 9091},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(c *DeduplicatorConfig)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(c *DeduplicatorConfig) {},
+		},
+		{
+			name:    "missing instance id",
+			modify:  func(c *DeduplicatorConfig) { c.Instance.ID = "" },
+			wantErr: "instance.id is required",
+		},
+		{
+			name:    "no sources",
+			modify:  func(c *DeduplicatorConfig) { c.Sources = nil },
+			wantErr: "sources must list at least one gatherer",
+		},
+		{
+			name:    "missing source id",
+			modify:  func(c *DeduplicatorConfig) { c.Sources[1].ID = "" },
+			wantErr: "sources[1].id is required",
+		},
+		{
+			name:    "duplicate source id",
+			modify:  func(c *DeduplicatorConfig) { c.Sources[1].ID = "gatherer-1" },
+			wantErr: `sources[1].id "gatherer-1" duplicates sources[0].id`,
+		},
+		{
+			name:    "missing source host",
+			modify:  func(c *DeduplicatorConfig) { c.Sources[0].Host = "" },
+			wantErr: "sources[0].host is required",
+		},
+		{
+			name:    "source min_conns exceeds max_conns",
+			modify:  func(c *DeduplicatorConfig) { c.Sources[1].MinConns = 20 },
+			wantErr: "sources[1].min_conns (20) cannot exceed max_conns (10)",
+		},
+		{
+			name:    "missing production password",
+			modify:  func(c *DeduplicatorConfig) { c.Production.Password = "" },
+			wantErr: "production.password is required",
+		},
+		{
+			name:    "zero poll interval",
+			modify:  func(c *DeduplicatorConfig) { c.Sync.PollInterval = 0 },
+			wantErr: "sync.poll_interval must be > 0, got 0s",
+		},
+		{
+			name:    "zero batch size",
+			modify:  func(c *DeduplicatorConfig) { c.Sync.BatchSize = 0 },
+			wantErr: "sync.batch_size must be >= 1",
+		},
+		{
+			name:    "negative lookback",
+			modify:  func(c *DeduplicatorConfig) { c.Sync.Lookback = -time.Second },
+			wantErr: "sync.lookback must be > 0 to re-scan late-committed gatherer rows, got -1s",
+		},
+		{
+			name:    "zero lookback",
+			modify:  func(c *DeduplicatorConfig) { c.Sync.Lookback = 0 },
+			wantErr: "sync.lookback must be > 0 to re-scan late-committed gatherer rows, got 0s",
+		},
+		{
+			name:    "zero query timeout",
+			modify:  func(c *DeduplicatorConfig) { c.Sync.QueryTimeout = 0 },
+			wantErr: "sync.query_timeout must be > 0, got 0s",
+		},
+		{
+			name:   "s3 disabled ignores bucket",
+			modify: func(c *DeduplicatorConfig) { c.S3 = S3Config{Enabled: false} },
+		},
+		{
+			name: "s3 enabled without bucket",
+			modify: func(c *DeduplicatorConfig) {
+				c.S3 = S3Config{Enabled: true, Region: "us-east-1", ExportInterval: time.Hour}
+			},
+			wantErr: "s3.bucket is required when s3.enabled is true",
+		},
+		{
+			name:    "s3 enabled without region",
+			modify:  func(c *DeduplicatorConfig) { c.S3 = S3Config{Enabled: true, Bucket: "b", ExportInterval: time.Hour} },
+			wantErr: "s3.region is required when s3.enabled is true",
+		},
+		{
+			name:    "s3 enabled without export interval",
+			modify:  func(c *DeduplicatorConfig) { c.S3 = S3Config{Enabled: true, Bucket: "b", Region: "us-east-1"} },
+			wantErr: "s3.export_interval must be > 0, got 0s",
+		},
+		{
+			name:    "invalid metrics port",
+			modify:  func(c *DeduplicatorConfig) { c.Metrics.Port = 70000 },
+			wantErr: "metrics.port must be between 1 and 65535, got 70000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want %q", tt.wantErr)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("Validate() = %q, want %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
 }
 
 func writeTempFile(t *testing.T, content string) string {
@@ -8,6 +8,9 @@ const (
 	DefaultWSURL                = "wss://api.elections.kalshi.com"
 	DefaultAPITimeout           = 30 * time.Second
 	DefaultMaxRetries           = 3
+	DefaultReadPerSecond        = 20 // Kalshi Basic tier
+	DefaultWritePerSecond       = 10
+	DefaultRateLimitBurst       = 5
 	DefaultDBPort               = 5432
 	DefaultDBSSLMode            = "prefer"
 	DefaultMaxConns             = 10
@@ -15,6 +18,11 @@ const (
 	DefaultOrderbookCount       = 144
 	DefaultMarketsPerConnection = 250
 	DefaultGlobalCount          = 6
+	DefaultMaxMessageRate       = 1000 // Messages per second per orderbook connection
+	DefaultRebalanceInterval    = 30 * time.Second
+	DefaultOverflowPolicy       = "block"
+	DefaultOverflowTimeout      = 1 * time.Second
+	DefaultSpillMaxMB           = 1024
 	DefaultReconnectBaseDelay   = 1 * time.Second
 	DefaultReconnectMaxDelay    = 60 * time.Second
 	DefaultPingInterval         = 15 * time.Second
@@ -22,12 +30,36 @@ const (
 	DefaultBatchSize            = 1000
 	DefaultFlushInterval        = 1 * time.Second
 	DefaultBufferSize           = 10000
+	DefaultInsertMode           = "copy"
+	DefaultRetryAttempts        = 3
+	DefaultRetryBaseDelay       = 500 * time.Millisecond
+	DefaultRetryMaxDelay        = 10 * time.Second
+	DefaultReplayInterval       = 10 * time.Second
 	DefaultPollInterval         = 15 * time.Minute
 	DefaultPollConcurrency      = 10
+	DefaultCandleInterval       = 5 * time.Minute
+	DefaultCandlePeriodMinutes  = 1
+	DefaultCandleLookback       = 1 * time.Hour
+	DefaultDerivedInterval      = 1 * time.Minute
+	DefaultDerivedMinChanges    = 1
+	DefaultJournalSegmentMB     = 256
+	DefaultJournalSegmentAge    = 1 * time.Hour
+	DefaultJournalFlush         = 1 * time.Second
 	DefaultMetricsPort          = 9090
 	DefaultMetricsPath          = "/metrics"
 )
 
+// Default values for optional deduplicator configuration fields.
+const (
+	DefaultSyncPollInterval = 1 * time.Second
+	DefaultSyncBatchSize    = 5000
+	DefaultSyncLookback     = 10 * time.Second
+	DefaultSyncQueryTimeout = 30 * time.Second
+	DefaultS3Region         = "us-east-1"
+	DefaultS3ExportInterval = 1 * time.Hour
+	DefaultDedupMetricsPort = 9091
+)
+
 func (c *GathererConfig) applyDefaults() {
 	// API defaults
 	if c.API.RestURL == "" {
@@ -42,6 +74,15 @@ func (c *GathererConfig) applyDefaults() {
 	if c.API.MaxRetries == 0 {
 		c.API.MaxRetries = DefaultMaxRetries
 	}
+	if c.API.RateLimit.ReadPerSecond == 0 {
+		c.API.RateLimit.ReadPerSecond = DefaultReadPerSecond
+	}
+	if c.API.RateLimit.WritePerSecond == 0 {
+		c.API.RateLimit.WritePerSecond = DefaultWritePerSecond
+	}
+	if c.API.RateLimit.Burst == 0 {
+		c.API.RateLimit.Burst = DefaultRateLimitBurst
+	}
 
 	// Database defaults (TimescaleDB only)
 	applyDBDefaults(&c.Database.Timescale)
@@ -56,6 +97,21 @@ func (c *GathererConfig) applyDefaults() {
 	if c.Connections.GlobalCount == 0 {
 		c.Connections.GlobalCount = DefaultGlobalCount
 	}
+	if c.Connections.MaxMessageRate == 0 {
+		c.Connections.MaxMessageRate = DefaultMaxMessageRate
+	}
+	if c.Connections.RebalanceInterval == 0 {
+		c.Connections.RebalanceInterval = DefaultRebalanceInterval
+	}
+	if c.Connections.OverflowPolicy == "" {
+		c.Connections.OverflowPolicy = DefaultOverflowPolicy
+	}
+	if c.Connections.OverflowTimeout == 0 {
+		c.Connections.OverflowTimeout = DefaultOverflowTimeout
+	}
+	if c.Connections.SpillMaxMB == 0 {
+		c.Connections.SpillMaxMB = DefaultSpillMaxMB
+	}
 	if c.Connections.ReconnectBaseDelay == 0 {
 		c.Connections.ReconnectBaseDelay = DefaultReconnectBaseDelay
 	}
@@ -79,6 +135,21 @@ func (c *GathererConfig) applyDefaults() {
 	if c.Writers.BufferSize == 0 {
 		c.Writers.BufferSize = DefaultBufferSize
 	}
+	if c.Writers.InsertMode == "" {
+		c.Writers.InsertMode = DefaultInsertMode
+	}
+	if c.Writers.Retry.MaxAttempts == 0 {
+		c.Writers.Retry.MaxAttempts = DefaultRetryAttempts
+	}
+	if c.Writers.Retry.BaseDelay == 0 {
+		c.Writers.Retry.BaseDelay = DefaultRetryBaseDelay
+	}
+	if c.Writers.Retry.MaxDelay == 0 {
+		c.Writers.Retry.MaxDelay = DefaultRetryMaxDelay
+	}
+	if c.Writers.Retry.ReplayInterval == 0 {
+		c.Writers.Retry.ReplayInterval = DefaultReplayInterval
+	}
 
 	// Poller defaults
 	if c.Poller.Interval == 0 {
@@ -87,6 +158,34 @@ func (c *GathererConfig) applyDefaults() {
 	if c.Poller.Concurrency == 0 {
 		c.Poller.Concurrency = DefaultPollConcurrency
 	}
+	if c.Poller.Candles.Interval == 0 {
+		c.Poller.Candles.Interval = DefaultCandleInterval
+	}
+	if c.Poller.Candles.PeriodMinutes == 0 {
+		c.Poller.Candles.PeriodMinutes = DefaultCandlePeriodMinutes
+	}
+	if c.Poller.Candles.Lookback == 0 {
+		c.Poller.Candles.Lookback = DefaultCandleLookback
+	}
+
+	// Book defaults
+	if c.Book.DerivedSnapshots.Interval == 0 {
+		c.Book.DerivedSnapshots.Interval = DefaultDerivedInterval
+	}
+	if c.Book.DerivedSnapshots.MinChanges == 0 {
+		c.Book.DerivedSnapshots.MinChanges = DefaultDerivedMinChanges
+	}
+
+	// Journal defaults
+	if c.Journal.MaxSegmentMB == 0 {
+		c.Journal.MaxSegmentMB = DefaultJournalSegmentMB
+	}
+	if c.Journal.MaxSegmentAge == 0 {
+		c.Journal.MaxSegmentAge = DefaultJournalSegmentAge
+	}
+	if c.Journal.FlushInterval == 0 {
+		c.Journal.FlushInterval = DefaultJournalFlush
+	}
 
 	// Metrics defaults
 	if c.Metrics.Port == 0 {
@@ -97,6 +196,44 @@ func (c *GathererConfig) applyDefaults() {
 	}
 }
 
+func (c *DeduplicatorConfig) applyDefaults() {
+	// Database defaults
+	for i := range c.Sources {
+		applyDBDefaults(&c.Sources[i].DBConfig)
+	}
+	applyDBDefaults(&c.Production)
+
+	// Sync defaults
+	if c.Sync.PollInterval == 0 {
+		c.Sync.PollInterval = DefaultSyncPollInterval
+	}
+	if c.Sync.BatchSize == 0 {
+		c.Sync.BatchSize = DefaultSyncBatchSize
+	}
+	if c.Sync.Lookback == 0 {
+		c.Sync.Lookback = DefaultSyncLookback
+	}
+	if c.Sync.QueryTimeout == 0 {
+		c.Sync.QueryTimeout = DefaultSyncQueryTimeout
+	}
+
+	// S3 defaults
+	if c.S3.Region == "" {
+		c.S3.Region = DefaultS3Region
+	}
+	if c.S3.ExportInterval == 0 {
+		c.S3.ExportInterval = DefaultS3ExportInterval
+	}
+
+	// Metrics defaults
+	if c.Metrics.Port == 0 {
+		c.Metrics.Port = DefaultDedupMetricsPort
+	}
+	if c.Metrics.Path == "" {
+		c.Metrics.Path = DefaultMetricsPath
+	}
+}
+
 func applyDBDefaults(db *DBConfig) {
 	if db.Port == 0 {
 		db.Port = DefaultDBPort
@@ -9,19 +9,10 @@ import (
 
 // Load reads a YAML config file and expands environment variables.
 func Load(path string) (*GathererConfig, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("read config file: %w", err)
-	}
-
-	// Expand ${VAR} environment variables
-	expanded := os.ExpandEnv(string(data))
-
 	var cfg GathererConfig
-	if err := yaml.Unmarshal([]byte(expanded), &cfg); err != nil {
-		return nil, fmt.Errorf("parse config yaml: %w", err)
+	if err := loadYAML(path, &cfg); err != nil {
+		return nil, err
 	}
-
 	return &cfg, nil
 }
 
@@ -46,3 +37,49 @@ func LoadAndValidate(path string) (*GathererConfig, error) {
 	}
 	return cfg, nil
 }
+
+// LoadDeduplicator reads a deduplicator YAML config file and expands environment variables.
+func LoadDeduplicator(path string) (*DeduplicatorConfig, error) {
+	var cfg DeduplicatorConfig
+	if err := loadYAML(path, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// LoadDeduplicatorWithDefaults loads deduplicator config and applies default values.
+func LoadDeduplicatorWithDefaults(path string) (*DeduplicatorConfig, error) {
+	cfg, err := LoadDeduplicator(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg.applyDefaults()
+	return cfg, nil
+}
+
+// LoadDeduplicatorAndValidate loads deduplicator config, applies defaults, and validates.
+func LoadDeduplicatorAndValidate(path string) (*DeduplicatorConfig, error) {
+	cfg, err := LoadDeduplicatorWithDefaults(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+	return cfg, nil
+}
+
+// loadYAML reads path, expands ${VAR} environment variables, and unmarshals into out.
+func loadYAML(path string, out any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	expanded := os.ExpandEnv(string(data))
+
+	if err := yaml.Unmarshal([]byte(expanded), out); err != nil {
+		return fmt.Errorf("parse config yaml: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Validate checks that all required fields are set and values are valid.
@@ -15,12 +16,43 @@ func (c *GathererConfig) Validate() error {
 		return err
 	}
 
+	if c.API.RateLimit.ReadPerSecond < 0 || c.API.RateLimit.WritePerSecond < 0 {
+		return errors.New("api.rate_limit.read_per_second and write_per_second must be > 0")
+	}
+	if c.API.RateLimit.Burst < 0 {
+		return fmt.Errorf("api.rate_limit.burst must be >= 1, got %d", c.API.RateLimit.Burst)
+	}
+
 	if c.Connections.OrderbookCount < 1 {
 		return errors.New("connections.orderbook_count must be >= 1")
 	}
 	if c.Connections.MarketsPerConnection < 1 {
 		return errors.New("connections.markets_per_connection must be >= 1")
 	}
+	if c.Connections.GlobalCount < 0 || c.Connections.GlobalCount%3 != 0 {
+		return fmt.Errorf("connections.global_count must be a multiple of 3, got %d", c.Connections.GlobalCount)
+	}
+	if c.Connections.MaxMessageRate < 0 {
+		return fmt.Errorf("connections.max_message_rate must be >= 0, got %d", c.Connections.MaxMessageRate)
+	}
+	if c.Connections.RebalanceInterval < 0 {
+		return fmt.Errorf("connections.rebalance_interval must be >= 0, got %s", c.Connections.RebalanceInterval)
+	}
+	switch c.Connections.OverflowPolicy {
+	case "", "block", "drop":
+	case "spill":
+		if c.Connections.SpillDir == "" {
+			return errors.New("connections.spill_dir is required with overflow_policy \"spill\"")
+		}
+	default:
+		return fmt.Errorf("connections.overflow_policy must be \"block\", \"spill\" or \"drop\", got %q", c.Connections.OverflowPolicy)
+	}
+	if c.Connections.OverflowTimeout < 0 {
+		return fmt.Errorf("connections.overflow_timeout must be >= 0, got %s", c.Connections.OverflowTimeout)
+	}
+	if c.Connections.SpillMaxMB < 0 {
+		return fmt.Errorf("connections.spill_max_mb must be >= 0, got %d", c.Connections.SpillMaxMB)
+	}
 
 	if c.Writers.BatchSize < 1 {
 		return errors.New("writers.batch_size must be >= 1")
@@ -28,10 +60,141 @@ func (c *GathererConfig) Validate() error {
 	if c.Writers.BufferSize < 1 {
 		return errors.New("writers.buffer_size must be >= 1")
 	}
+	switch c.Writers.InsertMode {
+	case "", "copy", "batch":
+	default:
+		return fmt.Errorf("writers.insert_mode must be \"copy\" or \"batch\", got %q", c.Writers.InsertMode)
+	}
+	if err := c.Writers.Retry.validate(); err != nil {
+		return err
+	}
 
 	if c.Poller.Concurrency < 1 {
 		return errors.New("poller.concurrency must be >= 1")
 	}
+	if c.Poller.DivergenceTolerance < 0 {
+		return fmt.Errorf("poller.divergence_tolerance must be >= 0, got %d", c.Poller.DivergenceTolerance)
+	}
+	if c.Poller.Candles.Enabled {
+		switch c.Poller.Candles.PeriodMinutes {
+		case 1, 60, 1440:
+		default:
+			return fmt.Errorf("poller.candles.period_minutes must be 1, 60 or 1440, got %d", c.Poller.Candles.PeriodMinutes)
+		}
+		if c.Poller.Candles.Interval <= 0 {
+			return fmt.Errorf("poller.candles.interval must be > 0, got %v", c.Poller.Candles.Interval)
+		}
+		if c.Poller.Candles.Lookback < time.Duration(c.Poller.Candles.PeriodMinutes)*time.Minute {
+			return fmt.Errorf("poller.candles.lookback must be at least one period, got %v", c.Poller.Candles.Lookback)
+		}
+	}
+
+	if c.Book.DerivedSnapshots.Enabled {
+		if c.Book.DerivedSnapshots.Interval <= 0 {
+			return fmt.Errorf("book.derived_snapshots.interval must be > 0, got %v", c.Book.DerivedSnapshots.Interval)
+		}
+		if c.Book.DerivedSnapshots.MinChanges < 1 {
+			return errors.New("book.derived_snapshots.min_changes must be >= 1")
+		}
+	}
+
+	if c.Journal.Enabled {
+		if c.Journal.Dir == "" {
+			return errors.New("journal.dir is required when journal.enabled is true")
+		}
+		if c.Journal.MaxSegmentMB < 1 {
+			return errors.New("journal.max_segment_mb must be >= 1")
+		}
+		if c.Journal.MaxSegmentAge <= 0 {
+			return fmt.Errorf("journal.max_segment_age must be > 0, got %v", c.Journal.MaxSegmentAge)
+		}
+		if c.Journal.FlushInterval <= 0 {
+			return fmt.Errorf("journal.flush_interval must be > 0, got %v", c.Journal.FlushInterval)
+		}
+		if c.Journal.Retention < 0 {
+			return fmt.Errorf("journal.retention must be >= 0, got %v", c.Journal.Retention)
+		}
+	}
+
+	if c.Metrics.Port < 1 || c.Metrics.Port > 65535 {
+		return fmt.Errorf("metrics.port must be between 1 and 65535, got %d", c.Metrics.Port)
+	}
+
+	return nil
+}
+
+// validate checks retry settings. Zero values are filled in by defaults.
+func (r RetryConfig) validate() error {
+	if r.MaxAttempts < 0 {
+		return fmt.Errorf("writers.retry.max_attempts must be >= 1, got %d", r.MaxAttempts)
+	}
+	if r.BaseDelay < 0 || r.MaxDelay < 0 {
+		return errors.New("writers.retry.base_delay and max_delay must be > 0")
+	}
+	if r.BaseDelay > 0 && r.MaxDelay > 0 && r.BaseDelay > r.MaxDelay {
+		return fmt.Errorf("writers.retry.base_delay (%v) must not exceed max_delay (%v)", r.BaseDelay, r.MaxDelay)
+	}
+	if r.ReplayInterval < 0 {
+		return fmt.Errorf("writers.retry.replay_interval must be > 0, got %v", r.ReplayInterval)
+	}
+	return nil
+}
+
+// Validate checks that all required fields are set and values are valid.
+func (c *DeduplicatorConfig) Validate() error {
+	if c.Instance.ID == "" {
+		return errors.New("instance.id is required")
+	}
+
+	if len(c.Sources) == 0 {
+		return errors.New("sources must list at least one gatherer")
+	}
+	seen := make(map[string]int, len(c.Sources))
+	for i := range c.Sources {
+		src := &c.Sources[i]
+		prefix := fmt.Sprintf("sources[%d]", i)
+		if src.ID == "" {
+			return fmt.Errorf("%s.id is required", prefix)
+		}
+		if j, ok := seen[src.ID]; ok {
+			return fmt.Errorf("%s.id %q duplicates sources[%d].id", prefix, src.ID, j)
+		}
+		seen[src.ID] = i
+		if err := src.DBConfig.validate(prefix); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Production.validate("production"); err != nil {
+		return err
+	}
+
+	if c.Sync.PollInterval <= 0 {
+		return fmt.Errorf("sync.poll_interval must be > 0, got %v", c.Sync.PollInterval)
+	}
+	if c.Sync.BatchSize < 1 {
+		return errors.New("sync.batch_size must be >= 1")
+	}
+	// Without a lookback, rows a gatherer commits behind the cursor are
+	// never synced, so it cannot be turned off
+	if c.Sync.Lookback <= 0 {
+		return fmt.Errorf("sync.lookback must be > 0 to re-scan late-committed gatherer rows, got %v", c.Sync.Lookback)
+	}
+	if c.Sync.QueryTimeout <= 0 {
+		return fmt.Errorf("sync.query_timeout must be > 0, got %v", c.Sync.QueryTimeout)
+	}
+
+	if c.S3.Enabled {
+		if c.S3.Bucket == "" {
+			return errors.New("s3.bucket is required when s3.enabled is true")
+		}
+		if c.S3.Region == "" {
+			return errors.New("s3.region is required when s3.enabled is true")
+		}
+		if c.S3.ExportInterval <= 0 {
+			return fmt.Errorf("s3.export_interval must be > 0, got %v", c.S3.ExportInterval)
+		}
+	}
 
 	if c.Metrics.Port < 1 || c.Metrics.Port > 65535 {
 		return fmt.Errorf("metrics.port must be between 1 and 65535, got %d", c.Metrics.Port)
@@ -367,11 +367,11 @@ func TestTypes_LifecycleMsg(t *testing.T) {
 
 func TestDefaultConfigs(t *testing.T) {
 	clientCfg := DefaultClientConfig()
-	if clientCfg.PingTimeout != 30*time.Second {
-		t.Errorf("PingTimeout = %v, want 30s", clientCfg.PingTimeout)
+	if clientCfg.PingTimeout != 60*time.Second {
+		t.Errorf("PingTimeout = %v, want 60s", clientCfg.PingTimeout)
 	}
-	if clientCfg.BufferSize != 1000 {
-		t.Errorf("BufferSize = %d, want 1000", clientCfg.BufferSize)
+	if clientCfg.BufferSize != 100000 {
+		t.Errorf("BufferSize = %d, want 100000", clientCfg.BufferSize)
 	}
 
 	mgrCfg := DefaultManagerConfig()
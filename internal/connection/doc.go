@@ -1,9 +1,9 @@
 // Package connection implements the Connection Manager component.
 //
 // The Connection Manager:
-//   - Maintains 150 WebSocket connections per gatherer
-//   - 144 orderbook connections (250 markets each)
-//   - 6 global connections (trades, tickers, lifecycle)
+//   - Maintains a pool of WebSocket connections sized from config
+//   - Global connections (trades, tickers, lifecycle), opened at start
+//   - Orderbook connections, opened as markets need them and retired when empty
 //   - Handles reconnection with exponential backoff
 //   - Routes incoming messages to the Message Router
 package connection
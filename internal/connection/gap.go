@@ -0,0 +1,300 @@
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// GapAction describes what the manager did about a sequence gap.
+type GapAction string
+
+const (
+	GapResubscribed GapAction = "resubscribed" // Unsubscribed and resubscribed for fresh snapshots
+	GapRateLimited  GapAction = "rate_limited" // Skipped by cooldown, rate limit or full queue
+	GapFailed       GapAction = "failed"       // Unsubscribe or resubscribe failed
+	GapSkipped      GapAction = "skipped"      // Subscription already replaced before recovery ran
+)
+
+// GapEvent records an orderbook sequence gap and its recovery outcome.
+type GapEvent struct {
+	DetectedAt  time.Time // When the gapped message was received
+	Ticker      string    // Always "": the subscription covers every market on the connection
+	SID         int64     // Subscription that gapped
+	ConnID      int       // Connection the subscription lives on
+	ExpectedSeq int64     // Sequence number we expected
+	ReceivedSeq int64     // Sequence number we got
+	GapSize     int       // Number of missed messages
+	Action      GapAction // Recovery outcome
+	Error       string    // Failure reason when Action is GapFailed
+}
+
+// gapQueueSize bounds pending gap recoveries. Recoveries are additionally
+// rate limited, so a full queue means the limiter is already saturated.
+const gapQueueSize = 1000
+
+// gapLimiter bounds gap resubscribes: at most one per key (a connection
+// for gaps, a market for resyncs) per cooldown, and at most limit across
+// the manager in any one-minute window.
+type gapLimiter struct {
+	mu       sync.Mutex
+	cooldown time.Duration
+	limit    int
+	last     map[string]time.Time // key → last allowed resubscribe
+	recent   []time.Time          // allowed resubscribes in the current window
+}
+
+func newGapLimiter(cooldown time.Duration, limit int) *gapLimiter {
+	return &gapLimiter{
+		cooldown: cooldown,
+		limit:    limit,
+		last:     make(map[string]time.Time),
+	}
+}
+
+// allow reports whether a resubscribe for key may run at now, and records
+// it if so.
+func (l *gapLimiter) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit <= 0 {
+		return false
+	}
+
+	if last, ok := l.last[key]; ok && now.Sub(last) < l.cooldown {
+		return false
+	}
+
+	// Slide the global window
+	cutoff := now.Add(-time.Minute)
+	i := 0
+	for i < len(l.recent) && !l.recent[i].After(cutoff) {
+		i++
+	}
+	l.recent = l.recent[i:]
+
+	if len(l.recent) >= l.limit {
+		return false
+	}
+
+	l.recent = append(l.recent, now)
+	l.last[key] = now
+
+	// Drop expired cooldowns; bounded by limit × cooldown/minute entries
+	for t, last := range l.last {
+		if now.Sub(last) >= l.cooldown {
+			delete(l.last, t)
+		}
+	}
+
+	return true
+}
+
+// handleGap is called from readLoop when checkSequence flags a gap. The
+// missed messages may belong to any market on the subscription, so recovery
+// resubscribes the whole connection. It must not block: resubscribing waits
+// for responses that readLoop itself routes.
+func (m *manager) handleGap(conn *connState, sid, seq int64, gapSize int, receivedAt time.Time) {
+	m.gapsDetected.Add(1)
+
+	event := GapEvent{
+		DetectedAt:  receivedAt,
+		SID:         sid,
+		ConnID:      conn.id,
+		ExpectedSeq: seq - int64(gapSize),
+		ReceivedSeq: seq,
+		GapSize:     gapSize,
+	}
+
+	if !m.subs.known(sid) {
+		event.Action = GapFailed
+		event.Error = "unknown sid"
+		m.gapFailures.Add(1)
+		m.emitGap(event)
+		return
+	}
+
+	if !m.gapLimiter.allow(fmt.Sprintf("conn %d", conn.id), receivedAt) {
+		m.rateLimitGap(event)
+		return
+	}
+
+	select {
+	case m.gapQueue <- event:
+	default:
+		m.rateLimitGap(event)
+	}
+}
+
+// rateLimitGap records a gap whose recovery was skipped.
+func (m *manager) rateLimitGap(event GapEvent) {
+	event.Action = GapRateLimited
+	m.gapRateLimited.Add(1)
+	m.logger.Debug("gap recovery rate limited",
+		"conn", event.ConnID,
+		"sid", event.SID,
+	)
+	m.emitGap(event)
+}
+
+// gapRecoveryLoop resubscribes connections queued by handleGap and markets
+// queued by ResyncOrderbook, one at a time.
+func (m *manager) gapRecoveryLoop() {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case ticker := <-m.resyncQueue:
+			m.resync(ticker)
+		case event := <-m.gapQueue:
+			m.recoverQueuedGap(event)
+		}
+	}
+}
+
+// recoverQueuedGap runs the recovery for a gap queued by handleGap and
+// records its outcome.
+func (m *manager) recoverQueuedGap(event GapEvent) {
+	n, err := m.recoverGap(event)
+	switch {
+	case errors.Is(err, errSubscriptionReplaced):
+		// A resubscribe since the gap already started from fresh snapshots
+		m.logger.Debug("gap recovery skipped, subscription already replaced",
+			"conn", event.ConnID,
+			"sid", event.SID,
+		)
+		event.Action = GapSkipped
+		m.gapSkipped.Add(1)
+	case err != nil:
+		m.logger.Warn("gap recovery failed",
+			"conn", event.ConnID,
+			"sid", event.SID,
+			"error", err,
+		)
+		event.Action = GapFailed
+		event.Error = err.Error()
+		m.gapFailures.Add(1)
+	default:
+		m.logger.Info("gap recovered",
+			"conn", event.ConnID,
+			"old_sid", event.SID,
+			"markets", n,
+			"gap", event.GapSize,
+		)
+		event.Action = GapResubscribed
+		m.gapResubscribes.Add(1)
+	}
+	m.emitGap(event)
+}
+
+// recoverGap resubscribes the connection whose subscription gapped.
+// Returns the number of markets resubscribed.
+func (m *manager) recoverGap(event GapEvent) (int, error) {
+	conn := m.orderbookConn(event.ConnID)
+	if conn == nil {
+		return 0, fmt.Errorf("orderbook connection %d closed", event.ConnID)
+	}
+	return m.resubscribeOrderbooks(conn, event.SID)
+}
+
+// ResyncOrderbook queues a resubscribe for a market whose book is known to
+// be wrong, e.g. after a REST cross-check found it diverged. It shares the
+// gap limiter, so a market is not resubscribed twice for one problem.
+func (m *manager) ResyncOrderbook(ticker string) error {
+	if conn, _ := m.orderbookSub(ticker); conn == nil {
+		return ErrNotSubscribed
+	}
+
+	if !m.gapLimiter.allow(ticker, time.Now()) {
+		return ErrRateLimited
+	}
+
+	select {
+	case m.resyncQueue <- ticker:
+		return nil
+	default:
+		return ErrRateLimited
+	}
+}
+
+// resync removes a market queued by ResyncOrderbook from its connection's
+// subscription and adds it back, which delivers a fresh snapshot without
+// disturbing the other markets.
+func (m *manager) resync(ticker string) {
+	conn, sid := m.orderbookSub(ticker)
+	if conn == nil {
+		m.resyncFailures.Add(1)
+		m.logger.Debug("resync skipped, market no longer subscribed", "ticker", ticker)
+		return
+	}
+
+	if err := m.readdOrderbookMarket(conn, ticker); err != nil {
+		m.resyncFailures.Add(1)
+		m.logger.Warn("orderbook resync failed",
+			"ticker", ticker,
+			"sid", sid,
+			"error", err,
+		)
+		return
+	}
+
+	m.resyncs.Add(1)
+	m.logger.Info("orderbook resynced",
+		"ticker", ticker,
+		"sid", sid,
+	)
+}
+
+// orderbookSub returns the connection holding ticker and its orderbook
+// SID, or nil if the market is not subscribed.
+func (m *manager) orderbookSub(ticker string) (*connState, int64) {
+	sub, ok := m.subs.tickerSub(ticker)
+	if !ok {
+		return nil, 0
+	}
+
+	conn := m.orderbookConn(sub.ConnID)
+	if conn == nil {
+		return nil, 0
+	}
+	return conn, sub.SID
+}
+
+// readdOrderbookMarket deletes ticker from conn's subscription and adds it
+// back on the same SID.
+func (m *manager) readdOrderbookMarket(conn *connState, ticker string) error {
+	conn.subMu.Lock()
+	defer conn.subMu.Unlock()
+
+	conn.mu.Lock()
+	sid := conn.sid
+	_, held := conn.markets[ticker]
+	conn.mu.Unlock()
+	if sid == 0 || !held {
+		return ErrNotSubscribed
+	}
+
+	if err := m.updateSubscription(conn, sid, "delete_markets", []string{ticker}); err != nil {
+		return fmt.Errorf("delete market: %w", err)
+	}
+	if err := m.updateSubscription(conn, sid, "add_markets", []string{ticker}); err != nil {
+		return fmt.Errorf("add market: %w", err)
+	}
+	return nil
+}
+
+// emitGap publishes a gap event without blocking.
+func (m *manager) emitGap(event GapEvent) {
+	select {
+	case m.gapEvents <- event:
+	default:
+		m.logger.Warn("gap event buffer full, dropping",
+			"conn", event.ConnID,
+			"sid", event.SID,
+		)
+	}
+}
@@ -0,0 +1,323 @@
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rickgao/kalshi-data/internal/model"
+)
+
+func TestGapLimiter_Allow(t *testing.T) {
+	base := time.Unix(1705320000, 0)
+
+	tests := []struct {
+		name   string
+		ticker string
+		at     time.Duration // Offset from base
+		want   bool
+	}{
+		{"first gap", "A", 0, true},
+		{"same market within cooldown", "A", 10 * time.Second, false},
+		{"other market", "B", 10 * time.Second, true},
+		{"global limit reached", "C", 20 * time.Second, false},
+		{"same market after cooldown, window still full", "A", 40 * time.Second, false},
+		{"window slid", "C", 61 * time.Second, true},
+		{"first market after window and cooldown", "A", 71 * time.Second, true},
+	}
+
+	l := newGapLimiter(30*time.Second, 2)
+
+	for _, tt := range tests {
+		if got := l.allow(tt.ticker, base.Add(tt.at)); got != tt.want {
+			t.Errorf("%s: allow(%s) = %v, want %v", tt.name, tt.ticker, got, tt.want)
+		}
+	}
+}
+
+func TestGapLimiter_Disabled(t *testing.T) {
+	l := newGapLimiter(time.Second, 0)
+
+	if l.allow("A", time.Now()) {
+		t.Error("allow() = true with limit 0, want false")
+	}
+}
+
+func TestGapLimiter_PrunesExpiredCooldowns(t *testing.T) {
+	base := time.Unix(1705320000, 0)
+	l := newGapLimiter(time.Second, 100)
+
+	for i := 0; i < 10; i++ {
+		l.allow(fmt.Sprintf("MKT-%d", i), base)
+	}
+	l.allow("LATE", base.Add(2*time.Second))
+
+	if len(l.last) != 1 {
+		t.Errorf("len(last) = %d, want 1", len(l.last))
+	}
+}
+
+func newGapTestManager(cfg ManagerConfig) *manager {
+	return &manager{
+		cfg:            cfg,
+		logger:         slog.Default(),
+		gapEvents:      make(chan GapEvent, 10),
+		orderbookConns: make(map[int]*connState),
+		marketToConn:   make(map[string]int),
+		subs:           newSubscriptionTable(),
+		gapQueue:       make(chan GapEvent, 1),
+		resyncQueue:    make(chan string, 1),
+		gapLimiter:     newGapLimiter(cfg.GapCooldown, cfg.GapMaxResubscribes),
+	}
+}
+
+func TestManager_HandleGap_UnknownSID(t *testing.T) {
+	m := newGapTestManager(DefaultManagerConfig())
+	conn := &connState{id: 7}
+
+	m.handleGap(conn, 99, 10, 3, time.Now())
+
+	event := <-m.gapEvents
+	if event.Action != GapFailed {
+		t.Errorf("Action = %s, want %s", event.Action, GapFailed)
+	}
+	if event.ExpectedSeq != 7 || event.ReceivedSeq != 10 {
+		t.Errorf("ExpectedSeq/ReceivedSeq = %d/%d, want 7/10", event.ExpectedSeq, event.ReceivedSeq)
+	}
+	if got := m.Stats().GapFailures; got != 1 {
+		t.Errorf("GapFailures = %d, want 1", got)
+	}
+}
+
+func TestManager_RecoverQueuedGap_Replaced(t *testing.T) {
+	m := newGapTestManager(DefaultManagerConfig())
+	m.orderbookConns[7] = &connState{id: 7, sid: 2, markets: map[string]struct{}{"A": {}}}
+
+	// SID 1 gapped, but the connection was resubscribed as SID 2 since
+	m.recoverQueuedGap(GapEvent{SID: 1, ConnID: 7, GapSize: 3})
+
+	event := <-m.gapEvents
+	if event.Action != GapSkipped {
+		t.Errorf("Action = %s (%s), want %s", event.Action, event.Error, GapSkipped)
+	}
+	skipped, resubscribed, failed := m.gapSkipped.Load(), m.gapResubscribes.Load(), m.gapFailures.Load()
+	if skipped != 1 || resubscribed != 0 || failed != 0 {
+		t.Errorf("skipped/resubscribed/failed = %d/%d/%d, want 1/0/0", skipped, resubscribed, failed)
+	}
+}
+
+func TestManager_HandleGap_RateLimited(t *testing.T) {
+	cfg := DefaultManagerConfig()
+	cfg.GapMaxResubscribes = 1
+	m := newGapTestManager(cfg)
+	m.subs.add(Subscription{SID: 1, Channel: "orderbook_delta", ConnID: 7}, nil)
+	m.subs.add(Subscription{SID: 2, Channel: "orderbook_delta", ConnID: 7}, nil)
+	m.subs.add(Subscription{SID: 3, Channel: "orderbook_delta", ConnID: 8}, nil)
+	now := time.Now()
+
+	m.handleGap(&connState{id: 7}, 1, 5, 1, now) // Queued
+	m.handleGap(&connState{id: 7}, 2, 9, 1, now) // Cooldown, same connection
+	m.handleGap(&connState{id: 8}, 3, 5, 1, now) // Global limit
+
+	if len(m.gapQueue) != 1 {
+		t.Fatalf("len(gapQueue) = %d, want 1", len(m.gapQueue))
+	}
+	if queued := <-m.gapQueue; queued.ConnID != 7 || queued.SID != 1 {
+		t.Errorf("queued ConnID/SID = %d/%d, want 7/1", queued.ConnID, queued.SID)
+	}
+
+	for i := 0; i < 2; i++ {
+		event := <-m.gapEvents
+		if event.Action != GapRateLimited {
+			t.Errorf("event %d Action = %s, want %s", i, event.Action, GapRateLimited)
+		}
+	}
+
+	stats := m.Stats()
+	if stats.SequenceGaps != 3 {
+		t.Errorf("SequenceGaps = %d, want 3", stats.SequenceGaps)
+	}
+	if stats.GapRateLimited != 2 {
+		t.Errorf("GapRateLimited = %d, want 2", stats.GapRateLimited)
+	}
+}
+
+func TestManager_ResyncOrderbook(t *testing.T) {
+	cfg := DefaultManagerConfig()
+	cfg.GapMaxResubscribes = 2
+	m := newGapTestManager(cfg)
+	m.orderbookConns[7] = &connState{
+		id:      7,
+		client:  NewClient(DefaultClientConfig(), nil),
+		sid:     1,
+		markets: map[string]struct{}{"MKT-A": {}, "MKT-B": {}},
+	}
+	m.marketToConn["MKT-A"] = 7
+	m.marketToConn["MKT-B"] = 7
+	m.subs.add(Subscription{SID: 1, Channel: "orderbook_delta", ConnID: 7}, []string{"MKT-A", "MKT-B"})
+
+	tests := []struct {
+		ticker  string
+		wantErr error
+	}{
+		{"MKT-A", nil},
+		{"MKT-A", ErrRateLimited}, // Cooldown
+		{"MKT-C", ErrNotSubscribed},
+		{"MKT-B", ErrRateLimited}, // Queue full
+	}
+
+	for _, tt := range tests {
+		if err := m.ResyncOrderbook(tt.ticker); !errors.Is(err, tt.wantErr) {
+			t.Errorf("ResyncOrderbook(%s) error = %v, want %v", tt.ticker, err, tt.wantErr)
+		}
+	}
+
+	if queued := <-m.resyncQueue; queued != "MKT-A" {
+		t.Errorf("queued = %s, want MKT-A", queued)
+	}
+
+	// Unsubscribed before the worker ran
+	delete(m.marketToConn, "MKT-A")
+	m.subs.removeMarkets(1, []string{"MKT-A"})
+	m.resync("MKT-A")
+	if got := m.Stats().ResyncFailures; got != 1 {
+		t.Errorf("ResyncFailures = %d, want 1", got)
+	}
+}
+
+func TestManager_GapRecovery_Resubscribes(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		nextSID  int64
+		commands []string
+		gapSent  bool
+	)
+
+	server := mockWSServerMulti(t, func(id int, conn *websocket.Conn) {
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var cmd struct {
+				ID     int64           `json:"id"`
+				Cmd    string          `json:"cmd"`
+				Params json.RawMessage `json:"params"`
+			}
+			if err := json.Unmarshal(msg, &cmd); err != nil {
+				continue
+			}
+
+			switch cmd.Cmd {
+			case "subscribe":
+				var params SubscribeParams
+				json.Unmarshal(cmd.Params, &params)
+				tickers := strings.Join(params.MarketTickers, ",")
+
+				mu.Lock()
+				nextSID++
+				sid := nextSID
+				if tickers != "" {
+					commands = append(commands, "subscribe "+tickers)
+				}
+				sendGap := tickers != "" && !gapSent
+				if sendGap {
+					gapSent = true
+				}
+				mu.Unlock()
+
+				subMsg, _ := json.Marshal(SubscribedMsg{SID: sid, Channel: params.Channels[0]})
+				data, _ := json.Marshal(Response{ID: cmd.ID, Type: "subscribed", Msg: subMsg})
+				conn.WriteMessage(websocket.TextMessage, data)
+
+				if sendGap {
+					// Let subscribe() record the SID before data arrives
+					time.Sleep(50 * time.Millisecond)
+					for _, seq := range []int64{1, 2, 5} {
+						data, _ := json.Marshal(DataMessage{Type: "orderbook_delta", SID: sid, Seq: seq, Msg: json.RawMessage(`{}`)})
+						conn.WriteMessage(websocket.TextMessage, data)
+					}
+				}
+
+			case "unsubscribe":
+				var params UnsubscribeParams
+				json.Unmarshal(cmd.Params, &params)
+
+				mu.Lock()
+				commands = append(commands, fmt.Sprintf("unsubscribe %d", params.SIDs[0]))
+				mu.Unlock()
+
+				data, _ := json.Marshal(Response{ID: cmd.ID, Type: "unsubscribed"})
+				conn.WriteMessage(websocket.TextMessage, data)
+			}
+		}
+	})
+	defer server.Close()
+
+	registry := newMockRegistry()
+	registry.AddMarket(model.Market{Ticker: "GAP-1", MarketStatus: "open"})
+
+	cfg := DefaultManagerConfig()
+	cfg.WSURL = wsURL(server)
+	cfg.SubscribeTimeout = 5 * time.Second
+	cfg.MessageBufferSize = 1000
+	cfg.WorkerCount = 2
+
+	mgr := NewManager(cfg, registry, nil)
+
+	if err := mgr.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		mgr.Stop(stopCtx)
+	}()
+
+	var event GapEvent
+	select {
+	case event = <-mgr.GapEvents():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for gap event")
+	}
+
+	if event.Action != GapResubscribed {
+		t.Fatalf("Action = %s (%s), want %s", event.Action, event.Error, GapResubscribed)
+	}
+	if event.Ticker != "" || event.ConnID != 7 {
+		t.Errorf("Ticker/ConnID = %q/%d, want \"\"/7", event.Ticker, event.ConnID)
+	}
+	if event.ExpectedSeq != 3 || event.ReceivedSeq != 5 || event.GapSize != 2 {
+		t.Errorf("Expected/Received/GapSize = %d/%d/%d, want 3/5/2", event.ExpectedSeq, event.ReceivedSeq, event.GapSize)
+	}
+
+	mu.Lock()
+	got := append([]string(nil), commands...)
+	mu.Unlock()
+
+	want := []string{"subscribe GAP-1", fmt.Sprintf("unsubscribe %d", event.SID), "subscribe GAP-1"}
+	if len(got) != len(want) {
+		t.Fatalf("commands = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("commands[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	stats := mgr.Stats()
+	if stats.GapResubscribes != 1 {
+		t.Errorf("GapResubscribes = %d, want 1", stats.GapResubscribes)
+	}
+	if stats.MarketsSubscribed != 1 {
+		t.Errorf("MarketsSubscribed = %d, want 1", stats.MarketsSubscribed)
+	}
+}
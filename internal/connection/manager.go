@@ -4,16 +4,25 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
+	"math/rand/v2"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/rickgao/kalshi-data/internal/market"
+	"github.com/rickgao/kalshi-data/internal/model"
 )
 
+// maintenanceJitter spreads reconnections at the end of a maintenance
+// window so every connection does not reconnect at once. Capped at
+// ReconnectMaxWait.
+const maintenanceJitter = 10 * time.Second
+
 // Manager orchestrates WebSocket connections and subscriptions.
 type Manager interface {
 	// Start begins listening for Market Registry events and manages connections.
@@ -25,8 +34,20 @@ type Manager interface {
 	// Messages returns channel of raw messages for Message Router.
 	Messages() <-chan RawMessage
 
+	// GapEvents returns orderbook sequence gaps and their recovery outcome.
+	GapEvents() <-chan GapEvent
+
+	// ResyncOrderbook queues a resubscribe for ticker's orderbook, which
+	// delivers a fresh snapshot. Returns ErrNotSubscribed or ErrRateLimited
+	// if nothing was queued.
+	ResyncOrderbook(ticker string) error
+
 	// Stats returns current connection and subscription statistics.
 	Stats() ManagerStats
+
+	// Subscriptions returns the live subscriptions with their markets and
+	// last sequence numbers, for auditing what is actually subscribed.
+	Subscriptions() []Subscription
 }
 
 // ManagerStats provides statistics about the connection manager.
@@ -34,20 +55,84 @@ type ManagerStats struct {
 	ConnectedCount     int
 	TotalSubscriptions int
 	MarketsSubscribed  int
+
+	// Orderbook sequence gaps (cumulative)
+	SequenceGaps    int64 // Gaps detected
+	GapResubscribes int64 // Gaps repaired by resubscribing
+	GapRateLimited  int64 // Gaps not repaired due to rate limiting
+	GapFailures     int64 // Gaps whose repair failed
+	GapSkipped      int64 // Gaps whose subscription was replaced before repair
+
+	// Requested resyncs (cumulative)
+	Resyncs        int64 // ResyncOrderbook requests that resubscribed
+	ResyncFailures int64 // ResyncOrderbook requests whose resubscribe failed
+
+	// Orderbook connections currently open (grows and shrinks with markets)
+	OrderbookConns int
+
+	// Scheduled exchange downtime
+	InMaintenance      bool  // The registry reports maintenance now
+	ReconnectsDeferred int64 // Reconnections held until a maintenance window ended (cumulative)
+
+	// Load rebalancing (cumulative)
+	Migrations        int64 // Markets moved to a less busy orderbook connection
+	MigrationFailures int64 // Moves that failed, leaving the market where it was
+
+	// Per-connection load, in ID order
+	Connections []ConnStats
+
+	// Router overflow (see ManagerConfig.OverflowPolicy)
+	Overflows    int64          // Data messages that found the Messages() channel full (cumulative)
+	Spilled      int64          // Messages queued to the spill file (cumulative)
+	SpillPending int64          // Spilled messages not yet delivered
+	Dropped      []DroppedStats // Dropped messages per connection, in ID order
+}
+
+// ConnStats describes the load on one connection.
+type ConnStats struct {
+	ID          int
+	Role        ConnectionRole
+	Markets     int     // Orderbook markets (0 for global connections)
+	MessageRate float64 // Data messages per second over the last RebalanceInterval
+}
+
+// DroppedStats counts the data messages dropped from one connection
+// (cumulative, kept after the connection is retired).
+type DroppedStats struct {
+	ConnID int
+	Role   ConnectionRole
+	Count  int64
 }
 
 // connState holds the state for a single connection.
 type connState struct {
 	client Client
-	id     int            // Connection ID (1-150)
+	id     int            // Connection ID, globals first, then orderbook
 	role   ConnectionRole // "ticker", "trade", "lifecycle", "orderbook"
 
-	// Markets on this connection (orderbook only)
+	// Markets on this connection and the orderbook_delta subscription
+	// covering them (orderbook only)
 	mu      sync.Mutex
 	markets map[string]struct{}
+	sid     int64 // 0 if not subscribed
+
+	// Serializes orderbook subscription commands, so markets are always
+	// added to the subscription that currently exists
+	subMu sync.Mutex
+
+	// Message rate, sampled every RebalanceInterval. received counts data
+	// messages since the last sample; tickerMsgs splits them by market
+	// (orderbook only).
+	received    atomic.Int64
+	rateMu      sync.Mutex
+	tickerMsgs  map[string]int64
+	sampledAt   time.Time
+	rate        float64
+	tickerRates map[string]float64
 
 	// Goroutine coordination
 	readLoopDone chan struct{}
+	retired      chan struct{} // Closed when an empty orderbook connection is retired
 
 	// Command/response correlation
 	pendingMu sync.Mutex
@@ -64,28 +149,64 @@ type manager struct {
 	// Output channels
 	router    chan RawMessage // Output to Message Router
 	lifecycle chan []byte     // Output to Market Registry (market_lifecycle messages)
+	gapEvents chan GapEvent   // Output to gap event writer
 
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 
-	// Fixed connection pool (150 connections)
-	tickerConns    [2]*connState   // Connections 1-2
-	tradeConns     [2]*connState   // Connections 3-4
-	lifecycleConns [2]*connState   // Connections 5-6
-	orderbookConns [144]*connState // Connections 7-150
+	// Global connections (ticker, trade, lifecycle), opened at Start with
+	// IDs 1 to GlobalConns
+	globalConns []*connState
 
-	// Market → connection mapping (for orderbook)
-	marketConnMu sync.RWMutex
-	marketToConn map[string]int // market ticker → connection ID (7-150)
+	// Orderbook connections, opened when every open one holds
+	// MarketsPerConn markets and retired when empty. IDs follow the
+	// global connections, lowest free first.
+	poolMu         sync.RWMutex
+	orderbookConns map[int]*connState // Connection ID → connection
+	openMu         sync.Mutex         // Serializes opening orderbook connections
+
+	// Serializes applying market changes and rebalancing, so a market is
+	// not moved while it is being unsubscribed
+	changeMu sync.Mutex
 
-	// Subscription tracking
-	subsMu sync.RWMutex
-	subs   map[int64]*Subscription // SID → subscription info
+	// Market changes from the registry waiting to be applied
+	pendingMu sync.Mutex
+	pending   map[string]bool // ticker → should be subscribed
+	pendingCh chan struct{}   // Signals pending changes
 
-	// Sequence tracking (per SID)
-	seqMu   sync.RWMutex
-	lastSeq map[int64]int64 // SID → last sequence number
+	// Market → connection mapping (for orderbook)
+	marketConnMu sync.RWMutex
+	marketToConn map[string]int // market ticker → orderbook connection ID
+
+	// Live subscriptions, their markets and sequence numbers
+	subs *subscriptionTable
+
+	// Gap recovery
+	gapQueue    chan GapEvent // Gaps awaiting resubscribe
+	resyncQueue chan string   // ResyncOrderbook tickers awaiting resubscribe
+	gapLimiter  *gapLimiter
+
+	gapsDetected    atomic.Int64
+	gapResubscribes atomic.Int64
+	gapRateLimited  atomic.Int64
+	gapFailures     atomic.Int64
+	gapSkipped      atomic.Int64
+	resyncs         atomic.Int64
+	resyncFailures  atomic.Int64
+
+	reconnectsDeferred atomic.Int64
+
+	// Markets moved between orderbook connections
+	migrated          atomic.Int64
+	migrationFailures atomic.Int64
+
+	// Router overflow handling (see handoff)
+	spill     *spillQueue // OverflowSpill only
+	overflows atomic.Int64
+	spilled   atomic.Int64
+	dropMu    sync.Mutex
+	drops     map[dropKey]int64
 }
 
 // NewManager creates a new Connection Manager.
@@ -94,15 +215,45 @@ func NewManager(cfg ManagerConfig, registry market.Registry, logger *slog.Logger
 		logger = slog.Default()
 	}
 
+	defaults := DefaultManagerConfig()
+	if cfg.OrderbookConns <= 0 {
+		cfg.OrderbookConns = defaults.OrderbookConns
+	}
+	if cfg.MarketsPerConn <= 0 {
+		cfg.MarketsPerConn = defaults.MarketsPerConn
+	}
+	if cfg.GlobalConns < len(globalRoles) {
+		cfg.GlobalConns = defaults.GlobalConns
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaults.BatchSize
+	}
+	if cfg.RebalanceInterval <= 0 {
+		cfg.RebalanceInterval = defaults.RebalanceInterval
+	}
+	if cfg.OverflowPolicy == "" {
+		cfg.OverflowPolicy = defaults.OverflowPolicy
+	}
+	if cfg.OverflowTimeout <= 0 {
+		cfg.OverflowTimeout = defaults.OverflowTimeout
+	}
+
 	return &manager{
-		cfg:          cfg,
-		registry:     registry,
-		logger:       logger,
-		router:       make(chan RawMessage, cfg.MessageBufferSize),
-		lifecycle:    make(chan []byte, 100),
-		marketToConn: make(map[string]int),
-		subs:         make(map[int64]*Subscription),
-		lastSeq:      make(map[int64]int64),
+		cfg:            cfg,
+		registry:       registry,
+		logger:         logger,
+		router:         make(chan RawMessage, cfg.MessageBufferSize),
+		lifecycle:      make(chan []byte, 100),
+		gapEvents:      make(chan GapEvent, gapQueueSize),
+		orderbookConns: make(map[int]*connState),
+		pending:        make(map[string]bool),
+		pendingCh:      make(chan struct{}, 1),
+		marketToConn:   make(map[string]int),
+		subs:           newSubscriptionTable(),
+		gapQueue:       make(chan GapEvent, gapQueueSize),
+		resyncQueue:    make(chan string, gapQueueSize),
+		gapLimiter:     newGapLimiter(cfg.GapCooldown, cfg.GapMaxResubscribes),
+		drops:          make(map[dropKey]int64),
 	}
 }
 
@@ -110,6 +261,23 @@ func NewManager(cfg ManagerConfig, registry market.Registry, logger *slog.Logger
 func (m *manager) Start(ctx context.Context) error {
 	m.ctx, m.cancel = context.WithCancel(ctx)
 
+	switch m.cfg.OverflowPolicy {
+	case OverflowBlock, OverflowDrop:
+	case OverflowSpill:
+		if m.cfg.SpillDir == "" {
+			return fmt.Errorf("overflow policy %q needs a spill dir", m.cfg.OverflowPolicy)
+		}
+		spill, err := openSpillQueue(m.cfg.SpillDir, m.cfg.SpillMaxBytes)
+		if err != nil {
+			return err
+		}
+		m.spill = spill
+		m.wg.Add(1)
+		go m.drainSpill()
+	default:
+		return fmt.Errorf("unknown overflow policy %q", m.cfg.OverflowPolicy)
+	}
+
 	// Initialize all connections
 	if err := m.initConnections(); err != nil {
 		return fmt.Errorf("init connections: %w", err)
@@ -131,12 +299,20 @@ func (m *manager) Start(ctx context.Context) error {
 	m.wg.Add(1)
 	go m.handleMarketChanges()
 
+	// Start gap recovery worker
+	m.wg.Add(1)
+	go m.gapRecoveryLoop()
+
 	// Subscribe to existing active markets
 	m.subscribeExistingMarkets()
 
+	// Start rate sampling and rebalancing
+	m.wg.Add(1)
+	go m.rebalanceLoop()
+
 	m.logger.Info("connection manager started",
-		"orderbook_conns", len(m.orderbookConns),
-		"global_conns", 6,
+		"orderbook_conns", m.orderbookConnCount(),
+		"global_conns", len(m.globalConns),
 	)
 
 	return nil
@@ -166,8 +342,15 @@ func (m *manager) Stop(ctx context.Context) error {
 	// Close all connections
 	m.closeAllConnections()
 
+	if m.spill != nil {
+		if n := m.spill.close(); n > 0 {
+			m.logger.Warn("discarded spilled messages", "messages", n)
+		}
+	}
+
 	close(m.router)
 	close(m.lifecycle)
+	close(m.gapEvents)
 
 	m.logger.Info("connection manager stopped")
 	return nil
@@ -178,48 +361,81 @@ func (m *manager) Messages() <-chan RawMessage {
 	return m.router
 }
 
+// GapEvents returns the output channel for gap events.
+func (m *manager) GapEvents() <-chan GapEvent {
+	return m.gapEvents
+}
+
 // Stats returns current statistics.
 func (m *manager) Stats() ManagerStats {
+	conns := m.allConns()
 	connected := 0
-	for _, c := range m.tickerConns {
-		if c != nil && c.client.IsConnected() {
-			connected++
-		}
-	}
-	for _, c := range m.tradeConns {
-		if c != nil && c.client.IsConnected() {
-			connected++
-		}
-	}
-	for _, c := range m.lifecycleConns {
-		if c != nil && c.client.IsConnected() {
+	connStats := make([]ConnStats, len(conns))
+	for i, c := range conns {
+		if c.client.IsConnected() {
 			connected++
 		}
+		connStats[i] = c.stats()
 	}
-	for _, c := range m.orderbookConns {
-		if c != nil && c.client.IsConnected() {
-			connected++
-		}
-	}
-
-	m.subsMu.RLock()
-	totalSubs := len(m.subs)
-	m.subsMu.RUnlock()
 
 	m.marketConnMu.RLock()
 	marketsSubbed := len(m.marketToConn)
 	m.marketConnMu.RUnlock()
 
+	_, inMaintenance := m.maintenance()
+
+	var spillPending int64
+	if m.spill != nil {
+		spillPending = m.spill.pending()
+	}
+
 	return ManagerStats{
 		ConnectedCount:     connected,
-		TotalSubscriptions: totalSubs,
+		TotalSubscriptions: m.subs.len(),
 		MarketsSubscribed:  marketsSubbed,
+		OrderbookConns:     len(conns) - len(m.globalConns),
+		SequenceGaps:       m.gapsDetected.Load(),
+		GapResubscribes:    m.gapResubscribes.Load(),
+		GapRateLimited:     m.gapRateLimited.Load(),
+		GapFailures:        m.gapFailures.Load(),
+		GapSkipped:         m.gapSkipped.Load(),
+		Resyncs:            m.resyncs.Load(),
+		ResyncFailures:     m.resyncFailures.Load(),
+		InMaintenance:      inMaintenance,
+		ReconnectsDeferred: m.reconnectsDeferred.Load(),
+		Migrations:         m.migrated.Load(),
+		MigrationFailures:  m.migrationFailures.Load(),
+		Connections:        connStats,
+		Overflows:          m.overflows.Load(),
+		Spilled:            m.spilled.Load(),
+		SpillPending:       spillPending,
+		Dropped:            m.droppedStats(),
 	}
 }
 
-// initConnections creates all WebSocket connections.
-func (m *manager) initConnections() error {
-	clientCfg := ClientConfig{
+// maintenance returns the scheduled exchange downtime in effect now, if any.
+func (m *manager) maintenance() (model.MaintenanceWindow, bool) {
+	if m.registry == nil {
+		return model.MaintenanceWindow{}, false
+	}
+	return m.registry.Maintenance(time.Now())
+}
+
+// globalRoles are the roles of the global connections, which split
+// GlobalConns evenly.
+var globalRoles = []ConnectionRole{RoleTicker, RoleTrade, RoleLifecycle}
+
+// roleChannel returns the channel a global connection subscribes to.
+func roleChannel(role ConnectionRole) string {
+	if role == RoleLifecycle {
+		return "market_lifecycle"
+	}
+	return string(role)
+}
+
+// clientConfig returns the WebSocket client settings for a new connection.
+func (m *manager) clientConfig() ClientConfig {
+	return ClientConfig{
 		URL:          m.cfg.WSURL,
 		KeyID:        m.cfg.KeyID,
 		PrivateKey:   m.cfg.PrivateKey,
@@ -227,42 +443,25 @@ func (m *manager) initConnections() error {
 		WriteTimeout: 5 * time.Second,
 		BufferSize:   1000,
 	}
+}
 
-	// Ticker connections (1-2)
-	for i := 0; i < 2; i++ {
-		conn := m.newConnState(i+1, RoleTicker, clientCfg)
-		if err := conn.client.Connect(m.ctx); err != nil {
-			m.logger.Warn("failed to connect ticker", "id", i+1, "error", err)
-			// Continue - will reconnect later
-		}
-		m.tickerConns[i] = conn
-	}
-
-	// Trade connections (3-4)
-	for i := 0; i < 2; i++ {
-		conn := m.newConnState(i+3, RoleTrade, clientCfg)
-		if err := conn.client.Connect(m.ctx); err != nil {
-			m.logger.Warn("failed to connect trade", "id", i+3, "error", err)
-		}
-		m.tradeConns[i] = conn
-	}
-
-	// Lifecycle connections (5-6)
-	for i := 0; i < 2; i++ {
-		conn := m.newConnState(i+5, RoleLifecycle, clientCfg)
-		if err := conn.client.Connect(m.ctx); err != nil {
-			m.logger.Warn("failed to connect lifecycle", "id", i+5, "error", err)
-		}
-		m.lifecycleConns[i] = conn
-	}
-
-	// Orderbook connections (7-150)
-	for i := 0; i < 144; i++ {
-		conn := m.newConnState(i+7, RoleOrderbook, clientCfg)
-		if err := conn.client.Connect(m.ctx); err != nil {
-			m.logger.Warn("failed to connect orderbook", "id", i+7, "error", err)
+// initConnections creates the global WebSocket connections. Orderbook
+// connections are opened as markets are subscribed.
+func (m *manager) initConnections() error {
+	clientCfg := m.clientConfig()
+	perRole := m.cfg.GlobalConns / len(globalRoles)
+
+	id := 1
+	for _, role := range globalRoles {
+		for i := 0; i < perRole; i++ {
+			conn := m.newConnState(id, role, clientCfg)
+			if err := conn.client.Connect(m.ctx); err != nil {
+				m.logger.Warn("failed to connect", "role", role, "id", id, "error", err)
+				// Continue - will reconnect later
+			}
+			m.globalConns = append(m.globalConns, conn)
+			id++
 		}
-		m.orderbookConns[i] = conn
 	}
 
 	return nil
@@ -275,68 +474,60 @@ func (m *manager) newConnState(id int, role ConnectionRole, cfg ClientConfig) *c
 		id:           id,
 		role:         role,
 		markets:      make(map[string]struct{}),
+		tickerMsgs:   make(map[string]int64),
+		sampledAt:    time.Now(),
 		readLoopDone: make(chan struct{}),
+		retired:      make(chan struct{}),
 		pending:      make(map[int64]chan Response),
 	}
 }
 
-// startReadLoops starts read loops for all connections.
-func (m *manager) startReadLoops() {
-	for _, c := range m.tickerConns {
-		if c != nil {
-			m.wg.Add(1)
-			go m.readLoop(c)
-		}
-	}
-	for _, c := range m.tradeConns {
-		if c != nil {
-			m.wg.Add(1)
-			go m.readLoop(c)
-		}
-	}
-	for _, c := range m.lifecycleConns {
-		if c != nil {
-			m.wg.Add(1)
-			go m.readLoop(c)
-		}
-	}
+// allConns returns the global connections followed by the open orderbook
+// connections in ID order.
+func (m *manager) allConns() []*connState {
+	m.poolMu.RLock()
+	conns := make([]*connState, 0, len(m.globalConns)+len(m.orderbookConns))
+	conns = append(conns, m.globalConns...)
 	for _, c := range m.orderbookConns {
-		if c != nil {
-			m.wg.Add(1)
-			go m.readLoop(c)
-		}
+		conns = append(conns, c)
 	}
+	m.poolMu.RUnlock()
+
+	sort.Slice(conns, func(i, j int) bool { return conns[i].id < conns[j].id })
+	return conns
 }
 
-// subscribeGlobalChannels subscribes to ticker, trade, and lifecycle channels.
-func (m *manager) subscribeGlobalChannels() error {
-	// Subscribe ticker on both connections
-	for i, c := range m.tickerConns {
-		if c == nil || !c.client.IsConnected() {
-			continue
-		}
-		if err := m.subscribe(c, "ticker", ""); err != nil {
-			m.logger.Warn("failed to subscribe ticker", "conn", i+1, "error", err)
-		}
-	}
+// orderbookConn returns the open orderbook connection with id, or nil.
+func (m *manager) orderbookConn(id int) *connState {
+	m.poolMu.RLock()
+	defer m.poolMu.RUnlock()
+	return m.orderbookConns[id]
+}
 
-	// Subscribe trade on both connections
-	for i, c := range m.tradeConns {
-		if c == nil || !c.client.IsConnected() {
-			continue
-		}
-		if err := m.subscribe(c, "trade", ""); err != nil {
-			m.logger.Warn("failed to subscribe trade", "conn", i+3, "error", err)
-		}
+// orderbookConnCount returns the number of open orderbook connections.
+func (m *manager) orderbookConnCount() int {
+	m.poolMu.RLock()
+	defer m.poolMu.RUnlock()
+	return len(m.orderbookConns)
+}
+
+// startReadLoops starts read loops for all connections.
+func (m *manager) startReadLoops() {
+	for _, c := range m.allConns() {
+		m.wg.Add(1)
+		go m.readLoop(c)
 	}
+}
 
-	// Subscribe lifecycle on both connections
-	for i, c := range m.lifecycleConns {
-		if c == nil || !c.client.IsConnected() {
+// subscribeGlobalChannels subscribes to ticker, trade, and lifecycle channels.
+func (m *manager) subscribeGlobalChannels() error {
+	for _, c := range m.globalConns {
+		if !c.client.IsConnected() {
 			continue
 		}
-		if err := m.subscribe(c, "market_lifecycle", ""); err != nil {
-			m.logger.Warn("failed to subscribe lifecycle", "conn", i+5, "error", err)
+		channel := roleChannel(c.role)
+		if _, err := m.subscribe(c, channel, nil); err != nil {
+			m.logger.Warn("failed to subscribe "+channel, "conn", c.id, "error", err)
 		}
 	}
 
@@ -348,98 +539,121 @@ func (m *manager) subscribeExistingMarkets() {
 	markets := m.registry.GetActiveMarkets()
 	m.logger.Info("subscribing to existing markets", "count", len(markets))
 
-	for _, mkt := range markets {
-		m.subscribeOrderbook(mkt.Ticker)
+	tickers := make([]string, len(markets))
+	for i, mkt := range markets {
+		tickers[i] = mkt.Ticker
 	}
+	m.subscribeOrderbooks(tickers)
 }
 
 // closeAllConnections closes all WebSocket connections.
 func (m *manager) closeAllConnections() {
-	for _, c := range m.tickerConns {
-		if c != nil {
-			c.client.Close()
-		}
-	}
-	for _, c := range m.tradeConns {
-		if c != nil {
-			c.client.Close()
-		}
-	}
-	for _, c := range m.lifecycleConns {
-		if c != nil {
-			c.client.Close()
-		}
-	}
-	for _, c := range m.orderbookConns {
-		if c != nil {
-			c.client.Close()
-		}
+	for _, c := range m.allConns() {
+		c.client.Close()
 	}
 }
 
-// handleMarketChanges processes market change events from the registry.
+// handleMarketChanges collects market change events from the registry for
+// applyMarketChanges. Changes are coalesced per market, so a batch carries
+// each market's latest state and a burst of lifecycle events goes out as a
+// few update_subscription commands.
 func (m *manager) handleMarketChanges() {
 	defer m.wg.Done()
 
 	changes := m.registry.SubscribeChanges()
 
-	// Worker pool for non-blocking subscribes
-	workCh := make(chan market.MarketChange, 100)
-	for i := 0; i < m.cfg.WorkerCount; i++ {
-		m.wg.Add(1)
-		go m.subscribeWorker(workCh)
-	}
+	m.wg.Add(1)
+	go m.applyMarketChanges()
 
 	for {
 		select {
 		case <-m.ctx.Done():
-			close(workCh)
 			return
 		case change, ok := <-changes:
 			if !ok {
-				close(workCh)
 				return
 			}
-			select {
-			case workCh <- change:
-			default:
-				m.logger.Warn("subscribe worker backpressure, dropping event",
-					"ticker", change.Ticker,
-				)
-			}
+			m.queueMarketChange(change)
 		}
 	}
 }
 
-// subscribeWorker processes market changes from the work queue.
-func (m *manager) subscribeWorker(workCh <-chan market.MarketChange) {
-	defer m.wg.Done()
-
-	for change := range workCh {
-		m.handleMarketChange(change)
-	}
-}
-
-// handleMarketChange processes a single market change event.
-func (m *manager) handleMarketChange(change market.MarketChange) {
+// queueMarketChange records whether change leaves its market subscribed.
+func (m *manager) queueMarketChange(change market.MarketChange) {
+	var subscribe bool
 	switch change.EventType {
 	case "created":
-		if isActiveStatus(change.NewStatus) {
-			m.subscribeOrderbook(change.Ticker)
+		if !isActiveStatus(change.NewStatus) {
+			return
 		}
+		subscribe = true
 
 	case "status_change":
 		wasActive := isActiveStatus(change.OldStatus)
 		isActive := isActiveStatus(change.NewStatus)
-
-		if isActive && !wasActive {
-			m.subscribeOrderbook(change.Ticker)
-		} else if !isActive && wasActive {
-			m.unsubscribeOrderbook(change.Ticker)
+		if isActive == wasActive {
+			return
 		}
+		subscribe = isActive
 
 	case "settled":
-		m.unsubscribeOrderbook(change.Ticker)
+		subscribe = false
+
+	default:
+		return
+	}
+
+	m.pendingMu.Lock()
+	m.pending[change.Ticker] = subscribe
+	m.pendingMu.Unlock()
+
+	select {
+	case m.pendingCh <- struct{}{}:
+	default:
+	}
+}
+
+// applyMarketChanges applies queued market changes, waiting BatchWindow
+// after the first so a burst is applied together.
+func (m *manager) applyMarketChanges() {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-m.pendingCh:
+		}
+
+		if m.cfg.BatchWindow > 0 {
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-time.After(m.cfg.BatchWindow):
+			}
+		}
+
+		m.pendingMu.Lock()
+		pending := m.pending
+		m.pending = make(map[string]bool)
+		m.pendingMu.Unlock()
+
+		var add, remove []string
+		for ticker, subscribe := range pending {
+			if subscribe {
+				add = append(add, ticker)
+			} else {
+				remove = append(remove, ticker)
+			}
+		}
+		sort.Strings(add)
+		sort.Strings(remove)
+
+		// Removals first, freeing room for additions
+		m.changeMu.Lock()
+		m.unsubscribeOrderbooks(remove)
+		m.subscribeOrderbooks(add)
+		m.changeMu.Unlock()
 	}
 }
 
@@ -448,116 +662,377 @@ func isActiveStatus(status string) bool {
 	return status == "open" || status == "active"
 }
 
-// selectOrderbookConn returns the orderbook connection with fewest subscriptions.
-func (m *manager) selectOrderbookConn() *connState {
-	var minConn *connState
-	minCount := math.MaxInt
+// reserveOrderbookConn adds ticker to an open, connected orderbook
+// connection and returns it, or nil if there is none. Markets are packed
+// onto the fullest connection below MarketsPerConn and MaxConnRate so the
+// pool stays small and emptied connections can be retired. With overfill,
+// the least loaded connection is used even if every connection is full.
+func (m *manager) reserveOrderbookConn(ticker string, overfill bool) *connState {
+	m.poolMu.Lock()
+	defer m.poolMu.Unlock()
+
+	var best *connState
+	bestCount := -1
+	if overfill {
+		bestCount = math.MaxInt
+	}
 
 	for _, conn := range m.orderbookConns {
-		if conn == nil || !conn.client.IsConnected() {
+		if !conn.client.IsConnected() {
 			continue
 		}
 		conn.mu.Lock()
 		count := len(conn.markets)
 		conn.mu.Unlock()
 
-		if count < minCount {
-			minCount = count
-			minConn = conn
+		if overfill {
+			if count < bestCount || (count == bestCount && conn.id < best.id) {
+				best, bestCount = conn, count
+			}
+		} else if count < m.cfg.MarketsPerConn && !m.overCeiling(conn) {
+			if count > bestCount || (count == bestCount && conn.id < best.id) {
+				best, bestCount = conn, count
+			}
 		}
 	}
 
-	return minConn
+	if best != nil {
+		best.mu.Lock()
+		best.markets[ticker] = struct{}{}
+		best.mu.Unlock()
+	}
+	return best
 }
 
-// subscribeOrderbook subscribes to orderbook updates for a market.
-func (m *manager) subscribeOrderbook(ticker string) {
-	// Check if already subscribed
-	m.marketConnMu.RLock()
-	_, exists := m.marketToConn[ticker]
-	m.marketConnMu.RUnlock()
-	if exists {
-		return
+// assignOrderbookConn adds ticker to an orderbook connection, opening a new
+// one if every open connection is full. Once the pool holds OrderbookConns
+// connections, markets go to the least loaded one. Returns nil if no
+// connection is available.
+func (m *manager) assignOrderbookConn(ticker string) *connState {
+	if conn := m.reserveOrderbookConn(ticker, false); conn != nil {
+		return conn
 	}
 
-	conn := m.selectOrderbookConn()
-	if conn == nil {
-		m.logger.Error("no healthy orderbook connections", "ticker", ticker)
+	m.openMu.Lock()
+	defer m.openMu.Unlock()
+
+	// Another worker may have opened one while we waited
+	if conn := m.reserveOrderbookConn(ticker, false); conn != nil {
+		return conn
+	}
+	if conn := m.openOrderbookConn(ticker); conn != nil {
+		return conn
+	}
+	return m.reserveOrderbookConn(ticker, true)
+}
+
+// openOrderbookConn connects a new orderbook connection holding ticker and
+// adds it to the pool. Returns nil if the pool is at OrderbookConns or the
+// connection fails. Callers hold openMu.
+func (m *manager) openOrderbookConn(ticker string) *connState {
+	first := m.cfg.GlobalConns + 1
+
+	m.poolMu.RLock()
+	id := 0
+	for i := first; i < first+m.cfg.OrderbookConns; i++ {
+		if _, used := m.orderbookConns[i]; !used {
+			id = i
+			break
+		}
+	}
+	m.poolMu.RUnlock()
+
+	if id == 0 {
+		m.logger.Warn("orderbook connection pool full",
+			"ticker", ticker,
+			"max_conns", m.cfg.OrderbookConns,
+		)
+		return nil
+	}
+
+	conn := m.newConnState(id, RoleOrderbook, m.clientConfig())
+	if err := conn.client.Connect(m.ctx); err != nil {
+		m.logger.Warn("failed to connect orderbook", "id", id, "error", err)
+		return nil
+	}
+	conn.markets[ticker] = struct{}{}
+
+	m.poolMu.Lock()
+	m.orderbookConns[id] = conn
+	count := len(m.orderbookConns)
+	m.poolMu.Unlock()
+
+	m.wg.Add(1)
+	go m.readLoop(conn)
+
+	m.logger.Info("opened orderbook connection",
+		"conn", id,
+		"orderbook_conns", count,
+	)
+	return conn
+}
+
+// retireIfEmpty closes an orderbook connection that no longer holds any
+// markets and removes it from the pool.
+func (m *manager) retireIfEmpty(conn *connState) {
+	m.poolMu.Lock()
+	conn.mu.Lock()
+	empty := len(conn.markets) == 0
+	conn.mu.Unlock()
+	if !empty || m.orderbookConns[conn.id] != conn {
+		m.poolMu.Unlock()
 		return
 	}
+	delete(m.orderbookConns, conn.id)
+	count := len(m.orderbookConns)
+	m.poolMu.Unlock()
 
-	// Track assignment
-	m.marketConnMu.Lock()
-	m.marketToConn[ticker] = conn.id
-	m.marketConnMu.Unlock()
+	close(conn.retired)
+	conn.client.Close()
 
+	// Closing the connection ends a subscription unsubscribe could not
 	conn.mu.Lock()
-	conn.markets[ticker] = struct{}{}
+	sid := conn.sid
+	conn.sid = 0
 	conn.mu.Unlock()
+	if sid != 0 {
+		m.dropSubscription(sid)
+	}
 
-	// Send subscribe command
-	if err := m.subscribe(conn, "orderbook_delta", ticker); err != nil {
-		m.logger.Warn("failed to subscribe orderbook",
-			"ticker", ticker,
+	m.logger.Info("retired orderbook connection",
+		"conn", conn.id,
+		"orderbook_conns", count,
+	)
+}
+
+// subscribeOrderbooks subscribes to orderbook updates for the markets not
+// already subscribed. Markets are assigned to connections first, then each
+// connection adds its share with one command per BatchSize tickers.
+func (m *manager) subscribeOrderbooks(tickers []string) {
+	batches := make(map[*connState][]string)
+	for _, ticker := range tickers {
+		// Claim the market so a concurrent caller skips it
+		m.marketConnMu.Lock()
+		_, exists := m.marketToConn[ticker]
+		if !exists {
+			m.marketToConn[ticker] = 0
+		}
+		m.marketConnMu.Unlock()
+		if exists {
+			continue
+		}
+
+		conn := m.assignOrderbookConn(ticker)
+
+		m.marketConnMu.Lock()
+		if conn == nil {
+			delete(m.marketToConn, ticker)
+		} else {
+			m.marketToConn[ticker] = conn.id
+		}
+		m.marketConnMu.Unlock()
+
+		if conn == nil {
+			m.logger.Error("no healthy orderbook connections", "ticker", ticker)
+			continue
+		}
+		batches[conn] = append(batches[conn], ticker)
+	}
+
+	m.forEachConn(batches, func(conn *connState, tickers []string) {
+		conn.subMu.Lock()
+		added, err := m.addOrderbookMarkets(conn, tickers)
+		conn.subMu.Unlock()
+		if err == nil {
+			return
+		}
+
+		failed := tickers[added:]
+		m.logger.Warn("failed to subscribe orderbooks",
 			"conn", conn.id,
+			"markets", len(failed),
 			"error", err,
 		)
 
 		// Rollback tracking
 		conn.mu.Lock()
-		delete(conn.markets, ticker)
+		for _, ticker := range failed {
+			delete(conn.markets, ticker)
+		}
 		conn.mu.Unlock()
 
 		m.marketConnMu.Lock()
-		delete(m.marketToConn, ticker)
+		for _, ticker := range failed {
+			delete(m.marketToConn, ticker)
+		}
 		m.marketConnMu.Unlock()
-	}
+
+		m.retireIfEmpty(conn)
+	})
 }
 
-// unsubscribeOrderbook unsubscribes from orderbook updates for a market.
-func (m *manager) unsubscribeOrderbook(ticker string) {
-	m.marketConnMu.RLock()
-	connID, ok := m.marketToConn[ticker]
-	m.marketConnMu.RUnlock()
+// unsubscribeOrderbooks unsubscribes from orderbook updates for markets,
+// with one command per connection and BatchSize tickers.
+func (m *manager) unsubscribeOrderbooks(tickers []string) {
+	batches := make(map[*connState][]string)
 
-	if !ok {
-		return
+	m.marketConnMu.Lock()
+	for _, ticker := range tickers {
+		connID, ok := m.marketToConn[ticker]
+		if !ok {
+			continue
+		}
+		delete(m.marketToConn, ticker)
+		if conn := m.orderbookConn(connID); conn != nil {
+			batches[conn] = append(batches[conn], ticker)
+		}
 	}
+	m.marketConnMu.Unlock()
 
-	// Convert connection ID (7-150) to array index (0-143)
-	if connID < 7 || connID > 150 {
-		return
+	m.forEachConn(batches, func(conn *connState, tickers []string) {
+		conn.mu.Lock()
+		for _, ticker := range tickers {
+			delete(conn.markets, ticker)
+		}
+		conn.mu.Unlock()
+
+		conn.subMu.Lock()
+		err := m.removeOrderbookMarkets(conn, tickers)
+		conn.subMu.Unlock()
+		if err != nil {
+			m.logger.Warn("failed to unsubscribe orderbooks",
+				"conn", conn.id,
+				"markets", len(tickers),
+				"error", err,
+			)
+		}
+
+		m.retireIfEmpty(conn)
+	})
+}
+
+// forEachConn runs fn for each connection's tickers, WorkerCount
+// connections at a time.
+func (m *manager) forEachConn(batches map[*connState][]string, fn func(*connState, []string)) {
+	sem := make(chan struct{}, max(m.cfg.WorkerCount, 1))
+	var wg sync.WaitGroup
+
+	for conn, tickers := range batches {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(conn, tickers)
+		}()
 	}
-	conn := m.orderbookConns[connID-7]
 
+	wg.Wait()
+}
+
+// addOrderbookMarkets adds tickers to conn's orderbook subscription,
+// creating it if conn has none, BatchSize tickers per command. Returns how
+// many tickers were added before an error. Caller holds conn.subMu.
+func (m *manager) addOrderbookMarkets(conn *connState, tickers []string) (int, error) {
+	for added := 0; added < len(tickers); {
+		batch := tickers[added:min(added+m.cfg.BatchSize, len(tickers))]
+
+		conn.mu.Lock()
+		sid := conn.sid
+		conn.mu.Unlock()
+
+		if sid == 0 {
+			newSID, err := m.subscribe(conn, "orderbook_delta", batch)
+			if err != nil {
+				return added, err
+			}
+			conn.mu.Lock()
+			conn.sid = newSID
+			conn.mu.Unlock()
+		} else if err := m.updateSubscription(conn, sid, "add_markets", batch); err != nil {
+			return added, err
+		}
+
+		added += len(batch)
+	}
+	return len(tickers), nil
+}
+
+// removeOrderbookMarkets removes tickers from conn's orderbook subscription,
+// or unsubscribes it once conn holds no markets. Caller holds conn.subMu and
+// has removed tickers from conn.markets.
+func (m *manager) removeOrderbookMarkets(conn *connState, tickers []string) error {
 	conn.mu.Lock()
-	delete(conn.markets, ticker)
+	sid := conn.sid
+	empty := len(conn.markets) == 0
 	conn.mu.Unlock()
 
-	// Find SID for this ticker and unsubscribe
-	m.subsMu.RLock()
-	var sid int64
-	for s, sub := range m.subs {
-		if sub.Ticker == ticker && sub.Channel == "orderbook_delta" {
-			sid = s
-			break
-		}
+	if sid == 0 {
+		return nil
 	}
-	m.subsMu.RUnlock()
 
-	if sid != 0 {
+	if empty {
 		if err := m.unsubscribe(conn, sid); err != nil {
-			m.logger.Warn("failed to unsubscribe orderbook",
-				"ticker", ticker,
-				"sid", sid,
-				"error", err,
-			)
+			return err
 		}
+		conn.mu.Lock()
+		conn.sid = 0
+		conn.mu.Unlock()
+		return nil
 	}
 
-	m.marketConnMu.Lock()
-	delete(m.marketToConn, ticker)
-	m.marketConnMu.Unlock()
+	for i := 0; i < len(tickers); i += m.cfg.BatchSize {
+		batch := tickers[i:min(i+m.cfg.BatchSize, len(tickers))]
+		if err := m.updateSubscription(conn, sid, "delete_markets", batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errSubscriptionReplaced is returned by resubscribeOrderbooks when the
+// stale subscription was already replaced, so nothing was resubscribed.
+var errSubscriptionReplaced = errors.New("subscription already replaced")
+
+// resubscribeOrderbooks replaces conn's orderbook subscription with a new
+// one for all of its markets, so each starts again from a snapshot. If stale
+// is set it is unsubscribed first, or errSubscriptionReplaced returned if it
+// was already replaced; with 0 (after a reconnect, when the server has
+// dropped it) the old subscription is only forgotten. Returns the number of
+// markets resubscribed.
+func (m *manager) resubscribeOrderbooks(conn *connState, stale int64) (int, error) {
+	conn.subMu.Lock()
+	defer conn.subMu.Unlock()
+
+	conn.mu.Lock()
+	oldSID := conn.sid
+	markets := make([]string, 0, len(conn.markets))
+	for ticker := range conn.markets {
+		markets = append(markets, ticker)
+	}
+	conn.mu.Unlock()
+	sort.Strings(markets)
+
+	if stale != 0 {
+		if oldSID != stale {
+			return 0, errSubscriptionReplaced
+		}
+		if err := m.unsubscribe(conn, oldSID); err != nil {
+			return 0, fmt.Errorf("unsubscribe sid %d: %w", oldSID, err)
+		}
+	} else if oldSID != 0 {
+		m.dropSubscription(oldSID)
+	}
+
+	conn.mu.Lock()
+	conn.sid = 0
+	conn.mu.Unlock()
+
+	added, err := m.addOrderbookMarkets(conn, markets)
+	if err != nil {
+		return added, fmt.Errorf("resubscribe: %w", err)
+	}
+	return added, nil
 }
 
 // readLoop reads messages from a connection and routes them.
@@ -570,12 +1045,24 @@ func (m *manager) readLoop(conn *connState) {
 		case <-m.ctx.Done():
 			return
 
+		case <-conn.retired:
+			return
+
 		case err := <-conn.client.Errors():
-			m.logger.Warn("connection error",
+			level := slog.LevelWarn
+			if _, ok := m.maintenance(); ok {
+				// Expected while the exchange is down
+				level = slog.LevelInfo
+			}
+			m.logger.Log(m.ctx, level, "connection error",
 				"conn", conn.id,
 				"role", conn.role,
 				"error", err,
 			)
+			// The server forgets a connection's subscriptions with it
+			if n := m.subs.resetConn(conn.id); n > 0 {
+				m.logger.Debug("dropped subscriptions", "conn", conn.id, "sids", n)
+			}
 			m.wg.Add(1)
 			go m.reconnect(conn)
 			return
@@ -591,6 +1078,11 @@ func (m *manager) readLoop(conn *connState) {
 				continue
 			}
 
+			// Orderbook messages are counted per market below
+			if conn.role != RoleOrderbook {
+				conn.countMessage("")
+			}
+
 			// Route lifecycle messages to Market Registry
 			if conn.role == RoleLifecycle {
 				select {
@@ -604,13 +1096,20 @@ func (m *manager) readLoop(conn *connState) {
 			// Check sequence for orderbook messages
 			var seqGap bool
 			var gapSize int
+			var sid int64
 			if conn.role == RoleOrderbook {
-				if sid, seq, ok := m.extractSequence(msg.Data); ok {
-					seqGap, gapSize = m.checkSequence(sid, seq)
+				hdr, ok := m.parseOrderbookHeader(msg.Data)
+				conn.countMessage(hdr.Msg.MarketTicker)
+				if ok && hdr.Seq != 0 {
+					sid = hdr.SID
+					seqGap, gapSize = m.checkSequence(hdr.SID, hdr.Seq)
+					if seqGap {
+						m.handleGap(conn, hdr.SID, hdr.Seq, gapSize, msg.ReceivedAt)
+					}
 				}
 			}
 
-			// Data message - forward to router (non-blocking)
+			// Data message - forward to router, applying OverflowPolicy if it is full
 			rawMsg := RawMessage{
 				Data:       msg.Data,
 				ConnID:     conn.id,
@@ -618,15 +1117,8 @@ func (m *manager) readLoop(conn *connState) {
 				SeqGap:     seqGap,
 				GapSize:    gapSize,
 			}
-
-			select {
-			case m.router <- rawMsg:
-			case <-m.ctx.Done():
+			if !m.handoff(conn, rawMsg, sid) {
 				return
-			default:
-				m.logger.Warn("message buffer full, dropping",
-					"conn", conn.id,
-				)
 			}
 		}
 	}
@@ -670,50 +1162,52 @@ func (c *connState) routeResponse(resp Response) {
 	}
 }
 
-// extractSequence extracts SID and sequence number from a data message.
-func (m *manager) extractSequence(data []byte) (sid int64, seq int64, ok bool) {
-	var msg DataMessage
-	if err := json.Unmarshal(data, &msg); err != nil {
-		return 0, 0, false
-	}
+// orderbookHeader is the part of an orderbook message the manager reads.
+type orderbookHeader struct {
+	SID int64 `json:"sid"`
+	Seq int64 `json:"seq"`
+	Msg struct {
+		MarketTicker string `json:"market_ticker"`
+	} `json:"msg"`
+}
 
-	if msg.Seq == 0 {
-		return 0, 0, false
+// parseOrderbookHeader extracts the SID, sequence number and market from an
+// orderbook data message.
+func (m *manager) parseOrderbookHeader(data []byte) (orderbookHeader, bool) {
+	var hdr orderbookHeader
+	if err := json.Unmarshal(data, &hdr); err != nil {
+		return orderbookHeader{}, false
 	}
-
-	return msg.SID, msg.Seq, true
+	return hdr, true
 }
 
-// checkSequence checks for sequence gaps and returns gap info.
+// checkSequence checks for sequence gaps and returns gap info. Messages
+// the manager dropped itself count as missed, even though they did not
+// break the sequence it saw.
 func (m *manager) checkSequence(sid int64, seq int64) (seqGap bool, gapSize int) {
-	m.seqMu.Lock()
-	defer m.seqMu.Unlock()
-
-	last, exists := m.lastSeq[sid]
-	if !exists {
-		// First message for this subscription
-		m.lastSeq[sid] = seq
-		return false, 0
+	last, dropped := m.subs.advanceSeq(sid, seq)
+	gap := dropped
+	if last != 0 && seq != last+1 {
+		gap += int(seq - last - 1)
 	}
-
-	if seq != last+1 {
-		gap := int(seq - last - 1)
-		m.logger.Warn("sequence gap detected",
-			"sid", sid,
-			"expected", last+1,
-			"got", seq,
-			"gap", gap,
-		)
-		m.lastSeq[sid] = seq
-		return true, gap
+	if gap == 0 {
+		// First message for this subscription, or in order
+		return false, 0
 	}
 
-	m.lastSeq[sid] = seq
-	return false, 0
+	m.logger.Warn("sequence gap detected",
+		"sid", sid,
+		"expected", seq-int64(gap),
+		"got", seq,
+		"gap", gap,
+		"dropped", dropped,
+	)
+	return true, gap
 }
 
-// subscribe sends a subscribe command and waits for response.
-func (m *manager) subscribe(conn *connState, channel, ticker string) error {
+// sendCommand sends a command and waits for its response. Error responses
+// are returned as errors.
+func (m *manager) sendCommand(conn *connState, cmd string, params interface{}) (Response, error) {
 	id := atomic.AddInt64(&conn.cmdID, 1)
 	respCh := make(chan Response, 1)
 
@@ -727,116 +1221,114 @@ func (m *manager) subscribe(conn *connState, channel, ticker string) error {
 		conn.pendingMu.Unlock()
 	}()
 
-	// Build command
-	params := map[string]interface{}{
-		"channels": []string{channel},
-	}
-	if ticker != "" {
-		params["market_ticker"] = ticker
-	}
-
-	cmd := Command{
+	data, _ := json.Marshal(Command{
 		ID:     id,
-		Cmd:    "subscribe",
+		Cmd:    cmd,
 		Params: params,
-	}
-
-	data, _ := json.Marshal(cmd)
+	})
 	if err := conn.client.Send(data); err != nil {
-		return err
+		return Response{}, err
 	}
 
-	// Wait for response
 	select {
 	case <-m.ctx.Done():
-		return m.ctx.Err()
+		return Response{}, m.ctx.Err()
 	case <-time.After(m.cfg.SubscribeTimeout):
-		return ErrTimeout
+		return Response{}, ErrTimeout
 	case resp := <-respCh:
 		if resp.Type == "error" {
 			var errMsg ErrorMsg
 			json.Unmarshal(resp.Msg, &errMsg)
-			return fmt.Errorf("%s: %s", errMsg.Code, errMsg.Message)
+			return resp, fmt.Errorf("%s: %s", errMsg.Code, errMsg.Message)
 		}
+		return resp, nil
+	}
+}
 
-		// Track subscription
-		var subMsg SubscribedMsg
-		json.Unmarshal(resp.Msg, &subMsg)
+// subscribe subscribes conn to channel, for tickers or for all markets if
+// tickers is empty, and returns the new SID.
+func (m *manager) subscribe(conn *connState, channel string, tickers []string) (int64, error) {
+	resp, err := m.sendCommand(conn, "subscribe", SubscribeParams{
+		Channels:      []string{channel},
+		MarketTickers: tickers,
+	})
+	if err != nil {
+		return 0, err
+	}
 
-		m.subsMu.Lock()
-		m.subs[subMsg.SID] = &Subscription{
-			SID:     subMsg.SID,
-			Channel: channel,
-			ConnID:  conn.id,
-			Ticker:  ticker,
-		}
-		m.subsMu.Unlock()
+	// Track subscription
+	var subMsg SubscribedMsg
+	json.Unmarshal(resp.Msg, &subMsg)
+
+	m.subs.add(Subscription{
+		SID:     subMsg.SID,
+		Channel: channel,
+		ConnID:  conn.id,
+	}, tickers)
+
+	m.logger.Debug("subscribed",
+		"channel", channel,
+		"markets", len(tickers),
+		"sid", subMsg.SID,
+		"conn", conn.id,
+	)
 
-		m.logger.Debug("subscribed",
-			"channel", channel,
-			"ticker", ticker,
-			"sid", subMsg.SID,
-			"conn", conn.id,
-		)
+	return subMsg.SID, nil
+}
 
-		return nil
+// updateSubscription adds markets to or deletes markets from a subscription.
+// action is "add_markets" or "delete_markets". The SID and its sequence
+// numbers carry on.
+func (m *manager) updateSubscription(conn *connState, sid int64, action string, tickers []string) error {
+	if _, err := m.sendCommand(conn, "update_subscription", UpdateSubscriptionParams{
+		SIDs:          []int64{sid},
+		Action:        action,
+		MarketTickers: tickers,
+	}); err != nil {
+		return err
+	}
+
+	if action == "add_markets" {
+		m.subs.addMarkets(sid, tickers)
+	} else {
+		m.subs.removeMarkets(sid, tickers)
 	}
+
+	m.logger.Debug("subscription updated",
+		"action", action,
+		"markets", len(tickers),
+		"sid", sid,
+		"conn", conn.id,
+	)
+	return nil
 }
 
 // unsubscribe sends an unsubscribe command and waits for response.
 func (m *manager) unsubscribe(conn *connState, sid int64) error {
-	id := atomic.AddInt64(&conn.cmdID, 1)
-	respCh := make(chan Response, 1)
-
-	conn.pendingMu.Lock()
-	conn.pending[id] = respCh
-	conn.pendingMu.Unlock()
-
-	defer func() {
-		conn.pendingMu.Lock()
-		delete(conn.pending, id)
-		conn.pendingMu.Unlock()
-	}()
-
-	cmd := Command{
-		ID:  id,
-		Cmd: "unsubscribe",
-		Params: UnsubscribeParams{
-			SIDs: []int64{sid},
-		},
-	}
-
-	data, _ := json.Marshal(cmd)
-	if err := conn.client.Send(data); err != nil {
+	if _, err := m.sendCommand(conn, "unsubscribe", UnsubscribeParams{
+		SIDs: []int64{sid},
+	}); err != nil {
 		return err
 	}
 
-	select {
-	case <-m.ctx.Done():
-		return m.ctx.Err()
-	case <-time.After(m.cfg.SubscribeTimeout):
-		return ErrTimeout
-	case resp := <-respCh:
-		if resp.Type == "error" {
-			var errMsg ErrorMsg
-			json.Unmarshal(resp.Msg, &errMsg)
-			return fmt.Errorf("%s: %s", errMsg.Code, errMsg.Message)
-		}
-
-		m.subsMu.Lock()
-		delete(m.subs, sid)
-		m.subsMu.Unlock()
+	m.dropSubscription(sid)
+	return nil
+}
 
-		// Clean up sequence tracking
-		m.seqMu.Lock()
-		delete(m.lastSeq, sid)
-		m.seqMu.Unlock()
+// dropSubscription forgets a subscription and its sequence tracking.
+func (m *manager) dropSubscription(sid int64) {
+	m.subs.remove(sid)
+}
 
-		return nil
-	}
+// Subscriptions returns the live subscriptions, ordered by connection and SID.
+func (m *manager) Subscriptions() []Subscription {
+	return m.subs.list()
 }
 
 // reconnect attempts to reconnect a connection with exponential backoff.
+// During scheduled maintenance it waits for the window to end instead of
+// retrying, then starts again from the base wait. Gives up once an
+// orderbook connection is retired.
 func (m *manager) reconnect(conn *connState) {
 	defer m.wg.Done()
 
@@ -847,9 +1339,30 @@ func (m *manager) reconnect(conn *connState) {
 		select {
 		case <-m.ctx.Done():
 			return
+		case <-conn.retired:
+			return
 		case <-time.After(wait):
 		}
 
+		if w, ok := m.maintenance(); ok {
+			end := time.UnixMicro(w.End)
+			m.logger.Info("exchange maintenance, deferring reconnection",
+				"conn", conn.id,
+				"role", conn.role,
+				"until", end.UTC(),
+			)
+			m.reconnectsDeferred.Add(1)
+
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-conn.retired:
+				return
+			case <-time.After(time.Until(end) + rand.N(min(maintenanceJitter, maxWait)+1)):
+			}
+			wait = m.cfg.ReconnectBaseWait
+		}
+
 		m.logger.Info("attempting reconnection",
 			"conn", conn.id,
 			"role", conn.role,
@@ -859,20 +1372,16 @@ func (m *manager) reconnect(conn *connState) {
 		conn.client.Close()
 
 		// Create new client
-		cfg := ClientConfig{
-			URL:          m.cfg.WSURL,
-			KeyID:        m.cfg.KeyID,
-			PrivateKey:   m.cfg.PrivateKey,
-			PingTimeout:  30 * time.Second,
-			WriteTimeout: 5 * time.Second,
-			BufferSize:   1000,
-		}
-		conn.client = NewClient(cfg, m.logger.With("conn_id", conn.id, "role", conn.role))
+		conn.client = NewClient(m.clientConfig(), m.logger.With("conn_id", conn.id, "role", conn.role))
 		conn.readLoopDone = make(chan struct{})
 		conn.pending = make(map[int64]chan Response)
 
 		if err := conn.client.Connect(m.ctx); err != nil {
-			m.logger.Warn("reconnection failed",
+			level := slog.LevelWarn
+			if _, ok := m.maintenance(); ok {
+				level = slog.LevelDebug
+			}
+			m.logger.Log(m.ctx, level, "reconnection failed",
 				"conn", conn.id,
 				"error", err,
 			)
@@ -885,34 +1394,37 @@ func (m *manager) reconnect(conn *connState) {
 			continue
 		}
 
+		select {
+		case <-conn.retired:
+			// Emptied while reconnecting
+			conn.client.Close()
+			return
+		default:
+		}
+
 		m.logger.Info("reconnected", "conn", conn.id)
 
+		// Restart read loop first: subscribe waits for responses it routes
+		m.wg.Add(1)
+		go m.readLoop(conn)
+
 		// Re-subscribe based on role
 		switch conn.role {
-		case RoleTicker:
-			m.subscribe(conn, "ticker", "")
-		case RoleTrade:
-			m.subscribe(conn, "trade", "")
-		case RoleLifecycle:
-			m.subscribe(conn, "market_lifecycle", "")
-		case RoleOrderbook:
-			// Re-subscribe to all markets on this connection
-			conn.mu.Lock()
-			markets := make([]string, 0, len(conn.markets))
-			for ticker := range conn.markets {
-				markets = append(markets, ticker)
+		case RoleTicker, RoleTrade, RoleLifecycle:
+			if _, err := m.subscribe(conn, roleChannel(conn.role), nil); err != nil {
+				m.logger.Warn("failed to resubscribe", "conn", conn.id, "role", conn.role, "error", err)
 			}
-			conn.mu.Unlock()
-
-			for _, ticker := range markets {
-				m.subscribe(conn, "orderbook_delta", ticker)
+		case RoleOrderbook:
+			// All markets on this connection, in batches
+			if n, err := m.resubscribeOrderbooks(conn, 0); err != nil {
+				m.logger.Warn("failed to resubscribe orderbooks",
+					"conn", conn.id,
+					"resubscribed", n,
+					"error", err,
+				)
 			}
 		}
 
-		// Restart read loop
-		m.wg.Add(1)
-		go m.readLoop(conn)
-
 		return
 	}
 }
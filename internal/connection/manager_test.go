@@ -22,6 +22,7 @@ type mockRegistry struct {
 	activeMarkets   []model.Market
 	changes         chan market.MarketChange
 	lifecycleSource <-chan []byte
+	maintenance     *model.MaintenanceWindow
 }
 
 func newMockRegistry() *mockRegistry {
@@ -31,12 +32,22 @@ func newMockRegistry() *mockRegistry {
 	}
 }
 
-func (r *mockRegistry) Start(ctx context.Context) error              { return nil }
-func (r *mockRegistry) Stop(ctx context.Context) error               { return nil }
-func (r *mockRegistry) GetActiveMarkets() []model.Market             { return r.activeMarkets }
-func (r *mockRegistry) GetMarket(ticker string) (model.Market, bool) { return model.Market{}, false }
-func (r *mockRegistry) SubscribeChanges() <-chan market.MarketChange { return r.changes }
-func (r *mockRegistry) SetLifecycleSource(ch <-chan []byte)          { r.lifecycleSource = ch }
+func (r *mockRegistry) Start(ctx context.Context) error                 { return nil }
+func (r *mockRegistry) Stop(ctx context.Context) error                  { return nil }
+func (r *mockRegistry) GetActiveMarkets() []model.Market                { return r.activeMarkets }
+func (r *mockRegistry) GetMarket(ticker string) (model.Market, bool)    { return model.Market{}, false }
+func (r *mockRegistry) SubscribeChanges() <-chan market.MarketChange    { return r.changes }
+func (r *mockRegistry) SubscribeMetadata() <-chan market.MetadataUpdate { return nil }
+func (r *mockRegistry) SetLifecycleSource(ch <-chan []byte)             { r.lifecycleSource = ch }
+
+func (r *mockRegistry) Maintenance(t time.Time) (model.MaintenanceWindow, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if w := r.maintenance; w != nil && t.UnixMicro() >= w.Start && t.UnixMicro() < w.End {
+		return *w, true
+	}
+	return model.MaintenanceWindow{}, false
+}
 
 func (r *mockRegistry) AddMarket(m model.Market) {
 	r.mu.Lock()
@@ -226,6 +237,294 @@ func TestManager_SubscribeOrderbook(t *testing.T) {
 	}
 }
 
+// subscribingServer answers every subscribe with a new SID, every
+// update_subscription with "ok" and every unsubscribe with "unsubscribed".
+// If record is set it is called with each command's connection and text.
+func subscribingServer(t *testing.T, record func(connID int, cmd string)) *httptest.Server {
+	var mu sync.Mutex
+	var nextSID int64
+
+	return mockWSServerMulti(t, func(id int, conn *websocket.Conn) {
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var cmd struct {
+				ID     int64           `json:"id"`
+				Cmd    string          `json:"cmd"`
+				Params json.RawMessage `json:"params"`
+			}
+			if err := json.Unmarshal(msg, &cmd); err != nil {
+				continue
+			}
+
+			var params UpdateSubscriptionParams
+			json.Unmarshal(cmd.Params, &params)
+			if record != nil && len(params.MarketTickers) > 0 {
+				text := cmd.Cmd
+				if params.Action != "" {
+					text = params.Action
+				}
+				record(id, text+" "+strings.Join(params.MarketTickers, ","))
+			}
+
+			resp := Response{ID: cmd.ID, Type: "unsubscribed"}
+			switch cmd.Cmd {
+			case "subscribe":
+				mu.Lock()
+				nextSID++
+				resp.Type = "subscribed"
+				resp.Msg, _ = json.Marshal(SubscribedMsg{SID: nextSID})
+				mu.Unlock()
+			case "update_subscription":
+				resp.Type = "ok"
+			}
+			data, _ := json.Marshal(resp)
+			conn.WriteMessage(websocket.TextMessage, data)
+		}
+	})
+}
+
+func TestManager_OrderbookPool_GrowsAndShrinks(t *testing.T) {
+	server := subscribingServer(t, nil)
+	defer server.Close()
+
+	registry := newMockRegistry()
+	for _, ticker := range []string{"MKT-1", "MKT-2", "MKT-3", "MKT-4", "MKT-5"} {
+		registry.AddMarket(model.Market{Ticker: ticker, MarketStatus: "open"})
+	}
+
+	cfg := ManagerConfig{
+		WSURL:             wsURL(server),
+		SubscribeTimeout:  5 * time.Second,
+		ReconnectBaseWait: 100 * time.Millisecond,
+		ReconnectMaxWait:  1 * time.Second,
+		MessageBufferSize: 1000,
+		WorkerCount:       2,
+		GlobalConns:       3,
+		OrderbookConns:    10,
+		MarketsPerConn:    2,
+	}
+
+	mgr := NewManager(cfg, registry, nil).(*manager)
+	if err := mgr.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		mgr.Stop(stopCtx)
+	}()
+
+	stats := mgr.Stats()
+	if stats.OrderbookConns != 3 {
+		t.Errorf("OrderbookConns = %d, want 3", stats.OrderbookConns)
+	}
+	if stats.ConnectedCount != 6 {
+		t.Errorf("ConnectedCount = %d, want 6", stats.ConnectedCount)
+	}
+
+	// Markets are packed in order: IDs 4 and 5 are full, 6 holds MKT-5
+	for ticker, want := range map[string]int{"MKT-1": 4, "MKT-2": 4, "MKT-3": 5, "MKT-4": 5, "MKT-5": 6} {
+		if got := mgr.marketToConn[ticker]; got != want {
+			t.Errorf("%s on conn %d, want %d", ticker, got, want)
+		}
+	}
+
+	mgr.unsubscribeOrderbooks([]string{"MKT-5"})
+	if got := mgr.Stats().OrderbookConns; got != 2 {
+		t.Errorf("OrderbookConns after emptying a connection = %d, want 2", got)
+	}
+
+	// Freed ID and room on the remaining connections are reused
+	mgr.unsubscribeOrderbooks([]string{"MKT-1"})
+	mgr.subscribeOrderbooks([]string{"MKT-6", "MKT-7"})
+	if got := mgr.marketToConn["MKT-6"]; got != 4 {
+		t.Errorf("MKT-6 on conn %d, want 4", got)
+	}
+	if got := mgr.marketToConn["MKT-7"]; got != 6 {
+		t.Errorf("MKT-7 on conn %d, want 6", got)
+	}
+}
+
+func TestManager_OrderbookPool_FullPoolOverfills(t *testing.T) {
+	server := subscribingServer(t, nil)
+	defer server.Close()
+
+	registry := newMockRegistry()
+	for _, ticker := range []string{"MKT-1", "MKT-2", "MKT-3"} {
+		registry.AddMarket(model.Market{Ticker: ticker, MarketStatus: "open"})
+	}
+
+	cfg := ManagerConfig{
+		WSURL:             wsURL(server),
+		SubscribeTimeout:  5 * time.Second,
+		ReconnectBaseWait: 100 * time.Millisecond,
+		ReconnectMaxWait:  1 * time.Second,
+		MessageBufferSize: 1000,
+		WorkerCount:       2,
+		GlobalConns:       3,
+		OrderbookConns:    2,
+		MarketsPerConn:    1,
+	}
+
+	mgr := NewManager(cfg, registry, nil)
+	if err := mgr.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		mgr.Stop(stopCtx)
+	}()
+
+	stats := mgr.Stats()
+	if stats.OrderbookConns != 2 {
+		t.Errorf("OrderbookConns = %d, want 2", stats.OrderbookConns)
+	}
+	if stats.MarketsSubscribed != 3 {
+		t.Errorf("MarketsSubscribed = %d, want 3", stats.MarketsSubscribed)
+	}
+}
+
+// commandLog collects commands recorded by subscribingServer.
+type commandLog struct {
+	mu       sync.Mutex
+	commands []string
+}
+
+func (l *commandLog) record(_ int, cmd string) {
+	l.mu.Lock()
+	l.commands = append(l.commands, cmd)
+	l.mu.Unlock()
+}
+
+// waitFor waits until n commands are recorded and returns them.
+func (l *commandLog) waitFor(t *testing.T, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		l.mu.Lock()
+		got := append([]string(nil), l.commands...)
+		l.mu.Unlock()
+		if len(got) >= n || time.Now().After(deadline) {
+			return got
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestManager_SubscribeOrderbooks_Batches(t *testing.T) {
+	var log commandLog
+	server := subscribingServer(t, log.record)
+	defer server.Close()
+
+	registry := newMockRegistry()
+	for _, ticker := range []string{"MKT-1", "MKT-2", "MKT-3", "MKT-4", "MKT-5"} {
+		registry.AddMarket(model.Market{Ticker: ticker, MarketStatus: "open"})
+	}
+
+	cfg := ManagerConfig{
+		WSURL:             wsURL(server),
+		SubscribeTimeout:  5 * time.Second,
+		ReconnectBaseWait: 100 * time.Millisecond,
+		ReconnectMaxWait:  1 * time.Second,
+		MessageBufferSize: 1000,
+		WorkerCount:       2,
+		GlobalConns:       3,
+		OrderbookConns:    10,
+		MarketsPerConn:    10,
+		BatchSize:         2,
+	}
+
+	mgr := NewManager(cfg, registry, nil).(*manager)
+	if err := mgr.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		mgr.Stop(stopCtx)
+	}()
+
+	if got := mgr.Stats().OrderbookConns; got != 1 {
+		t.Errorf("OrderbookConns = %d, want 1", got)
+	}
+
+	mgr.unsubscribeOrderbooks([]string{"MKT-1", "MKT-2", "MKT-3"})
+
+	want := []string{
+		"subscribe MKT-1,MKT-2",
+		"add_markets MKT-3,MKT-4",
+		"add_markets MKT-5",
+		"delete_markets MKT-1,MKT-2",
+		"delete_markets MKT-3",
+	}
+	got := log.waitFor(t, len(want))
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("commands = %v, want %v", got, want)
+	}
+
+	// All markets share the connection's one SID
+	conn := mgr.orderbookConn(mgr.marketToConn["MKT-4"])
+	if conn == nil || conn.sid == 0 {
+		t.Fatal("MKT-4 has no orderbook subscription")
+	}
+	if got := mgr.Stats().TotalSubscriptions; got != 4 {
+		t.Errorf("TotalSubscriptions = %d, want 4", got)
+	}
+}
+
+func TestManager_MarketChanges_Coalesced(t *testing.T) {
+	var log commandLog
+	server := subscribingServer(t, log.record)
+	defer server.Close()
+
+	registry := newMockRegistry()
+	registry.AddMarket(model.Market{Ticker: "MKT-1", MarketStatus: "open"})
+	registry.AddMarket(model.Market{Ticker: "MKT-2", MarketStatus: "open"})
+
+	cfg := ManagerConfig{
+		WSURL:             wsURL(server),
+		SubscribeTimeout:  5 * time.Second,
+		ReconnectBaseWait: 100 * time.Millisecond,
+		ReconnectMaxWait:  1 * time.Second,
+		MessageBufferSize: 1000,
+		WorkerCount:       2,
+		GlobalConns:       3,
+		BatchWindow:       200 * time.Millisecond,
+	}
+
+	mgr := NewManager(cfg, registry, nil)
+	if err := mgr.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		mgr.Stop(stopCtx)
+	}()
+
+	// A burst within one window, including a market created and settled
+	for _, ticker := range []string{"NEW-1", "NEW-2", "NEW-3"} {
+		registry.SendChange(market.MarketChange{Ticker: ticker, EventType: "created", NewStatus: "open"})
+	}
+	registry.SendChange(market.MarketChange{Ticker: "NEW-2", EventType: "settled"})
+	registry.SendChange(market.MarketChange{Ticker: "MKT-1", EventType: "settled"})
+
+	want := []string{
+		"subscribe MKT-1,MKT-2",
+		"delete_markets MKT-1",
+		"add_markets NEW-1,NEW-3",
+	}
+	got := log.waitFor(t, len(want))
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("commands = %v, want %v", got, want)
+	}
+}
+
 func TestManager_HandleMarketChange_Created(t *testing.T) {
 	var subscriptions []string
 	var mu sync.Mutex
@@ -244,9 +543,11 @@ func TestManager_HandleMarketChange_Created(t *testing.T) {
 
 			if cmd.Cmd == "subscribe" {
 				params := cmd.Params.(map[string]interface{})
-				if ticker, ok := params["market_ticker"].(string); ok {
+				if tickers, ok := params["market_tickers"].([]interface{}); ok {
 					mu.Lock()
-					subscriptions = append(subscriptions, ticker)
+					for _, ticker := range tickers {
+						subscriptions = append(subscriptions, ticker.(string))
+					}
 					mu.Unlock()
 				}
 
@@ -387,11 +688,76 @@ func TestManager_MessageRouting(t *testing.T) {
 	}
 }
 
+func TestManager_ReconnectDeferredDuringMaintenance(t *testing.T) {
+	server := mockWSServerMulti(t, func(id int, conn *websocket.Conn) {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer server.Close()
+
+	registry := newMockRegistry()
+	cfg := ManagerConfig{
+		WSURL:             wsURL(server),
+		SubscribeTimeout:  50 * time.Millisecond,
+		ReconnectBaseWait: 10 * time.Millisecond,
+		ReconnectMaxWait:  100 * time.Millisecond,
+		MessageBufferSize: 1000,
+	}
+	mgr := NewManager(cfg, registry, nil).(*manager)
+	mgr.ctx, mgr.cancel = context.WithCancel(context.Background())
+
+	start := time.Now()
+	registry.maintenance = &model.MaintenanceWindow{
+		Start: start.Add(-time.Minute).UnixMicro(),
+		End:   start.Add(300 * time.Millisecond).UnixMicro(),
+	}
+	if !mgr.Stats().InMaintenance {
+		t.Error("InMaintenance = false, want true")
+	}
+
+	conn := mgr.newConnState(1, RoleTicker, ClientConfig{URL: cfg.WSURL})
+	done := make(chan struct{})
+	mgr.wg.Add(1)
+	go func() {
+		mgr.reconnect(conn)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for reconnect")
+	}
+	if !conn.client.IsConnected() {
+		t.Fatal("not reconnected after maintenance")
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("reconnected after %v, want after the window ended", elapsed)
+	}
+
+	stats := mgr.Stats()
+	if stats.ReconnectsDeferred != 1 {
+		t.Errorf("ReconnectsDeferred = %d, want 1", stats.ReconnectsDeferred)
+	}
+	if stats.InMaintenance {
+		t.Error("InMaintenance = true after the window, want false")
+	}
+
+	mgr.cancel()
+	mgr.wg.Wait()
+	conn.client.Close()
+}
+
 func TestManager_SequenceGapDetection(t *testing.T) {
 	mgr := &manager{
-		lastSeq: make(map[int64]int64),
-		logger:  slog.Default(),
+		subs:   newSubscriptionTable(),
+		logger: slog.Default(),
 	}
+	mgr.subs.add(Subscription{SID: 1, Channel: "orderbook_delta", ConnID: 7}, nil)
+	mgr.subs.add(Subscription{SID: 2, Channel: "orderbook_delta", ConnID: 8}, nil)
 
 	// First message - no gap
 	gap, size := mgr.checkSequence(1, 1)
@@ -422,6 +788,13 @@ func TestManager_SequenceGapDetection(t *testing.T) {
 	if gap {
 		t.Error("expected no gap for new SID")
 	}
+
+	// Dropped SID - not tracked
+	mgr.dropSubscription(1)
+	mgr.checkSequence(1, 6)
+	if gap, _ = mgr.checkSequence(1, 9); gap {
+		t.Error("expected no gap for dropped SID")
+	}
 }
 
 func TestManager_TryParseResponse(t *testing.T) {
@@ -476,27 +849,30 @@ func TestManager_TryParseResponse(t *testing.T) {
 	}
 }
 
-func TestManager_ExtractSequence(t *testing.T) {
+func TestManager_ParseOrderbookHeader(t *testing.T) {
 	mgr := &manager{}
 
 	tests := []struct {
-		name    string
-		data    string
-		wantSID int64
-		wantSeq int64
-		wantOK  bool
+		name       string
+		data       string
+		wantSID    int64
+		wantSeq    int64
+		wantTicker string
+		wantOK     bool
 	}{
 		{
-			name:    "valid message with sequence",
-			data:    `{"type":"orderbook_delta","sid":42,"seq":100,"msg":{}}`,
-			wantSID: 42,
-			wantSeq: 100,
-			wantOK:  true,
+			name:       "valid message with sequence",
+			data:       `{"type":"orderbook_delta","sid":42,"seq":100,"msg":{"market_ticker":"MKT-A"}}`,
+			wantSID:    42,
+			wantSeq:    100,
+			wantTicker: "MKT-A",
+			wantOK:     true,
 		},
 		{
-			name:   "message without sequence",
-			data:   `{"type":"ticker","sid":1,"msg":{}}`,
-			wantOK: false,
+			name:    "message without sequence",
+			data:    `{"type":"ticker","sid":1,"msg":{}}`,
+			wantSID: 1,
+			wantOK:  true,
 		},
 		{
 			name:   "invalid json",
@@ -507,17 +883,13 @@ func TestManager_ExtractSequence(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			sid, seq, ok := mgr.extractSequence([]byte(tt.data))
+			hdr, ok := mgr.parseOrderbookHeader([]byte(tt.data))
 			if ok != tt.wantOK {
-				t.Errorf("extractSequence() ok = %v, want %v", ok, tt.wantOK)
+				t.Errorf("parseOrderbookHeader() ok = %v, want %v", ok, tt.wantOK)
 			}
-			if ok {
-				if sid != tt.wantSID {
-					t.Errorf("extractSequence() sid = %d, want %d", sid, tt.wantSID)
-				}
-				if seq != tt.wantSeq {
-					t.Errorf("extractSequence() seq = %d, want %d", seq, tt.wantSeq)
-				}
+			if hdr.SID != tt.wantSID || hdr.Seq != tt.wantSeq || hdr.Msg.MarketTicker != tt.wantTicker {
+				t.Errorf("parseOrderbookHeader() sid/seq/ticker = %d/%d/%q, want %d/%d/%q",
+					hdr.SID, hdr.Seq, hdr.Msg.MarketTicker, tt.wantSID, tt.wantSeq, tt.wantTicker)
 			}
 		})
 	}
@@ -0,0 +1,339 @@
+package connection
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrSpillFull is returned when the spill file has reached SpillMaxBytes.
+var ErrSpillFull = errors.New("spill file full")
+
+// spillFile is the spill queue's file name in SpillDir. It is truncated
+// when the manager starts: messages spilled before a restart are stale.
+const spillFile = "conn-manager.spill"
+
+// dropKey identifies a connection for drop accounting. Counts outlive the
+// connection, since orderbook connections are retired and their IDs reused.
+type dropKey struct {
+	connID int
+	role   ConnectionRole
+}
+
+// handoff passes msg to the Messages() channel. When the channel is full it
+// applies OverflowPolicy; a message that is dropped is counted against conn,
+// and sid (the orderbook subscription it belongs to, or 0) is marked as
+// gapped. Returns false once the manager is stopping.
+func (m *manager) handoff(conn *connState, msg RawMessage, sid int64) bool {
+	// Once messages are spilled, later ones queue behind them so each
+	// subscription's messages stay in sequence order
+	if m.spill != nil && m.spill.pending() > 0 {
+		m.spillMessage(conn, msg, sid)
+		return true
+	}
+
+	select {
+	case m.router <- msg:
+		return true
+	case <-m.ctx.Done():
+		return false
+	default:
+	}
+
+	m.overflows.Add(1)
+
+	switch m.cfg.OverflowPolicy {
+	case OverflowSpill:
+		m.spillMessage(conn, msg, sid)
+		return true
+
+	case OverflowBlock:
+		timer := time.NewTimer(m.cfg.OverflowTimeout)
+		defer timer.Stop()
+
+		select {
+		case m.router <- msg:
+			return true
+		case <-m.ctx.Done():
+			return false
+		case <-timer.C:
+		}
+	}
+
+	m.dropMessage(conn, msg, sid, "message buffer full")
+	return true
+}
+
+// spillMessage appends msg to the spill queue, dropping it if the queue is
+// full or cannot be written.
+func (m *manager) spillMessage(conn *connState, msg RawMessage, sid int64) {
+	if err := m.spill.push(msg); err != nil {
+		m.dropMessage(conn, msg, sid, fmt.Sprintf("spill failed: %v", err))
+		return
+	}
+	m.spilled.Add(1)
+}
+
+// dropMessage counts a dropped data message from conn and marks sid as
+// gapped, so the next message delivered for it is flagged and recovered.
+// Only msg itself carries over: a gap it was flagged with has already been
+// reported and recovered by handleGap.
+func (m *manager) dropMessage(conn *connState, msg RawMessage, sid int64, reason string) {
+	m.dropMu.Lock()
+	m.drops[dropKey{connID: conn.id, role: conn.role}]++
+	m.dropMu.Unlock()
+
+	if sid != 0 {
+		m.subs.markDropped(sid, 1)
+	}
+
+	m.logger.Warn("dropping message",
+		"conn", conn.id,
+		"role", conn.role,
+		"sid", sid,
+		"reason", reason,
+	)
+}
+
+// droppedStats returns the drop counts per connection, in ID order.
+func (m *manager) droppedStats() []DroppedStats {
+	m.dropMu.Lock()
+	stats := make([]DroppedStats, 0, len(m.drops))
+	for key, n := range m.drops {
+		stats = append(stats, DroppedStats{ConnID: key.connID, Role: key.role, Count: n})
+	}
+	m.dropMu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].ConnID != stats[j].ConnID {
+			return stats[i].ConnID < stats[j].ConnID
+		}
+		return stats[i].Role < stats[j].Role
+	})
+	return stats
+}
+
+// drainSpill feeds spilled messages to the Messages() channel in the order
+// they were spilled, blocking until there is room.
+func (m *manager) drainSpill() {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-m.spill.ready:
+		}
+
+		for {
+			msg, ok, err := m.spill.next()
+			if err != nil {
+				n := m.spill.reset()
+				m.logger.Error("spill file unreadable, discarding",
+					"messages", n,
+					"error", err,
+				)
+				break
+			}
+			if !ok {
+				break
+			}
+
+			select {
+			case m.router <- msg:
+				m.spill.delivered()
+			case <-m.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// spillQueue is a FIFO of RawMessages in a file, used when the Messages()
+// channel is full. A message counts as pending until delivered, so new
+// messages keep queuing behind it; the file is truncated whenever the
+// queue empties.
+type spillQueue struct {
+	mu       sync.Mutex
+	path     string
+	w        *os.File // Opened O_APPEND
+	bw       *bufio.Writer
+	r        *os.File
+	br       *bufio.Reader
+	maxBytes int64
+	size     int64 // Bytes written since the last truncate
+	unread   int64 // Messages not yet returned by next
+	queued   int64 // Messages not yet delivered
+	buf      []byte
+
+	ready chan struct{} // Signals messages to drain
+}
+
+// Spill records use the journal's layout (big-endian):
+//
+//	uint32  len(Data)
+//	int64   ReceivedAt (Unix nanoseconds)
+//	uint16  ConnID
+//	uint8   flags (bit 0: SeqGap)
+//	uint32  GapSize
+//	[]byte  Data
+const (
+	spillHeaderSize = 4 + 8 + 2 + 1 + 4
+	spillFlagSeqGap = 1 << 0
+)
+
+// openSpillQueue creates an empty spill file in dir.
+func openSpillQueue(dir string, maxBytes int64) (*spillQueue, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create spill dir: %w", err)
+	}
+
+	path := filepath.Join(dir, spillFile)
+	w, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open spill file: %w", err)
+	}
+	r, err := os.Open(path)
+	if err != nil {
+		w.Close()
+		return nil, fmt.Errorf("open spill file: %w", err)
+	}
+
+	return &spillQueue{
+		path:     path,
+		w:        w,
+		bw:       bufio.NewWriter(w),
+		r:        r,
+		br:       bufio.NewReader(r),
+		maxBytes: maxBytes,
+		ready:    make(chan struct{}, 1),
+	}, nil
+}
+
+// push appends msg to the queue.
+func (q *spillQueue) push(msg RawMessage) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := int64(spillHeaderSize + len(msg.Data))
+	if q.maxBytes > 0 && q.size+n > q.maxBytes {
+		return ErrSpillFull
+	}
+
+	var hdr [spillHeaderSize]byte
+	binary.BigEndian.PutUint32(hdr[0:4], uint32(len(msg.Data)))
+	binary.BigEndian.PutUint64(hdr[4:12], uint64(msg.ReceivedAt.UnixNano()))
+	binary.BigEndian.PutUint16(hdr[12:14], uint16(msg.ConnID))
+	if msg.SeqGap {
+		hdr[14] |= spillFlagSeqGap
+	}
+	binary.BigEndian.PutUint32(hdr[15:19], uint32(msg.GapSize))
+
+	q.buf = append(append(q.buf[:0], hdr[:]...), msg.Data...)
+	if _, err := q.bw.Write(q.buf); err != nil {
+		return err
+	}
+
+	q.size += n
+	q.unread++
+	q.queued++
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// next returns the oldest message not yet returned, or false if there is
+// none. It stays pending until delivered is called.
+func (q *spillQueue) next() (RawMessage, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.unread == 0 {
+		return RawMessage{}, false, nil
+	}
+	if q.bw.Buffered() > 0 {
+		if err := q.bw.Flush(); err != nil {
+			return RawMessage{}, false, err
+		}
+	}
+
+	var hdr [spillHeaderSize]byte
+	if _, err := io.ReadFull(q.br, hdr[:]); err != nil {
+		return RawMessage{}, false, err
+	}
+	msg := RawMessage{
+		ReceivedAt: time.Unix(0, int64(binary.BigEndian.Uint64(hdr[4:12]))),
+		ConnID:     int(binary.BigEndian.Uint16(hdr[12:14])),
+		SeqGap:     hdr[14]&spillFlagSeqGap != 0,
+		GapSize:    int(binary.BigEndian.Uint32(hdr[15:19])),
+		Data:       make([]byte, binary.BigEndian.Uint32(hdr[0:4])),
+	}
+	if _, err := io.ReadFull(q.br, msg.Data); err != nil {
+		return RawMessage{}, false, err
+	}
+
+	q.unread--
+	return msg, true, nil
+}
+
+// delivered marks the message last returned by next as handed off. The
+// file is truncated once every spilled message has been delivered.
+func (q *spillQueue) delivered() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.queued--
+	if q.queued == 0 {
+		q.truncateLocked()
+	}
+}
+
+// reset discards every queued message. Returns how many were discarded.
+func (q *spillQueue) reset() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := q.queued
+	q.truncateLocked()
+	return n
+}
+
+func (q *spillQueue) truncateLocked() {
+	q.bw.Reset(q.w)
+	q.w.Truncate(0)
+	q.r.Seek(0, io.SeekStart)
+	q.br.Reset(q.r)
+	q.size = 0
+	q.unread = 0
+	q.queued = 0
+}
+
+// pending returns the number of messages not yet delivered.
+func (q *spillQueue) pending() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.queued
+}
+
+// close closes the spill file and removes it. Returns the number of
+// messages that were never delivered.
+func (q *spillQueue) close() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := q.queued
+	q.w.Close()
+	q.r.Close()
+	os.Remove(q.path)
+	return n
+}
@@ -0,0 +1,223 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// newOverflowTestManager returns a manager whose Messages() channel holds
+// one message and is already full.
+func newOverflowTestManager(t *testing.T, cfg ManagerConfig) *manager {
+	t.Helper()
+	m := &manager{
+		cfg:    cfg,
+		logger: slog.Default(),
+		router: make(chan RawMessage, 1),
+		subs:   newSubscriptionTable(),
+		drops:  make(map[dropKey]int64),
+	}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	t.Cleanup(m.cancel)
+
+	m.router <- RawMessage{Data: []byte("full")}
+	return m
+}
+
+func overflowMessage(i int) RawMessage {
+	return RawMessage{
+		Data:       []byte(fmt.Sprintf(`{"type":"orderbook_delta","sid":1,"seq":%d}`, i)),
+		ConnID:     7,
+		ReceivedAt: time.Unix(1705320000, int64(i)),
+	}
+}
+
+func TestSpillQueue_FIFO(t *testing.T) {
+	q, err := openSpillQueue(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("openSpillQueue: %v", err)
+	}
+	defer q.close()
+
+	for round := 0; round < 2; round++ {
+		for i := 1; i <= 3; i++ {
+			if err := q.push(overflowMessage(i)); err != nil {
+				t.Fatalf("push: %v", err)
+			}
+		}
+
+		for i := 1; i <= 3; i++ {
+			msg, ok, err := q.next()
+			if err != nil || !ok {
+				t.Fatalf("round %d: next() = %v, %v", round, ok, err)
+			}
+			want := overflowMessage(i)
+			if string(msg.Data) != string(want.Data) || msg.ConnID != 7 || !msg.ReceivedAt.Equal(want.ReceivedAt) {
+				t.Errorf("round %d: next() = %+v, want %+v", round, msg, want)
+			}
+			if got := q.pending(); got != int64(4-i) {
+				t.Errorf("pending before delivery = %d, want %d", got, 4-i)
+			}
+			q.delivered()
+		}
+
+		// Emptied, so the file starts over
+		if _, ok, _ := q.next(); ok {
+			t.Error("next() returned a message from an empty queue")
+		}
+		if q.size != 0 {
+			t.Errorf("size = %d after draining, want 0", q.size)
+		}
+	}
+}
+
+func TestSpillQueue_Full(t *testing.T) {
+	q, err := openSpillQueue(t.TempDir(), int64(2*(spillHeaderSize+len(overflowMessage(1).Data))))
+	if err != nil {
+		t.Fatalf("openSpillQueue: %v", err)
+	}
+	defer q.close()
+
+	var pushed int
+	for i := 1; i <= 3; i++ {
+		if err := q.push(overflowMessage(i)); err == nil {
+			pushed++
+		} else if !errors.Is(err, ErrSpillFull) {
+			t.Fatalf("push error = %v, want ErrSpillFull", err)
+		}
+	}
+	if pushed != 2 {
+		t.Errorf("pushed %d messages, want 2", pushed)
+	}
+}
+
+func TestManager_Handoff_DropMarksGap(t *testing.T) {
+	cfg := DefaultManagerConfig()
+	cfg.OverflowPolicy = OverflowDrop
+	m := newOverflowTestManager(t, cfg)
+	m.subs.add(Subscription{SID: 1, Channel: "orderbook_delta", ConnID: 7}, []string{"MKT-A"})
+	conn := &connState{id: 7, role: RoleOrderbook}
+
+	// As readLoop does: check the sequence, then hand off
+	m.checkSequence(1, 1)
+	for i := 2; i <= 3; i++ {
+		msg := overflowMessage(i)
+		msg.SeqGap, msg.GapSize = m.checkSequence(1, int64(i))
+		if !m.handoff(conn, msg, 1) {
+			t.Fatal("handoff() = false, want true")
+		}
+	}
+
+	stats := m.Stats()
+	want := []DroppedStats{{ConnID: 7, Role: RoleOrderbook, Count: 2}}
+	if len(stats.Dropped) != 1 || stats.Dropped[0] != want[0] {
+		t.Errorf("Dropped = %+v, want %+v", stats.Dropped, want)
+	}
+	if stats.Overflows != 2 {
+		t.Errorf("Overflows = %d, want 2", stats.Overflows)
+	}
+
+	// The next message is in sequence, but follows the dropped third one;
+	// the second's drop was already reported as the third's gap
+	gap, size := m.checkSequence(1, 4)
+	if !gap || size != 1 {
+		t.Errorf("checkSequence after drops = %v, %d, want true, 1", gap, size)
+	}
+	if gap, _ := m.checkSequence(1, 5); gap {
+		t.Error("gap reported again after it was flagged")
+	}
+}
+
+func TestManager_Handoff_DropGapFlagged(t *testing.T) {
+	cfg := DefaultManagerConfig()
+	cfg.OverflowPolicy = OverflowDrop
+	m := newOverflowTestManager(t, cfg)
+	m.subs.add(Subscription{SID: 1, Channel: "orderbook_delta", ConnID: 7}, []string{"MKT-A"})
+	conn := &connState{id: 7, role: RoleOrderbook}
+
+	// Seqs 2-4 never arrive, and the message reporting them is dropped
+	m.checkSequence(1, 1)
+	msg := overflowMessage(5)
+	msg.SeqGap, msg.GapSize = m.checkSequence(1, 5)
+	if !msg.SeqGap || msg.GapSize != 3 {
+		t.Fatalf("checkSequence(5) = %v, %d, want true, 3", msg.SeqGap, msg.GapSize)
+	}
+	m.handoff(conn, msg, 1)
+
+	// Only the dropped message itself is new to the next one's gap
+	gap, size := m.checkSequence(1, 6)
+	if !gap || size != 1 {
+		t.Errorf("checkSequence after drop = %v, %d, want true, 1", gap, size)
+	}
+}
+
+func TestManager_Handoff_BlockTimeout(t *testing.T) {
+	cfg := DefaultManagerConfig()
+	cfg.OverflowTimeout = 50 * time.Millisecond
+	m := newOverflowTestManager(t, cfg)
+	conn := &connState{id: 1, role: RoleTicker}
+
+	// Room frees up while waiting
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-m.router
+	}()
+	m.handoff(conn, overflowMessage(1), 0)
+	if got := m.Stats().Dropped; len(got) != 0 {
+		t.Fatalf("Dropped = %+v, want none", got)
+	}
+
+	// No room before the timeout
+	start := time.Now()
+	m.handoff(conn, overflowMessage(2), 0)
+	if elapsed := time.Since(start); elapsed < cfg.OverflowTimeout {
+		t.Errorf("dropped after %v, want after %v", elapsed, cfg.OverflowTimeout)
+	}
+	if got := m.Stats().Dropped; len(got) != 1 || got[0].Count != 1 {
+		t.Errorf("Dropped = %+v, want 1 for conn 1", got)
+	}
+}
+
+func TestManager_Handoff_SpillKeepsOrder(t *testing.T) {
+	cfg := DefaultManagerConfig()
+	cfg.OverflowPolicy = OverflowSpill
+	m := newOverflowTestManager(t, cfg)
+	spill, err := openSpillQueue(t.TempDir(), cfg.SpillMaxBytes)
+	if err != nil {
+		t.Fatalf("openSpillQueue: %v", err)
+	}
+	defer spill.close()
+	m.spill = spill
+	conn := &connState{id: 7, role: RoleOrderbook}
+
+	for i := 1; i <= 5; i++ {
+		m.handoff(conn, overflowMessage(i), 1)
+	}
+	if stats := m.Stats(); stats.Spilled != 5 || stats.SpillPending != 5 {
+		t.Errorf("Spilled/SpillPending = %d/%d, want 5/5", stats.Spilled, stats.SpillPending)
+	}
+
+	m.wg.Add(1)
+	go m.drainSpill()
+
+	<-m.router // "full"
+	for i := 1; i <= 5; i++ {
+		select {
+		case msg := <-m.router:
+			if want := overflowMessage(i); string(msg.Data) != string(want.Data) {
+				t.Errorf("message %d = %s, want %s", i, msg.Data, want.Data)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for message %d", i)
+		}
+	}
+
+	m.cancel()
+	m.wg.Wait()
+	if stats := m.Stats(); stats.SpillPending != 0 || len(stats.Dropped) != 0 {
+		t.Errorf("SpillPending/Dropped = %d/%+v, want 0/none", stats.SpillPending, stats.Dropped)
+	}
+}
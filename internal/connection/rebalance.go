@@ -0,0 +1,311 @@
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxMovesPerRebalance bounds how many markets one rebalance pass moves, so
+// a burst of activity does not turn into a burst of subscription churn.
+const maxMovesPerRebalance = 20
+
+// connLoad is an orderbook connection's sampled load during a rebalance,
+// updated as markets are moved.
+type connLoad struct {
+	conn    *connState
+	rate    float64
+	markets int
+	tickers []tickerLoad // Busiest first
+}
+
+// tickerLoad is one market's sampled message rate.
+type tickerLoad struct {
+	ticker string
+	rate   float64
+}
+
+// countMessage records a data message for conn's message rate. ticker is
+// the market of an orderbook message, or "" for global channels.
+func (c *connState) countMessage(ticker string) {
+	c.received.Add(1)
+	if ticker == "" {
+		return
+	}
+	c.rateMu.Lock()
+	c.tickerMsgs[ticker]++
+	c.rateMu.Unlock()
+}
+
+// sampleRate sets conn's message rates from the messages counted since the
+// last sample and resets the counts.
+func (c *connState) sampleRate(now time.Time) {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	elapsed := now.Sub(c.sampledAt).Seconds()
+	counts := c.tickerMsgs
+	c.tickerMsgs = make(map[string]int64, len(counts))
+	c.sampledAt = now
+	received := c.received.Swap(0)
+	if elapsed <= 0 {
+		return
+	}
+
+	c.rate = float64(received) / elapsed
+	c.tickerRates = make(map[string]float64, len(counts))
+	for ticker, n := range counts {
+		c.tickerRates[ticker] = float64(n) / elapsed
+	}
+}
+
+// messageRate returns conn's data messages per second at the last sample.
+func (c *connState) messageRate() float64 {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+	return c.rate
+}
+
+// stats returns conn's current load.
+func (c *connState) stats() ConnStats {
+	c.mu.Lock()
+	markets := len(c.markets)
+	c.mu.Unlock()
+
+	return ConnStats{
+		ID:          c.id,
+		Role:        c.role,
+		Markets:     markets,
+		MessageRate: c.messageRate(),
+	}
+}
+
+// overCeiling reports whether conn's message rate is at MaxConnRate, so it
+// should not take new markets.
+func (m *manager) overCeiling(conn *connState) bool {
+	return m.cfg.MaxConnRate > 0 && conn.messageRate() >= float64(m.cfg.MaxConnRate)
+}
+
+// rebalanceLoop samples every connection's message rate each
+// RebalanceInterval and, with MaxConnRate set, rebalances the orderbook
+// connections.
+func (m *manager) rebalanceLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.cfg.RebalanceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, conn := range m.allConns() {
+				conn.sampleRate(now)
+			}
+			if m.cfg.MaxConnRate > 0 {
+				m.rebalance()
+			}
+		}
+	}
+}
+
+// rebalance moves the busiest markets off orderbook connections above
+// MaxConnRate, one market at a time, until each is back under the ceiling
+// or has a single market left. A market goes to the least busy connection
+// it fits on without passing MaxConnRate or MarketsPerConn; if none has
+// room, a new connection is opened for it.
+func (m *manager) rebalance() {
+	m.changeMu.Lock()
+	defer m.changeMu.Unlock()
+
+	ceiling := float64(m.cfg.MaxConnRate)
+	loads := m.orderbookLoads()
+	sort.Slice(loads, func(i, j int) bool { return loads[i].rate > loads[j].rate })
+
+	moves := 0
+	for _, src := range loads {
+		if src.rate <= ceiling {
+			break // Busiest first, so the rest are under too
+		}
+
+		for _, t := range src.tickers {
+			if src.rate <= ceiling || src.markets <= 1 || moves >= maxMovesPerRebalance {
+				break
+			}
+
+			dst := m.rebalanceTarget(&loads, src, t)
+			if dst == nil {
+				continue
+			}
+			if err := m.migrateOrderbook(t.ticker, src.conn, dst.conn); err != nil {
+				m.logger.Warn("failed to migrate market",
+					"ticker", t.ticker,
+					"from", src.conn.id,
+					"to", dst.conn.id,
+					"error", err,
+				)
+				continue
+			}
+
+			moves++
+			src.rate -= t.rate
+			src.markets--
+			dst.rate += t.rate
+			dst.markets++
+		}
+	}
+
+	if moves > 0 {
+		m.logger.Info("rebalanced orderbook connections",
+			"moves", moves,
+			"max_conn_rate", m.cfg.MaxConnRate,
+		)
+	}
+}
+
+// orderbookLoads returns the sampled load of every open orderbook
+// connection.
+func (m *manager) orderbookLoads() []*connLoad {
+	m.poolMu.RLock()
+	conns := make([]*connState, 0, len(m.orderbookConns))
+	for _, conn := range m.orderbookConns {
+		conns = append(conns, conn)
+	}
+	m.poolMu.RUnlock()
+
+	loads := make([]*connLoad, 0, len(conns))
+	for _, conn := range conns {
+		conn.rateMu.Lock()
+		rate := conn.rate
+		rates := conn.tickerRates
+		conn.rateMu.Unlock()
+
+		load := &connLoad{conn: conn, rate: rate}
+		conn.mu.Lock()
+		load.markets = len(conn.markets)
+		for ticker, r := range rates {
+			// Markets moved or unsubscribed since the sample no longer count
+			if _, ok := conn.markets[ticker]; !ok {
+				load.rate -= r
+				continue
+			}
+			load.tickers = append(load.tickers, tickerLoad{ticker: ticker, rate: r})
+		}
+		conn.mu.Unlock()
+
+		sort.Slice(load.tickers, func(i, j int) bool {
+			a, b := load.tickers[i], load.tickers[j]
+			return a.rate > b.rate || (a.rate == b.rate && a.ticker < b.ticker)
+		})
+		loads = append(loads, load)
+	}
+	return loads
+}
+
+// rebalanceTarget returns the connection with the lowest message rate that
+// can take t without passing MaxConnRate or MarketsPerConn, opening a new
+// one (added to loads) if none can. Returns nil if the pool is full.
+func (m *manager) rebalanceTarget(loads *[]*connLoad, src *connLoad, t tickerLoad) *connLoad {
+	ceiling := float64(m.cfg.MaxConnRate)
+
+	var best *connLoad
+	for _, l := range *loads {
+		if l == src || !l.conn.client.IsConnected() {
+			continue
+		}
+		if l.markets >= m.cfg.MarketsPerConn || l.rate+t.rate > ceiling {
+			continue
+		}
+		if best == nil || l.rate < best.rate || (l.rate == best.rate && l.conn.id < best.conn.id) {
+			best = l
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	if m.orderbookConnCount() >= m.cfg.OrderbookConns {
+		return nil
+	}
+
+	m.openMu.Lock()
+	conn := m.openOrderbookConn(t.ticker)
+	m.openMu.Unlock()
+	if conn == nil {
+		return nil
+	}
+
+	load := &connLoad{conn: conn}
+	*loads = append(*loads, load)
+	return load
+}
+
+// migrateOrderbook moves ticker from one orderbook connection to another,
+// make-before-break: it is added to to's subscription before it is removed
+// from from's, so its book is never without a feed. Both connections carry
+// the market in between; the new subscription starts with a snapshot, after
+// which the Orderbook Engine and writer ignore deltas from the old SID.
+func (m *manager) migrateOrderbook(ticker string, from, to *connState) error {
+	m.marketConnMu.RLock()
+	connID, ok := m.marketToConn[ticker]
+	m.marketConnMu.RUnlock()
+
+	// to may be a connection opened for ticker, which already holds it
+	to.mu.Lock()
+	to.markets[ticker] = struct{}{}
+	to.mu.Unlock()
+
+	err := ErrNotSubscribed
+	if ok && connID == from.id {
+		to.subMu.Lock()
+		_, err = m.addOrderbookMarkets(to, []string{ticker})
+		to.subMu.Unlock()
+	}
+	if err != nil {
+		to.mu.Lock()
+		delete(to.markets, ticker)
+		to.mu.Unlock()
+		m.retireIfEmpty(to)
+
+		m.migrationFailures.Add(1)
+		return fmt.Errorf("add to conn %d: %w", to.id, err)
+	}
+
+	m.marketConnMu.Lock()
+	m.marketToConn[ticker] = to.id
+	m.marketConnMu.Unlock()
+
+	from.mu.Lock()
+	delete(from.markets, ticker)
+	sid := from.sid
+	from.mu.Unlock()
+
+	from.subMu.Lock()
+	err = m.removeOrderbookMarkets(from, []string{ticker})
+	from.subMu.Unlock()
+	if err != nil {
+		// from may still be sending ticker; replace its subscription
+		m.logger.Warn("failed to remove migrated market, resubscribing",
+			"ticker", ticker,
+			"conn", from.id,
+			"error", err,
+		)
+		if _, err := m.resubscribeOrderbooks(from, sid); err != nil && !errors.Is(err, errSubscriptionReplaced) {
+			m.logger.Warn("failed to resubscribe orderbooks",
+				"conn", from.id,
+				"error", err,
+			)
+		}
+	}
+	m.retireIfEmpty(from)
+
+	m.migrated.Add(1)
+	m.logger.Info("migrated market",
+		"ticker", ticker,
+		"from", from.id,
+		"to", to.id,
+	)
+	return nil
+}
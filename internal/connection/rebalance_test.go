@@ -0,0 +1,163 @@
+package connection
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/model"
+)
+
+func TestConnState_SampleRate(t *testing.T) {
+	start := time.Now()
+	conn := &connState{tickerMsgs: make(map[string]int64), sampledAt: start}
+
+	for i := 0; i < 6; i++ {
+		conn.countMessage("MKT-A")
+	}
+	for i := 0; i < 2; i++ {
+		conn.countMessage("MKT-B")
+	}
+	conn.countMessage("")
+
+	conn.sampleRate(start.Add(2 * time.Second))
+	if got := conn.messageRate(); got != 4.5 {
+		t.Errorf("messageRate = %v, want 4.5", got)
+	}
+	if got := conn.tickerRates["MKT-A"]; got != 3 {
+		t.Errorf("MKT-A rate = %v, want 3", got)
+	}
+	if got := conn.tickerRates["MKT-B"]; got != 1 {
+		t.Errorf("MKT-B rate = %v, want 1", got)
+	}
+
+	// Counts reset after each sample
+	conn.sampleRate(start.Add(4 * time.Second))
+	if got := conn.messageRate(); got != 0 {
+		t.Errorf("messageRate after idle sample = %v, want 0", got)
+	}
+}
+
+// setLoad sets conn's sampled message rates as if they had been measured.
+func setLoad(conn *connState, rates map[string]float64) {
+	conn.rateMu.Lock()
+	defer conn.rateMu.Unlock()
+	conn.rate = 0
+	conn.tickerRates = rates
+	for _, r := range rates {
+		conn.rate += r
+	}
+}
+
+// startRebalanceManager starts a manager with three global connections,
+// three markets per orderbook connection and a 100 msg/s ceiling, with
+// markets MKT-1 to MKT-4 on connections 4 and 5. Rebalancing only runs
+// when the test calls it.
+func startRebalanceManager(t *testing.T, log *commandLog) *manager {
+	t.Helper()
+	server := subscribingServer(t, log.record)
+	t.Cleanup(server.Close)
+
+	registry := newMockRegistry()
+	for _, ticker := range []string{"MKT-1", "MKT-2", "MKT-3", "MKT-4"} {
+		registry.AddMarket(model.Market{Ticker: ticker, MarketStatus: "open"})
+	}
+
+	cfg := ManagerConfig{
+		WSURL:             wsURL(server),
+		SubscribeTimeout:  5 * time.Second,
+		ReconnectBaseWait: 100 * time.Millisecond,
+		ReconnectMaxWait:  1 * time.Second,
+		MessageBufferSize: 1000,
+		WorkerCount:       2,
+		GlobalConns:       3,
+		OrderbookConns:    4,
+		MarketsPerConn:    3,
+		MaxConnRate:       100,
+		RebalanceInterval: time.Hour,
+	}
+
+	mgr := NewManager(cfg, registry, nil).(*manager)
+	if err := mgr.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		mgr.Stop(stopCtx)
+	})
+
+	// subscribe MKT-1,MKT-2,MKT-3 and subscribe MKT-4
+	log.waitFor(t, 2)
+	return mgr
+}
+
+func TestManager_Rebalance_MovesToConnectionWithHeadroom(t *testing.T) {
+	var log commandLog
+	mgr := startRebalanceManager(t, &log)
+
+	setLoad(mgr.orderbookConn(4), map[string]float64{"MKT-1": 20, "MKT-2": 90, "MKT-3": 40})
+	setLoad(mgr.orderbookConn(5), map[string]float64{"MKT-4": 10})
+
+	mgr.rebalance()
+
+	// The busiest market that fits under the ceiling moves, added before it is removed
+	if got := mgr.marketToConn["MKT-2"]; got != 5 {
+		t.Errorf("MKT-2 on conn %d, want 5", got)
+	}
+	got := log.waitFor(t, 4)
+	want := "add_markets MKT-2; delete_markets MKT-2"
+	if len(got) < 4 || strings.Join(got[2:], "; ") != want {
+		t.Errorf("commands = %v, want ... %s", got, want)
+	}
+
+	stats := mgr.Stats()
+	if stats.Migrations != 1 || stats.MigrationFailures != 0 {
+		t.Errorf("Migrations/MigrationFailures = %d/%d, want 1/0", stats.Migrations, stats.MigrationFailures)
+	}
+	if stats.OrderbookConns != 2 {
+		t.Errorf("OrderbookConns = %d, want 2", stats.OrderbookConns)
+	}
+}
+
+func TestManager_Rebalance_IsolatesHotMarket(t *testing.T) {
+	var log commandLog
+	mgr := startRebalanceManager(t, &log)
+
+	setLoad(mgr.orderbookConn(4), map[string]float64{"MKT-1": 150, "MKT-2": 60, "MKT-3": 40})
+	setLoad(mgr.orderbookConn(5), map[string]float64{"MKT-4": 10})
+
+	mgr.rebalance()
+
+	// MKT-1 fits nowhere under the ceiling, so it gets a new connection;
+	// conn 4 is then at 100 and keeps the rest
+	for ticker, want := range map[string]int{"MKT-1": 6, "MKT-2": 4, "MKT-3": 4, "MKT-4": 5} {
+		if got := mgr.marketToConn[ticker]; got != want {
+			t.Errorf("%s on conn %d, want %d", ticker, got, want)
+		}
+	}
+	if got := mgr.Stats().OrderbookConns; got != 3 {
+		t.Errorf("OrderbookConns = %d, want 3", got)
+	}
+
+	// A connection with a single market is left alone
+	setLoad(mgr.orderbookConn(6), map[string]float64{"MKT-1": 150})
+	mgr.rebalance()
+	if got := mgr.Stats().Migrations; got != 1 {
+		t.Errorf("Migrations = %d, want 1", got)
+	}
+}
+
+func TestManager_Assign_SkipsConnectionAtCeiling(t *testing.T) {
+	var log commandLog
+	mgr := startRebalanceManager(t, &log)
+
+	// Conn 5 has room but is at the ceiling; conn 4 is full
+	setLoad(mgr.orderbookConn(5), map[string]float64{"MKT-4": 100})
+	mgr.subscribeOrderbooks([]string{"MKT-5"})
+
+	if got := mgr.marketToConn["MKT-5"]; got != 6 {
+		t.Errorf("MKT-5 on conn %d, want 6", got)
+	}
+}
@@ -0,0 +1,210 @@
+package connection
+
+import (
+	"sort"
+	"sync"
+)
+
+// subscriptionTable tracks the live subscriptions, their markets and their
+// last sequence numbers, indexed by SID, connection and ticker. A
+// connection's entries are replaced as a whole: resetConn drops all of them
+// when it disconnects, so SIDs the server has forgotten never linger.
+type subscriptionTable struct {
+	mu       sync.Mutex
+	bySID    map[int64]*subEntry
+	byConn   map[int]map[int64]struct{} // Connection ID → SIDs
+	byTicker map[string]int64           // Market ticker → orderbook SID
+}
+
+// subEntry is one subscription in the table.
+type subEntry struct {
+	sub     Subscription
+	markets map[string]struct{}
+	lastSeq int64 // 0 until the first sequenced message
+	dropped int   // Messages dropped since the last one delivered
+}
+
+func newSubscriptionTable() *subscriptionTable {
+	return &subscriptionTable{
+		bySID:    make(map[int64]*subEntry),
+		byConn:   make(map[int]map[int64]struct{}),
+		byTicker: make(map[string]int64),
+	}
+}
+
+// add records a new subscription covering tickers (none for channels that
+// cover all markets).
+func (t *subscriptionTable) add(sub Subscription, tickers []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.removeLocked(sub.SID)
+
+	entry := &subEntry{sub: sub, markets: make(map[string]struct{}, len(tickers))}
+	t.bySID[sub.SID] = entry
+	if t.byConn[sub.ConnID] == nil {
+		t.byConn[sub.ConnID] = make(map[int64]struct{})
+	}
+	t.byConn[sub.ConnID][sub.SID] = struct{}{}
+	t.addMarketsLocked(entry, tickers)
+}
+
+// addMarkets records tickers added to sid. A ticker on another SID (while
+// it is being moved between connections) is indexed to sid from now on.
+func (t *subscriptionTable) addMarkets(sid int64, tickers []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if entry, ok := t.bySID[sid]; ok {
+		t.addMarketsLocked(entry, tickers)
+	}
+}
+
+func (t *subscriptionTable) addMarketsLocked(entry *subEntry, tickers []string) {
+	for _, ticker := range tickers {
+		entry.markets[ticker] = struct{}{}
+		t.byTicker[ticker] = entry.sub.SID
+	}
+}
+
+// removeMarkets records tickers deleted from sid.
+func (t *subscriptionTable) removeMarkets(sid int64, tickers []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.bySID[sid]
+	if !ok {
+		return
+	}
+	for _, ticker := range tickers {
+		delete(entry.markets, ticker)
+		if t.byTicker[ticker] == sid {
+			delete(t.byTicker, ticker)
+		}
+	}
+}
+
+// remove forgets sid, its markets and its sequence number.
+func (t *subscriptionTable) remove(sid int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.removeLocked(sid)
+}
+
+func (t *subscriptionTable) removeLocked(sid int64) {
+	entry, ok := t.bySID[sid]
+	if !ok {
+		return
+	}
+
+	for ticker := range entry.markets {
+		if t.byTicker[ticker] == sid {
+			delete(t.byTicker, ticker)
+		}
+	}
+	delete(t.bySID, sid)
+
+	sids := t.byConn[entry.sub.ConnID]
+	delete(sids, sid)
+	if len(sids) == 0 {
+		delete(t.byConn, entry.sub.ConnID)
+	}
+}
+
+// resetConn forgets every subscription on a connection. Returns how many
+// were dropped.
+func (t *subscriptionTable) resetConn(connID int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sids := t.byConn[connID]
+	n := len(sids)
+	for sid := range sids {
+		t.removeLocked(sid)
+	}
+	return n
+}
+
+// known reports whether sid is a live subscription.
+func (t *subscriptionTable) known(sid int64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.bySID[sid]
+	return ok
+}
+
+// tickerSub returns the orderbook subscription carrying ticker.
+func (t *subscriptionTable) tickerSub(ticker string) (Subscription, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sid, ok := t.byTicker[ticker]
+	if !ok {
+		return Subscription{}, false
+	}
+	return t.bySID[sid].sub, true
+}
+
+// advanceSeq records seq as sid's latest sequence number and returns the
+// previous one (0 for the first message) and how many of sid's messages
+// were dropped since then. Messages for SIDs not in the table (late
+// arrivals for a dropped subscription) are not tracked.
+func (t *subscriptionTable) advanceSeq(sid, seq int64) (last int64, dropped int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.bySID[sid]
+	if !ok {
+		return 0, 0
+	}
+	last, dropped = entry.lastSeq, entry.dropped
+	entry.lastSeq = seq
+	entry.dropped = 0
+	return last, dropped
+}
+
+// markDropped records that n of sid's messages never reached the router,
+// so the next one is reported as a gap.
+func (t *subscriptionTable) markDropped(sid int64, n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if entry, ok := t.bySID[sid]; ok {
+		entry.dropped += n
+	}
+}
+
+// len returns the number of live subscriptions.
+func (t *subscriptionTable) len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.bySID)
+}
+
+// list returns every live subscription ordered by connection and SID, with
+// its markets and last sequence number.
+func (t *subscriptionTable) list() []Subscription {
+	t.mu.Lock()
+	subs := make([]Subscription, 0, len(t.bySID))
+	for _, entry := range t.bySID {
+		sub := entry.sub
+		sub.LastSeq = entry.lastSeq
+		if len(entry.markets) > 0 {
+			sub.Markets = make([]string, 0, len(entry.markets))
+			for ticker := range entry.markets {
+				sub.Markets = append(sub.Markets, ticker)
+			}
+			sort.Strings(sub.Markets)
+		}
+		subs = append(subs, sub)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(subs, func(i, j int) bool {
+		if subs[i].ConnID != subs[j].ConnID {
+			return subs[i].ConnID < subs[j].ConnID
+		}
+		return subs[i].SID < subs[j].SID
+	})
+	return subs
+}
@@ -0,0 +1,107 @@
+package connection
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionTable_TickerIndex(t *testing.T) {
+	table := newSubscriptionTable()
+	table.add(Subscription{SID: 1, Channel: "orderbook_delta", ConnID: 7}, []string{"MKT-A", "MKT-B"})
+	table.add(Subscription{SID: 2, Channel: "orderbook_delta", ConnID: 8}, []string{"MKT-C"})
+
+	// Moving MKT-A: added to SID 2 before it is deleted from SID 1
+	table.addMarkets(2, []string{"MKT-A"})
+	table.removeMarkets(1, []string{"MKT-A"})
+
+	tests := []struct {
+		ticker  string
+		wantSID int64
+		wantOK  bool
+	}{
+		{"MKT-A", 2, true},
+		{"MKT-B", 1, true},
+		{"MKT-C", 2, true},
+		{"MKT-D", 0, false},
+	}
+	for _, tt := range tests {
+		sub, ok := table.tickerSub(tt.ticker)
+		if ok != tt.wantOK || sub.SID != tt.wantSID {
+			t.Errorf("tickerSub(%s) = %d, %v, want %d, %v", tt.ticker, sub.SID, ok, tt.wantSID, tt.wantOK)
+		}
+	}
+
+	table.remove(1)
+	if _, ok := table.tickerSub("MKT-B"); ok {
+		t.Error("tickerSub(MKT-B) found after its SID was removed")
+	}
+}
+
+func TestSubscriptionTable_ResetConn(t *testing.T) {
+	table := newSubscriptionTable()
+	table.add(Subscription{SID: 1, Channel: "ticker", ConnID: 1}, nil)
+	table.add(Subscription{SID: 2, Channel: "orderbook_delta", ConnID: 7}, []string{"MKT-A"})
+	table.add(Subscription{SID: 3, Channel: "orderbook_delta", ConnID: 7}, []string{"MKT-B"})
+	table.advanceSeq(2, 5)
+
+	if n := table.resetConn(7); n != 2 {
+		t.Errorf("resetConn(7) = %d, want 2", n)
+	}
+	if table.known(2) || table.known(3) {
+		t.Error("conn 7 SIDs still known after resetConn")
+	}
+	if _, ok := table.tickerSub("MKT-A"); ok {
+		t.Error("tickerSub(MKT-A) found after resetConn")
+	}
+
+	// Late messages for a dropped SID leave no state behind
+	if last, _ := table.advanceSeq(2, 6); last != 0 {
+		t.Errorf("advanceSeq on dropped SID = %d, want 0", last)
+	}
+
+	want := []Subscription{{SID: 1, Channel: "ticker", ConnID: 1}}
+	if got := table.list(); !reflect.DeepEqual(got, want) {
+		t.Errorf("list() = %+v, want %+v", got, want)
+	}
+}
+
+func TestManager_Reconnect_ReplacesSubscriptions(t *testing.T) {
+	var log commandLog
+	mgr := startRebalanceManager(t, &log)
+
+	before := mgr.Subscriptions()
+	if len(before) != 5 {
+		t.Fatalf("len(Subscriptions) = %d, want 5", len(before))
+	}
+	oldSID := before[3].SID
+	if before[3].ConnID != 4 || !reflect.DeepEqual(before[3].Markets, []string{"MKT-1", "MKT-2", "MKT-3"}) {
+		t.Fatalf("Subscriptions[3] = %+v, want conn 4 with MKT-1 to MKT-3", before[3])
+	}
+
+	// Drop the socket under the client, as a network failure would
+	mgr.orderbookConn(4).client.(*client).conn.Close()
+	log.waitFor(t, 3)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		sub, ok := mgr.subs.tickerSub("MKT-1")
+		if ok && sub.SID != oldSID {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("MKT-1 still on SID %d after reconnect", oldSID)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	after := mgr.Subscriptions()
+	if len(after) != 5 {
+		t.Errorf("len(Subscriptions) = %d, want 5", len(after))
+	}
+	for _, sub := range after {
+		if sub.SID == oldSID {
+			t.Errorf("stale SID %d still tracked", oldSID)
+		}
+	}
+}
@@ -12,6 +12,8 @@ var (
 	ErrStaleConnection = errors.New("connection stale (no ping)")
 	ErrTimeout         = errors.New("operation timeout")
 	ErrAlreadyClosed   = errors.New("already closed")
+	ErrNotSubscribed   = errors.New("market not subscribed")
+	ErrRateLimited     = errors.New("resubscribe rate limited")
 )
 
 // TimestampedMessage wraps raw message data with receive timestamp.
@@ -23,7 +25,7 @@ type TimestampedMessage struct {
 // RawMessage is a message from Connection Manager to Message Router.
 type RawMessage struct {
 	Data       []byte    // Raw message bytes from WebSocket
-	ConnID     int       // Which connection this came from (1-150)
+	ConnID     int       // Which connection this came from
 	ReceivedAt time.Time // Local timestamp when WS Client received message
 	SeqGap     bool      // True if sequence gap detected before this message
 	GapSize    int       // Number of missed messages (0 if no gap)
@@ -125,7 +127,39 @@ type ManagerConfig struct {
 	ReconnectBaseWait time.Duration // Base wait time for reconnection
 	ReconnectMaxWait  time.Duration // Max wait time for reconnection
 	MessageBufferSize int           // Buffer size for output message channel
-	WorkerCount       int           // Number of subscribe workers
+	WorkerCount       int           // Orderbook connections updated concurrently
+
+	// Batching: each orderbook connection holds one orderbook_delta
+	// subscription, and markets are added and removed with
+	// update_subscription in batches.
+	BatchSize   int           // Max tickers per subscribe or update_subscription command
+	BatchWindow time.Duration // How long market changes are collected before being applied
+
+	// Connection pool. Global connections are split evenly between ticker,
+	// trade and lifecycle. Orderbook connections are opened as markets need
+	// them, up to OrderbookConns, and retired when empty.
+	GlobalConns    int // Global connections (at least 3)
+	OrderbookConns int // Max orderbook connections
+	MarketsPerConn int // Markets per orderbook connection before another is opened
+
+	// Load rebalancing: every RebalanceInterval each connection's message
+	// rate is sampled, and the busiest markets on an orderbook connection
+	// above MaxConnRate are moved to connections with headroom.
+	MaxConnRate       int           // Messages per second per orderbook connection (0 = no rebalancing)
+	RebalanceInterval time.Duration // How often rates are sampled and connections rebalanced
+
+	// Gap recovery: an orderbook sequence gap triggers unsubscribe + resubscribe
+	// for a fresh snapshot, rate limited so a flapping connection cannot storm the exchange.
+	GapCooldown        time.Duration // Min time between gap resubscribes of the same market
+	GapMaxResubscribes int           // Max gap resubscribes per minute across all connections (0 = disabled)
+
+	// Overflow: what readLoop does with a data message when the Messages()
+	// channel is full. Dropped orderbook messages mark their SID as gapped,
+	// so the next message delivered for it triggers gap recovery.
+	OverflowPolicy  OverflowPolicy // OverflowBlock, OverflowSpill or OverflowDrop
+	OverflowTimeout time.Duration  // OverflowBlock: how long to wait before dropping
+	SpillDir        string         // OverflowSpill: directory for the spill file
+	SpillMaxBytes   int64          // OverflowSpill: drop once the spill file holds this much
 }
 
 // DefaultManagerConfig returns sensible defaults.
@@ -136,9 +170,35 @@ func DefaultManagerConfig() ManagerConfig {
 		ReconnectMaxWait:  60 * time.Second,
 		MessageBufferSize: 1000000, // 1M central buffer for 300K+ markets
 		WorkerCount:       10,
+		BatchSize:         500,
+		BatchWindow:       100 * time.Millisecond,
+
+		GlobalConns:    6,
+		OrderbookConns: 144,
+		MarketsPerConn: 250,
+
+		MaxConnRate:       1000,
+		RebalanceInterval: 30 * time.Second,
+
+		GapCooldown:        30 * time.Second,
+		GapMaxResubscribes: 60,
+
+		OverflowPolicy:  OverflowBlock,
+		OverflowTimeout: 1 * time.Second,
+		SpillMaxBytes:   1 << 30,
 	}
 }
 
+// OverflowPolicy selects how a data message is handed off when the
+// Messages() channel is full.
+type OverflowPolicy string
+
+const (
+	OverflowBlock OverflowPolicy = "block" // Wait up to OverflowTimeout, then drop
+	OverflowSpill OverflowPolicy = "spill" // Queue to a file in SpillDir, drained in order
+	OverflowDrop  OverflowPolicy = "drop"  // Drop at once
+)
+
 // ConnectionRole identifies the purpose of a connection.
 type ConnectionRole string
 
@@ -149,10 +209,12 @@ const (
 	RoleOrderbook ConnectionRole = "orderbook"
 )
 
-// Subscription tracks an active subscription.
+// Subscription tracks an active subscription. Orderbook subscriptions
+// cover every market on their connection.
 type Subscription struct {
-	SID     int64
-	Channel string
-	ConnID  int
-	Ticker  string // Empty for global subscriptions
+	SID     int64    `json:"sid"`
+	Channel string   `json:"channel"`
+	ConnID  int      `json:"conn_id"`
+	Markets []string `json:"markets,omitempty"`  // Orderbook only, sorted
+	LastSeq int64    `json:"last_seq,omitempty"` // 0 until the first sequenced message
 }
@@ -0,0 +1,10 @@
+// Package migrate applies versioned schema migrations to a gatherer's
+// TimescaleDB.
+//
+// Migrations are numbered SQL file pairs embedded from sql/
+// (0001_initial_schema.up.sql, 0001_initial_schema.down.sql). Applied
+// versions are recorded in schema_migrations, and each migration runs in
+// its own transaction together with that record. Up and Down hold a
+// PostgreSQL advisory lock, so gatherers starting at the same time apply
+// each migration exactly once.
+package migrate
@@ -0,0 +1,372 @@
+package migrate
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockID is the pg_advisory_lock key held while migrating, so gatherers
+// starting together apply each migration once.
+const lockID int64 = 0x6b616c7368690001 // "kalshi" + 0001
+
+const createTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version     INTEGER PRIMARY KEY,
+    name        TEXT NOT NULL,
+    applied_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+)`
+
+var (
+	// ErrUnknownVersion is returned when the database has a migration
+	// applied that this binary does not know, i.e. a newer binary migrated it.
+	ErrUnknownVersion = errors.New("migrate: database schema is newer than this binary")
+
+	// ErrPending is returned by Check when migrations have not been applied.
+	ErrPending = errors.New("migrate: database schema has pending migrations")
+)
+
+// Migration is one numbered schema change.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and whether it is applied.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrations returns the migrations embedded in the binary.
+func Migrations() ([]Migration, error) {
+	sub, err := fs.Sub(files, "sql")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	return Load(sub)
+}
+
+// Load reads NNNN_name.up.sql and NNNN_name.down.sql pairs from the root
+// of fsys. Versions must start at 1 and have no gaps.
+func Load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, name := range names {
+		version, label, direction, err := parseName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: read %s: %w", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		}
+		if m.Name != label {
+			return nil, fmt.Errorf("migrate: version %d has two names: %q and %q", version, m.Name, label)
+		}
+
+		switch direction {
+		case "up":
+			m.Up = string(data)
+		case "down":
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migrate: expected version %d, found %d", i+1, m.Version)
+		}
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migrate: version %d needs both up and down files", m.Version)
+		}
+	}
+
+	return migrations, nil
+}
+
+// parseName splits "0001_initial_schema.up.sql" into 1, "initial_schema", "up".
+func parseName(name string) (version int, label, direction string, err error) {
+	base := strings.TrimSuffix(path.Base(name), ".sql")
+
+	dot := strings.LastIndex(base, ".")
+	if dot < 0 {
+		return 0, "", "", fmt.Errorf("migrate: %s: missing .up or .down", name)
+	}
+	base, direction = base[:dot], base[dot+1:]
+	if direction != "up" && direction != "down" {
+		return 0, "", "", fmt.Errorf("migrate: %s: missing .up or .down", name)
+	}
+
+	num, label, ok := strings.Cut(base, "_")
+	if !ok || label == "" {
+		return 0, "", "", fmt.Errorf("migrate: %s: expected NNNN_name", name)
+	}
+	version, err = strconv.Atoi(num)
+	if err != nil || version < 1 {
+		return 0, "", "", fmt.Errorf("migrate: %s: invalid version %q", name, num)
+	}
+
+	return version, label, direction, nil
+}
+
+// Migrator applies migrations to a database.
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+	logger     *slog.Logger
+}
+
+// New creates a Migrator. Migrations must be ordered and gap-free, as
+// returned by Load.
+func New(pool *pgxpool.Pool, migrations []Migration, logger *slog.Logger) *Migrator {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Migrator{
+		pool:       pool,
+		migrations: migrations,
+		logger:     logger,
+	}
+}
+
+// Latest returns the highest migration version known to this binary.
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// Up applies all pending migrations and returns how many were applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(current, m.Latest()); err != nil && !errors.Is(err, ErrPending) {
+			return err
+		}
+
+		for _, mig := range m.migrations[current:] {
+			if err := m.apply(ctx, conn, mig, true); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the last steps applied migrations and returns how many
+// were rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	rolledBack := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(current, m.Latest()); err != nil && !errors.Is(err, ErrPending) {
+			return err
+		}
+
+		for v := current; v > 0 && rolledBack < steps; v-- {
+			if err := m.apply(ctx, conn, m.migrations[v-1], false); err != nil {
+				return err
+			}
+			rolledBack++
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status lists every known migration and whether it is applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	appliedAt := make(map[int]time.Time)
+
+	exists, err := tableExists(ctx, m.pool)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		rows, err := m.pool.Query(ctx, "SELECT version, applied_at FROM schema_migrations")
+		if err != nil {
+			return nil, fmt.Errorf("migrate: query status: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var v int
+			var at time.Time
+			if err := rows.Scan(&v, &at); err != nil {
+				return nil, fmt.Errorf("migrate: scan status: %w", err)
+			}
+			appliedAt[v] = at
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("migrate: query status: %w", err)
+		}
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		at, ok := appliedAt[mig.Version]
+		statuses = append(statuses, Status{Migration: mig, Applied: ok, AppliedAt: at})
+	}
+	return statuses, nil
+}
+
+// Version returns the database's schema version, 0 if never migrated.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	exists, err := tableExists(ctx, m.pool)
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	return version(ctx, m.pool)
+}
+
+// Check returns nil if the database is at exactly Latest, ErrPending if it
+// is behind, and ErrUnknownVersion if it is ahead. It does not take the
+// migration lock or change the database.
+func (m *Migrator) Check(ctx context.Context) error {
+	current, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	return checkVersion(current, m.Latest())
+}
+
+// checkVersion compares a database version to the latest known version.
+func checkVersion(current, latest int) error {
+	switch {
+	case current > latest:
+		return fmt.Errorf("%w: database at version %d, binary knows up to %d", ErrUnknownVersion, current, latest)
+	case current < latest:
+		return fmt.Errorf("%w: database at version %d, latest is %d", ErrPending, current, latest)
+	}
+	return nil
+}
+
+// withLock runs fn on one connection holding the migration advisory lock,
+// after ensuring schema_migrations exists.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate: acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	m.logger.Debug("waiting for migration lock")
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("migrate: acquire lock: %w", err)
+	}
+	defer func() {
+		// Unlock even if ctx was cancelled; the lock is per session
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(unlockCtx, "SELECT pg_advisory_unlock($1)", lockID); err != nil {
+			m.logger.Error("failed to release migration lock", "error", err)
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, createTable); err != nil {
+		return fmt.Errorf("migrate: create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// apply runs one migration and records it in a single transaction.
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, mig Migration, up bool) error {
+	direction, script := "up", mig.Up
+	if !up {
+		direction, script = "down", mig.Down
+	}
+
+	start := time.Now()
+	err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, script); err != nil {
+			return err
+		}
+		if up {
+			_, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", mig.Version, mig.Name)
+			return err
+		}
+		_, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", mig.Version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migrate: %s %04d_%s: %w", direction, mig.Version, mig.Name, err)
+	}
+
+	m.logger.Info("migration applied",
+		"version", mig.Version,
+		"name", mig.Name,
+		"direction", direction,
+		"duration", time.Since(start),
+	)
+	return nil
+}
+
+// querier is satisfied by *pgxpool.Pool and *pgxpool.Conn.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// version returns the highest applied version.
+func version(ctx context.Context, q querier) (int, error) {
+	var v int
+	if err := q.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&v); err != nil {
+		return 0, fmt.Errorf("migrate: query version: %w", err)
+	}
+	return v, nil
+}
+
+// tableExists reports whether schema_migrations exists.
+func tableExists(ctx context.Context, q querier) (bool, error) {
+	var exists bool
+	if err := q.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return false, fmt.Errorf("migrate: query schema_migrations: %w", err)
+	}
+	return exists, nil
+}
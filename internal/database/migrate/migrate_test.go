@@ -0,0 +1,146 @@
+package migrate
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "ordered pairs",
+			fsys: fstest.MapFS{
+				"0002_add_index.up.sql":   file("CREATE INDEX"),
+				"0002_add_index.down.sql": file("DROP INDEX"),
+				"0001_init.up.sql":        file("CREATE TABLE"),
+				"0001_init.down.sql":      file("DROP TABLE"),
+				"README.md":               file("ignored"),
+			},
+			want: 2,
+		},
+		{
+			name: "empty",
+			fsys: fstest.MapFS{},
+			want: 0,
+		},
+		{
+			name: "missing down",
+			fsys: fstest.MapFS{
+				"0001_init.up.sql": file("CREATE TABLE"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "gap in versions",
+			fsys: fstest.MapFS{
+				"0001_init.up.sql":   file("CREATE TABLE"),
+				"0001_init.down.sql": file("DROP TABLE"),
+				"0003_late.up.sql":   file("CREATE INDEX"),
+				"0003_late.down.sql": file("DROP INDEX"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "mismatched names",
+			fsys: fstest.MapFS{
+				"0001_init.up.sql":    file("CREATE TABLE"),
+				"0001_other.down.sql": file("DROP TABLE"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "no direction",
+			fsys: fstest.MapFS{
+				"0001_init.sql": file("CREATE TABLE"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad version",
+			fsys: fstest.MapFS{
+				"first_init.up.sql":   file("CREATE TABLE"),
+				"first_init.down.sql": file("DROP TABLE"),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.fsys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != tt.want {
+				t.Errorf("len(Load()) = %d, want %d", len(got), tt.want)
+			}
+			for i, m := range got {
+				if m.Version != i+1 {
+					t.Errorf("Load()[%d].Version = %d, want %d", i, m.Version, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestParseName(t *testing.T) {
+	version, label, direction, err := parseName("0012_add_settlements.down.sql")
+	if err != nil {
+		t.Fatalf("parseName() error = %v", err)
+	}
+	if version != 12 || label != "add_settlements" || direction != "down" {
+		t.Errorf("parseName() = %d, %q, %q, want 12, \"add_settlements\", \"down\"", version, label, direction)
+	}
+}
+
+func TestMigrations_Embedded(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations() error = %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Migrations() returned no migrations")
+	}
+	if migrations[0].Name != "initial_schema" {
+		t.Errorf("Migrations()[0].Name = %q, want %q", migrations[0].Name, "initial_schema")
+	}
+
+	m := New(nil, migrations, nil)
+	if m.Latest() != len(migrations) {
+		t.Errorf("Latest() = %d, want %d", m.Latest(), len(migrations))
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int
+		latest  int
+		wantErr error
+	}{
+		{"current", 3, 3, nil},
+		{"behind", 1, 3, ErrPending},
+		{"fresh database", 0, 3, ErrPending},
+		{"newer binary migrated", 4, 3, ErrUnknownVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkVersion(tt.current, tt.latest)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkVersion(%d, %d) = %v, want %v", tt.current, tt.latest, err, tt.wantErr)
+			}
+		})
+	}
+}
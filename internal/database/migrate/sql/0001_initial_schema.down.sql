@@ -0,0 +1,9 @@
+-- Drops the initial gatherer schema and all collected data.
+
+DROP TABLE IF EXISTS sync_cursors;
+DROP TABLE IF EXISTS gap_events;
+DROP TABLE IF EXISTS tickers;
+DROP TABLE IF EXISTS orderbook_snapshots;
+DROP TABLE IF EXISTS orderbook_deltas;
+DROP TABLE IF EXISTS trades;
+DROP FUNCTION IF EXISTS unix_now_microseconds();
@@ -0,0 +1,18 @@
+-- Restore the (exchange_ts, ticker, price, side) key. Deltas with
+-- ordinal > 0 cannot be represented under it and are deleted.
+
+SELECT remove_compression_policy('orderbook_deltas', if_exists => TRUE);
+SELECT decompress_chunk(c, if_compressed => TRUE) FROM show_chunks('orderbook_deltas') c;
+ALTER TABLE orderbook_deltas SET (timescaledb.compress = false);
+
+DELETE FROM orderbook_deltas WHERE ordinal > 0;
+ALTER TABLE orderbook_deltas DROP CONSTRAINT orderbook_deltas_pkey;
+ALTER TABLE orderbook_deltas ADD PRIMARY KEY (exchange_ts, ticker, price, side);
+ALTER TABLE orderbook_deltas DROP COLUMN ordinal;
+
+ALTER TABLE orderbook_deltas SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'ticker',
+    timescaledb.compress_orderby = 'exchange_ts DESC'
+);
+SELECT add_compression_policy('orderbook_deltas', 86400000000::BIGINT, if_not_exists => TRUE);  -- 1 day in µs
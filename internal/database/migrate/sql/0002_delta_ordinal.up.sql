@@ -0,0 +1,29 @@
+-- Key orderbook_deltas on a per-level ordinal instead of the bare level.
+--
+-- exchange_ts has one-second resolution, so the old key
+-- (exchange_ts, ticker, price, side) collapsed every delta after the first
+-- at a price level within the same second. ordinal numbers deltas sharing
+-- (exchange_ts, ticker, side, price) in sequence order, starting at 0.
+-- Gatherers assign the same key to the same delta only in seconds in which
+-- none of them received a snapshot for the ticker, which is what the
+-- deduplicator relies on; in a resubscribe second the ordinals can differ.
+--
+-- Timescale cannot change constraints on a hypertable with compression
+-- enabled, so compressed chunks are decompressed first and compression is
+-- re-enabled afterwards. Existing rows were unique under the old key and
+-- all get ordinal 0.
+
+SELECT remove_compression_policy('orderbook_deltas', if_exists => TRUE);
+SELECT decompress_chunk(c, if_compressed => TRUE) FROM show_chunks('orderbook_deltas') c;
+ALTER TABLE orderbook_deltas SET (timescaledb.compress = false);
+
+ALTER TABLE orderbook_deltas ADD COLUMN ordinal INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE orderbook_deltas DROP CONSTRAINT orderbook_deltas_pkey;
+ALTER TABLE orderbook_deltas ADD PRIMARY KEY (exchange_ts, ticker, price, side, ordinal);
+
+ALTER TABLE orderbook_deltas SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'ticker',
+    timescaledb.compress_orderby = 'exchange_ts DESC'
+);
+SELECT add_compression_policy('orderbook_deltas', 86400000000::BIGINT, if_not_exists => TRUE);  -- 1 day in µs
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS market_status_history;
+DROP TABLE IF EXISTS markets;
+DROP TABLE IF EXISTS events;
+DROP TABLE IF EXISTS series;
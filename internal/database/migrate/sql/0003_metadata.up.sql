@@ -0,0 +1,64 @@
+-- Series, event and market metadata, written by the metadata writer from
+-- the Market Registry so time-series rows can be joined to titles,
+-- categories and settlement results.
+--
+-- Plain tables, not hypertables: one row per ticker, upserted in place.
+-- There are no foreign keys between them because markets are written as
+-- soon as they are seen, before their event and series are fetched.
+
+CREATE TABLE series (
+    ticker              TEXT PRIMARY KEY,
+    title               TEXT,
+    category            TEXT,
+    frequency           TEXT,
+    tags                JSONB,
+    settlement_sources  JSONB,
+    updated_at          BIGINT NOT NULL           -- Last written (µs since epoch)
+);
+
+CREATE INDEX idx_series_category ON series (category);
+
+CREATE TABLE events (
+    event_ticker    TEXT PRIMARY KEY,
+    series_ticker   TEXT,
+    title           TEXT,
+    sub_title       TEXT,
+    category        TEXT,
+    updated_at      BIGINT NOT NULL           -- Last written (µs since epoch)
+);
+
+CREATE INDEX idx_events_series ON events (series_ticker);
+
+CREATE TABLE markets (
+    ticker          TEXT PRIMARY KEY,
+    event_ticker    TEXT,
+    title           TEXT,
+    subtitle        TEXT,
+    market_status   TEXT NOT NULL,             -- Kalshi status as received
+    trading_status  TEXT,
+    market_type     TEXT,                      -- 'binary' or 'scalar'
+    result          TEXT,                      -- 'yes', 'no' once settled
+    volume          BIGINT,
+    volume_24h      BIGINT,
+    open_interest   BIGINT,
+    open_ts         BIGINT,                    -- µs since epoch
+    close_ts        BIGINT,
+    expiration_ts   BIGINT,
+    created_ts      BIGINT,
+    updated_at      BIGINT NOT NULL           -- Last written (µs since epoch)
+);
+
+CREATE INDEX idx_markets_event ON markets (event_ticker);
+CREATE INDEX idx_markets_status ON markets (market_status);
+
+-- One row each time a market's status or result differs from the stored
+-- row, including the first time it is seen (old_status NULL).
+CREATE TABLE market_status_history (
+    ticker          TEXT NOT NULL,
+    changed_at      BIGINT NOT NULL,           -- When the registry observed it (µs since epoch)
+    old_status      TEXT,
+    new_status      TEXT NOT NULL,
+    result          TEXT,
+    source          TEXT NOT NULL,             -- 'sync', 'reconcile' or 'lifecycle'
+    PRIMARY KEY (ticker, changed_at, new_status)
+);
@@ -0,0 +1,20 @@
+-- Final outcome of each settled market, written by the metadata writer when
+-- the registry sees a market_lifecycle "settled" message, and synced to
+-- production by the deduplicator.
+--
+-- One row per ticker. settlement_value and settled_ts stay NULL if the
+-- gatherer could not fetch or did not see them; the deduplicator fills them
+-- from other gatherers. received_at is bumped on every write so the
+-- deduplicator's cursor picks up amendments.
+
+CREATE TABLE market_settlements (
+    ticker              TEXT PRIMARY KEY,
+    result              TEXT NOT NULL,         -- 'yes', 'no' (scalar markets may differ)
+    settlement_value    INTEGER,               -- YES payout, hundred-thousandths (0-100000)
+    settled_ts          BIGINT,                -- From market_lifecycle (µs since epoch)
+    final_volume        BIGINT,
+    final_open_interest BIGINT,
+    received_at         BIGINT NOT NULL        -- Last written by the gatherer (µs since epoch)
+);
+
+CREATE INDEX idx_market_settlements_received ON market_settlements (received_at);
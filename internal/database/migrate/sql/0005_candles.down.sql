@@ -0,0 +1,3 @@
+DROP MATERIALIZED VIEW IF EXISTS trade_candles_1h;
+DROP MATERIALIZED VIEW IF EXISTS trade_candles_1m;
+DROP TABLE IF EXISTS candles;
@@ -0,0 +1,85 @@
+-- OHLC candles, two ways, so they can be compared:
+--
+--   candles            Exchange candlesticks fetched by the candle poller.
+--   trade_candles_1m   Candles computed locally from the trades hypertable
+--   trade_candles_1h   by TimescaleDB continuous aggregates.
+--
+-- Prices are hundred-thousandths (0-100000), timestamps µs since epoch. The
+-- exchange's candles are keyed by the end of their period (close_ts); the
+-- local ones by the start of their bucket (open_ts), so the exchange candle
+-- with close_ts = T matches the local candle with open_ts = T - period.
+
+-- =============================================================================
+-- Exchange Candles
+-- =============================================================================
+CREATE TABLE candles (
+    close_ts        BIGINT NOT NULL,          -- Period end (µs since epoch)
+    ticker          TEXT NOT NULL,
+    period_minutes  INTEGER NOT NULL,         -- 1, 60 or 1440
+    open_ts         BIGINT NOT NULL,          -- Period start (µs since epoch)
+    open            INTEGER NOT NULL,         -- Hundred-thousandths (0-100000)
+    high            INTEGER NOT NULL,
+    low             INTEGER NOT NULL,
+    close           INTEGER NOT NULL,
+    volume          BIGINT NOT NULL,
+    open_interest   BIGINT NOT NULL,
+    received_at     BIGINT NOT NULL,          -- Last written by the gatherer (µs since epoch)
+    PRIMARY KEY (close_ts, ticker, period_minutes)
+);
+
+SELECT create_hypertable('candles', 'close_ts',
+    chunk_time_interval => 604800000000);  -- 7 days in microseconds
+
+CREATE INDEX idx_candles_ticker ON candles (ticker, period_minutes, close_ts DESC);
+CREATE INDEX idx_candles_received ON candles (received_at DESC);
+
+SELECT set_integer_now_func('candles', 'unix_now_microseconds', replace_if_exists => TRUE);
+
+-- =============================================================================
+-- Local Candles From Trades
+-- =============================================================================
+-- Created WITH NO DATA so the migration can run in a transaction; the
+-- refresh policies fill them in. Trades older than a policy's start_offset
+-- (for example from cmd/backfill) need a manual refresh:
+--   CALL refresh_continuous_aggregate('trade_candles_1m', <from_µs>, <to_µs>);
+
+CREATE MATERIALIZED VIEW trade_candles_1m
+WITH (timescaledb.continuous) AS
+SELECT
+    ticker,
+    time_bucket(60000000::BIGINT, exchange_ts) AS open_ts,   -- 1 minute in µs
+    first(price, exchange_ts) AS open,
+    max(price) AS high,
+    min(price) AS low,
+    last(price, exchange_ts) AS close,
+    sum(size)::BIGINT AS volume,
+    count(*) AS trade_count
+FROM trades
+GROUP BY ticker, time_bucket(60000000::BIGINT, exchange_ts)
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW trade_candles_1h
+WITH (timescaledb.continuous) AS
+SELECT
+    ticker,
+    time_bucket(3600000000::BIGINT, exchange_ts) AS open_ts,  -- 1 hour in µs
+    first(price, exchange_ts) AS open,
+    max(price) AS high,
+    min(price) AS low,
+    last(price, exchange_ts) AS close,
+    sum(size)::BIGINT AS volume,
+    count(*) AS trade_count
+FROM trades
+GROUP BY ticker, time_bucket(3600000000::BIGINT, exchange_ts)
+WITH NO DATA;
+
+-- Refresh the last day (1m) and three days (1h), leaving the open bucket
+SELECT add_continuous_aggregate_policy('trade_candles_1m',
+    start_offset => 86400000000::BIGINT,   -- 1 day in µs
+    end_offset => 60000000::BIGINT,        -- 1 minute in µs
+    schedule_interval => INTERVAL '1 minute');
+
+SELECT add_continuous_aggregate_policy('trade_candles_1h',
+    start_offset => 259200000000::BIGINT,  -- 3 days in µs
+    end_offset => 3600000000::BIGINT,      -- 1 hour in µs
+    schedule_interval => INTERVAL '15 minutes');
@@ -0,0 +1,18 @@
+-- Exchange announcements (maintenance notices and the like), written by the
+-- metadata writer when the Market Registry sees a new or changed one, so
+-- gaps and outages in the data can be read against what the exchange said.
+--
+-- Plain table, one row per announcement, upserted in place. Gatherer-local.
+
+CREATE TABLE exchange_announcements (
+    id              TEXT PRIMARY KEY,
+    title           TEXT,
+    message         TEXT,
+    type            TEXT,                     -- 'maintenance', 'market', 'feature', 'general'
+    status          TEXT,
+    created_ts      BIGINT,                   -- µs since epoch
+    delivery_ts     BIGINT,                   -- µs since epoch
+    updated_at      BIGINT NOT NULL           -- Last written (µs since epoch)
+);
+
+CREATE INDEX idx_exchange_announcements_delivery ON exchange_announcements (delivery_ts DESC);
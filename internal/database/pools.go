@@ -9,10 +9,11 @@ import (
 )
 
 // Pools holds database connections for a gatherer.
-// Note: Gatherers only use TimescaleDB for time-series data.
-// Market metadata lives in-memory (Market Registry).
+// Note: Gatherers only use TimescaleDB, for time-series data and the
+// market metadata written from the Market Registry.
 type Pools struct {
-	// Timescale holds trades, orderbook deltas, snapshots (time-series data).
+	// Timescale holds trades, orderbook deltas, snapshots (time-series data)
+	// and the markets, events and series tables.
 	Timescale *pgxpool.Pool
 }
 
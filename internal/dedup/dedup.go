@@ -0,0 +1,401 @@
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Deduplicator polls every gatherer via cursor-based sync and writes the
+// merged, deduplicated rows to production.
+type Deduplicator struct {
+	cfg        Config
+	sources    []Source
+	production *pgxpool.Pool
+	logger     *slog.Logger
+
+	// Cursor state, guarded by mu.
+	// cursors mirrors sync_cursors in production (last committed position).
+	// positions holds the in-memory read position while paging through a
+	// backlog; when absent, reads restart at cursor - Lookback.
+	mu        sync.Mutex
+	cursors   map[cursorKey]int64
+	positions map[cursorKey]readPosition
+	stats     Stats
+
+	// Lifecycle
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a new Deduplicator.
+func New(cfg Config, sources []Source, production *pgxpool.Pool, logger *slog.Logger) *Deduplicator {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	stats := Stats{Sources: make(map[string]SourceStats, len(sources))}
+	for _, src := range sources {
+		stats.Sources[src.ID] = SourceStats{
+			Healthy: true,
+			Cursors: make(map[string]int64, len(tables)),
+		}
+	}
+
+	return &Deduplicator{
+		cfg:        cfg,
+		sources:    sources,
+		production: production,
+		logger:     logger,
+		cursors:    make(map[cursorKey]int64),
+		positions:  make(map[cursorKey]readPosition),
+		stats:      stats,
+	}
+}
+
+// Start loads sync cursors from production and begins syncing.
+func (d *Deduplicator) Start(ctx context.Context) error {
+	d.ctx, d.cancel = context.WithCancel(ctx)
+
+	if err := d.loadCursors(d.ctx); err != nil {
+		d.cancel()
+		return fmt.Errorf("load sync cursors: %w", err)
+	}
+
+	// One sync loop per table so a deep orderbook_deltas backlog
+	// does not hold up trades.
+	for _, t := range tables {
+		d.wg.Add(1)
+		go d.syncLoop(t)
+	}
+
+	d.logger.Info("deduplicator started",
+		"sources", len(d.sources),
+		"tables", len(tables),
+		"batch_size", d.cfg.BatchSize,
+		"poll_interval", d.cfg.PollInterval,
+	)
+
+	return nil
+}
+
+// Stop gracefully shuts down the deduplicator.
+func (d *Deduplicator) Stop(ctx context.Context) error {
+	d.logger.Info("stopping deduplicator")
+
+	if d.cancel != nil {
+		d.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.logger.Info("deduplicator stopped")
+		return nil
+	case <-ctx.Done():
+		d.logger.Warn("deduplicator stop timed out")
+		return ctx.Err()
+	}
+}
+
+// Stats returns current statistics.
+func (d *Deduplicator) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := d.stats
+	stats.Sources = make(map[string]SourceStats, len(d.stats.Sources))
+	for id, s := range d.stats.Sources {
+		cursors := make(map[string]int64, len(s.Cursors))
+		for table, ts := range s.Cursors {
+			cursors[table] = ts
+		}
+		s.Cursors = cursors
+		stats.Sources[id] = s
+	}
+	return stats
+}
+
+// loadCursors reads all sync cursors for the configured sources.
+func (d *Deduplicator) loadCursors(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.QueryTimeout)
+	defer cancel()
+
+	rows, err := d.production.Query(ctx, `SELECT gatherer_id, table_name, last_sync_ts FROM sync_cursors`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for rows.Next() {
+		var k cursorKey
+		var ts int64
+		if err := rows.Scan(&k.gathererID, &k.table, &ts); err != nil {
+			return err
+		}
+		d.cursors[k] = ts
+		if s, ok := d.stats.Sources[k.gathererID]; ok {
+			s.Cursors[k.table] = ts
+		}
+	}
+
+	return rows.Err()
+}
+
+// syncLoop runs sync cycles for one table until shutdown.
+// While any source has a backlog, cycles run back to back (catch-up mode).
+func (d *Deduplicator) syncLoop(t tableSpec) {
+	defer d.wg.Done()
+
+	for {
+		more, err := d.syncTable(t)
+		if err != nil {
+			d.logger.Error("sync cycle failed", "table", t.name, "error", err)
+			d.mu.Lock()
+			d.stats.Errors++
+			d.mu.Unlock()
+		}
+
+		wait := d.cfg.PollInterval
+		if more && err == nil {
+			wait = 0
+		}
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// syncTable runs a single sync cycle for one table: read a batch from each
+// source, merge by key, then insert rows and advance cursors in one
+// production transaction. Returns true if any source has more rows pending.
+func (d *Deduplicator) syncTable(t tableSpec) (more bool, err error) {
+	batches := make([][]row, len(d.sources))
+	errs := make([]error, len(d.sources))
+
+	var wg sync.WaitGroup
+	for i, src := range d.sources {
+		from := d.readFrom(cursorKey{gathererID: src.ID, table: t.name})
+
+		wg.Add(1)
+		go func(i int, src Source, from readPosition) {
+			defer wg.Done()
+			batches[i], errs[i] = d.fetch(src, t, from)
+		}(i, src, from)
+	}
+	wg.Wait()
+
+	// Unreachable sources are skipped; the others carry the same data.
+	advanced := make(map[cursorKey]int64)
+	var read int
+	for i, src := range d.sources {
+		d.recordSourceResult(src.ID, len(batches[i]), errs[i])
+		if errs[i] != nil {
+			d.logger.Warn("gatherer read failed",
+				"gatherer", src.ID,
+				"table", t.name,
+				"error", errs[i],
+			)
+			continue
+		}
+
+		read += len(batches[i])
+		if n := len(batches[i]); n > 0 {
+			k := cursorKey{gathererID: src.ID, table: t.name}
+			advanced[k] = batches[i][n-1].cursor
+		}
+	}
+
+	merged, duplicates := mergeBatches(t, batches)
+
+	conflicts := 0
+	if len(merged) > 0 || len(advanced) > 0 {
+		conflicts, err = d.write(t, merged, advanced)
+		if err != nil {
+			// Cursors are untouched, so the next cycle retries the same rows.
+			return false, fmt.Errorf("write %s: %w", t.name, err)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, src := range d.sources {
+		if errs[i] != nil {
+			continue
+		}
+		k := cursorKey{gathererID: src.ID, table: t.name}
+
+		if ts, ok := advanced[k]; ok && ts > d.cursors[k] {
+			d.cursors[k] = ts
+			d.stats.Sources[src.ID].Cursors[t.name] = ts
+		}
+
+		if n := len(batches[i]); n >= d.cfg.BatchSize {
+			last := batches[i][n-1]
+			d.positions[k] = readPosition{cursor: last.cursor, key: t.pageKey(last.values)}
+			more = true
+		} else {
+			delete(d.positions, k)
+		}
+	}
+
+	d.stats.Cycles++
+	d.stats.RowsRead += int64(read)
+	d.stats.RowsWritten += int64(len(merged) - conflicts)
+	d.stats.Duplicates += int64(duplicates)
+	d.stats.Conflicts += int64(conflicts)
+
+	if read > 0 {
+		d.logger.Debug("sync cycle complete",
+			"table", t.name,
+			"read", read,
+			"written", len(merged)-conflicts,
+			"duplicates", duplicates,
+			"conflicts", conflicts,
+		)
+	}
+
+	return more, nil
+}
+
+// readFrom returns the position to read from for a source and table.
+// Must not be called with mu held.
+func (d *Deduplicator) readFrom(k cursorKey) readPosition {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if pos, ok := d.positions[k]; ok {
+		return pos
+	}
+
+	from := d.cursors[k] - d.cfg.Lookback.Microseconds()
+	if from < 0 {
+		from = 0
+	}
+	return readPosition{cursor: from}
+}
+
+// recordSourceResult updates per-source health and counters.
+func (d *Deduplicator) recordSourceResult(id string, rows int, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := d.stats.Sources[id]
+	if err != nil {
+		s.Healthy = false
+		s.LastError = err.Error()
+	} else {
+		s.Healthy = true
+		s.LastError = ""
+		s.RowsRead += int64(rows)
+	}
+	d.stats.Sources[id] = s
+}
+
+// fetch reads up to BatchSize rows from a read position, ordered by the
+// cursor column and then the dedup key.
+func (d *Deduplicator) fetch(src Source, t tableSpec, from readPosition) ([]row, error) {
+	ctx, cancel := context.WithTimeout(d.ctx, d.cfg.QueryTimeout)
+	defer cancel()
+
+	query, args := t.readQuery(from, d.cfg.BatchSize)
+	rows, err := src.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cursorIdx := t.columnIndex(t.cursorCol)
+	result := make([]row, 0, d.cfg.BatchSize)
+
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, err
+		}
+		ts, ok := values[cursorIdx].(int64)
+		if !ok {
+			return nil, fmt.Errorf("%s.%s: unexpected type %T", t.name, t.cursorCol, values[cursorIdx])
+		}
+		result = append(result, row{values: values, cursor: ts})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// write inserts merged rows and advances cursors atomically.
+// Returns the number of rows that already existed in production.
+func (d *Deduplicator) write(t tableSpec, rows []row, advanced map[cursorKey]int64) (conflicts int, err error) {
+	ctx, cancel := context.WithTimeout(d.ctx, d.cfg.QueryTimeout)
+	defer cancel()
+
+	tx, err := d.production.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	insertSQL := t.insertSQL()
+	for _, r := range rows {
+		batch.Queue(insertSQL, r.values...)
+	}
+	for k, ts := range advanced {
+		batch.Queue(`
+			INSERT INTO sync_cursors (gatherer_id, table_name, last_sync_ts, last_sync_at)
+			VALUES ($1, $2, $3, NOW())
+			ON CONFLICT (gatherer_id, table_name) DO UPDATE SET
+				last_sync_ts = GREATEST(sync_cursors.last_sync_ts, EXCLUDED.last_sync_ts),
+				last_sync_at = NOW()
+		`, k.gathererID, k.table, ts)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for range rows {
+		ct, err := results.Exec()
+		if err != nil {
+			results.Close()
+			return 0, err
+		}
+		if ct.RowsAffected() == 0 {
+			conflicts++
+		}
+	}
+	for range advanced {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return 0, fmt.Errorf("update cursor: %w", err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+
+	return conflicts, nil
+}
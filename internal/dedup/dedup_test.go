@@ -0,0 +1,283 @@
+package dedup
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if cfg.PollInterval != time.Second {
+		t.Errorf("PollInterval = %v, want 1s", cfg.PollInterval)
+	}
+	if cfg.BatchSize != 5000 {
+		t.Errorf("BatchSize = %d, want 5000", cfg.BatchSize)
+	}
+	if cfg.Lookback != 10*time.Second {
+		t.Errorf("Lookback = %v, want 10s", cfg.Lookback)
+	}
+	if cfg.QueryTimeout != 30*time.Second {
+		t.Errorf("QueryTimeout = %v, want 30s", cfg.QueryTimeout)
+	}
+}
+
+func TestTables_KeysAndCursorAreColumns(t *testing.T) {
+	for _, tbl := range tables {
+		t.Run(tbl.name, func(t *testing.T) {
+			if tbl.columnIndex(tbl.cursorCol) < 0 {
+				t.Errorf("cursor column %q not in columns", tbl.cursorCol)
+			}
+			for _, k := range tbl.keyCols {
+				if tbl.columnIndex(k) < 0 {
+					t.Errorf("key column %q not in columns", k)
+				}
+				if k == "seq" || k == "sid" {
+					t.Errorf("key column %q is per-subscription and must not be a dedup key", k)
+				}
+			}
+		})
+	}
+}
+
+func TestTableSpec_SelectSQL(t *testing.T) {
+	tbl := tableSpec{
+		name:      "trades",
+		cursorCol: "received_at",
+		columns:   []string{"trade_id", "received_at"},
+		keyCols:   []string{"trade_id"},
+	}
+
+	want := "SELECT trade_id, received_at FROM trades WHERE received_at >= $1 ORDER BY received_at, trade_id LIMIT $2"
+	if got := tbl.selectSQL(); got != want {
+		t.Errorf("selectSQL() = %q, want %q", got, want)
+	}
+}
+
+func TestTableSpec_ReadQuery(t *testing.T) {
+	tbl := tableSpec{
+		name:      "orderbook_snapshots",
+		cursorCol: "snapshot_ts",
+		columns:   []string{"snapshot_ts", "ticker", "source", "yes_bids"},
+		keyCols:   []string{"ticker", "snapshot_ts", "source"},
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		query, args := tbl.readQuery(readPosition{cursor: 100}, 50)
+		want := "SELECT snapshot_ts, ticker, source, yes_bids FROM orderbook_snapshots WHERE snapshot_ts >= $1 ORDER BY snapshot_ts, ticker, source LIMIT $2"
+		if query != want {
+			t.Errorf("query = %q, want %q", query, want)
+		}
+		if len(args) != 2 || args[0] != int64(100) || args[1] != 50 {
+			t.Errorf("args = %v, want [100 50]", args)
+		}
+	})
+
+	// A full batch ending mid-way through rows tied on snapshot_ts resumes
+	// after the last row's key, not after the whole timestamp
+	t.Run("next page", func(t *testing.T) {
+		last := []any{int64(100), "MKT-A", "rest", "[]"}
+		pos := readPosition{cursor: 100, key: tbl.pageKey(last)}
+		query, args := tbl.readQuery(pos, 50)
+		want := "SELECT snapshot_ts, ticker, source, yes_bids FROM orderbook_snapshots WHERE (snapshot_ts, ticker, source) > ($1, $2, $3) ORDER BY snapshot_ts, ticker, source LIMIT $4"
+		if query != want {
+			t.Errorf("query = %q, want %q", query, want)
+		}
+		if len(args) != 4 || args[0] != int64(100) || args[1] != "MKT-A" || args[2] != "rest" || args[3] != 50 {
+			t.Errorf("args = %v, want [100 MKT-A rest 50]", args)
+		}
+	})
+}
+
+func TestTableSpec_InsertSQL(t *testing.T) {
+	tbl := tableSpec{
+		name:      "tickers",
+		cursorCol: "received_at",
+		columns:   []string{"exchange_ts", "received_at", "ticker"},
+		keyCols:   []string{"ticker", "exchange_ts"},
+	}
+
+	want := "INSERT INTO tickers (exchange_ts, received_at, ticker) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING"
+	if got := tbl.insertSQL(); got != want {
+		t.Errorf("insertSQL() = %q, want %q", got, want)
+	}
+
+	for _, tbl := range tables {
+		sql := tbl.insertSQL()
+		if !strings.Contains(sql, "$"+strconv.Itoa(len(tbl.columns))+")") {
+			t.Errorf("%s: insertSQL() missing last placeholder: %q", tbl.name, sql)
+		}
+	}
+}
+
+func TestTableSpec_Key(t *testing.T) {
+	deltas := tables[1]
+	if deltas.name != "orderbook_deltas" {
+		t.Fatalf("tables[1] = %s, want orderbook_deltas", deltas.name)
+	}
+
+	// exchange_ts, received_at, ticker, side, price, size_delta, seq, ordinal, sid
+	a := []any{int64(100), int64(150), "MKT", true, int32(52000), int32(10), int64(7), int32(0), int64(1)}
+	b := []any{int64(100), int64(180), "MKT", true, int32(52000), int32(10), int64(42), int32(0), int64(9)}
+	c := []any{int64(100), int64(150), "MKT", false, int32(52000), int32(10), int64(7), int32(0), int64(1)}
+	d := []any{int64(100), int64(150), "MKT", true, int32(52000), int32(10), int64(8), int32(1), int64(1)}
+
+	if deltas.key(a) != deltas.key(b) {
+		t.Errorf("rows differing only in received_at/seq/sid should share a key: %q vs %q", deltas.key(a), deltas.key(b))
+	}
+	if deltas.key(a) == deltas.key(c) {
+		t.Errorf("rows on different sides should not share a key: %q", deltas.key(a))
+	}
+	if deltas.key(a) == deltas.key(d) {
+		t.Errorf("deltas at the same level and second with different ordinals should not share a key: %q", deltas.key(a))
+	}
+}
+
+func TestMergeBatches(t *testing.T) {
+	trades := tables[0]
+
+	mk := func(id string, receivedAt int64) row {
+		return row{
+			values: []any{id, int64(1000), receivedAt, "MKT", int32(52000), int32(1), true, int64(1)},
+			cursor: receivedAt,
+		}
+	}
+
+	batches := [][]row{
+		{mk("t1", 10), mk("t2", 11)},
+		{mk("t1", 12), mk("t3", 13)},
+		{mk("t2", 14), mk("t3", 15), mk("t4", 16)},
+		nil, // failed source
+	}
+
+	merged, dups := mergeBatches(trades, batches)
+
+	if len(merged) != 4 {
+		t.Errorf("len(merged) = %d, want 4", len(merged))
+	}
+	if dups != 3 {
+		t.Errorf("duplicates = %d, want 3", dups)
+	}
+
+	// First occurrence wins.
+	if merged[0].cursor != 10 {
+		t.Errorf("merged[0].cursor = %d, want 10 (first occurrence)", merged[0].cursor)
+	}
+
+	ids := make([]string, len(merged))
+	for i, r := range merged {
+		ids[i] = r.values[0].(string)
+	}
+	if got := strings.Join(ids, ","); got != "t1,t2,t3,t4" {
+		t.Errorf("merged ids = %s, want t1,t2,t3,t4", got)
+	}
+}
+
+func TestMergeBatches_OnConflictKeepsAll(t *testing.T) {
+	var settlements tableSpec
+	for _, tbl := range tables {
+		if tbl.name == "market_settlements" {
+			settlements = tbl
+		}
+	}
+	if settlements.onConflict == "" {
+		t.Fatal("market_settlements has no onConflict merge")
+	}
+
+	// ticker, result, settlement_value, settled_ts, final_volume, final_open_interest, received_at
+	mk := func(value any, receivedAt int64) row {
+		return row{
+			values: []any{"MKT", "yes", value, int64(1705328200000000), int64(5000), int64(1200), receivedAt},
+			cursor: receivedAt,
+		}
+	}
+
+	// The first gatherer could not fetch the settlement value; production
+	// must still see the second gatherer's row.
+	merged, dups := mergeBatches(settlements, [][]row{
+		{mk(nil, 10)},
+		{mk(int32(100000), 12)},
+	})
+
+	if len(merged) != 2 || dups != 0 {
+		t.Errorf("mergeBatches() = %d rows, %d dups; want 2, 0", len(merged), dups)
+	}
+
+	sql := settlements.insertSQL()
+	if !strings.HasSuffix(sql, settlementConflict) || strings.Contains(sql, "DO NOTHING") {
+		t.Errorf("insertSQL() = %q, want settlement merge", sql)
+	}
+}
+
+func TestMergeBatches_Empty(t *testing.T) {
+	merged, dups := mergeBatches(tables[0], [][]row{nil, {}})
+	if len(merged) != 0 || dups != 0 {
+		t.Errorf("mergeBatches(empty) = %d rows, %d dups; want 0, 0", len(merged), dups)
+	}
+}
+
+func TestDeduplicator_ReadFrom(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Lookback = 5 * time.Second
+	d := New(cfg, []Source{{ID: "gatherer-1"}}, nil, nil)
+
+	k := cursorKey{gathererID: "gatherer-1", table: "trades"}
+
+	t.Run("no cursor starts at zero", func(t *testing.T) {
+		if got := d.readFrom(k); got.cursor != 0 || got.key != nil {
+			t.Errorf("readFrom() = %+v, want cursor 0 without key", got)
+		}
+	})
+
+	t.Run("cursor minus lookback", func(t *testing.T) {
+		d.cursors[k] = 60_000_000
+		if got := d.readFrom(k); got.cursor != 55_000_000 || got.key != nil {
+			t.Errorf("readFrom() = %+v, want cursor 55000000 without key", got)
+		}
+	})
+
+	t.Run("lookback clamps at zero", func(t *testing.T) {
+		d.cursors[k] = 1_000_000
+		if got := d.readFrom(k); got.cursor != 0 {
+			t.Errorf("readFrom() = %+v, want cursor 0", got)
+		}
+	})
+
+	t.Run("paging position wins", func(t *testing.T) {
+		d.cursors[k] = 60_000_000
+		d.positions[k] = readPosition{cursor: 61_000_000, key: []any{"trade-9"}}
+		got := d.readFrom(k)
+		if got.cursor != 61_000_000 || len(got.key) != 1 || got.key[0] != "trade-9" {
+			t.Errorf("readFrom() = %+v, want cursor 61000000 after trade-9", got)
+		}
+	})
+}
+
+func TestDeduplicator_Stats(t *testing.T) {
+	d := New(DefaultConfig(), []Source{{ID: "gatherer-1"}, {ID: "gatherer-2"}}, nil, nil)
+
+	d.recordSourceResult("gatherer-1", 10, nil)
+	d.recordSourceResult("gatherer-2", 0, errors.New("connection refused"))
+	d.stats.Sources["gatherer-1"].Cursors["trades"] = 42
+
+	stats := d.Stats()
+
+	if len(stats.Sources) != 2 {
+		t.Fatalf("len(Sources) = %d, want 2", len(stats.Sources))
+	}
+	if s := stats.Sources["gatherer-1"]; !s.Healthy || s.RowsRead != 10 {
+		t.Errorf("gatherer-1 = %+v, want healthy with 10 rows", s)
+	}
+	if s := stats.Sources["gatherer-2"]; s.Healthy || s.LastError != "connection refused" {
+		t.Errorf("gatherer-2 = %+v, want unhealthy with error", s)
+	}
+
+	// Returned cursors are a copy.
+	stats.Sources["gatherer-1"].Cursors["trades"] = 0
+	if got := d.Stats().Sources["gatherer-1"].Cursors["trades"]; got != 42 {
+		t.Errorf("Cursors[trades] = %d after mutating copy, want 42", got)
+	}
+}
@@ -4,9 +4,11 @@
 //   - Polls all 3 gatherers via cursor-based sync
 //   - Deduplicates records using composite keys:
 //   - trades: trade_id (UUID from Kalshi)
-//   - orderbook_deltas: (ticker, exchange_ts, price, side)
+//   - orderbook_deltas: (ticker, exchange_ts, price, side, ordinal)
 //   - orderbook_snapshots: (ticker, snapshot_ts, source)
 //   - tickers: (ticker, exchange_ts)
+//   - Merges market_settlements rows from every gatherer, so a settlement
+//     value or timestamp one gatherer missed is filled from another
 //   - Writes deduplicated data to production RDS
 //   - Optionally exports to S3 for archival
 package dedup
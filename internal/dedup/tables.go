@@ -0,0 +1,222 @@
+package dedup
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tableSpec describes how a time-series table is polled and deduplicated.
+type tableSpec struct {
+	name      string
+	cursorCol string   // Column polled by the sync cursor (µs since epoch)
+	columns   []string // Columns copied from gatherer to production
+	keyCols   []string // Composite dedup key (exchange-provided identifiers)
+
+	// onConflict, if set, replaces ON CONFLICT DO NOTHING for tables whose
+	// rows change after they are first written. Every source's row is then
+	// written and production merges them, instead of keeping the first.
+	onConflict string
+}
+
+// tables lists the tables synced from every gatherer.
+// seq and sid are copied but never part of a key: they are per-subscription
+// and differ across gatherers. A delta's ordinal is derived from seq order
+// without sid, so it is the same on every gatherer outside the exchange
+// second in which one of them received a snapshot.
+var tables = []tableSpec{
+	{
+		name:      "trades",
+		cursorCol: "received_at",
+		columns:   []string{"trade_id", "exchange_ts", "received_at", "ticker", "price", "size", "taker_side", "sid"},
+		keyCols:   []string{"trade_id"},
+	},
+	{
+		name:      "orderbook_deltas",
+		cursorCol: "received_at",
+		columns:   []string{"exchange_ts", "received_at", "ticker", "side", "price", "size_delta", "seq", "ordinal", "sid"},
+		keyCols:   []string{"ticker", "exchange_ts", "price", "side", "ordinal"},
+	},
+	{
+		name:      "orderbook_snapshots",
+		cursorCol: "snapshot_ts",
+		columns:   []string{"snapshot_ts", "exchange_ts", "ticker", "source", "yes_bids", "yes_asks", "no_bids", "no_asks", "best_yes_bid", "best_yes_ask", "spread", "sid"},
+		keyCols:   []string{"ticker", "snapshot_ts", "source"},
+	},
+	{
+		name:      "tickers",
+		cursorCol: "received_at",
+		columns:   []string{"exchange_ts", "received_at", "ticker", "yes_bid", "yes_ask", "last_price", "volume", "open_interest", "dollar_volume", "dollar_open_interest", "sid"},
+		keyCols:   []string{"ticker", "exchange_ts"},
+	},
+	{
+		name:       "market_settlements",
+		cursorCol:  "received_at",
+		columns:    []string{"ticker", "result", "settlement_value", "settled_ts", "final_volume", "final_open_interest", "received_at"},
+		keyCols:    []string{"ticker"},
+		onConflict: settlementConflict,
+	},
+}
+
+// settlementConflict merges a gatherer's settlement into production. The
+// most recently written row supplies result and final volume, so amended
+// settlements win; settlement_value and settled_ts are taken from whichever
+// gatherer knew them. Rows that add nothing count as conflicts.
+const settlementConflict = `ON CONFLICT (ticker) DO UPDATE SET
+	result = CASE WHEN EXCLUDED.received_at > market_settlements.received_at
+		THEN EXCLUDED.result ELSE market_settlements.result END,
+	settlement_value = CASE WHEN EXCLUDED.received_at > market_settlements.received_at
+		THEN COALESCE(EXCLUDED.settlement_value, market_settlements.settlement_value)
+		ELSE COALESCE(market_settlements.settlement_value, EXCLUDED.settlement_value) END,
+	settled_ts = COALESCE(market_settlements.settled_ts, EXCLUDED.settled_ts),
+	final_volume = CASE WHEN EXCLUDED.received_at > market_settlements.received_at
+		THEN EXCLUDED.final_volume ELSE market_settlements.final_volume END,
+	final_open_interest = CASE WHEN EXCLUDED.received_at > market_settlements.received_at
+		THEN EXCLUDED.final_open_interest ELSE market_settlements.final_open_interest END,
+	received_at = GREATEST(EXCLUDED.received_at, market_settlements.received_at)
+WHERE EXCLUDED.received_at > market_settlements.received_at
+	OR (market_settlements.settlement_value IS NULL AND EXCLUDED.settlement_value IS NOT NULL)
+	OR (market_settlements.settled_ts IS NULL AND EXCLUDED.settled_ts IS NOT NULL)`
+
+// pageCols returns the columns that order rows sharing a cursor value: the
+// dedup key, which is unique within a gatherer, less the cursor column.
+func (t tableSpec) pageCols() []string {
+	cols := make([]string, 0, len(t.keyCols))
+	for _, col := range t.keyCols {
+		if col != t.cursorCol {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// selectSQL returns the poll query for a first page, which includes rows
+// at the cursor position itself. $1 = cursor position, $2 = limit.
+func (t tableSpec) selectSQL() string {
+	return fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s >= $1 ORDER BY %s LIMIT $2",
+		strings.Join(t.columns, ", "), t.name, t.cursorCol, t.orderBy(),
+	)
+}
+
+// pageSQL returns the poll query for the page after a row. It compares the
+// (cursor, pageCols) tuple, so rows tied with that row on the cursor are
+// not skipped when a batch fills. $1 = the row's cursor, $2.. = its
+// pageCols values, then the limit.
+func (t tableSpec) pageSQL() string {
+	cols := append([]string{t.cursorCol}, t.pageCols()...)
+	placeholders := make([]string, len(cols))
+	for i := range cols {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	return fmt.Sprintf(
+		"SELECT %s FROM %s WHERE (%s) > (%s) ORDER BY %s LIMIT $%d",
+		strings.Join(t.columns, ", "), t.name,
+		strings.Join(cols, ", "), strings.Join(placeholders, ", "),
+		t.orderBy(), len(cols)+1,
+	)
+}
+
+// orderBy returns the ORDER BY list shared by selectSQL and pageSQL.
+func (t tableSpec) orderBy() string {
+	return strings.Join(append([]string{t.cursorCol}, t.pageCols()...), ", ")
+}
+
+// readQuery returns the poll query and its arguments for a read position.
+func (t tableSpec) readQuery(pos readPosition, limit int) (string, []any) {
+	if pos.key == nil {
+		return t.selectSQL(), []any{pos.cursor, limit}
+	}
+	args := make([]any, 0, len(pos.key)+2)
+	args = append(args, pos.cursor)
+	args = append(args, pos.key...)
+	return t.pageSQL(), append(args, limit)
+}
+
+// pageKey returns a row's pageCols values, where the next page starts.
+func (t tableSpec) pageKey(values []any) []any {
+	cols := t.pageCols()
+	key := make([]any, len(cols))
+	for i, col := range cols {
+		key[i] = values[t.columnIndex(col)]
+	}
+	return key
+}
+
+// insertSQL returns the production insert, with ON CONFLICT DO NOTHING
+// unless the table sets onConflict.
+func (t tableSpec) insertSQL() string {
+	placeholders := make([]string, len(t.columns))
+	for i := range t.columns {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	conflict := "ON CONFLICT DO NOTHING"
+	if t.onConflict != "" {
+		conflict = t.onConflict
+	}
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s) %s",
+		t.name, strings.Join(t.columns, ", "), strings.Join(placeholders, ", "), conflict,
+	)
+}
+
+// columnIndex returns the position of col in columns, or -1.
+func (t tableSpec) columnIndex(col string) int {
+	for i, c := range t.columns {
+		if c == col {
+			return i
+		}
+	}
+	return -1
+}
+
+// key builds the dedup key for a row's values.
+func (t tableSpec) key(values []any) string {
+	var b strings.Builder
+	for i, col := range t.keyCols {
+		if i > 0 {
+			b.WriteByte('|')
+		}
+		fmt.Fprint(&b, values[t.columnIndex(col)])
+	}
+	return b.String()
+}
+
+// row is a single record read from a gatherer.
+type row struct {
+	values []any
+	cursor int64 // Value of the table's cursor column
+}
+
+// mergeBatches combines batches from all sources, keeping the first
+// occurrence of each key. Returns the unique rows and the number of
+// duplicates dropped. Tables with onConflict keep every row.
+func mergeBatches(t tableSpec, batches [][]row) (merged []row, duplicates int) {
+	total := 0
+	for _, b := range batches {
+		total += len(b)
+	}
+
+	if t.onConflict != "" {
+		merged = make([]row, 0, total)
+		for _, b := range batches {
+			merged = append(merged, b...)
+		}
+		return merged, 0
+	}
+
+	seen := make(map[string]struct{}, total)
+	merged = make([]row, 0, total)
+	for _, b := range batches {
+		for _, r := range b {
+			k := t.key(r.values)
+			if _, ok := seen[k]; ok {
+				duplicates++
+				continue
+			}
+			seen[k] = struct{}{}
+			merged = append(merged, r)
+		}
+	}
+
+	return merged, duplicates
+}
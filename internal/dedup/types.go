@@ -0,0 +1,73 @@
+package dedup
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Config holds Deduplicator configuration.
+type Config struct {
+	// PollInterval is the wait between sync cycles once all sources are caught up.
+	PollInterval time.Duration
+
+	// BatchSize is the max rows read from each source per table per cycle.
+	BatchSize int
+
+	// Lookback is how far behind the cursor each caught-up cycle re-scans.
+	// Gatherer writers flush in batches, so rows can commit with a
+	// received_at older than rows already synced.
+	Lookback time.Duration
+
+	// QueryTimeout bounds each source read and production write.
+	QueryTimeout time.Duration
+}
+
+// DefaultConfig returns sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: 1 * time.Second,
+		BatchSize:    5000,
+		Lookback:     10 * time.Second,
+		QueryTimeout: 30 * time.Second,
+	}
+}
+
+// Source is a gatherer TimescaleDB to sync from.
+type Source struct {
+	ID string // Gatherer ID, stored as sync_cursors.gatherer_id
+	DB *pgxpool.Pool
+}
+
+// Stats contains runtime statistics.
+type Stats struct {
+	Cycles      int64
+	RowsRead    int64 // Rows read across all sources
+	RowsWritten int64 // Rows newly inserted into production
+	Duplicates  int64 // Rows dropped because another source returned the same key
+	Conflicts   int64 // Rows already in production (includes Lookback re-reads)
+	Errors      int64
+	Sources     map[string]SourceStats
+}
+
+// SourceStats contains per-gatherer statistics.
+type SourceStats struct {
+	Healthy   bool
+	LastError string
+	RowsRead  int64
+	Cursors   map[string]int64 // table name → last_sync_ts (µs)
+}
+
+// readPosition is where the next read of a source's table starts. Without a
+// key it starts at cursor; with one, it starts after the row whose
+// (cursor, pageCols) tuple it is.
+type readPosition struct {
+	cursor int64
+	key    []any
+}
+
+// cursorKey identifies a sync cursor row.
+type cursorKey struct {
+	gathererID string
+	table      string
+}
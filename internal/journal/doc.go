@@ -0,0 +1,11 @@
+// Package journal records raw WebSocket messages to local disk.
+//
+// The Journal:
+//   - Sits between the Connection Manager and the Message Router
+//   - Appends every connection.RawMessage to gzip-compressed segment files
+//   - Rotates segments by size and age, optionally deleting old ones
+//   - Never blocks or drops messages on its own errors
+//
+// Reader reads segments back, so history can be reprocessed after a parser
+// fix or an upstream format change.
+package journal
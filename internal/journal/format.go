@@ -0,0 +1,102 @@
+package journal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/connection"
+)
+
+// Segment files are gzip streams of a magic header followed by records.
+//
+// Record layout (big-endian):
+//
+//	uint32  len(Data)
+//	int64   ReceivedAt (Unix nanoseconds)
+//	uint16  ConnID
+//	uint8   flags (bit 0: SeqGap)
+//	uint32  GapSize
+//	[]byte  Data
+const (
+	magic        = "KDJ1"
+	headerSize   = 4 + 8 + 2 + 1 + 4
+	flagSeqGap   = 1 << 0
+	maxDataSize  = 16 << 20 // Larger lengths mean a corrupt record
+	segmentExt   = ".jrnl.gz"
+	segmentTime  = "20060102T150405.000000000Z"
+	segmentGlob  = "*" + segmentExt
+	segmentDelim = "-"
+)
+
+// ErrCorrupt is returned for records that cannot be decoded.
+var ErrCorrupt = errors.New("journal: corrupt record")
+
+// encodeRecord appends the encoded form of msg to buf.
+func encodeRecord(buf []byte, msg connection.RawMessage) []byte {
+	var hdr [headerSize]byte
+	binary.BigEndian.PutUint32(hdr[0:4], uint32(len(msg.Data)))
+	binary.BigEndian.PutUint64(hdr[4:12], uint64(msg.ReceivedAt.UnixNano()))
+	binary.BigEndian.PutUint16(hdr[12:14], uint16(msg.ConnID))
+	if msg.SeqGap {
+		hdr[14] |= flagSeqGap
+	}
+	binary.BigEndian.PutUint32(hdr[15:19], uint32(msg.GapSize))
+
+	buf = append(buf, hdr[:]...)
+	return append(buf, msg.Data...)
+}
+
+// decodeRecord reads one record from r. It returns io.EOF at a clean end
+// of stream and io.ErrUnexpectedEOF for a record cut short, as happens with
+// the last record of a segment that was being written during a crash.
+func decodeRecord(r io.Reader) (connection.RawMessage, error) {
+	var hdr [headerSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return connection.RawMessage{}, err
+	}
+
+	n := binary.BigEndian.Uint32(hdr[0:4])
+	if n > maxDataSize {
+		return connection.RawMessage{}, fmt.Errorf("%w: data length %d", ErrCorrupt, n)
+	}
+
+	msg := connection.RawMessage{
+		ReceivedAt: time.Unix(0, int64(binary.BigEndian.Uint64(hdr[4:12]))),
+		ConnID:     int(binary.BigEndian.Uint16(hdr[12:14])),
+		SeqGap:     hdr[14]&flagSeqGap != 0,
+		GapSize:    int(binary.BigEndian.Uint32(hdr[15:19])),
+		Data:       make([]byte, n),
+	}
+	if _, err := io.ReadFull(r, msg.Data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return connection.RawMessage{}, err
+	}
+	return msg, nil
+}
+
+// segmentName returns the file name for a segment started at t. Names sort
+// in start order.
+func segmentName(prefix string, t time.Time) string {
+	return prefix + segmentDelim + t.UTC().Format(segmentTime) + segmentExt
+}
+
+// parseSegmentName returns the start time encoded in a segment file name.
+func parseSegmentName(path string) (time.Time, bool) {
+	name := strings.TrimSuffix(filepath.Base(path), segmentExt)
+	i := strings.LastIndex(name, segmentDelim)
+	if i < 0 {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(segmentTime, name[i+1:])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,340 @@
+package journal
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/connection"
+)
+
+// Config holds journal configuration.
+type Config struct {
+	Dir             string        // Segment directory (created if missing)
+	Prefix          string        // Segment file name prefix (default: "journal")
+	MaxSegmentBytes int64         // Rotate after this many uncompressed bytes (default: 256MB)
+	MaxSegmentAge   time.Duration // Rotate segments older than this (default: 1h)
+	FlushInterval   time.Duration // Flush compressed data to disk (default: 1s)
+	Retention       time.Duration // Delete segments older than this on rotation (0 keeps all)
+	BufferSize      int           // Output channel buffer (default: 10000)
+}
+
+// DefaultConfig returns sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Prefix:          "journal",
+		MaxSegmentBytes: 256 << 20,
+		MaxSegmentAge:   time.Hour,
+		FlushInterval:   time.Second,
+		BufferSize:      10000,
+	}
+}
+
+// Stats holds journal statistics.
+type Stats struct {
+	Records  int64 // Messages written
+	Skipped  int64 // Messages forwarded without being written
+	Bytes    int64 // Uncompressed bytes written
+	Segments int64 // Segments opened
+	Deleted  int64 // Segments removed by retention
+	Errors   int64 // Write, flush, rotate or retention errors
+}
+
+// Journal sits between the Connection Manager and the Message Router. It
+// appends every RawMessage to rotating, gzip-compressed segment files and
+// forwards it unchanged. Journal errors are logged and counted but never
+// stop messages from reaching the router.
+type Journal struct {
+	cfg    Config
+	logger *slog.Logger
+
+	input  <-chan connection.RawMessage
+	output chan connection.RawMessage
+
+	// Active segment, owned by run
+	file    *os.File
+	gz      *gzip.Writer
+	bw      *bufio.Writer
+	opened  time.Time
+	written int64
+	retryAt time.Time // No new segment before this after an open failure
+	buf     []byte
+
+	mu    sync.Mutex
+	stats Stats
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a Journal reading from input, normally Manager.Messages().
+func New(cfg Config, input <-chan connection.RawMessage, logger *slog.Logger) *Journal {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Journal{
+		cfg:    cfg,
+		logger: logger,
+		input:  input,
+		output: make(chan connection.RawMessage, cfg.BufferSize),
+	}
+}
+
+// Messages returns the forwarded messages, for the Message Router. The
+// channel is closed when the journal stops.
+func (j *Journal) Messages() <-chan connection.RawMessage {
+	return j.output
+}
+
+// Start creates the segment directory and begins journaling.
+func (j *Journal) Start(ctx context.Context) error {
+	if err := os.MkdirAll(j.cfg.Dir, 0o755); err != nil {
+		return fmt.Errorf("create journal dir: %w", err)
+	}
+
+	j.ctx, j.cancel = context.WithCancel(ctx)
+
+	j.wg.Add(1)
+	go j.run()
+
+	j.logger.Info("journal started",
+		"dir", j.cfg.Dir,
+		"max_segment_bytes", j.cfg.MaxSegmentBytes,
+		"max_segment_age", j.cfg.MaxSegmentAge,
+	)
+	return nil
+}
+
+// Stop closes the active segment and the output channel.
+func (j *Journal) Stop(ctx context.Context) error {
+	j.logger.Info("stopping journal")
+
+	if j.cancel != nil {
+		j.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		j.logger.Info("journal stopped")
+	case <-ctx.Done():
+		j.logger.Warn("journal stop timed out")
+	}
+
+	return nil
+}
+
+// Stats returns current statistics.
+func (j *Journal) Stats() Stats {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.stats
+}
+
+// run appends and forwards messages until the input closes or ctx is done.
+func (j *Journal) run() {
+	defer j.wg.Done()
+	defer close(j.output)
+	defer j.closeSegment()
+
+	ticker := time.NewTicker(j.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-j.ctx.Done():
+			return
+
+		case msg, ok := <-j.input:
+			if !ok {
+				j.logger.Info("input channel closed")
+				return
+			}
+			j.append(msg)
+
+			select {
+			case j.output <- msg:
+			case <-j.ctx.Done():
+				return
+			}
+
+		case now := <-ticker.C:
+			if j.file != nil && now.Sub(j.opened) >= j.cfg.MaxSegmentAge {
+				j.closeSegment()
+				continue
+			}
+			j.flush()
+		}
+	}
+}
+
+// append writes msg to the active segment, rotating first if needed.
+func (j *Journal) append(msg connection.RawMessage) {
+	now := time.Now()
+	if j.file != nil && (j.written >= j.cfg.MaxSegmentBytes || now.Sub(j.opened) >= j.cfg.MaxSegmentAge) {
+		j.closeSegment()
+	}
+	if j.file == nil {
+		if now.Before(j.retryAt) {
+			j.skip()
+			return
+		}
+		if err := j.openSegment(now); err != nil {
+			j.retryAt = now.Add(j.cfg.FlushInterval)
+			j.fail("open segment", err)
+			j.skip()
+			return
+		}
+	}
+
+	j.buf = encodeRecord(j.buf[:0], msg)
+	if _, err := j.bw.Write(j.buf); err != nil {
+		j.fail("write", err)
+		j.abandonSegment()
+		j.skip()
+		return
+	}
+	j.written += int64(len(j.buf))
+
+	j.mu.Lock()
+	j.stats.Records++
+	j.stats.Bytes += int64(len(j.buf))
+	j.mu.Unlock()
+}
+
+// openSegment creates a new segment file and writes its header.
+func (j *Journal) openSegment(now time.Time) error {
+	path := filepath.Join(j.cfg.Dir, segmentName(j.cfg.Prefix, now))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	j.file = f
+	j.gz = gzip.NewWriter(f)
+	j.bw = bufio.NewWriterSize(j.gz, 64<<10)
+	j.opened = now
+	j.written = 0
+
+	if _, err := j.bw.WriteString(magic); err != nil {
+		j.abandonSegment()
+		return err
+	}
+
+	j.mu.Lock()
+	j.stats.Segments++
+	j.mu.Unlock()
+
+	j.logger.Debug("journal segment opened", "path", path)
+
+	j.applyRetention(now)
+	return nil
+}
+
+// flush pushes buffered records through gzip to the file, so a crash loses
+// at most one FlushInterval of messages.
+func (j *Journal) flush() {
+	if j.file == nil {
+		return
+	}
+	if err := j.bw.Flush(); err != nil {
+		j.fail("flush", err)
+		j.abandonSegment()
+		return
+	}
+	if err := j.gz.Flush(); err != nil {
+		j.fail("flush", err)
+		j.abandonSegment()
+	}
+}
+
+// closeSegment flushes and closes the active segment.
+func (j *Journal) closeSegment() {
+	if j.file == nil {
+		return
+	}
+
+	path := j.file.Name()
+	var err error
+	if ferr := j.bw.Flush(); ferr != nil {
+		err = ferr
+	}
+	if cerr := j.gz.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if cerr := j.file.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	j.file, j.gz, j.bw = nil, nil, nil
+
+	if err != nil {
+		j.fail("close segment", err)
+		return
+	}
+	j.logger.Debug("journal segment closed", "path", path, "bytes", j.written)
+}
+
+// abandonSegment drops the active segment after an error. The next append
+// opens a new one.
+func (j *Journal) abandonSegment() {
+	if j.file == nil {
+		return
+	}
+	j.file.Close()
+	j.file, j.gz, j.bw = nil, nil, nil
+}
+
+// applyRetention deletes segments that started before now - Retention.
+// The active segment is never deleted.
+func (j *Journal) applyRetention(now time.Time) {
+	if j.cfg.Retention <= 0 {
+		return
+	}
+
+	segments, err := Segments(j.cfg.Dir)
+	if err != nil {
+		j.fail("list segments", err)
+		return
+	}
+
+	cutoff := now.Add(-j.cfg.Retention)
+	for _, s := range segments {
+		if !s.Start.Before(cutoff) || (j.file != nil && s.Path == j.file.Name()) {
+			continue
+		}
+		if err := os.Remove(s.Path); err != nil {
+			j.fail("delete segment", err)
+			continue
+		}
+		j.mu.Lock()
+		j.stats.Deleted++
+		j.mu.Unlock()
+	}
+}
+
+// skip records a message that was forwarded but not journaled.
+func (j *Journal) skip() {
+	j.mu.Lock()
+	j.stats.Skipped++
+	j.mu.Unlock()
+}
+
+// fail records a journal error.
+func (j *Journal) fail(op string, err error) {
+	j.mu.Lock()
+	j.stats.Errors++
+	j.mu.Unlock()
+	j.logger.Warn("journal error", "op", op, "error", err)
+}
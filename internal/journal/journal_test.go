@@ -0,0 +1,230 @@
+package journal
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/connection"
+)
+
+func testMessage(i int, at time.Time) connection.RawMessage {
+	return connection.RawMessage{
+		Data:       []byte(`{"type":"trade","seq":` + string(rune('0'+i%10)) + `}`),
+		ConnID:     3 + i%2,
+		ReceivedAt: at,
+		SeqGap:     i%3 == 0,
+		GapSize:    i % 3,
+	}
+}
+
+func testConfig(dir string) Config {
+	cfg := DefaultConfig()
+	cfg.Dir = dir
+	cfg.FlushInterval = 10 * time.Millisecond
+	cfg.BufferSize = 100
+	return cfg
+}
+
+// runJournal sends msgs through a journal and stops it.
+func runJournal(t *testing.T, cfg Config, msgs []connection.RawMessage) *Journal {
+	t.Helper()
+
+	input := make(chan connection.RawMessage, len(msgs))
+	j := New(cfg, input, nil)
+	if err := j.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	for _, m := range msgs {
+		input <- m
+	}
+	close(input)
+
+	for i := range msgs {
+		select {
+		case got := <-j.Messages():
+			if string(got.Data) != string(msgs[i].Data) {
+				t.Errorf("forwarded[%d] = %s, want %s", i, got.Data, msgs[i].Data)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for forwarded message %d", i)
+		}
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := j.Stop(stopCtx); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+	if _, ok := <-j.Messages(); ok {
+		t.Error("Messages() not closed after Stop()")
+	}
+	return j
+}
+
+func readAll(t *testing.T, r *Reader) []connection.RawMessage {
+	t.Helper()
+	defer r.Close()
+
+	var msgs []connection.RawMessage
+	for {
+		m, err := r.Next()
+		if err == io.EOF {
+			return msgs
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		msgs = append(msgs, m)
+	}
+}
+
+func TestJournal_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now()
+
+	var msgs []connection.RawMessage
+	for i := 0; i < 20; i++ {
+		msgs = append(msgs, testMessage(i, base.Add(time.Duration(i)*time.Millisecond)))
+	}
+
+	j := runJournal(t, testConfig(dir), msgs)
+
+	stats := j.Stats()
+	if stats.Records != 20 || stats.Segments != 1 || stats.Errors != 0 || stats.Skipped != 0 {
+		t.Errorf("Stats() = %+v, want 20 records in 1 segment", stats)
+	}
+
+	r, err := Open(dir, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	got := readAll(t, r)
+
+	if len(got) != len(msgs) {
+		t.Fatalf("read %d messages, want %d", len(got), len(msgs))
+	}
+	for i := range msgs {
+		want := msgs[i]
+		if string(got[i].Data) != string(want.Data) ||
+			got[i].ConnID != want.ConnID ||
+			!got[i].ReceivedAt.Equal(want.ReceivedAt) ||
+			got[i].SeqGap != want.SeqGap ||
+			got[i].GapSize != want.GapSize {
+			t.Errorf("message %d = %+v, want %+v", i, got[i], want)
+		}
+	}
+	if r.Truncated() != 0 {
+		t.Errorf("Truncated() = %d, want 0", r.Truncated())
+	}
+}
+
+func TestJournal_SizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	cfg := testConfig(dir)
+	cfg.MaxSegmentBytes = 100 // A few records per segment
+
+	var msgs []connection.RawMessage
+	for i := 0; i < 10; i++ {
+		msgs = append(msgs, testMessage(i, time.Now()))
+	}
+
+	j := runJournal(t, cfg, msgs)
+
+	segments, err := Segments(dir)
+	if err != nil {
+		t.Fatalf("Segments() error = %v", err)
+	}
+	if len(segments) < 3 || int64(len(segments)) != j.Stats().Segments {
+		t.Errorf("len(Segments()) = %d, Stats().Segments = %d, want equal and >= 3", len(segments), j.Stats().Segments)
+	}
+
+	r, _ := Open(dir, time.Time{}, time.Time{})
+	if got := readAll(t, r); len(got) != 10 {
+		t.Errorf("read %d messages across segments, want 10", len(got))
+	}
+}
+
+func TestJournal_AgeRotation(t *testing.T) {
+	dir := t.TempDir()
+	cfg := testConfig(dir)
+	cfg.MaxSegmentAge = 20 * time.Millisecond
+
+	input := make(chan connection.RawMessage)
+	j := New(cfg, input, nil)
+	j.Start(context.Background())
+
+	input <- testMessage(1, time.Now())
+	<-j.Messages()
+	time.Sleep(50 * time.Millisecond) // Idle segment closed by the flush ticker
+	input <- testMessage(2, time.Now())
+	<-j.Messages()
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	j.Stop(stopCtx)
+
+	if got := j.Stats().Segments; got != 2 {
+		t.Errorf("Stats().Segments = %d, want 2", got)
+	}
+}
+
+func TestJournal_Retention(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, segmentName("journal", time.Now().Add(-48*time.Hour)))
+	recent := filepath.Join(dir, segmentName("journal", time.Now().Add(-time.Hour)))
+	for _, p := range []string{old, recent} {
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := testConfig(dir)
+	cfg.Retention = 24 * time.Hour
+	j := runJournal(t, cfg, []connection.RawMessage{testMessage(1, time.Now())})
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("old segment still exists (err = %v)", err)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("recent segment removed: %v", err)
+	}
+	if got := j.Stats().Deleted; got != 1 {
+		t.Errorf("Stats().Deleted = %d, want 1", got)
+	}
+}
+
+func TestJournal_UnwritableDir(t *testing.T) {
+	dir := t.TempDir()
+	cfg := testConfig(filepath.Join(dir, "journal"))
+
+	input := make(chan connection.RawMessage, 2)
+	j := New(cfg, input, nil)
+	j.Start(context.Background())
+
+	// Remove the directory so segments cannot be created
+	os.RemoveAll(cfg.Dir)
+
+	input <- testMessage(1, time.Now())
+	input <- testMessage(2, time.Now())
+	for i := 0; i < 2; i++ {
+		select {
+		case <-j.Messages():
+		case <-time.After(time.Second):
+			t.Fatal("message not forwarded after journal error")
+		}
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	j.Stop(stopCtx)
+
+	stats := j.Stats()
+	if stats.Skipped != 2 || stats.Errors != 1 || stats.Records != 0 {
+		t.Errorf("Stats() = %+v, want 2 skipped, 1 error (retry backoff)", stats)
+	}
+}
@@ -0,0 +1,192 @@
+package journal
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/connection"
+)
+
+// errEmptySegment marks a segment created but never flushed.
+var errEmptySegment = errors.New("empty segment")
+
+// Segment is a journal file and the time its first record could have been
+// received.
+type Segment struct {
+	Path  string
+	Start time.Time
+}
+
+// Segments lists the segments in dir, oldest first. Files that do not look
+// like segments are ignored.
+func Segments(dir string) ([]Segment, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, segmentGlob))
+	if err != nil {
+		return nil, fmt.Errorf("list segments: %w", err)
+	}
+
+	segments := make([]Segment, 0, len(paths))
+	for _, p := range paths {
+		if start, ok := parseSegmentName(p); ok {
+			segments = append(segments, Segment{Path: p, Start: start})
+		}
+	}
+	sort.Slice(segments, func(i, k int) bool { return segments[i].Start.Before(segments[k].Start) })
+	return segments, nil
+}
+
+// Reader reads RawMessages back from journal segments in order.
+//
+// A segment that ends mid-record (the active segment of a crashed gatherer)
+// is read up to its last complete record; Truncated reports how many
+// segments ended this way.
+type Reader struct {
+	segments []Segment
+	from, to time.Time // Zero means unbounded
+
+	next      int // Index of the next segment to open
+	file      *os.File
+	gz        *gzip.Reader
+	br        *bufio.Reader
+	truncated int
+}
+
+// Open returns a Reader over the segments in dir with messages received in
+// [from, to). Zero from or to leaves that end unbounded.
+func Open(dir string, from, to time.Time) (*Reader, error) {
+	segments, err := Segments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// A segment may hold messages up to the next segment's start
+	var selected []Segment
+	for i, s := range segments {
+		if !to.IsZero() && !s.Start.Before(to) {
+			break
+		}
+		if !from.IsZero() && i+1 < len(segments) && !segments[i+1].Start.After(from) {
+			continue
+		}
+		selected = append(selected, s)
+	}
+
+	return &Reader{segments: selected, from: from, to: to}, nil
+}
+
+// NewReader returns a Reader over the given segment files, in the order given.
+func NewReader(paths ...string) *Reader {
+	segments := make([]Segment, len(paths))
+	for i, p := range paths {
+		segments[i] = Segment{Path: p}
+	}
+	return &Reader{segments: segments}
+}
+
+// Segments returns the segments the reader will read.
+func (r *Reader) Segments() []Segment {
+	return r.segments
+}
+
+// Next returns the next message, or io.EOF after the last segment.
+func (r *Reader) Next() (connection.RawMessage, error) {
+	for {
+		if r.br == nil {
+			if r.next >= len(r.segments) {
+				return connection.RawMessage{}, io.EOF
+			}
+			s := r.segments[r.next]
+			r.next++
+			if err := r.open(s); err != nil {
+				if errors.Is(err, errEmptySegment) {
+					r.truncated++
+					continue
+				}
+				return connection.RawMessage{}, err
+			}
+		}
+
+		msg, err := decodeRecord(r.br)
+		switch {
+		case err == nil:
+		case errors.Is(err, io.EOF):
+			r.closeSegment()
+			continue
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			r.truncated++
+			r.closeSegment()
+			continue
+		default:
+			return connection.RawMessage{}, fmt.Errorf("%s: %w", r.file.Name(), err)
+		}
+
+		if !r.from.IsZero() && msg.ReceivedAt.Before(r.from) {
+			continue
+		}
+		if !r.to.IsZero() && !msg.ReceivedAt.Before(r.to) {
+			continue
+		}
+		return msg, nil
+	}
+}
+
+// Truncated returns the number of segments read so far that ended mid-record.
+func (r *Reader) Truncated() int {
+	return r.truncated
+}
+
+// Close closes the current segment.
+func (r *Reader) Close() error {
+	r.closeSegment()
+	r.next = len(r.segments)
+	return nil
+}
+
+// open opens a segment and checks its header.
+func (r *Reader) open(s Segment) error {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return fmt.Errorf("open segment: %w", err)
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return errEmptySegment
+		}
+		return fmt.Errorf("%s: %w", s.Path, err)
+	}
+
+	br := bufio.NewReaderSize(gz, 64<<10)
+	hdr := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, hdr); err != nil || string(hdr) != magic {
+		gz.Close()
+		f.Close()
+		if err != nil && (errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)) {
+			return errEmptySegment
+		}
+		return fmt.Errorf("%s: %w: bad header", s.Path, ErrCorrupt)
+	}
+
+	r.file, r.gz, r.br = f, gz, br
+	return nil
+}
+
+// closeSegment closes the current segment, if any.
+func (r *Reader) closeSegment() {
+	if r.gz != nil {
+		r.gz.Close()
+	}
+	if r.file != nil {
+		r.file.Close()
+	}
+	r.file, r.gz, r.br = nil, nil, nil
+}
@@ -0,0 +1,151 @@
+package journal
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/connection"
+)
+
+// writeSegment writes msgs to a segment named for start. If complete is
+// false the gzip stream is flushed but never closed, as after a crash, and
+// the last record is cut short.
+func writeSegment(t *testing.T, dir string, start time.Time, msgs []connection.RawMessage, complete bool) string {
+	t.Helper()
+
+	path := filepath.Join(dir, segmentName("journal", start))
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	bw := bufio.NewWriter(gz)
+	bw.WriteString(magic)
+
+	var buf []byte
+	for i, m := range msgs {
+		buf = encodeRecord(buf[:0], m)
+		if !complete && i == len(msgs)-1 {
+			buf = buf[:len(buf)/2]
+		}
+		bw.Write(buf)
+	}
+	if !complete {
+		bw.Flush()
+		gz.Flush()
+		return path
+	}
+	bw.Flush()
+	gz.Close()
+	return path
+}
+
+func TestOpen_TimeRange(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	// Three hourly segments with a message every 20 minutes
+	for h := 0; h < 3; h++ {
+		start := base.Add(time.Duration(h) * time.Hour)
+		var msgs []connection.RawMessage
+		for m := 0; m < 3; m++ {
+			msgs = append(msgs, testMessage(h*3+m, start.Add(time.Duration(m)*20*time.Minute)))
+		}
+		writeSegment(t, dir, start, msgs, true)
+	}
+
+	tests := []struct {
+		name         string
+		from, to     time.Time
+		wantSegments int
+		wantMessages int
+	}{
+		{"unbounded", time.Time{}, time.Time{}, 3, 9},
+		{"from mid second segment", base.Add(80 * time.Minute), time.Time{}, 2, 5},
+		{"to start of third segment", time.Time{}, base.Add(2 * time.Hour), 2, 6},
+		{"inside one segment", base.Add(65 * time.Minute), base.Add(90 * time.Minute), 1, 1},
+		{"after all", base.Add(5 * time.Hour), time.Time{}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Open(dir, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if got := len(r.Segments()); got != tt.wantSegments {
+				t.Errorf("len(Segments()) = %d, want %d", got, tt.wantSegments)
+			}
+			if got := len(readAll(t, r)); got != tt.wantMessages {
+				t.Errorf("read %d messages, want %d", got, tt.wantMessages)
+			}
+		})
+	}
+}
+
+func TestReader_TruncatedSegment(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now()
+
+	msgs := []connection.RawMessage{testMessage(1, base), testMessage(2, base), testMessage(3, base)}
+	first := writeSegment(t, dir, base, msgs, false)
+	second := writeSegment(t, dir, base.Add(time.Minute), msgs[:1], true)
+
+	empty := filepath.Join(dir, segmentName("journal", base.Add(2*time.Minute)))
+	os.WriteFile(empty, nil, 0o644)
+
+	r := NewReader(first, second, empty)
+	got := readAll(t, r)
+
+	// Two complete records from the crashed segment, one from the next
+	if len(got) != 3 {
+		t.Errorf("read %d messages, want 3", len(got))
+	}
+	if r.Truncated() != 2 {
+		t.Errorf("Truncated() = %d, want 2", r.Truncated())
+	}
+}
+
+func TestReader_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+
+	notJournal := filepath.Join(dir, segmentName("journal", time.Now()))
+	f, _ := os.Create(notJournal)
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte("not a journal"))
+	gz.Close()
+	f.Close()
+
+	r := NewReader(notJournal)
+	if _, err := r.Next(); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Next() error = %v, want %v", err, ErrCorrupt)
+	}
+
+	r = NewReader(filepath.Join(dir, "missing"+segmentExt))
+	if _, err := r.Next(); err == nil || err == io.EOF {
+		t.Errorf("Next() error = %v, want open error", err)
+	}
+}
+
+func TestSegments_IgnoresOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o644)
+	os.WriteFile(filepath.Join(dir, "bad-name"+segmentExt), nil, 0o644)
+	writeSegment(t, dir, time.Now(), nil, true)
+
+	segments, err := Segments(dir)
+	if err != nil {
+		t.Fatalf("Segments() error = %v", err)
+	}
+	if len(segments) != 1 {
+		t.Errorf("len(Segments()) = %d, want 1", len(segments))
+	}
+}
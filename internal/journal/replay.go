@@ -0,0 +1,106 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/connection"
+)
+
+// ReplayOptions controls Replay.
+type ReplayOptions struct {
+	// Tickers limits replay to messages for these markets. Messages without
+	// a market ticker (subscription responses, errors) are skipped when set.
+	// Nil replays everything.
+	Tickers map[string]struct{}
+
+	// Speed paces messages by their original ReceivedAt spacing divided by
+	// Speed: 1 is wall-clock pacing, 10 is ten times faster. 0 replays as
+	// fast as the consumer accepts.
+	Speed float64
+
+	// Throttle, if set, is called before each send so the caller can hold
+	// replay while downstream buffers drain.
+	Throttle func(ctx context.Context) error
+}
+
+// ReplayStats summarises a replay.
+type ReplayStats struct {
+	Read     int64 // Messages read from the journal
+	Sent     int64 // Messages sent to out
+	Filtered int64 // Messages skipped by the ticker filter
+}
+
+// tickerEnvelope extracts the market ticker from a data message.
+type tickerEnvelope struct {
+	Msg struct {
+		MarketTicker string `json:"market_ticker"`
+	} `json:"msg"`
+}
+
+// Replay reads every message from r and sends it to out, applying the
+// ticker filter and pacing in opts. It returns when r is exhausted or ctx
+// is done; out is not closed.
+func Replay(ctx context.Context, r *Reader, out chan<- connection.RawMessage, opts ReplayOptions) (ReplayStats, error) {
+	var (
+		stats     ReplayStats
+		firstAt   time.Time
+		startedAt time.Time
+	)
+
+	for {
+		msg, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, err
+		}
+		stats.Read++
+
+		if opts.Tickers != nil && !matchTicker(msg.Data, opts.Tickers) {
+			stats.Filtered++
+			continue
+		}
+
+		if opts.Speed > 0 {
+			if firstAt.IsZero() {
+				firstAt, startedAt = msg.ReceivedAt, time.Now()
+			}
+			offset := time.Duration(float64(msg.ReceivedAt.Sub(firstAt)) / opts.Speed)
+			if wait := time.Until(startedAt.Add(offset)); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return stats, ctx.Err()
+				}
+			}
+		}
+
+		if opts.Throttle != nil {
+			if err := opts.Throttle(ctx); err != nil {
+				return stats, err
+			}
+		}
+
+		select {
+		case out <- msg:
+			stats.Sent++
+		case <-ctx.Done():
+			return stats, ctx.Err()
+		}
+	}
+}
+
+// matchTicker reports whether data is a message for one of tickers.
+func matchTicker(data []byte, tickers map[string]struct{}) bool {
+	var env tickerEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return false
+	}
+	_, ok := tickers[env.Msg.MarketTicker]
+	return ok
+}
@@ -0,0 +1,129 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/connection"
+)
+
+func tickerMessage(ticker string, at time.Time) connection.RawMessage {
+	return connection.RawMessage{
+		Data:       []byte(`{"type":"trade","sid":1,"msg":{"market_ticker":"` + ticker + `"}}`),
+		ConnID:     3,
+		ReceivedAt: at,
+	}
+}
+
+func TestReplay_Filter(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now()
+	writeSegment(t, dir, base, []connection.RawMessage{
+		tickerMessage("A", base),
+		tickerMessage("B", base),
+		{Data: []byte(`{"id":1,"type":"subscribed","msg":{"sid":1}}`), ReceivedAt: base},
+		tickerMessage("A", base),
+	}, true)
+
+	tests := []struct {
+		name    string
+		tickers map[string]struct{}
+		want    ReplayStats
+	}{
+		{"all", nil, ReplayStats{Read: 4, Sent: 4}},
+		{"one ticker", map[string]struct{}{"A": {}}, ReplayStats{Read: 4, Sent: 2, Filtered: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := Open(dir, time.Time{}, time.Time{})
+			defer r.Close()
+
+			out := make(chan connection.RawMessage, 10)
+			got, err := Replay(context.Background(), r, out, ReplayOptions{Tickers: tt.tickers})
+			if err != nil {
+				t.Fatalf("Replay() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Replay() = %+v, want %+v", got, tt.want)
+			}
+			if len(out) != int(tt.want.Sent) {
+				t.Errorf("len(out) = %d, want %d", len(out), tt.want.Sent)
+			}
+		})
+	}
+}
+
+func TestReplay_Pacing(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now()
+	writeSegment(t, dir, base, []connection.RawMessage{
+		tickerMessage("A", base),
+		tickerMessage("A", base.Add(400*time.Millisecond)),
+	}, true)
+
+	tests := []struct {
+		speed   float64
+		minTime time.Duration
+		maxTime time.Duration
+	}{
+		{0, 0, 100 * time.Millisecond},
+		{4, 90 * time.Millisecond, 300 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		r, _ := Open(dir, time.Time{}, time.Time{})
+		out := make(chan connection.RawMessage, 10)
+
+		start := time.Now()
+		if _, err := Replay(context.Background(), r, out, ReplayOptions{Speed: tt.speed}); err != nil {
+			t.Fatalf("Replay() error = %v", err)
+		}
+		elapsed := time.Since(start)
+		r.Close()
+
+		if elapsed < tt.minTime || elapsed > tt.maxTime {
+			t.Errorf("speed %v: elapsed %v, want between %v and %v", tt.speed, elapsed, tt.minTime, tt.maxTime)
+		}
+	}
+}
+
+func TestReplay_ThrottleAndCancel(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now()
+	writeSegment(t, dir, base, []connection.RawMessage{tickerMessage("A", base), tickerMessage("A", base)}, true)
+
+	stop := errors.New("stop")
+	calls := 0
+	throttle := func(context.Context) error {
+		calls++
+		if calls > 1 {
+			return stop
+		}
+		return nil
+	}
+
+	r, _ := Open(dir, time.Time{}, time.Time{})
+	defer r.Close()
+
+	out := make(chan connection.RawMessage, 10)
+	got, err := Replay(context.Background(), r, out, ReplayOptions{Throttle: throttle})
+	if !errors.Is(err, stop) {
+		t.Errorf("Replay() error = %v, want %v", err, stop)
+	}
+	if got.Sent != 1 {
+		t.Errorf("Sent = %d, want 1", got.Sent)
+	}
+
+	// Unbuffered output nobody reads: cancellation must unblock
+	r2, _ := Open(dir, time.Time{}, time.Time{})
+	defer r2.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := Replay(ctx, r2, make(chan connection.RawMessage), ReplayOptions{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Replay() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -5,4 +5,5 @@
 //   - Receives live updates via market_lifecycle WebSocket channel
 //   - Maintains in-memory registry of active markets
 //   - Notifies Connection Manager of market additions/removals
+//   - Sends market metadata to the Metadata Writer once subscribed
 package market
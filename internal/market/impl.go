@@ -34,6 +34,9 @@ type registryImpl struct {
 
 	state *registryState
 
+	// First delay between settled market fetches, doubling per attempt.
+	settleRetry time.Duration
+
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
@@ -50,6 +53,8 @@ func NewRegistry(cfg Config, rest *api.Client, logger *slog.Logger) Registry {
 		rest:   rest,
 		logger: logger,
 		state:  newState(),
+
+		settleRetry: time.Second,
 	}
 }
 
@@ -92,6 +97,7 @@ func (r *registryImpl) Stop(ctx context.Context) error {
 	if r.cancel != nil {
 		r.cancel()
 	}
+	r.state.stopMetadata()
 
 	done := make(chan struct{})
 	go func() {
@@ -123,6 +129,16 @@ func (r *registryImpl) SubscribeChanges() <-chan MarketChange {
 	return r.state.changes
 }
 
+// SubscribeMetadata returns a channel of market metadata updates.
+func (r *registryImpl) SubscribeMetadata() <-chan MetadataUpdate {
+	return r.state.subscribeMetadata()
+}
+
+// Maintenance returns the scheduled exchange downtime covering t, if any.
+func (r *registryImpl) Maintenance(t time.Time) (model.MaintenanceWindow, bool) {
+	return r.state.maintenanceAt(t.UnixMicro())
+}
+
 // SetLifecycleSource sets the channel for lifecycle messages.
 func (r *registryImpl) SetLifecycleSource(ch <-chan []byte) {
 	r.state.lifecycle = ch
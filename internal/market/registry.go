@@ -2,6 +2,7 @@ package market
 
 import (
 	"context"
+	"time"
 
 	"github.com/rickgao/kalshi-data/internal/model"
 )
@@ -9,6 +10,19 @@ import (
 // ChangeBufferSize is the capacity of the MarketChange channel.
 const ChangeBufferSize = 1000
 
+// MetadataBufferSize is the capacity of the MetadataUpdate channel. Updates
+// from sync and reconcile carry every known market, so it is kept small;
+// updates beyond it wait in the registry until the writer catches up.
+const MetadataBufferSize = 64
+
+// Metadata update sources.
+const (
+	SourceSync          = "sync"          // Initial REST sync
+	SourceReconcile     = "reconcile"     // Periodic REST reconciliation
+	SourceLifecycle     = "lifecycle"     // market_lifecycle WebSocket message
+	SourceAnnouncements = "announcements" // Exchange announcements, refreshed with reconcile
+)
+
 // Registry manages market discovery and lifecycle.
 type Registry interface {
 	// Start begins market discovery in background, returns immediately.
@@ -28,6 +42,16 @@ type Registry interface {
 	// Connection Manager uses this to know when to subscribe/unsubscribe.
 	SubscribeChanges() <-chan MarketChange
 
+	// SubscribeMetadata returns a channel of market metadata updates.
+	// Metadata Writer uses this to persist markets. Updates are only
+	// produced once this has been called, and are never dropped.
+	SubscribeMetadata() <-chan MetadataUpdate
+
+	// Maintenance returns the scheduled exchange downtime covering t, if
+	// any: a closure or maintenance window from the exchange schedule, or
+	// an outage the status endpoint reported with an estimated resume time.
+	Maintenance(t time.Time) (model.MaintenanceWindow, bool)
+
 	// SetLifecycleSource sets the channel from which lifecycle messages are received.
 	// Connection Manager calls this to provide market_lifecycle WebSocket messages.
 	SetLifecycleSource(ch <-chan []byte)
@@ -41,3 +65,16 @@ type MarketChange struct {
 	NewStatus string        // New status
 	Market    *model.Market // Full market data (nil for "settled")
 }
+
+// MetadataUpdate carries current market data for persistence. Unlike
+// MarketChange it covers every market the registry fetched, including
+// unopened and settled markets, and Market data is always present.
+//
+// Updates with Source SourceAnnouncements carry new or changed exchange
+// announcements instead of markets.
+type MetadataUpdate struct {
+	Markets       []model.Market
+	Announcements []model.Announcement
+	Source        string    // SourceSync, SourceReconcile, SourceLifecycle or SourceAnnouncements
+	ObservedAt    time.Time // When the registry received the data
+}
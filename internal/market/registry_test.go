@@ -3,10 +3,12 @@ package market
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -466,7 +468,7 @@ func TestDefaultConfig(t *testing.T) {
 func TestNewRegistry(t *testing.T) {
 	t.Run("with nil logger", func(t *testing.T) {
 		cfg := DefaultConfig()
-		client := api.NewClient("http://localhost", "")
+		client := api.NewClient("http://localhost", "", nil)
 		reg := NewRegistry(cfg, client, nil)
 		if reg == nil {
 			t.Fatal("NewRegistry returned nil")
@@ -475,7 +477,7 @@ func TestNewRegistry(t *testing.T) {
 
 	t.Run("with logger", func(t *testing.T) {
 		cfg := DefaultConfig()
-		client := api.NewClient("http://localhost", "")
+		client := api.NewClient("http://localhost", "", nil)
 		logger := slog.Default()
 		reg := NewRegistry(cfg, client, logger)
 		if reg == nil {
@@ -486,7 +488,7 @@ func TestNewRegistry(t *testing.T) {
 
 func TestRegistryImpl_GetActiveMarkets(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 
 	// Cast to registryImpl for internal access
@@ -502,7 +504,7 @@ func TestRegistryImpl_GetActiveMarkets(t *testing.T) {
 
 func TestRegistryImpl_GetMarket(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 
 	impl := reg.(*registryImpl)
@@ -528,7 +530,7 @@ func TestRegistryImpl_GetMarket(t *testing.T) {
 
 func TestRegistryImpl_SubscribeChanges(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 
 	ch := reg.SubscribeChanges()
@@ -537,9 +539,293 @@ func TestRegistryImpl_SubscribeChanges(t *testing.T) {
 	}
 }
 
+func TestRegistryState_NotifyMetadata(t *testing.T) {
+	s := newState()
+	defer s.stopMetadata()
+
+	// Nothing is queued until someone subscribes
+	s.notifyMetadata(SourceSync, model.Market{Ticker: "A"})
+	if len(s.metadataQueue) != 0 {
+		t.Fatalf("metadata queue = %d before subscribe, want 0", len(s.metadataQueue))
+	}
+
+	ch := s.subscribeMetadata()
+	s.notifyMetadata(SourceReconcile, model.Market{Ticker: "A"}, model.Market{Ticker: "B"})
+	s.notifyMetadata(SourceLifecycle) // empty updates are skipped
+
+	update := receiveMetadata(t, ch)
+	if update.Source != SourceReconcile {
+		t.Errorf("Source = %q, want %q", update.Source, SourceReconcile)
+	}
+	if len(update.Markets) != 2 {
+		t.Errorf("len(Markets) = %d, want 2", len(update.Markets))
+	}
+	if update.ObservedAt.IsZero() {
+		t.Error("ObservedAt is zero")
+	}
+	expectNoMetadata(t, ch)
+}
+
+func TestRegistryState_NotifyMetadata_Lossless(t *testing.T) {
+	s := newState()
+	defer s.stopMetadata()
+	ch := s.subscribeMetadata()
+
+	// Far more updates than the channel holds, none read yet
+	n := 3 * MetadataBufferSize
+	for i := range n {
+		s.notifyMetadata(SourceLifecycle, model.Market{Ticker: fmt.Sprintf("M-%d", i)})
+	}
+
+	for i := range n {
+		update := receiveMetadata(t, ch)
+		if want := fmt.Sprintf("M-%d", i); update.Markets[0].Ticker != want {
+			t.Fatalf("update %d = %s, want %s", i, update.Markets[0].Ticker, want)
+		}
+	}
+	expectNoMetadata(t, ch)
+}
+
+func TestRegistryState_NotifyMetadata_Coalesces(t *testing.T) {
+	s := newState()
+	defer s.stopMetadata()
+
+	// Queue without a pump so nothing is handed on yet
+	s.metadataSubscribed.Store(true)
+
+	for range 5 {
+		s.notifyMetadata(SourceReconcile,
+			model.Market{Ticker: "A", MarketStatus: "open"},
+			model.Market{Ticker: "B", MarketStatus: "open"},
+		)
+	}
+	s.notifyAnnouncements(model.Announcement{ID: "1"})
+	s.notifyMetadata(SourceLifecycle, model.Market{Ticker: "A", MarketStatus: "settled"})
+
+	if len(s.metadataQueue) != 3 {
+		t.Fatalf("metadata queue = %d, want 3 (A, B, announcements)", len(s.metadataQueue))
+	}
+
+	go s.pumpMetadata()
+	ch := s.metadata
+
+	// A keeps its place but carries the lifecycle version
+	first := receiveMetadata(t, ch)
+	if first.Source != SourceLifecycle || len(first.Markets) != 1 || first.Markets[0].MarketStatus != "settled" {
+		t.Errorf("first update = %+v, want settled A from lifecycle", first)
+	}
+	second := receiveMetadata(t, ch)
+	if second.Source != SourceReconcile || len(second.Markets) != 1 || second.Markets[0].Ticker != "B" {
+		t.Errorf("second update = %+v, want B from reconcile", second)
+	}
+	third := receiveMetadata(t, ch)
+	if len(third.Announcements) != 1 || len(third.Markets) != 0 {
+		t.Errorf("third update = %+v, want the announcement", third)
+	}
+	expectNoMetadata(t, ch)
+
+	// Sent markets are no longer coalesced
+	s.notifyMetadata(SourceReconcile, model.Market{Ticker: "A"})
+	if update := receiveMetadata(t, ch); len(update.Markets) != 1 {
+		t.Errorf("len(Markets) = %d, want 1", len(update.Markets))
+	}
+}
+
+// receiveMetadata waits for the next metadata update.
+func receiveMetadata(t *testing.T, ch <-chan MetadataUpdate) MetadataUpdate {
+	t.Helper()
+	select {
+	case update := <-ch:
+		return update
+	case <-time.After(time.Second):
+		t.Fatal("no metadata update")
+		return MetadataUpdate{}
+	}
+}
+
+// expectNoMetadata fails if a metadata update arrives shortly.
+func expectNoMetadata(t *testing.T, ch <-chan MetadataUpdate) {
+	t.Helper()
+	select {
+	case update := <-ch:
+		t.Errorf("unexpected metadata update %+v", update)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRegistryImpl_HandleSettled_Metadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/markets/TEST-MARKET" {
+			json.NewEncoder(w).Encode(map[string]any{
+				"market": map[string]any{
+					"ticker":                   "TEST-MARKET",
+					"title":                    "Test",
+					"status":                   "finalized",
+					"result":                   "yes",
+					"volume":                   5000,
+					"open_interest":            1200,
+					"settlement_value":         100,
+					"settlement_value_dollars": "1.0000",
+				},
+			})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	client := api.NewClient(server.URL, "", nil)
+	reg := NewRegistry(cfg, client, nil)
+	impl := reg.(*registryImpl)
+	ch := reg.SubscribeMetadata()
+
+	impl.state.upsertMarket(model.Market{
+		Ticker:       "TEST-MARKET",
+		Title:        "Test",
+		MarketStatus: "open",
+	})
+	impl.handleSettled(context.Background(), "TEST-MARKET", "yes", 1705328200)
+
+	// The settlement is sent at once from the lifecycle message
+	update := receiveMetadata(t, ch)
+	if update.Source != SourceLifecycle {
+		t.Errorf("Source = %q, want %q", update.Source, SourceLifecycle)
+	}
+	if len(update.Markets) != 1 {
+		t.Fatalf("len(Markets) = %d, want 1", len(update.Markets))
+	}
+	m := update.Markets[0]
+	if m.MarketStatus != "settled" || m.Result != "yes" || m.Title != "Test" {
+		t.Errorf("market = %+v, want settled with result yes and title", m)
+	}
+	if m.SettledTS != 1705328200000000 {
+		t.Errorf("SettledTS = %d, want 1705328200000000", m.SettledTS)
+	}
+
+	// Then again with the settlement value and final volume from REST
+	update = receiveMetadata(t, ch)
+	if len(update.Markets) != 1 {
+		t.Fatalf("len(Markets) = %d, want 1", len(update.Markets))
+	}
+	m = update.Markets[0]
+	if m.MarketStatus != "settled" || m.Result != "yes" {
+		t.Errorf("market = %+v, want settled with result yes", m)
+	}
+	if m.SettlementValue == nil || *m.SettlementValue != 100000 {
+		t.Errorf("SettlementValue = %v, want 100000", m.SettlementValue)
+	}
+	if m.SettledTS != 1705328200000000 {
+		t.Errorf("SettledTS = %d, want 1705328200000000", m.SettledTS)
+	}
+	if m.Volume != 5000 || m.OpenInterest != 1200 {
+		t.Errorf("Volume, OpenInterest = %d, %d, want 5000, 1200", m.Volume, m.OpenInterest)
+	}
+}
+
+func TestRegistryImpl_HandleSettled_Retry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"market": map[string]any{"ticker": "TEST-MARKET", "status": "finalized", "result": "no", "settlement_value": 0},
+		})
+	}))
+	defer server.Close()
+
+	reg := NewRegistry(DefaultConfig(), api.NewClient(server.URL, "", nil), nil)
+	impl := reg.(*registryImpl)
+	impl.settleRetry = time.Millisecond
+	ch := reg.SubscribeMetadata()
+
+	impl.state.upsertMarket(model.Market{Ticker: "TEST-MARKET", MarketStatus: "open"})
+	impl.handleSettled(context.Background(), "TEST-MARKET", "no", 0)
+
+	if m := receiveMetadata(t, ch).Markets[0]; m.Result != "no" || m.SettlementValue != nil {
+		t.Errorf("first update = %+v, want result without settlement value", m)
+	}
+	if m := receiveMetadata(t, ch).Markets[0]; m.SettlementValue == nil || *m.SettlementValue != 0 {
+		t.Errorf("SettlementValue = %v, want 0 after retries", m.SettlementValue)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("fetches = %d, want 3", got)
+	}
+}
+
+func TestRegistryImpl_Maintenance(t *testing.T) {
+	now := time.Now()
+	announcements := []map[string]any{
+		{"id": "ann-1", "type": "maintenance", "status": "active", "title": "Maintenance"},
+		{"id": "ann-2", "type": "general", "status": "active"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/exchange/status":
+			json.NewEncoder(w).Encode(map[string]any{
+				"exchange_active":                false,
+				"exchange_estimated_resume_time": now.Add(10 * time.Minute).UTC().Format(time.RFC3339),
+			})
+		case "/exchange/schedule":
+			json.NewEncoder(w).Encode(map[string]any{
+				"schedule": map[string]any{
+					"maintenance_windows": []map[string]any{
+						{"start_datetime": now.Add(time.Hour).UTC().Format(time.RFC3339), "end_datetime": now.Add(2 * time.Hour).UTC().Format(time.RFC3339)},
+						{"start_datetime": now.Add(-3 * time.Hour).UTC().Format(time.RFC3339), "end_datetime": now.Add(-2 * time.Hour).UTC().Format(time.RFC3339)},
+					},
+				},
+			})
+		case "/exchange/announcements":
+			json.NewEncoder(w).Encode(map[string]any{"announcements": announcements})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := NewRegistry(DefaultConfig(), api.NewClient(server.URL, "", nil), nil)
+	impl := reg.(*registryImpl)
+	ch := reg.SubscribeMetadata()
+
+	impl.refreshExchange(context.Background())
+
+	// Status outage until the estimated resume time
+	if w, ok := reg.Maintenance(now.Add(5 * time.Minute)); !ok || w.End != now.Add(10*time.Minute).Truncate(time.Second).UnixMicro() {
+		t.Errorf("Maintenance(+5m) = %+v, %v, want status outage", w, ok)
+	}
+	if _, ok := reg.Maintenance(now.Add(30 * time.Minute)); ok {
+		t.Error("Maintenance(+30m) = true, want false")
+	}
+	// Scheduled window; the ended one is dropped
+	if _, ok := reg.Maintenance(now.Add(90 * time.Minute)); !ok {
+		t.Error("Maintenance(+90m) = false, want scheduled window")
+	}
+	impl.state.mu.RLock()
+	windows := len(impl.state.maintenance)
+	impl.state.mu.RUnlock()
+	if windows != 1 {
+		t.Errorf("maintenance windows = %d, want 1", windows)
+	}
+
+	if update := receiveMetadata(t, ch); update.Source != SourceAnnouncements || len(update.Announcements) != 2 || len(update.Markets) != 0 {
+		t.Errorf("update = %+v, want two announcements", update)
+	}
+
+	// Only new or changed announcements are sent again
+	announcements[1]["status"] = "archived"
+	impl.refreshAnnouncements(context.Background())
+	if update := receiveMetadata(t, ch); len(update.Announcements) != 1 || update.Announcements[0].ID != "ann-2" {
+		t.Errorf("announcements = %+v, want only ann-2", update.Announcements)
+	}
+	impl.refreshAnnouncements(context.Background())
+	expectNoMetadata(t, ch)
+}
+
 func TestRegistryImpl_SetLifecycleSource(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 
 	ch := make(chan []byte)
@@ -553,7 +839,7 @@ func TestRegistryImpl_SetLifecycleSource(t *testing.T) {
 
 func TestRegistryImpl_Stop_NilCancel(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 
 	// Stop without Start should not panic
@@ -591,7 +877,7 @@ func TestRegistryImpl_StartAndStop(t *testing.T) {
 		PageSize:           1000,
 		InitialLoadTimeout: 5 * time.Minute,
 	}
-	client := api.NewClient(server.URL, "")
+	client := api.NewClient(server.URL, "", nil)
 	reg := NewRegistry(cfg, client, nil)
 
 	ctx := context.Background()
@@ -633,7 +919,7 @@ func TestRegistryImpl_Start_ExchangeInactive(t *testing.T) {
 	defer server.Close()
 
 	cfg := DefaultConfig()
-	client := api.NewClient(server.URL, "")
+	client := api.NewClient(server.URL, "", nil)
 	reg := NewRegistry(cfg, client, nil)
 
 	ctx := context.Background()
@@ -664,7 +950,7 @@ func TestConfig_ZeroValues(t *testing.T) {
 
 func TestRegistry_Interface(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 
 	// Verify that registryImpl implements Registry interface
@@ -675,7 +961,7 @@ func TestRegistry_Interface(t *testing.T) {
 
 func TestRegistryImpl_HandleLifecycleMessage_StatusChange(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 	impl := reg.(*registryImpl)
 
@@ -719,8 +1005,12 @@ func TestRegistryImpl_HandleLifecycleMessage_StatusChange(t *testing.T) {
 }
 
 func TestRegistryImpl_HandleLifecycleMessage_Settled(t *testing.T) {
+	// REST lookup fails; the settlement is still applied from the message
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient(server.URL, "", nil)
 	reg := NewRegistry(cfg, client, nil)
 	impl := reg.(*registryImpl)
 
@@ -758,11 +1048,14 @@ func TestRegistryImpl_HandleLifecycleMessage_Settled(t *testing.T) {
 	if market.Result != "yes" {
 		t.Errorf("Result = %q, want %q", market.Result, "yes")
 	}
+	if market.SettledTS != 1705328200000000 {
+		t.Errorf("SettledTS = %d, want 1705328200000000", market.SettledTS)
+	}
 }
 
 func TestRegistryImpl_HandleLifecycleMessage_InvalidJSON(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 	impl := reg.(*registryImpl)
 
@@ -774,7 +1067,7 @@ func TestRegistryImpl_HandleLifecycleMessage_InvalidJSON(t *testing.T) {
 
 func TestRegistryImpl_HandleLifecycleMessage_WrongType(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 	impl := reg.(*registryImpl)
 
@@ -792,7 +1085,7 @@ func TestRegistryImpl_HandleLifecycleMessage_WrongType(t *testing.T) {
 
 func TestRegistryImpl_HandleStatusChange_UnknownMarket(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 	impl := reg.(*registryImpl)
 
@@ -803,12 +1096,12 @@ func TestRegistryImpl_HandleStatusChange_UnknownMarket(t *testing.T) {
 
 func TestRegistryImpl_HandleSettled_UnknownMarket(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 	impl := reg.(*registryImpl)
 
 	// Settlement for unknown market should not panic
-	impl.handleSettled("UNKNOWN", "yes")
+	impl.handleSettled(context.Background(), "UNKNOWN", "yes", 0)
 	// No assertion - just verify it doesn't panic
 }
 
@@ -831,7 +1124,7 @@ func TestRegistryImpl_HandleMarketCreated(t *testing.T) {
 	defer server.Close()
 
 	cfg := DefaultConfig()
-	client := api.NewClient(server.URL, "")
+	client := api.NewClient(server.URL, "", nil)
 	reg := NewRegistry(cfg, client, nil)
 	impl := reg.(*registryImpl)
 
@@ -850,7 +1143,7 @@ func TestRegistryImpl_HandleMarketCreated(t *testing.T) {
 
 func TestRegistryImpl_HandleStatusChange_ToActive(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 	impl := reg.(*registryImpl)
 
@@ -878,7 +1171,7 @@ func TestRegistryImpl_HandleStatusChange_ToActive(t *testing.T) {
 
 func TestLifecycleLoop_ContextCancellation(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 	impl := reg.(*registryImpl)
 
@@ -910,7 +1203,7 @@ func TestLifecycleLoop_ContextCancellation(t *testing.T) {
 
 func TestLifecycleLoop_ChannelClosed(t *testing.T) {
 	cfg := DefaultConfig()
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	reg := NewRegistry(cfg, client, nil)
 	impl := reg.(*registryImpl)
 
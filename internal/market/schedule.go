@@ -0,0 +1,93 @@
+package market
+
+import (
+	"context"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/api"
+	"github.com/rickgao/kalshi-data/internal/model"
+)
+
+// announcementsPageSize is the number of most recent announcements fetched
+// on each refresh.
+const announcementsPageSize = 100
+
+// refreshExchange re-checks exchange status, the schedule and
+// announcements. Failures are logged; the previous values are kept.
+func (r *registryImpl) refreshExchange(ctx context.Context) {
+	if err := r.checkExchangeStatus(ctx); err != nil {
+		r.logger.Warn("failed to check exchange status", "err", err)
+	}
+	r.refreshSchedule(ctx)
+	r.refreshAnnouncements(ctx)
+}
+
+// refreshSchedule replaces the known maintenance windows with the
+// exchange schedule's closures and maintenance windows that have not ended.
+func (r *registryImpl) refreshSchedule(ctx context.Context) {
+	resp, err := r.rest.GetExchangeSchedule(ctx)
+	if err != nil {
+		r.logger.Warn("failed to fetch exchange schedule", "err", err)
+		return
+	}
+
+	now := time.Now().UnixMicro()
+	var windows []model.MaintenanceWindow
+	for _, w := range resp.Schedule.Windows() {
+		if w.End > now {
+			windows = append(windows, w)
+		}
+	}
+
+	r.state.mu.Lock()
+	known := make(map[model.MaintenanceWindow]struct{}, len(r.state.maintenance))
+	for _, w := range r.state.maintenance {
+		known[w] = struct{}{}
+	}
+	r.state.maintenance = windows
+	r.state.mu.Unlock()
+
+	for _, w := range windows {
+		if _, ok := known[w]; ok {
+			continue
+		}
+		r.logger.Info("exchange maintenance scheduled",
+			"start", time.UnixMicro(w.Start).UTC(),
+			"end", time.UnixMicro(w.End).UTC(),
+		)
+	}
+}
+
+// refreshAnnouncements fetches recent announcements and forwards new or
+// changed ones to the metadata channel.
+func (r *registryImpl) refreshAnnouncements(ctx context.Context) {
+	resp, err := r.rest.GetAnnouncements(ctx, api.GetAnnouncementsOptions{Limit: announcementsPageSize})
+	if err != nil {
+		r.logger.Warn("failed to fetch exchange announcements", "err", err)
+		return
+	}
+
+	var changed []model.Announcement
+	r.state.mu.Lock()
+	for _, a := range resp.Announcements {
+		if a.ID == "" {
+			continue
+		}
+		if status, ok := r.state.announcements[a.ID]; ok && status == a.Status {
+			continue
+		}
+		r.state.announcements[a.ID] = a.Status
+		changed = append(changed, a.ToModel())
+	}
+	r.state.mu.Unlock()
+
+	for _, a := range changed {
+		r.logger.Info("exchange announcement",
+			"id", a.ID,
+			"type", a.Type,
+			"status", a.Status,
+			"title", a.Title,
+		)
+	}
+	r.state.notifyAnnouncements(changed...)
+}
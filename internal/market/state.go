@@ -2,6 +2,7 @@ package market
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rickgao/kalshi-data/internal/model"
@@ -21,24 +22,64 @@ type registryState struct {
 	exchangeActive bool
 	tradingActive  bool
 
+	// Scheduled closures and maintenance from the exchange schedule, plus
+	// the outage reported by the status endpoint when it gives a resume
+	// time. Refreshed with each reconcile.
+	maintenance   []model.MaintenanceWindow
+	statusOutage  *model.MaintenanceWindow
+	announcements map[string]string // Announcement ID → status already sent
+
 	// Last successful REST sync timestamp.
 	lastSyncAt time.Time
 
 	// Output channel for Connection Manager.
 	changes chan MarketChange
 
+	// Output channel for Metadata Writer, fed once subscribed. Updates are
+	// queued per market in metadataQueue, guarded by metadataMu, and
+	// pumpMetadata hands them to metadata in order, so none is dropped
+	// while the writer is busy. A market already queued is replaced in
+	// place by its newer version, which keeps the queue bounded by the
+	// number of markets however far the writer falls behind.
+	metadata           chan MetadataUpdate
+	metadataMu         sync.Mutex
+	metadataQueue      []metadataItem
+	metadataQueued     map[string]int64 // Ticker → position in metadataQueue, counted from metadataPopped
+	metadataPopped     int64            // Items taken off the front of metadataQueue so far
+	metadataSends      int64            // sendMetadata calls so far, numbering each update's items
+	metadataReady      chan struct{}
+	metadataSubscribed atomic.Bool
+	metadataStop       chan struct{}
+	metadataStopOnce   sync.Once
+
 	// Input channel from Connection Manager (market_lifecycle messages).
 	lifecycle <-chan []byte
 }
 
 func newState() *registryState {
 	return &registryState{
-		markets:   make(map[string]*model.Market),
-		activeSet: make(map[string]struct{}),
-		changes:   make(chan MarketChange, ChangeBufferSize),
+		markets:        make(map[string]*model.Market),
+		activeSet:      make(map[string]struct{}),
+		announcements:  make(map[string]string),
+		changes:        make(chan MarketChange, ChangeBufferSize),
+		metadata:       make(chan MetadataUpdate, MetadataBufferSize),
+		metadataQueued: make(map[string]int64),
+		metadataReady:  make(chan struct{}, 1),
+		metadataStop:   make(chan struct{}),
 	}
 }
 
+// metadataItem is one queued market, or the announcements of one update,
+// with the source and time of the update that carried it. send numbers the
+// sendMetadata call so pumpMetadata can regroup an update's items.
+type metadataItem struct {
+	market        *model.Market
+	announcements []model.Announcement
+	source        string
+	observedAt    time.Time
+	send          int64
+}
+
 // getMarket returns a market by ticker (read-locked).
 func (s *registryState) getMarket(ticker string) (model.Market, bool) {
 	s.mu.RLock()
@@ -121,6 +162,149 @@ func (s *registryState) notifyChange(change MarketChange) {
 	}
 }
 
+// maintenanceAt returns the maintenance window covering ts (µs), if any.
+func (s *registryState) maintenanceAt(ts int64) (model.MaintenanceWindow, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if w := s.statusOutage; w != nil && ts >= w.Start && ts < w.End {
+		return *w, true
+	}
+	for _, w := range s.maintenance {
+		if ts >= w.Start && ts < w.End {
+			return w, true
+		}
+	}
+	return model.MaintenanceWindow{}, false
+}
+
+// notifyMetadata queues market data for the metadata channel (non-blocking).
+// Does nothing until SubscribeMetadata is called.
+func (s *registryState) notifyMetadata(source string, markets ...model.Market) {
+	if len(markets) == 0 {
+		return
+	}
+	s.sendMetadata(MetadataUpdate{
+		Markets:    markets,
+		Source:     source,
+		ObservedAt: time.Now(),
+	})
+}
+
+// notifyAnnouncements queues exchange announcements for the metadata
+// channel (non-blocking). Does nothing until SubscribeMetadata is called.
+func (s *registryState) notifyAnnouncements(announcements ...model.Announcement) {
+	if len(announcements) == 0 {
+		return
+	}
+	s.sendMetadata(MetadataUpdate{
+		Announcements: announcements,
+		Source:        SourceAnnouncements,
+		ObservedAt:    time.Now(),
+	})
+}
+
+// sendMetadata queues an update and wakes pumpMetadata. Each market
+// replaces any version of it still queued, keeping that version's place.
+func (s *registryState) sendMetadata(update MetadataUpdate) {
+	if !s.metadataSubscribed.Load() {
+		return
+	}
+
+	s.metadataMu.Lock()
+	s.metadataSends++
+	for _, m := range update.Markets {
+		item := metadataItem{
+			market:     &m,
+			source:     update.Source,
+			observedAt: update.ObservedAt,
+			send:       s.metadataSends,
+		}
+		ticker := item.market.Ticker
+		if pos, ok := s.metadataQueued[ticker]; ok {
+			s.metadataQueue[pos-s.metadataPopped] = item
+			continue
+		}
+		s.metadataQueued[ticker] = s.metadataPopped + int64(len(s.metadataQueue))
+		s.metadataQueue = append(s.metadataQueue, item)
+	}
+	if len(update.Announcements) > 0 {
+		s.metadataQueue = append(s.metadataQueue, metadataItem{
+			announcements: update.Announcements,
+			source:        update.Source,
+			observedAt:    update.ObservedAt,
+			send:          s.metadataSends,
+		})
+	}
+	s.metadataMu.Unlock()
+
+	select {
+	case s.metadataReady <- struct{}{}:
+	default:
+	}
+}
+
+// subscribeMetadata starts pumpMetadata on first call and returns the
+// metadata channel.
+func (s *registryState) subscribeMetadata() <-chan MetadataUpdate {
+	if s.metadataSubscribed.CompareAndSwap(false, true) {
+		go s.pumpMetadata()
+	}
+	return s.metadata
+}
+
+// stopMetadata stops pumpMetadata. Updates still queued are discarded.
+func (s *registryState) stopMetadata() {
+	s.metadataStopOnce.Do(func() { close(s.metadataStop) })
+}
+
+// pumpMetadata moves queued updates to the metadata channel in order,
+// blocking while the channel is full, until stopMetadata is called.
+func (s *registryState) pumpMetadata() {
+	for {
+		s.metadataMu.Lock()
+		if len(s.metadataQueue) == 0 {
+			s.metadataMu.Unlock()
+			select {
+			case <-s.metadataReady:
+				continue
+			case <-s.metadataStop:
+				return
+			}
+		}
+		update := s.popMetadata()
+		s.metadataMu.Unlock()
+
+		select {
+		case s.metadata <- update:
+		case <-s.metadataStop:
+			return
+		}
+	}
+}
+
+// popMetadata takes the item at the front of the queue, with the items
+// after it from the same sendMetadata call, as one update. Callers hold
+// metadataMu and ensure the queue is non-empty.
+func (s *registryState) popMetadata() MetadataUpdate {
+	head := s.metadataQueue[0]
+	update := MetadataUpdate{Source: head.source, ObservedAt: head.observedAt}
+
+	n := 0
+	for ; n < len(s.metadataQueue) && s.metadataQueue[n].send == head.send; n++ {
+		item := s.metadataQueue[n]
+		if item.market != nil {
+			update.Markets = append(update.Markets, *item.market)
+			delete(s.metadataQueued, item.market.Ticker)
+		}
+		update.Announcements = append(update.Announcements, item.announcements...)
+		s.metadataQueue[n] = metadataItem{}
+	}
+	s.metadataQueue = s.metadataQueue[n:]
+	s.metadataPopped += int64(n)
+	return update
+}
+
 // isActive returns true if the status means the market is tradeable.
 func isActive(status string) bool {
 	return status == "active" || status == "open"
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/rickgao/kalshi-data/internal/api"
+	"github.com/rickgao/kalshi-data/internal/model"
 )
 
 // initialSync fetches active markets from REST API on startup.
@@ -15,6 +16,8 @@ func (r *registryImpl) initialSync(ctx context.Context) error {
 	if err := r.checkExchangeStatus(ctx); err != nil {
 		return err
 	}
+	r.refreshSchedule(ctx)
+	r.refreshAnnouncements(ctx)
 
 	r.logger.Info("starting initial market sync (open + unopened markets)")
 	start := time.Now()
@@ -44,9 +47,12 @@ func (r *registryImpl) initialSync(ctx context.Context) error {
 	apiMarkets = append(apiMarkets, openMarkets...)
 	apiMarkets = append(apiMarkets, unopenedMarkets...)
 
+	markets := make([]model.Market, 0, len(apiMarkets))
+
 	r.state.mu.Lock()
 	for _, am := range apiMarkets {
 		m := am.ToModel()
+		markets = append(markets, m)
 		r.state.upsertMarketLocked(m)
 
 		if isActive(m.MarketStatus) {
@@ -62,6 +68,8 @@ func (r *registryImpl) initialSync(ctx context.Context) error {
 	r.state.lastSyncAt = time.Now()
 	r.state.mu.Unlock()
 
+	r.state.notifyMetadata(SourceSync, markets...)
+
 	r.logger.Info("initial sync complete",
 		"total_markets", len(apiMarkets),
 		"active_markets", len(r.state.activeSet),
@@ -71,16 +79,26 @@ func (r *registryImpl) initialSync(ctx context.Context) error {
 	return nil
 }
 
-// checkExchangeStatus verifies the exchange is active.
+// checkExchangeStatus verifies the exchange is active. An inactive exchange
+// with an estimated resume time counts as maintenance until then.
 func (r *registryImpl) checkExchangeStatus(ctx context.Context) error {
 	status, err := r.rest.GetExchangeStatus(ctx)
 	if err != nil {
 		return err
 	}
 
+	var outage *model.MaintenanceWindow
+	if !status.ExchangeActive {
+		now := time.Now().UnixMicro()
+		if resume := api.ParseTimestamp(status.EstimatedResumeTime); resume > now {
+			outage = &model.MaintenanceWindow{Start: now, End: resume}
+		}
+	}
+
 	r.state.mu.Lock()
 	r.state.exchangeActive = status.ExchangeActive
 	r.state.tradingActive = status.TradingActive
+	r.state.statusOutage = outage
 	r.state.mu.Unlock()
 
 	if !status.ExchangeActive {
@@ -103,6 +121,7 @@ func (r *registryImpl) reconciliationLoop(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+			r.refreshExchange(ctx)
 			r.reconcile(ctx)
 		}
 	}
@@ -136,10 +155,12 @@ func (r *registryImpl) reconcile(ctx context.Context) {
 	apiMarkets = append(apiMarkets, unopenedMarkets...)
 
 	var created, changed int
+	markets := make([]model.Market, 0, len(apiMarkets))
 
 	r.state.mu.Lock()
 	for _, am := range apiMarkets {
 		m := am.ToModel()
+		markets = append(markets, m)
 		existing, ok := r.state.markets[m.Ticker]
 
 		if !ok {
@@ -175,6 +196,9 @@ func (r *registryImpl) reconcile(ctx context.Context) {
 	r.state.lastSyncAt = time.Now()
 	r.state.mu.Unlock()
 
+	// Every fetched market, so metadata such as volume stays current.
+	r.state.notifyMetadata(SourceReconcile, markets...)
+
 	if created > 0 || changed > 0 {
 		r.logger.Info("reconciliation found changes",
 			"created", created,
@@ -252,7 +276,7 @@ func (r *registryImpl) handleLifecycleMessage(ctx context.Context, msg []byte) {
 
 	case "settled":
 		// Market settled
-		r.handleSettled(ticker, lm.Msg.Result)
+		r.handleSettled(ctx, ticker, lm.Msg.Result, lm.Msg.Timestamp)
 
 	default:
 		r.logger.Warn("unknown lifecycle event type", "type", eventType)
@@ -274,6 +298,8 @@ func (r *registryImpl) handleMarketCreated(ctx context.Context, ticker, status s
 	r.state.upsertMarketLocked(m)
 	r.state.mu.Unlock()
 
+	r.state.notifyMetadata(SourceLifecycle, m)
+
 	// Notify if active
 	if isActive(m.MarketStatus) {
 		r.state.notifyChange(MarketChange{
@@ -317,6 +343,8 @@ func (r *registryImpl) handleStatusChange(ticker, oldStatus, newStatus string) {
 	marketCopy := *existing
 	r.state.mu.Unlock()
 
+	r.state.notifyMetadata(SourceLifecycle, marketCopy)
+
 	// Notify of change
 	r.state.notifyChange(MarketChange{
 		Ticker:    ticker,
@@ -333,14 +361,31 @@ func (r *registryImpl) handleStatusChange(ticker, oldStatus, newStatus string) {
 	)
 }
 
-// handleSettled handles a market being settled.
-func (r *registryImpl) handleSettled(ticker, result string) {
+// settleFetchAttempts is how many times a settled market is fetched from
+// REST before its enrichment is given up.
+const settleFetchAttempts = 5
+
+// handleSettled handles a market being settled. ts is the lifecycle
+// message timestamp in seconds.
+//
+// The lifecycle message only carries the result, which is recorded at once
+// so the settlement row is always written. Settlement value and final
+// volume come from REST afterwards; if every fetch fails the deduplicator
+// fills the gaps from other gatherers.
+func (r *registryImpl) handleSettled(ctx context.Context, ticker, result string, ts int64) {
 	r.state.mu.Lock()
 	existing, ok := r.state.markets[ticker]
+	var marketCopy model.Market
 	if ok {
 		existing.MarketStatus = "settled"
-		existing.Result = result
+		if result != "" {
+			existing.Result = result
+		}
+		if ts > 0 {
+			existing.SettledTS = ts * 1_000_000
+		}
 		delete(r.state.activeSet, ticker)
+		marketCopy = *existing
 	}
 	r.state.mu.Unlock()
 
@@ -348,6 +393,8 @@ func (r *registryImpl) handleSettled(ticker, result string) {
 		return
 	}
 
+	r.state.notifyMetadata(SourceLifecycle, marketCopy)
+
 	// Notify of settlement
 	r.state.notifyChange(MarketChange{
 		Ticker:    ticker,
@@ -359,8 +406,60 @@ func (r *registryImpl) handleSettled(ticker, result string) {
 
 	r.logger.Debug("market settled",
 		"ticker", ticker,
-		"result", result,
+		"result", marketCopy.Result,
 	)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.enrichSettled(ctx, ticker, result, ts)
+	}()
+}
+
+// enrichSettled fetches a settled market from REST, retrying on failure,
+// and sends it again with its settlement value and final volume. The
+// lifecycle result and timestamp win over the fetched ones.
+func (r *registryImpl) enrichSettled(ctx context.Context, ticker, result string, ts int64) {
+	var apiMarket *api.APIMarket
+	delay := r.settleRetry
+	for attempt := 1; ; attempt++ {
+		var err error
+		apiMarket, err = r.fetchMarket(ctx, ticker)
+		if err == nil {
+			break
+		}
+		if attempt == settleFetchAttempts || ctx.Err() != nil {
+			r.logger.Warn("failed to fetch settled market", "ticker", ticker, "attempts", attempt, "error", err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	r.state.mu.Lock()
+	existing, ok := r.state.markets[ticker]
+	var marketCopy model.Market
+	if ok {
+		*existing = apiMarket.ToModel()
+		existing.MarketStatus = "settled"
+		if result != "" || existing.Result == "" {
+			existing.Result = result
+		}
+		if ts > 0 {
+			existing.SettledTS = ts * 1_000_000
+		}
+		delete(r.state.activeSet, ticker)
+		marketCopy = *existing
+	}
+	r.state.mu.Unlock()
+
+	if ok {
+		r.state.notifyMetadata(SourceLifecycle, marketCopy)
+	}
 }
 
 // fetchMarket fetches a single market from REST API.
@@ -0,0 +1,781 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rickgao/kalshi-data/internal/api"
+	"github.com/rickgao/kalshi-data/internal/book"
+	"github.com/rickgao/kalshi-data/internal/connection"
+	"github.com/rickgao/kalshi-data/internal/dedup"
+	"github.com/rickgao/kalshi-data/internal/journal"
+	"github.com/rickgao/kalshi-data/internal/router"
+	"github.com/rickgao/kalshi-data/internal/writer"
+)
+
+// Collectors read component Stats() at scrape time and emit const metrics.
+// Stats counters are cumulative, so they map directly onto Prometheus counters.
+
+// managerCollector exports connection.ManagerStats.
+type managerCollector struct {
+	stats func() connection.ManagerStats
+}
+
+var (
+	managerConnectionsHealthy = prometheus.NewDesc(
+		"conn_manager_connections_healthy",
+		"Connected WebSocket connections.",
+		nil, nil,
+	)
+	managerOrderbookConns = prometheus.NewDesc(
+		"conn_manager_orderbook_connections",
+		"Open orderbook connections.",
+		nil, nil,
+	)
+	managerSubscriptions = prometheus.NewDesc(
+		"conn_manager_subscriptions",
+		"Active subscriptions.",
+		nil, nil,
+	)
+	managerMarkets = prometheus.NewDesc(
+		"conn_manager_markets",
+		"Markets with orderbook subscriptions.",
+		nil, nil,
+	)
+	managerSequenceGaps = prometheus.NewDesc(
+		"conn_manager_sequence_gaps_total",
+		"Orderbook sequence gaps detected.",
+		nil, nil,
+	)
+	managerGapRecoveries = prometheus.NewDesc(
+		"conn_manager_gap_recoveries_total",
+		"Sequence gap recovery outcomes.",
+		[]string{"action"}, nil,
+	)
+	managerResyncs = prometheus.NewDesc(
+		"conn_manager_resyncs_total",
+		"Requested orderbook resync outcomes.",
+		[]string{"result"}, nil,
+	)
+	managerMaintenance = prometheus.NewDesc(
+		"conn_manager_exchange_maintenance",
+		"1 while the exchange is in a maintenance window.",
+		nil, nil,
+	)
+	managerReconnectsDeferred = prometheus.NewDesc(
+		"conn_manager_reconnects_deferred_total",
+		"Reconnects deferred until a maintenance window ended.",
+		nil, nil,
+	)
+	managerMigrations = prometheus.NewDesc(
+		"conn_manager_migrations_total",
+		"Markets moved between orderbook connections by the rebalancer.",
+		[]string{"result"}, nil,
+	)
+	managerConnMessageRate = prometheus.NewDesc(
+		"conn_manager_connection_message_rate",
+		"Data messages per second on a connection over the last rebalance interval.",
+		[]string{"conn", "role"}, nil,
+	)
+	managerOverflows = prometheus.NewDesc(
+		"conn_manager_router_overflows_total",
+		"Data messages that found the router channel full.",
+		nil, nil,
+	)
+	managerSpilled = prometheus.NewDesc(
+		"conn_manager_spilled_total",
+		"Data messages queued to the spill file.",
+		nil, nil,
+	)
+	managerSpillPending = prometheus.NewDesc(
+		"conn_manager_spill_pending",
+		"Spilled data messages not yet delivered to the router.",
+		nil, nil,
+	)
+	managerDropped = prometheus.NewDesc(
+		"conn_manager_messages_dropped_total",
+		"Data messages dropped before reaching the router.",
+		[]string{"conn", "role"}, nil,
+	)
+)
+
+func (c *managerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- managerConnectionsHealthy
+	ch <- managerOrderbookConns
+	ch <- managerSubscriptions
+	ch <- managerMarkets
+	ch <- managerSequenceGaps
+	ch <- managerGapRecoveries
+	ch <- managerResyncs
+	ch <- managerMaintenance
+	ch <- managerReconnectsDeferred
+	ch <- managerMigrations
+	ch <- managerConnMessageRate
+	ch <- managerOverflows
+	ch <- managerSpilled
+	ch <- managerSpillPending
+	ch <- managerDropped
+}
+
+func (c *managerCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(managerConnectionsHealthy, prometheus.GaugeValue, float64(s.ConnectedCount))
+	ch <- prometheus.MustNewConstMetric(managerOrderbookConns, prometheus.GaugeValue, float64(s.OrderbookConns))
+	ch <- prometheus.MustNewConstMetric(managerSubscriptions, prometheus.GaugeValue, float64(s.TotalSubscriptions))
+	ch <- prometheus.MustNewConstMetric(managerMarkets, prometheus.GaugeValue, float64(s.MarketsSubscribed))
+	ch <- prometheus.MustNewConstMetric(managerSequenceGaps, prometheus.CounterValue, float64(s.SequenceGaps))
+	ch <- prometheus.MustNewConstMetric(managerGapRecoveries, prometheus.CounterValue, float64(s.GapResubscribes), string(connection.GapResubscribed))
+	ch <- prometheus.MustNewConstMetric(managerGapRecoveries, prometheus.CounterValue, float64(s.GapRateLimited), string(connection.GapRateLimited))
+	ch <- prometheus.MustNewConstMetric(managerGapRecoveries, prometheus.CounterValue, float64(s.GapFailures), string(connection.GapFailed))
+	ch <- prometheus.MustNewConstMetric(managerGapRecoveries, prometheus.CounterValue, float64(s.GapSkipped), string(connection.GapSkipped))
+	ch <- prometheus.MustNewConstMetric(managerResyncs, prometheus.CounterValue, float64(s.Resyncs), "resubscribed")
+	ch <- prometheus.MustNewConstMetric(managerResyncs, prometheus.CounterValue, float64(s.ResyncFailures), "failed")
+	maintenance := 0.0
+	if s.InMaintenance {
+		maintenance = 1
+	}
+	ch <- prometheus.MustNewConstMetric(managerMaintenance, prometheus.GaugeValue, maintenance)
+	ch <- prometheus.MustNewConstMetric(managerReconnectsDeferred, prometheus.CounterValue, float64(s.ReconnectsDeferred))
+	ch <- prometheus.MustNewConstMetric(managerMigrations, prometheus.CounterValue, float64(s.Migrations), "moved")
+	ch <- prometheus.MustNewConstMetric(managerMigrations, prometheus.CounterValue, float64(s.MigrationFailures), "failed")
+	for _, conn := range s.Connections {
+		ch <- prometheus.MustNewConstMetric(managerConnMessageRate, prometheus.GaugeValue, conn.MessageRate, strconv.Itoa(conn.ID), string(conn.Role))
+	}
+	ch <- prometheus.MustNewConstMetric(managerOverflows, prometheus.CounterValue, float64(s.Overflows))
+	ch <- prometheus.MustNewConstMetric(managerSpilled, prometheus.CounterValue, float64(s.Spilled))
+	ch <- prometheus.MustNewConstMetric(managerSpillPending, prometheus.GaugeValue, float64(s.SpillPending))
+	for _, d := range s.Dropped {
+		ch <- prometheus.MustNewConstMetric(managerDropped, prometheus.CounterValue, float64(d.Count), strconv.Itoa(d.ConnID), string(d.Role))
+	}
+}
+
+// routerCollector exports router.RouterStats and its GrowableBuffer stats.
+type routerCollector struct {
+	stats func() router.RouterStats
+}
+
+var (
+	routerReceived = prometheus.NewDesc(
+		"router_messages_received_total",
+		"Messages received from Connection Manager.",
+		nil, nil,
+	)
+	routerRouted = prometheus.NewDesc(
+		"router_messages_routed_total",
+		"Messages successfully routed to writer buffers.",
+		nil, nil,
+	)
+	routerParseErrors = prometheus.NewDesc(
+		"router_parse_errors_total",
+		"JSON parse failures.",
+		nil, nil,
+	)
+	routerUnknown = prometheus.NewDesc(
+		"router_unknown_messages_total",
+		"Messages with unknown type.",
+		nil, nil,
+	)
+	bufferItems = prometheus.NewDesc(
+		"router_buffer_items",
+		"Messages waiting in the writer buffer.",
+		[]string{"buffer"}, nil,
+	)
+	bufferCapacity = prometheus.NewDesc(
+		"router_buffer_capacity",
+		"Current writer buffer capacity.",
+		[]string{"buffer"}, nil,
+	)
+	bufferReceived = prometheus.NewDesc(
+		"router_buffer_received_total",
+		"Messages sent into the writer buffer.",
+		[]string{"buffer"}, nil,
+	)
+	bufferSent = prometheus.NewDesc(
+		"router_buffer_sent_total",
+		"Messages taken from the writer buffer.",
+		[]string{"buffer"}, nil,
+	)
+	bufferResizes = prometheus.NewDesc(
+		"router_buffer_resizes_total",
+		"Times the writer buffer doubled its capacity.",
+		[]string{"buffer"}, nil,
+	)
+)
+
+func (c *routerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- routerReceived
+	ch <- routerRouted
+	ch <- routerParseErrors
+	ch <- routerUnknown
+	ch <- bufferItems
+	ch <- bufferCapacity
+	ch <- bufferReceived
+	ch <- bufferSent
+	ch <- bufferResizes
+}
+
+func (c *routerCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(routerReceived, prometheus.CounterValue, float64(s.MessagesReceived))
+	ch <- prometheus.MustNewConstMetric(routerRouted, prometheus.CounterValue, float64(s.MessagesRouted))
+	ch <- prometheus.MustNewConstMetric(routerParseErrors, prometheus.CounterValue, float64(s.ParseErrors))
+	ch <- prometheus.MustNewConstMetric(routerUnknown, prometheus.CounterValue, float64(s.UnknownMessages))
+
+	buffers := map[string]router.BufferStats{
+		"orderbook": s.OrderbookBuffer,
+		"trade":     s.TradeBuffer,
+		"ticker":    s.TickerBuffer,
+	}
+	if s.BookBuffer.Capacity > 0 {
+		buffers["book"] = s.BookBuffer
+	}
+
+	for name, b := range buffers {
+		ch <- prometheus.MustNewConstMetric(bufferItems, prometheus.GaugeValue, float64(b.Count), name)
+		ch <- prometheus.MustNewConstMetric(bufferCapacity, prometheus.GaugeValue, float64(b.Capacity), name)
+		ch <- prometheus.MustNewConstMetric(bufferReceived, prometheus.CounterValue, float64(b.TotalReceived), name)
+		ch <- prometheus.MustNewConstMetric(bufferSent, prometheus.CounterValue, float64(b.TotalSent), name)
+		ch <- prometheus.MustNewConstMetric(bufferResizes, prometheus.CounterValue, float64(b.ResizeCount), name)
+	}
+}
+
+// bookCollector exports book.EngineStats.
+type bookCollector struct {
+	stats func() book.EngineStats
+}
+
+var (
+	bookMarkets = prometheus.NewDesc(
+		"book_markets",
+		"Markets with an in-memory book.",
+		nil, nil,
+	)
+	bookMarketsInvalid = prometheus.NewDesc(
+		"book_markets_invalid",
+		"Books waiting for a snapshot after an impossible state.",
+		nil, nil,
+	)
+	bookSnapshots = prometheus.NewDesc(
+		"book_snapshots_applied_total",
+		"Snapshots applied to in-memory books.",
+		nil, nil,
+	)
+	bookDeltas = prometheus.NewDesc(
+		"book_deltas_applied_total",
+		"Deltas applied to in-memory books.",
+		nil, nil,
+	)
+	bookIgnored = prometheus.NewDesc(
+		"book_messages_ignored_total",
+		"Stale, duplicate or pre-snapshot deltas skipped.",
+		nil, nil,
+	)
+	bookInvalidations = prometheus.NewDesc(
+		"book_invalidations_total",
+		"Books invalidated by an impossible state.",
+		[]string{"reason"}, nil,
+	)
+	bookErrors = prometheus.NewDesc(
+		"book_errors_total",
+		"Malformed orderbook messages.",
+		nil, nil,
+	)
+	bookPruned = prometheus.NewDesc(
+		"book_markets_pruned_total",
+		"Books dropped because their market stopped trading.",
+		nil, nil,
+	)
+)
+
+func (c *bookCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- bookMarkets
+	ch <- bookMarketsInvalid
+	ch <- bookSnapshots
+	ch <- bookDeltas
+	ch <- bookIgnored
+	ch <- bookInvalidations
+	ch <- bookErrors
+	ch <- bookPruned
+}
+
+func (c *bookCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(bookMarkets, prometheus.GaugeValue, float64(s.Books))
+	ch <- prometheus.MustNewConstMetric(bookMarketsInvalid, prometheus.GaugeValue, float64(s.InvalidBooks))
+	ch <- prometheus.MustNewConstMetric(bookSnapshots, prometheus.CounterValue, float64(s.Snapshots))
+	ch <- prometheus.MustNewConstMetric(bookDeltas, prometheus.CounterValue, float64(s.Deltas))
+	ch <- prometheus.MustNewConstMetric(bookIgnored, prometheus.CounterValue, float64(s.Ignored))
+	ch <- prometheus.MustNewConstMetric(bookInvalidations, prometheus.CounterValue, float64(s.SequenceGaps), "sequence_gap")
+	ch <- prometheus.MustNewConstMetric(bookInvalidations, prometheus.CounterValue, float64(s.NegativeSizes), "negative_size")
+	ch <- prometheus.MustNewConstMetric(bookInvalidations, prometheus.CounterValue, float64(s.CrossedBooks), "crossed")
+	ch <- prometheus.MustNewConstMetric(bookErrors, prometheus.CounterValue, float64(s.Errors))
+	ch <- prometheus.MustNewConstMetric(bookPruned, prometheus.CounterValue, float64(s.Pruned))
+}
+
+// derivedCollector exports book.DerivedStats.
+type derivedCollector struct {
+	stats func() book.DerivedStats
+}
+
+var (
+	derivedCycles = prometheus.NewDesc(
+		"book_derived_cycles_total",
+		"Derived snapshot cycles run.",
+		nil, nil,
+	)
+	derivedEmitted = prometheus.NewDesc(
+		"book_derived_snapshots_total",
+		"Derived snapshots handed to the snapshot writer.",
+		nil, nil,
+	)
+	derivedIdle = prometheus.NewDesc(
+		"book_derived_idle_total",
+		"Books skipped for having fewer changes than min_changes.",
+		nil, nil,
+	)
+	derivedErrors = prometheus.NewDesc(
+		"book_derived_errors_total",
+		"Derived snapshots rejected by the snapshot writer.",
+		nil, nil,
+	)
+)
+
+func (c *derivedCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- derivedCycles
+	ch <- derivedEmitted
+	ch <- derivedIdle
+	ch <- derivedErrors
+}
+
+func (c *derivedCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(derivedCycles, prometheus.CounterValue, float64(s.Cycles))
+	ch <- prometheus.MustNewConstMetric(derivedEmitted, prometheus.CounterValue, float64(s.Emitted))
+	ch <- prometheus.MustNewConstMetric(derivedIdle, prometheus.CounterValue, float64(s.Idle))
+	ch <- prometheus.MustNewConstMetric(derivedErrors, prometheus.CounterValue, float64(s.Errors))
+}
+
+// crossCheckCollector exports book.CrossCheckStats.
+type crossCheckCollector struct {
+	stats func() book.CrossCheckStats
+}
+
+var (
+	crossCheckChecks = prometheus.NewDesc(
+		"book_crosscheck_checks_total",
+		"REST snapshots compared with an in-memory book.",
+		nil, nil,
+	)
+	crossCheckUnchecked = prometheus.NewDesc(
+		"book_crosscheck_unchecked_total",
+		"REST snapshots with no valid in-memory book to compare.",
+		nil, nil,
+	)
+	crossCheckDivergences = prometheus.NewDesc(
+		"book_crosscheck_divergences_total",
+		"REST snapshots that diverged from the in-memory book.",
+		nil, nil,
+	)
+	crossCheckUnconfirmed = prometheus.NewDesc(
+		"book_crosscheck_unconfirmed_total",
+		"Divergences not repeated on consecutive checks, so not resynced.",
+		nil, nil,
+	)
+	crossCheckDivergentLevels = prometheus.NewDesc(
+		"book_crosscheck_divergent_levels_total",
+		"Price levels that diverged across all checks.",
+		nil, nil,
+	)
+	crossCheckMaxLevelDiff = prometheus.NewDesc(
+		"book_crosscheck_max_level_diff",
+		"Largest level size difference seen.",
+		nil, nil,
+	)
+	crossCheckResyncs = prometheus.NewDesc(
+		"book_crosscheck_resyncs_total",
+		"Resync requests after a divergence.",
+		[]string{"result"}, nil,
+	)
+)
+
+func (c *crossCheckCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- crossCheckChecks
+	ch <- crossCheckUnchecked
+	ch <- crossCheckDivergences
+	ch <- crossCheckUnconfirmed
+	ch <- crossCheckDivergentLevels
+	ch <- crossCheckMaxLevelDiff
+	ch <- crossCheckResyncs
+}
+
+func (c *crossCheckCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(crossCheckChecks, prometheus.CounterValue, float64(s.Checks))
+	ch <- prometheus.MustNewConstMetric(crossCheckUnchecked, prometheus.CounterValue, float64(s.Unchecked))
+	ch <- prometheus.MustNewConstMetric(crossCheckDivergences, prometheus.CounterValue, float64(s.Divergences))
+	ch <- prometheus.MustNewConstMetric(crossCheckUnconfirmed, prometheus.CounterValue, float64(s.Unconfirmed))
+	ch <- prometheus.MustNewConstMetric(crossCheckDivergentLevels, prometheus.CounterValue, float64(s.DivergentLevels))
+	ch <- prometheus.MustNewConstMetric(crossCheckMaxLevelDiff, prometheus.GaugeValue, float64(s.MaxLevelDiff))
+	ch <- prometheus.MustNewConstMetric(crossCheckResyncs, prometheus.CounterValue, float64(s.Resyncs), "requested")
+	ch <- prometheus.MustNewConstMetric(crossCheckResyncs, prometheus.CounterValue, float64(s.ResyncErrors), "refused")
+}
+
+// journalCollector exports journal.Stats.
+type journalCollector struct {
+	stats func() journal.Stats
+}
+
+var (
+	journalRecords = prometheus.NewDesc(
+		"journal_records_total",
+		"Raw messages written to the journal.",
+		nil, nil,
+	)
+	journalSkipped = prometheus.NewDesc(
+		"journal_skipped_total",
+		"Raw messages forwarded without being journaled.",
+		nil, nil,
+	)
+	journalBytes = prometheus.NewDesc(
+		"journal_bytes_total",
+		"Uncompressed bytes written to the journal.",
+		nil, nil,
+	)
+	journalSegments = prometheus.NewDesc(
+		"journal_segments_total",
+		"Journal segments opened.",
+		nil, nil,
+	)
+	journalDeleted = prometheus.NewDesc(
+		"journal_segments_deleted_total",
+		"Journal segments removed by retention.",
+		nil, nil,
+	)
+	journalErrors = prometheus.NewDesc(
+		"journal_errors_total",
+		"Journal write, flush, rotate or retention errors.",
+		nil, nil,
+	)
+)
+
+func (c *journalCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- journalRecords
+	ch <- journalSkipped
+	ch <- journalBytes
+	ch <- journalSegments
+	ch <- journalDeleted
+	ch <- journalErrors
+}
+
+func (c *journalCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(journalRecords, prometheus.CounterValue, float64(s.Records))
+	ch <- prometheus.MustNewConstMetric(journalSkipped, prometheus.CounterValue, float64(s.Skipped))
+	ch <- prometheus.MustNewConstMetric(journalBytes, prometheus.CounterValue, float64(s.Bytes))
+	ch <- prometheus.MustNewConstMetric(journalSegments, prometheus.CounterValue, float64(s.Segments))
+	ch <- prometheus.MustNewConstMetric(journalDeleted, prometheus.CounterValue, float64(s.Deleted))
+	ch <- prometheus.MustNewConstMetric(journalErrors, prometheus.CounterValue, float64(s.Errors))
+}
+
+// rateLimitCollector exports api.RateLimitStats.
+type rateLimitCollector struct {
+	stats func() api.RateLimitStats
+}
+
+var (
+	apiThrottleWaits = prometheus.NewDesc(
+		"api_throttle_waits_total",
+		"REST requests delayed by the client-side rate limiter.",
+		[]string{"tier"}, nil,
+	)
+	apiThrottleWaitSeconds = prometheus.NewDesc(
+		"api_throttle_wait_seconds_total",
+		"Time REST requests were delayed by the client-side rate limiter.",
+		[]string{"tier"}, nil,
+	)
+	apiRateLimited = prometheus.NewDesc(
+		"api_rate_limited_total",
+		"HTTP 429 responses from the Kalshi REST API.",
+		nil, nil,
+	)
+	apiRateLimitPauses = prometheus.NewDesc(
+		"api_rate_limit_pauses_total",
+		"429 responses whose Retry-After paused all REST requests.",
+		nil, nil,
+	)
+)
+
+func (c *rateLimitCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- apiThrottleWaits
+	ch <- apiThrottleWaitSeconds
+	ch <- apiRateLimited
+	ch <- apiRateLimitPauses
+}
+
+func (c *rateLimitCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(apiThrottleWaits, prometheus.CounterValue, float64(s.ReadWaits), api.TierRead.String())
+	ch <- prometheus.MustNewConstMetric(apiThrottleWaits, prometheus.CounterValue, float64(s.WriteWaits), api.TierWrite.String())
+	ch <- prometheus.MustNewConstMetric(apiThrottleWaitSeconds, prometheus.CounterValue, s.ReadWaitTime.Seconds(), api.TierRead.String())
+	ch <- prometheus.MustNewConstMetric(apiThrottleWaitSeconds, prometheus.CounterValue, s.WriteWaitTime.Seconds(), api.TierWrite.String())
+	ch <- prometheus.MustNewConstMetric(apiRateLimited, prometheus.CounterValue, float64(s.RateLimited))
+	ch <- prometheus.MustNewConstMetric(apiRateLimitPauses, prometheus.CounterValue, float64(s.Pauses))
+}
+
+// dedupCollector exports dedup.Stats.
+type dedupCollector struct {
+	stats func() dedup.Stats
+}
+
+var (
+	dedupCycles = prometheus.NewDesc(
+		"dedup_cycles_total",
+		"Sync cycles run across all tables.",
+		nil, nil,
+	)
+	dedupRowsRead = prometheus.NewDesc(
+		"dedup_rows_read_total",
+		"Rows read from gatherers.",
+		[]string{"gatherer"}, nil,
+	)
+	dedupRowsWritten = prometheus.NewDesc(
+		"dedup_rows_written_total",
+		"Rows newly inserted into production.",
+		nil, nil,
+	)
+	dedupDuplicates = prometheus.NewDesc(
+		"dedup_duplicates_total",
+		"Rows dropped because another gatherer returned the same key.",
+		nil, nil,
+	)
+	dedupConflicts = prometheus.NewDesc(
+		"dedup_conflicts_total",
+		"Rows already in production, including lookback re-reads.",
+		nil, nil,
+	)
+	dedupErrors = prometheus.NewDesc(
+		"dedup_errors_total",
+		"Sync cycles that failed to write to production.",
+		nil, nil,
+	)
+	dedupGathererHealth = prometheus.NewDesc(
+		"dedup_gatherer_health",
+		"1 if the gatherer's last read succeeded, 0 otherwise.",
+		[]string{"gatherer"}, nil,
+	)
+	dedupCursor = prometheus.NewDesc(
+		"dedup_cursor_timestamp_seconds",
+		"Last committed sync cursor position.",
+		[]string{"gatherer", "table"}, nil,
+	)
+)
+
+func (c *dedupCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dedupCycles
+	ch <- dedupRowsRead
+	ch <- dedupRowsWritten
+	ch <- dedupDuplicates
+	ch <- dedupConflicts
+	ch <- dedupErrors
+	ch <- dedupGathererHealth
+	ch <- dedupCursor
+}
+
+func (c *dedupCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(dedupCycles, prometheus.CounterValue, float64(s.Cycles))
+	ch <- prometheus.MustNewConstMetric(dedupRowsWritten, prometheus.CounterValue, float64(s.RowsWritten))
+	ch <- prometheus.MustNewConstMetric(dedupDuplicates, prometheus.CounterValue, float64(s.Duplicates))
+	ch <- prometheus.MustNewConstMetric(dedupConflicts, prometheus.CounterValue, float64(s.Conflicts))
+	ch <- prometheus.MustNewConstMetric(dedupErrors, prometheus.CounterValue, float64(s.Errors))
+	for id, src := range s.Sources {
+		healthy := 0.0
+		if src.Healthy {
+			healthy = 1
+		}
+		ch <- prometheus.MustNewConstMetric(dedupRowsRead, prometheus.CounterValue, float64(src.RowsRead), id)
+		ch <- prometheus.MustNewConstMetric(dedupGathererHealth, prometheus.GaugeValue, healthy, id)
+		for table, ts := range src.Cursors {
+			ch <- prometheus.MustNewConstMetric(dedupCursor, prometheus.GaugeValue, float64(ts)/1e6, id, table)
+		}
+	}
+}
+
+// writerDescs are per-writer descriptors. The writer label is a const label
+// so each writer can be registered as its own collector.
+type writerDescs struct {
+	inserts   *prometheus.Desc
+	conflicts *prometheus.Desc
+	errors    *prometheus.Desc
+	flushes   *prometheus.Desc
+	seqGaps   *prometheus.Desc
+	fallbacks *prometheus.Desc
+	retries   *prometheus.Desc
+	spilled   *prometheus.Desc
+	replayed  *prometheus.Desc
+	rejected  *prometheus.Desc
+	dropped   *prometheus.Desc
+	pending   *prometheus.Desc
+	retrying  *prometheus.Desc
+}
+
+func newWriterDescs(name string) writerDescs {
+	labels := prometheus.Labels{"writer": name}
+	return writerDescs{
+		inserts:   prometheus.NewDesc("writer_inserts_total", "Rows successfully inserted.", nil, labels),
+		conflicts: prometheus.NewDesc("writer_conflicts_total", "Rows skipped by ON CONFLICT.", nil, labels),
+		errors:    prometheus.NewDesc("writer_errors_total", "Failed batch inserts.", nil, labels),
+		flushes:   prometheus.NewDesc("writer_flushes_total", "Completed flushes.", nil, labels),
+		seqGaps:   prometheus.NewDesc("writer_seq_gaps_total", "Sequence gaps detected.", nil, labels),
+		fallbacks: prometheus.NewDesc("writer_copy_fallbacks_total", "COPY flushes that failed and were retried as batch inserts.", nil, labels),
+		retries:   prometheus.NewDesc("writer_retries_total", "Batch insert attempts after the first.", nil, labels),
+		spilled:   prometheus.NewDesc("writer_spilled_batches_total", "Batches written to the dead-letter queue.", nil, labels),
+		replayed:  prometheus.NewDesc("writer_replayed_batches_total", "Dead-letter batches later written to the database.", nil, labels),
+		rejected:  prometheus.NewDesc("writer_rejected_batches_total", "Batches the database refused as invalid.", nil, labels),
+		dropped:   prometheus.NewDesc("writer_dropped_batches_total", "Batches dropped after their last attempt with no dead-letter queue.", nil, labels),
+		pending:   prometheus.NewDesc("writer_dead_letter_pending", "Dead-letter batches awaiting replay.", nil, labels),
+		retrying:  prometheus.NewDesc("writer_retry_pending", "Failed batches awaiting a background retry.", nil, labels),
+	}
+}
+
+// describeRetry sends the retry and dead-letter descriptors.
+func (d writerDescs) describeRetry(ch chan<- *prometheus.Desc) {
+	ch <- d.retries
+	ch <- d.spilled
+	ch <- d.replayed
+	ch <- d.rejected
+	ch <- d.dropped
+	ch <- d.pending
+	ch <- d.retrying
+}
+
+// collectRetry sends retry and dead-letter metrics.
+func (d writerDescs) collectRetry(ch chan<- prometheus.Metric, s writer.RetryMetrics) {
+	ch <- prometheus.MustNewConstMetric(d.retries, prometheus.CounterValue, float64(s.Retries))
+	ch <- prometheus.MustNewConstMetric(d.spilled, prometheus.CounterValue, float64(s.Spilled))
+	ch <- prometheus.MustNewConstMetric(d.replayed, prometheus.CounterValue, float64(s.Replayed))
+	ch <- prometheus.MustNewConstMetric(d.rejected, prometheus.CounterValue, float64(s.Rejected))
+	ch <- prometheus.MustNewConstMetric(d.dropped, prometheus.CounterValue, float64(s.Dropped))
+	ch <- prometheus.MustNewConstMetric(d.pending, prometheus.GaugeValue, float64(s.Pending))
+	ch <- prometheus.MustNewConstMetric(d.retrying, prometheus.GaugeValue, float64(s.Retrying))
+}
+
+// writerCollector exports writer.WriterMetrics.
+type writerCollector struct {
+	descs writerDescs
+	stats func() writer.WriterMetrics
+}
+
+func (c *writerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.descs.inserts
+	ch <- c.descs.conflicts
+	ch <- c.descs.errors
+	ch <- c.descs.flushes
+	ch <- c.descs.seqGaps
+	ch <- c.descs.fallbacks
+	c.descs.describeRetry(ch)
+}
+
+func (c *writerCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(c.descs.inserts, prometheus.CounterValue, float64(s.Inserts))
+	ch <- prometheus.MustNewConstMetric(c.descs.conflicts, prometheus.CounterValue, float64(s.Conflicts))
+	ch <- prometheus.MustNewConstMetric(c.descs.errors, prometheus.CounterValue, float64(s.Errors))
+	ch <- prometheus.MustNewConstMetric(c.descs.flushes, prometheus.CounterValue, float64(s.Flushes))
+	ch <- prometheus.MustNewConstMetric(c.descs.seqGaps, prometheus.CounterValue, float64(s.SeqGaps))
+	ch <- prometheus.MustNewConstMetric(c.descs.fallbacks, prometheus.CounterValue, float64(s.CopyFallbacks))
+	c.descs.collectRetry(ch, s.Retry)
+}
+
+// orderbookWriterCollector exports writer.OrderbookWriterMetrics.
+// Flushes and sequence gaps belong to the writer as a whole and are
+// reported under writer="orderbook".
+type orderbookWriterCollector struct {
+	deltas    writerDescs
+	snapshots writerDescs
+	stale     *prometheus.Desc
+	stats     func() writer.OrderbookWriterMetrics
+}
+
+func (c *orderbookWriterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.deltas.inserts
+	ch <- c.deltas.conflicts
+	ch <- c.deltas.errors
+	ch <- c.deltas.flushes
+	ch <- c.deltas.seqGaps
+	ch <- c.deltas.fallbacks
+	c.deltas.describeRetry(ch)
+	ch <- c.stale
+	ch <- c.snapshots.inserts
+	ch <- c.snapshots.conflicts
+	ch <- c.snapshots.errors
+	c.snapshots.describeRetry(ch)
+}
+
+func (c *orderbookWriterCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(c.deltas.inserts, prometheus.CounterValue, float64(s.DeltaInserts))
+	ch <- prometheus.MustNewConstMetric(c.deltas.conflicts, prometheus.CounterValue, float64(s.DeltaConflicts))
+	ch <- prometheus.MustNewConstMetric(c.deltas.errors, prometheus.CounterValue, float64(s.DeltaErrors))
+	ch <- prometheus.MustNewConstMetric(c.deltas.flushes, prometheus.CounterValue, float64(s.Flushes))
+	ch <- prometheus.MustNewConstMetric(c.deltas.seqGaps, prometheus.CounterValue, float64(s.SeqGaps))
+	ch <- prometheus.MustNewConstMetric(c.deltas.fallbacks, prometheus.CounterValue, float64(s.DeltaCopyFallbacks))
+	ch <- prometheus.MustNewConstMetric(c.snapshots.inserts, prometheus.CounterValue, float64(s.SnapshotInserts))
+	ch <- prometheus.MustNewConstMetric(c.snapshots.conflicts, prometheus.CounterValue, float64(s.SnapshotConflicts))
+	c.deltas.collectRetry(ch, s.DeltaRetry)
+	ch <- prometheus.MustNewConstMetric(c.stale, prometheus.CounterValue, float64(s.StaleDeltas))
+	ch <- prometheus.MustNewConstMetric(c.snapshots.errors, prometheus.CounterValue, float64(s.SnapshotErrors))
+	c.snapshots.collectRetry(ch, s.SnapshotRetry)
+}
+
+// poolCollector exports pgxpool statistics.
+type poolCollector struct {
+	totalConns      *prometheus.Desc
+	idleConns       *prometheus.Desc
+	acquiredConns   *prometheus.Desc
+	maxConns        *prometheus.Desc
+	acquires        *prometheus.Desc
+	acquireDuration *prometheus.Desc
+	acquireTimeouts *prometheus.Desc
+	emptyAcquires   *prometheus.Desc
+	stats           func() *pgxpool.Stat
+}
+
+func newPoolCollector(database string, stats func() *pgxpool.Stat) *poolCollector {
+	labels := prometheus.Labels{"database": database}
+	return &poolCollector{
+		totalConns:      prometheus.NewDesc("db_pool_total_conns", "Total connections in pool.", nil, labels),
+		idleConns:       prometheus.NewDesc("db_pool_idle_conns", "Idle connections.", nil, labels),
+		acquiredConns:   prometheus.NewDesc("db_pool_acquired_conns", "In-use connections.", nil, labels),
+		maxConns:        prometheus.NewDesc("db_pool_max_conns", "Maximum pool size.", nil, labels),
+		acquires:        prometheus.NewDesc("db_pool_acquire_total", "Successful connection acquires.", nil, labels),
+		acquireDuration: prometheus.NewDesc("db_pool_acquire_duration_seconds_total", "Cumulative time spent acquiring connections.", nil, labels),
+		acquireTimeouts: prometheus.NewDesc("db_pool_acquire_timeout_total", "Acquires canceled by their context.", nil, labels),
+		emptyAcquires:   prometheus.NewDesc("db_pool_empty_acquire_total", "Acquires that waited because the pool was empty.", nil, labels),
+		stats:           stats,
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.acquiredConns
+	ch <- c.maxConns
+	ch <- c.acquires
+	ch <- c.acquireDuration
+	ch <- c.acquireTimeouts
+	ch <- c.emptyAcquires
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.acquireTimeouts, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+}
@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rickgao/kalshi-data/internal/api"
+	"github.com/rickgao/kalshi-data/internal/book"
+	"github.com/rickgao/kalshi-data/internal/connection"
+	"github.com/rickgao/kalshi-data/internal/dedup"
+	"github.com/rickgao/kalshi-data/internal/journal"
+	"github.com/rickgao/kalshi-data/internal/router"
+	"github.com/rickgao/kalshi-data/internal/writer"
+)
+
+// Histogram buckets, see docs/kalshi-data/monitoring/metrics.md.
+var (
+	batchSizeBuckets     = []float64{10, 50, 100, 500, 1000, 5000}
+	flushDurationBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1.0}
+)
+
+// Registry holds all Prometheus collectors for a process.
+//
+// Component statistics (manager, router, writers, pools) are read from
+// their Stats() methods at scrape time, so components need no changes to
+// be exported. Writer flush histograms are fed through ObserveFlush.
+type Registry struct {
+	reg *prometheus.Registry
+
+	writerBatchSize     *prometheus.HistogramVec
+	writerFlushDuration *prometheus.HistogramVec
+}
+
+// NewRegistry creates a registry with Go runtime and process collectors.
+func NewRegistry() *Registry {
+	r := &Registry{
+		reg: prometheus.NewRegistry(),
+		writerBatchSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "writer_batch_size",
+			Help:    "Rows per batch insert.",
+			Buckets: batchSizeBuckets,
+		}, []string{"writer"}),
+		writerFlushDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "writer_flush_duration_seconds",
+			Help:    "Time to execute a batch insert.",
+			Buckets: flushDurationBuckets,
+		}, []string{"writer"}),
+	}
+
+	r.reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		r.writerBatchSize,
+		r.writerFlushDuration,
+	)
+
+	return r
+}
+
+// Handler returns an HTTP handler serving the registry in Prometheus format.
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.reg, promhttp.HandlerOpts{})
+}
+
+// ObserveFlush records a writer batch insert. Implements writer.FlushObserver.
+func (r *Registry) ObserveFlush(writer string, rows int, duration time.Duration) {
+	r.writerBatchSize.WithLabelValues(writer).Observe(float64(rows))
+	r.writerFlushDuration.WithLabelValues(writer).Observe(duration.Seconds())
+}
+
+// RegisterManager exports connection manager statistics.
+func (r *Registry) RegisterManager(m connection.Manager) {
+	r.reg.MustRegister(&managerCollector{stats: m.Stats})
+}
+
+// RegisterRouter exports router statistics, including its output buffers.
+func (r *Registry) RegisterRouter(rt router.Router) {
+	r.reg.MustRegister(&routerCollector{stats: rt.Stats})
+}
+
+// WriterSource provides batch writer statistics (TradeWriter, TickerWriter).
+type WriterSource interface {
+	Stats() writer.WriterMetrics
+}
+
+// OrderbookWriterSource provides orderbook writer statistics.
+type OrderbookWriterSource interface {
+	Stats() writer.OrderbookWriterMetrics
+}
+
+// RegisterWriter exports a batch writer's statistics under the given writer label.
+func (r *Registry) RegisterWriter(name string, w WriterSource) {
+	r.reg.MustRegister(&writerCollector{descs: newWriterDescs(name), stats: w.Stats})
+}
+
+// RegisterOrderbookWriter exports orderbook writer statistics.
+// Deltas are labeled writer="orderbook" and WS snapshots writer="orderbook_snapshot".
+func (r *Registry) RegisterOrderbookWriter(w OrderbookWriterSource) {
+	r.reg.MustRegister(&orderbookWriterCollector{
+		deltas:    newWriterDescs("orderbook"),
+		snapshots: newWriterDescs("orderbook_snapshot"),
+		stale: prometheus.NewDesc("writer_stale_deltas_total",
+			"Deltas dropped because a newer snapshot arrived under another SID.",
+			nil, prometheus.Labels{"writer": "orderbook"}),
+		stats: w.Stats,
+	})
+}
+
+// BookSource provides book engine statistics.
+type BookSource interface {
+	Stats() book.EngineStats
+}
+
+// RegisterBookEngine exports in-memory book engine statistics.
+func (r *Registry) RegisterBookEngine(b BookSource) {
+	r.reg.MustRegister(&bookCollector{stats: b.Stats})
+}
+
+// DerivedSource provides derived snapshot statistics.
+type DerivedSource interface {
+	Stats() book.DerivedStats
+}
+
+// RegisterDerivedSnapshotter exports derived snapshot statistics.
+func (r *Registry) RegisterDerivedSnapshotter(d DerivedSource) {
+	r.reg.MustRegister(&derivedCollector{stats: d.Stats})
+}
+
+// CrossCheckSource provides REST cross-check statistics.
+type CrossCheckSource interface {
+	Stats() book.CrossCheckStats
+}
+
+// RegisterCrossChecker exports REST cross-check statistics.
+func (r *Registry) RegisterCrossChecker(c CrossCheckSource) {
+	r.reg.MustRegister(&crossCheckCollector{stats: c.Stats})
+}
+
+// JournalSource provides raw message journal statistics.
+type JournalSource interface {
+	Stats() journal.Stats
+}
+
+// RegisterJournal exports raw message journal statistics.
+func (r *Registry) RegisterJournal(j JournalSource) {
+	r.reg.MustRegister(&journalCollector{stats: j.Stats})
+}
+
+// RateLimiterSource provides REST rate limiter statistics.
+type RateLimiterSource interface {
+	Stats() api.RateLimitStats
+}
+
+// RegisterRateLimiter exports REST client throttling statistics.
+func (r *Registry) RegisterRateLimiter(l RateLimiterSource) {
+	r.reg.MustRegister(&rateLimitCollector{stats: l.Stats})
+}
+
+// DeduplicatorSource provides deduplicator sync statistics.
+type DeduplicatorSource interface {
+	Stats() dedup.Stats
+}
+
+// RegisterDeduplicator exports deduplicator sync statistics.
+func (r *Registry) RegisterDeduplicator(d DeduplicatorSource) {
+	r.reg.MustRegister(&dedupCollector{stats: d.Stats})
+}
+
+// RegisterPool exports connection pool statistics under the given database label.
+func (r *Registry) RegisterPool(database string, pool *pgxpool.Pool) {
+	r.reg.MustRegister(newPoolCollector(database, pool.Stat))
+}
@@ -0,0 +1,534 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rickgao/kalshi-data/internal/api"
+	"github.com/rickgao/kalshi-data/internal/book"
+	"github.com/rickgao/kalshi-data/internal/connection"
+	"github.com/rickgao/kalshi-data/internal/dedup"
+	"github.com/rickgao/kalshi-data/internal/journal"
+	"github.com/rickgao/kalshi-data/internal/router"
+	"github.com/rickgao/kalshi-data/internal/writer"
+)
+
+type fakeManager struct {
+	connection.Manager
+	stats connection.ManagerStats
+}
+
+func (f *fakeManager) Stats() connection.ManagerStats { return f.stats }
+
+type fakeRouter struct {
+	router.Router
+	stats router.RouterStats
+}
+
+func (f *fakeRouter) Stats() router.RouterStats { return f.stats }
+
+type fakeBook struct{ stats book.EngineStats }
+
+func (f *fakeBook) Stats() book.EngineStats { return f.stats }
+
+type fakeWriter struct{ stats writer.WriterMetrics }
+
+func (f *fakeWriter) Stats() writer.WriterMetrics { return f.stats }
+
+type fakeDerived struct{ stats book.DerivedStats }
+
+func (f *fakeDerived) Stats() book.DerivedStats { return f.stats }
+
+type fakeCrossChecker struct{ stats book.CrossCheckStats }
+
+func (f *fakeCrossChecker) Stats() book.CrossCheckStats { return f.stats }
+
+type fakeJournal struct{ stats journal.Stats }
+
+func (f *fakeJournal) Stats() journal.Stats { return f.stats }
+
+type fakeRateLimiter struct{ stats api.RateLimitStats }
+
+func (f *fakeRateLimiter) Stats() api.RateLimitStats { return f.stats }
+
+type fakeDeduplicator struct{ stats dedup.Stats }
+
+func (f *fakeDeduplicator) Stats() dedup.Stats { return f.stats }
+
+type fakeOrderbookWriter struct{ stats writer.OrderbookWriterMetrics }
+
+func (f *fakeOrderbookWriter) Stats() writer.OrderbookWriterMetrics { return f.stats }
+
+// value returns the value of the metric with the given name and labels.
+func value(t *testing.T, r *Registry, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := r.reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			if !hasLabels(m, labels) {
+				continue
+			}
+			switch {
+			case m.Gauge != nil:
+				return m.GetGauge().GetValue()
+			case m.Counter != nil:
+				return m.GetCounter().GetValue()
+			case m.Histogram != nil:
+				return float64(m.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+
+	t.Fatalf("metric %s%v not found", name, labels)
+	return 0
+}
+
+func hasLabels(m *dto.Metric, want map[string]string) bool {
+	got := make(map[string]string, len(m.GetLabel()))
+	for _, l := range m.GetLabel() {
+		got[l.GetName()] = l.GetValue()
+	}
+	for k, v := range want {
+		if got[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRegistry_Manager(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterManager(&fakeManager{stats: connection.ManagerStats{
+		ConnectedCount:     150,
+		OrderbookConns:     144,
+		TotalSubscriptions: 1200,
+		MarketsSubscribed:  1000,
+		SequenceGaps:       12,
+		GapResubscribes:    8,
+		GapRateLimited:     3,
+		GapFailures:        1,
+		GapSkipped:         2,
+		Resyncs:            4,
+		ResyncFailures:     2,
+		InMaintenance:      true,
+		ReconnectsDeferred: 5,
+		Migrations:         6,
+		MigrationFailures:  1,
+		Connections: []connection.ConnStats{
+			{ID: 1, Role: connection.RoleTicker, MessageRate: 40},
+			{ID: 7, Role: connection.RoleOrderbook, Markets: 250, MessageRate: 850.5},
+		},
+		Overflows:    12,
+		Spilled:      9,
+		SpillPending: 4,
+		Dropped: []connection.DroppedStats{
+			{ConnID: 7, Role: connection.RoleOrderbook, Count: 3},
+		},
+	}})
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"conn_manager_connections_healthy", nil, 150},
+		{"conn_manager_orderbook_connections", nil, 144},
+		{"conn_manager_subscriptions", nil, 1200},
+		{"conn_manager_markets", nil, 1000},
+		{"conn_manager_sequence_gaps_total", nil, 12},
+		{"conn_manager_gap_recoveries_total", map[string]string{"action": "resubscribed"}, 8},
+		{"conn_manager_gap_recoveries_total", map[string]string{"action": "rate_limited"}, 3},
+		{"conn_manager_gap_recoveries_total", map[string]string{"action": "failed"}, 1},
+		{"conn_manager_gap_recoveries_total", map[string]string{"action": "skipped"}, 2},
+		{"conn_manager_resyncs_total", map[string]string{"result": "resubscribed"}, 4},
+		{"conn_manager_resyncs_total", map[string]string{"result": "failed"}, 2},
+		{"conn_manager_exchange_maintenance", nil, 1},
+		{"conn_manager_reconnects_deferred_total", nil, 5},
+		{"conn_manager_migrations_total", map[string]string{"result": "moved"}, 6},
+		{"conn_manager_migrations_total", map[string]string{"result": "failed"}, 1},
+		{"conn_manager_connection_message_rate", map[string]string{"conn": "1", "role": "ticker"}, 40},
+		{"conn_manager_connection_message_rate", map[string]string{"conn": "7", "role": "orderbook"}, 850.5},
+		{"conn_manager_router_overflows_total", nil, 12},
+		{"conn_manager_spilled_total", nil, 9},
+		{"conn_manager_spill_pending", nil, 4},
+		{"conn_manager_messages_dropped_total", map[string]string{"conn": "7", "role": "orderbook"}, 3},
+	}
+
+	for _, tt := range tests {
+		if got := value(t, r, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_Router(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterRouter(&fakeRouter{stats: router.RouterStats{
+		MessagesReceived: 100,
+		MessagesRouted:   95,
+		ParseErrors:      3,
+		UnknownMessages:  2,
+		OrderbookBuffer:  router.BufferStats{Count: 10, Capacity: 2048, TotalReceived: 60, TotalSent: 50, ResizeCount: 1},
+		TradeBuffer:      router.BufferStats{Capacity: 1024, TotalReceived: 20, TotalSent: 20},
+		TickerBuffer:     router.BufferStats{Capacity: 1024, TotalReceived: 15, TotalSent: 15},
+		BookBuffer:       router.BufferStats{Count: 4, Capacity: 5000},
+	}})
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"router_messages_received_total", nil, 100},
+		{"router_messages_routed_total", nil, 95},
+		{"router_parse_errors_total", nil, 3},
+		{"router_unknown_messages_total", nil, 2},
+		{"router_buffer_items", map[string]string{"buffer": "orderbook"}, 10},
+		{"router_buffer_capacity", map[string]string{"buffer": "orderbook"}, 2048},
+		{"router_buffer_received_total", map[string]string{"buffer": "orderbook"}, 60},
+		{"router_buffer_sent_total", map[string]string{"buffer": "orderbook"}, 50},
+		{"router_buffer_resizes_total", map[string]string{"buffer": "orderbook"}, 1},
+		{"router_buffer_received_total", map[string]string{"buffer": "trade"}, 20},
+		{"router_buffer_capacity", map[string]string{"buffer": "ticker"}, 1024},
+		{"router_buffer_items", map[string]string{"buffer": "book"}, 4},
+	}
+
+	for _, tt := range tests {
+		if got := value(t, r, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_BookEngine(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterBookEngine(&fakeBook{stats: book.EngineStats{
+		Books:         900,
+		InvalidBooks:  3,
+		Snapshots:     950,
+		Deltas:        120000,
+		Ignored:       12,
+		SequenceGaps:  2,
+		NegativeSizes: 1,
+		CrossedBooks:  4,
+		Errors:        5,
+		Pruned:        7,
+	}})
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"book_markets", nil, 900},
+		{"book_markets_invalid", nil, 3},
+		{"book_snapshots_applied_total", nil, 950},
+		{"book_deltas_applied_total", nil, 120000},
+		{"book_messages_ignored_total", nil, 12},
+		{"book_invalidations_total", map[string]string{"reason": "sequence_gap"}, 2},
+		{"book_invalidations_total", map[string]string{"reason": "negative_size"}, 1},
+		{"book_invalidations_total", map[string]string{"reason": "crossed"}, 4},
+		{"book_errors_total", nil, 5},
+		{"book_markets_pruned_total", nil, 7},
+	}
+
+	for _, tt := range tests {
+		if got := value(t, r, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_Writers(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterWriter("trade", &fakeWriter{stats: writer.WriterMetrics{
+		Inserts:       500,
+		Conflicts:     20,
+		Errors:        1,
+		Flushes:       7,
+		CopyFallbacks: 3,
+		Retry:         writer.RetryMetrics{Retries: 6, Spilled: 2, Replayed: 1, Dropped: 2, Pending: 1, Retrying: 3},
+	}})
+	r.RegisterWriter("ticker", &fakeWriter{stats: writer.WriterMetrics{Inserts: 300, Flushes: 4}})
+	r.RegisterOrderbookWriter(&fakeOrderbookWriter{stats: writer.OrderbookWriterMetrics{
+		DeltaInserts:      900,
+		DeltaConflicts:    30,
+		DeltaErrors:       2,
+		SnapshotInserts:   40,
+		SnapshotConflicts: 6,
+		SnapshotErrors:    1,
+		SeqGaps:           5,
+		Flushes:           9,
+		StaleDeltas:       4,
+
+		DeltaCopyFallbacks: 2,
+		SnapshotRetry:      writer.RetryMetrics{Spilled: 3, Rejected: 1},
+	}})
+
+	tests := []struct {
+		name   string
+		writer string
+		want   float64
+	}{
+		{"writer_inserts_total", "trade", 500},
+		{"writer_conflicts_total", "trade", 20},
+		{"writer_errors_total", "trade", 1},
+		{"writer_flushes_total", "trade", 7},
+		{"writer_copy_fallbacks_total", "trade", 3},
+		{"writer_retries_total", "trade", 6},
+		{"writer_spilled_batches_total", "trade", 2},
+		{"writer_replayed_batches_total", "trade", 1},
+		{"writer_dropped_batches_total", "trade", 2},
+		{"writer_dead_letter_pending", "trade", 1},
+		{"writer_retry_pending", "trade", 3},
+		{"writer_inserts_total", "ticker", 300},
+		{"writer_inserts_total", "orderbook", 900},
+		{"writer_conflicts_total", "orderbook", 30},
+		{"writer_errors_total", "orderbook", 2},
+		{"writer_seq_gaps_total", "orderbook", 5},
+		{"writer_flushes_total", "orderbook", 9},
+		{"writer_copy_fallbacks_total", "orderbook", 2},
+		{"writer_stale_deltas_total", "orderbook", 4},
+		{"writer_inserts_total", "orderbook_snapshot", 40},
+		{"writer_conflicts_total", "orderbook_snapshot", 6},
+		{"writer_errors_total", "orderbook_snapshot", 1},
+		{"writer_spilled_batches_total", "orderbook_snapshot", 3},
+		{"writer_rejected_batches_total", "orderbook_snapshot", 1},
+	}
+
+	for _, tt := range tests {
+		if got := value(t, r, tt.name, map[string]string{"writer": tt.writer}); got != tt.want {
+			t.Errorf("%s{writer=%s} = %v, want %v", tt.name, tt.writer, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_ObserveFlush(t *testing.T) {
+	r := NewRegistry()
+
+	var _ writer.FlushObserver = r
+
+	r.ObserveFlush("trade", 1000, 20*time.Millisecond)
+	r.ObserveFlush("trade", 50, 2*time.Millisecond)
+	r.ObserveFlush("ticker", 10, time.Millisecond)
+
+	if got := value(t, r, "writer_batch_size", map[string]string{"writer": "trade"}); got != 2 {
+		t.Errorf("writer_batch_size{writer=trade} count = %v, want 2", got)
+	}
+	if got := value(t, r, "writer_flush_duration_seconds", map[string]string{"writer": "ticker"}); got != 1 {
+		t.Errorf("writer_flush_duration_seconds{writer=ticker} count = %v, want 1", got)
+	}
+}
+
+func TestRegistry_Pool(t *testing.T) {
+	// Pools connect lazily, so no database is needed.
+	pool, err := pgxpool.New(context.Background(), "postgres://u:p@127.0.0.1:1/db?pool_max_conns=7")
+	if err != nil {
+		t.Fatalf("pgxpool.New failed: %v", err)
+	}
+	defer pool.Close()
+
+	r := NewRegistry()
+	r.RegisterPool("timescaledb", pool)
+
+	labels := map[string]string{"database": "timescaledb"}
+	if got := value(t, r, "db_pool_max_conns", labels); got != 7 {
+		t.Errorf("db_pool_max_conns = %v, want 7", got)
+	}
+	if got := value(t, r, "db_pool_acquired_conns", labels); got != 0 {
+		t.Errorf("db_pool_acquired_conns = %v, want 0", got)
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterWriter("trade", &fakeWriter{stats: writer.WriterMetrics{Inserts: 42}})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, _ := io.ReadAll(rec.Body)
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if !strings.Contains(string(body), `writer_inserts_total{writer="trade"} 42`) {
+		t.Errorf("response missing writer_inserts_total, got:\n%s", body)
+	}
+	if !strings.Contains(string(body), "go_goroutines") {
+		t.Error("response missing Go runtime metrics")
+	}
+}
+
+func TestRegistry_DerivedSnapshotter(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterDerivedSnapshotter(&fakeDerived{stats: book.DerivedStats{
+		Cycles:  60,
+		Emitted: 4200,
+		Idle:    1800,
+		Errors:  2,
+	}})
+
+	tests := []struct {
+		name string
+		want float64
+	}{
+		{"book_derived_cycles_total", 60},
+		{"book_derived_snapshots_total", 4200},
+		{"book_derived_idle_total", 1800},
+		{"book_derived_errors_total", 2},
+	}
+
+	for _, tt := range tests {
+		if got := value(t, r, tt.name, nil); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_CrossChecker(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterCrossChecker(&fakeCrossChecker{stats: book.CrossCheckStats{
+		Checks:          900,
+		Unchecked:       40,
+		Divergences:     6,
+		Unconfirmed:     3,
+		DivergentLevels: 11,
+		MaxLevelDiff:    250,
+		Resyncs:         5,
+		ResyncErrors:    1,
+	}})
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"book_crosscheck_checks_total", nil, 900},
+		{"book_crosscheck_unchecked_total", nil, 40},
+		{"book_crosscheck_divergences_total", nil, 6},
+		{"book_crosscheck_unconfirmed_total", nil, 3},
+		{"book_crosscheck_divergent_levels_total", nil, 11},
+		{"book_crosscheck_max_level_diff", nil, 250},
+		{"book_crosscheck_resyncs_total", map[string]string{"result": "requested"}, 5},
+		{"book_crosscheck_resyncs_total", map[string]string{"result": "refused"}, 1},
+	}
+
+	for _, tt := range tests {
+		if got := value(t, r, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_Journal(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterJournal(&fakeJournal{stats: journal.Stats{
+		Records:  50000,
+		Skipped:  12,
+		Bytes:    9000000,
+		Segments: 3,
+		Deleted:  1,
+		Errors:   2,
+	}})
+
+	tests := []struct {
+		name string
+		want float64
+	}{
+		{"journal_records_total", 50000},
+		{"journal_skipped_total", 12},
+		{"journal_bytes_total", 9000000},
+		{"journal_segments_total", 3},
+		{"journal_segments_deleted_total", 1},
+		{"journal_errors_total", 2},
+	}
+
+	for _, tt := range tests {
+		if got := value(t, r, tt.name, nil); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_RateLimiter(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterRateLimiter(&fakeRateLimiter{stats: api.RateLimitStats{
+		ReadWaits:     40,
+		WriteWaits:    2,
+		ReadWaitTime:  3 * time.Second,
+		WriteWaitTime: 500 * time.Millisecond,
+		RateLimited:   4,
+		Pauses:        3,
+	}})
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"api_throttle_waits_total", map[string]string{"tier": "read"}, 40},
+		{"api_throttle_waits_total", map[string]string{"tier": "write"}, 2},
+		{"api_throttle_wait_seconds_total", map[string]string{"tier": "read"}, 3},
+		{"api_throttle_wait_seconds_total", map[string]string{"tier": "write"}, 0.5},
+		{"api_rate_limited_total", nil, 4},
+		{"api_rate_limit_pauses_total", nil, 3},
+	}
+
+	for _, tt := range tests {
+		if got := value(t, r, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_Deduplicator(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterDeduplicator(&fakeDeduplicator{stats: dedup.Stats{
+		Cycles:      12,
+		RowsWritten: 900,
+		Duplicates:  850,
+		Conflicts:   40,
+		Errors:      1,
+		Sources: map[string]dedup.SourceStats{
+			"gatherer-1": {Healthy: true, RowsRead: 1000, Cursors: map[string]int64{"trades": 1_700_000_000_500_000}},
+			"gatherer-2": {Healthy: false, RowsRead: 790},
+		},
+	}})
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"dedup_cycles_total", nil, 12},
+		{"dedup_rows_written_total", nil, 900},
+		{"dedup_duplicates_total", nil, 850},
+		{"dedup_conflicts_total", nil, 40},
+		{"dedup_errors_total", nil, 1},
+		{"dedup_rows_read_total", map[string]string{"gatherer": "gatherer-1"}, 1000},
+		{"dedup_rows_read_total", map[string]string{"gatherer": "gatherer-2"}, 790},
+		{"dedup_gatherer_health", map[string]string{"gatherer": "gatherer-1"}, 1},
+		{"dedup_gatherer_health", map[string]string{"gatherer": "gatherer-2"}, 0},
+		{"dedup_cursor_timestamp_seconds", map[string]string{"gatherer": "gatherer-1", "table": "trades"}, 1_700_000_000.5},
+	}
+
+	for _, tt := range tests {
+		if got := value(t, r, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+}
@@ -55,6 +55,10 @@ type Market struct {
 	ExpirationTS int64 // Expiration time
 	CreatedTS    int64 // Creation time
 	UpdatedAt    int64 // Last update
+
+	// Settlement
+	SettlementValue *int  // YES payout (hundred-thousandths), nil until published
+	SettledTS       int64 // Settlement time from market_lifecycle (µs), 0 if unknown
 }
 
 // -----------------------------------------------------------------------------
@@ -81,6 +85,7 @@ type OrderbookDelta struct {
 	Price      int    // Price level (hundred-thousandths, 0-100,000)
 	SizeDelta  int    // Change in size (positive = add, negative = remove)
 	Seq        int64  // Kalshi sequence number (per-subscription)
+	Ordinal    int    // Occurrence of (ExchangeTS, Ticker, Side, Price), in seq order
 }
 
 // PriceLevel represents a single price level in an orderbook.
@@ -117,3 +122,38 @@ type Ticker struct {
 	DollarVolume       int64  // Dollar-denominated volume
 	DollarOpenInterest int64  // Dollar-denominated open interest
 }
+
+// Candle represents an OHLC candlestick for one market and period.
+type Candle struct {
+	Ticker        string // Market ticker
+	PeriodMinutes int    // Candle length: 1, 60 or 1440
+	OpenTS        int64  // Period start (µs since epoch)
+	CloseTS       int64  // Period end (µs since epoch)
+	Open          int    // Open price (hundred-thousandths)
+	High          int    // High price (hundred-thousandths)
+	Low           int    // Low price (hundred-thousandths)
+	Close         int    // Close price (hundred-thousandths)
+	Volume        int64  // Contracts traded in the period
+	OpenInterest  int64  // Open interest at period end
+}
+
+// -----------------------------------------------------------------------------
+// Exchange Types
+// -----------------------------------------------------------------------------
+
+// MaintenanceWindow is a period when the exchange is scheduled to be closed.
+type MaintenanceWindow struct {
+	Start int64 // Window start (µs since epoch)
+	End   int64 // Window end (µs since epoch)
+}
+
+// Announcement is an exchange-wide notice such as scheduled maintenance.
+type Announcement struct {
+	ID         string // Primary key
+	Title      string
+	Message    string
+	Type       string // maintenance, market, feature, general
+	Status     string
+	CreatedTS  int64 // Creation time (µs since epoch)
+	DeliveryTS int64 // Delivery time (µs since epoch)
+}
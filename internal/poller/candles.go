@@ -0,0 +1,226 @@
+package poller
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/api"
+	"github.com/rickgao/kalshi-data/internal/model"
+)
+
+// CandleHandler receives fetched candles, one batch request at a time.
+type CandleHandler interface {
+	HandleCandles(candles []model.Candle) error
+}
+
+// CandleHandlerFunc is a function adapter for CandleHandler.
+type CandleHandlerFunc func([]model.Candle) error
+
+func (f CandleHandlerFunc) HandleCandles(c []model.Candle) error {
+	return f(c)
+}
+
+// CandleConfig holds candle fetcher configuration.
+type CandleConfig struct {
+	Interval      time.Duration // Fetch interval (default: 5m)
+	PeriodMinutes int           // Candle period: 1, 60 or 1440 (default: 1)
+	Lookback      time.Duration // Window fetched each cycle, ending now (default: 1h)
+	Timeout       time.Duration // Per-request timeout (default: 30s)
+}
+
+// DefaultCandleConfig returns sensible defaults.
+func DefaultCandleConfig() CandleConfig {
+	return CandleConfig{
+		Interval:      5 * time.Minute,
+		PeriodMinutes: api.PeriodMinute,
+		Lookback:      time.Hour,
+		Timeout:       30 * time.Second,
+	}
+}
+
+// CandleFetcher periodically fetches exchange candlesticks for all active
+// markets with the batch endpoint. The lookback overlaps previous cycles
+// so candles still open at the last fetch are rewritten once they close.
+type CandleFetcher struct {
+	cfg     CandleConfig
+	client  *api.Client
+	markets MarketSource
+	handler CandleHandler
+	logger  *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewCandleFetcher creates a new CandleFetcher.
+func NewCandleFetcher(cfg CandleConfig, client *api.Client, markets MarketSource, handler CandleHandler, logger *slog.Logger) *CandleFetcher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &CandleFetcher{
+		cfg:     cfg,
+		client:  client,
+		markets: markets,
+		handler: handler,
+		logger:  logger,
+	}
+}
+
+// Start begins the fetch loop.
+func (f *CandleFetcher) Start(ctx context.Context) error {
+	f.ctx, f.cancel = context.WithCancel(ctx)
+
+	f.wg.Add(1)
+	go f.run()
+
+	f.logger.Info("candle fetcher started",
+		"interval", f.cfg.Interval,
+		"period_minutes", f.cfg.PeriodMinutes,
+		"lookback", f.cfg.Lookback,
+	)
+
+	return nil
+}
+
+// Stop gracefully shuts down the fetcher.
+func (f *CandleFetcher) Stop(ctx context.Context) error {
+	if f.cancel != nil {
+		f.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		f.logger.Info("candle fetcher stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run is the main fetch loop.
+func (f *CandleFetcher) run() {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(f.cfg.Interval)
+	defer ticker.Stop()
+
+	// Fetch immediately on start.
+	f.fetchAll()
+
+	for {
+		select {
+		case <-f.ctx.Done():
+			return
+		case <-ticker.C:
+			f.fetchAll()
+		}
+	}
+}
+
+// batchSize returns how many tickers fit in one batch request without the
+// response exceeding api.MaxBatchCandlesticks.
+func (f *CandleFetcher) batchSize() int {
+	period := time.Duration(max(f.cfg.PeriodMinutes, 1)) * time.Minute
+	perTicker := int(f.cfg.Lookback/period) + 1 // The window may straddle one extra period
+	return max(min(api.MaxBatchCandlestickTickers, api.MaxBatchCandlesticks/perTicker), 1)
+}
+
+// fetchAll fetches candles for all active markets, one batch at a time.
+// Requests are paced by the client's rate limiter.
+func (f *CandleFetcher) fetchAll() {
+	start := time.Now()
+
+	if w, ok := inMaintenance(f.markets); ok {
+		f.logger.Info("skipping candle cycle during exchange maintenance",
+			"until", time.UnixMicro(w.End).UTC(),
+		)
+		return
+	}
+
+	markets := f.markets.GetActiveMarkets()
+	if len(markets) == 0 {
+		f.logger.Debug("no active markets to fetch candles for")
+		return
+	}
+
+	end := time.Now()
+	opts := api.BatchCandlesticksOptions{
+		PeriodInterval: f.cfg.PeriodMinutes,
+		StartTS:        end.Add(-f.cfg.Lookback).Unix(),
+		EndTS:          end.Unix(),
+	}
+
+	size := f.batchSize()
+	var candles, errors int
+	for i := 0; i < len(markets); i += size {
+		if f.ctx.Err() != nil {
+			return
+		}
+
+		batch := markets[i:min(i+size, len(markets))]
+		opts.Tickers = make([]string, len(batch))
+		for j, m := range batch {
+			opts.Tickers[j] = m.Ticker
+		}
+
+		n, err := f.fetchBatch(opts)
+		candles += n
+		if err != nil {
+			level := slog.LevelWarn
+			if _, ok := inMaintenance(f.markets); ok {
+				level = slog.LevelDebug
+			}
+			f.logger.Log(f.ctx, level, "failed to fetch candles",
+				"tickers", len(opts.Tickers),
+				"first", opts.Tickers[0],
+				"err", err,
+			)
+			errors++
+		}
+	}
+
+	f.logger.Info("candle cycle complete",
+		"markets", len(markets),
+		"candles", candles,
+		"errors", errors,
+		"duration", time.Since(start),
+	)
+}
+
+// fetchBatch fetches and handles one batch request's candles.
+func (f *CandleFetcher) fetchBatch(opts api.BatchCandlesticksOptions) (int, error) {
+	ctx, cancel := context.WithTimeout(f.ctx, f.cfg.Timeout)
+	defer cancel()
+
+	resp, err := f.client.BatchGetCandlesticks(ctx, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	var candles []model.Candle
+	for ticker, sticks := range resp.Candlesticks {
+		for _, s := range sticks {
+			c := s.ToModel()
+			// The batch response is keyed by ticker and may omit it per candle
+			c.Ticker = ticker
+			if c.PeriodMinutes == 0 {
+				c.PeriodMinutes = opts.PeriodInterval
+			}
+			candles = append(candles, c)
+		}
+	}
+
+	if len(candles) == 0 || f.handler == nil {
+		return len(candles), nil
+	}
+	return len(candles), f.handler.HandleCandles(candles)
+}
@@ -0,0 +1,130 @@
+package poller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/api"
+	"github.com/rickgao/kalshi-data/internal/model"
+)
+
+func TestDefaultCandleConfig(t *testing.T) {
+	cfg := DefaultCandleConfig()
+
+	if cfg.Interval != 5*time.Minute {
+		t.Errorf("Interval = %v, want 5m", cfg.Interval)
+	}
+	if cfg.PeriodMinutes != api.PeriodMinute {
+		t.Errorf("PeriodMinutes = %d, want %d", cfg.PeriodMinutes, api.PeriodMinute)
+	}
+	if cfg.Lookback != time.Hour {
+		t.Errorf("Lookback = %v, want 1h", cfg.Lookback)
+	}
+}
+
+func TestCandleFetcher_BatchSize(t *testing.T) {
+	tests := []struct {
+		period   int
+		lookback time.Duration
+		want     int
+	}{
+		{api.PeriodMinute, time.Hour, 100},         // 61 candles per ticker
+		{api.PeriodMinute, 6 * time.Hour, 27},      // 361 per ticker
+		{api.PeriodHour, 24 * time.Hour, 100},      // 25 per ticker
+		{api.PeriodMinute, 30 * 24 * time.Hour, 1}, // More than the response limit
+	}
+
+	for _, tt := range tests {
+		f := NewCandleFetcher(CandleConfig{PeriodMinutes: tt.period, Lookback: tt.lookback}, nil, nil, nil, nil)
+		if got := f.batchSize(); got != tt.want {
+			t.Errorf("batchSize(%dm, %v) = %d, want %d", tt.period, tt.lookback, got, tt.want)
+		}
+	}
+}
+
+func TestCandleFetcher_FetchAll(t *testing.T) {
+	var mu sync.Mutex
+	var requests [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/markets/candlesticks" {
+			t.Errorf("path = %q, want /markets/candlesticks", r.URL.Path)
+		}
+		tickers := strings.Split(r.URL.Query().Get("tickers"), ",")
+		mu.Lock()
+		requests = append(requests, tickers)
+		mu.Unlock()
+
+		resp := map[string][]map[string]any{}
+		for _, ticker := range tickers {
+			resp[ticker] = []map[string]any{{"close_ts": 1705320060, "close_dollars": "0.5200", "volume": 10}}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"candlesticks": resp})
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "", nil, api.WithTimeout(5*time.Second))
+
+	var markets []model.Market
+	for i := 0; i < 150; i++ {
+		markets = append(markets, model.Market{Ticker: fmt.Sprintf("MARKET-%d", i)})
+	}
+
+	var candles []model.Candle
+	handler := CandleHandlerFunc(func(c []model.Candle) error {
+		candles = append(candles, c...)
+		return nil
+	})
+
+	f := NewCandleFetcher(DefaultCandleConfig(), client, &mockMarketSource{markets: markets}, handler, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	f.ctx = ctx
+
+	f.fetchAll()
+
+	if len(requests) != 2 || len(requests[0]) != 100 || len(requests[1]) != 50 {
+		t.Fatalf("requests = %d, want batches of 100 and 50", len(requests))
+	}
+	if len(candles) != 150 {
+		t.Fatalf("candles = %d, want 150", len(candles))
+	}
+	c := candles[0]
+	if !strings.HasPrefix(c.Ticker, "MARKET-") || c.PeriodMinutes != api.PeriodMinute {
+		t.Errorf("Ticker, PeriodMinutes = %q, %d, want filled from the request", c.Ticker, c.PeriodMinutes)
+	}
+	if c.Close != 52000 || c.CloseTS != 1705320060000000 || c.Volume != 10 {
+		t.Errorf("candle = %+v", c)
+	}
+}
+
+func TestCandleFetcher_FetchAll_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "", nil, api.WithTimeout(5*time.Second))
+	markets := &mockMarketSource{markets: []model.Market{{Ticker: "MARKET-1"}}}
+
+	called := false
+	handler := CandleHandlerFunc(func(c []model.Candle) error {
+		called = true
+		return nil
+	})
+
+	f := NewCandleFetcher(DefaultCandleConfig(), client, markets, handler, nil)
+	f.ctx = context.Background()
+
+	f.fetchAll()
+
+	if called {
+		t.Error("handler called after API error")
+	}
+}
@@ -5,4 +5,7 @@
 //   - Provides backup data source for gap recovery
 //   - Uses concurrent requests with rate limiting
 //   - Stores snapshots with source="rest" marker
+//
+// The optional CandleFetcher periodically fetches exchange candlesticks
+// for active markets with the batch candlesticks endpoint.
 package poller
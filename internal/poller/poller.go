@@ -16,6 +16,23 @@ type MarketSource interface {
 	GetActiveMarkets() []model.Market
 }
 
+// MaintenanceSource reports scheduled exchange downtime. A MarketSource
+// that also implements it (the Market Registry does) makes the pollers
+// skip cycles during maintenance instead of logging every failed request.
+type MaintenanceSource interface {
+	Maintenance(t time.Time) (model.MaintenanceWindow, bool)
+}
+
+// inMaintenance reports whether markets says the exchange is down for
+// scheduled maintenance.
+func inMaintenance(markets MarketSource) (model.MaintenanceWindow, bool) {
+	ms, ok := markets.(MaintenanceSource)
+	if !ok {
+		return model.MaintenanceWindow{}, false
+	}
+	return ms.Maintenance(time.Now())
+}
+
 // SnapshotHandler receives fetched snapshots.
 type SnapshotHandler interface {
 	HandleSnapshot(snapshot model.OrderbookSnapshot) error
@@ -131,6 +148,13 @@ func (p *Poller) run() {
 func (p *Poller) pollAll() {
 	start := time.Now()
 
+	if w, ok := inMaintenance(p.markets); ok {
+		p.logger.Info("skipping poll cycle during exchange maintenance",
+			"until", time.UnixMicro(w.End).UTC(),
+		)
+		return
+	}
+
 	markets := p.markets.GetActiveMarkets()
 	if len(markets) == 0 {
 		p.logger.Debug("no active markets to poll")
@@ -156,7 +180,12 @@ func (p *Poller) pollAll() {
 			}
 
 			if err := p.pollMarket(ticker); err != nil {
-				p.logger.Warn("failed to poll market",
+				level := slog.LevelWarn
+				if _, ok := inMaintenance(p.markets); ok {
+					// Maintenance started mid-cycle
+					level = slog.LevelDebug
+				}
+				p.logger.Log(p.ctx, level, "failed to poll market",
 					"ticker", ticker,
 					"err", err,
 				)
@@ -40,7 +40,7 @@ func TestDefaultConfig(t *testing.T) {
 }
 
 func TestNew(t *testing.T) {
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	markets := &mockMarketSource{}
 	handler := SnapshotHandlerFunc(func(s model.OrderbookSnapshot) error { return nil })
 	cfg := DefaultConfig()
@@ -120,7 +120,7 @@ func TestPoller_PollAll(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "", api.WithTimeout(5*time.Second))
+	client := api.NewClient(server.URL, "", nil, api.WithTimeout(5*time.Second))
 
 	markets := &mockMarketSource{
 		markets: []model.Market{
@@ -157,7 +157,7 @@ func TestPoller_PollAll(t *testing.T) {
 }
 
 func TestPoller_PollAll_EmptyMarkets(t *testing.T) {
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 
 	markets := &mockMarketSource{
 		markets: []model.Market{},
@@ -185,6 +185,49 @@ func TestPoller_PollAll_EmptyMarkets(t *testing.T) {
 	}
 }
 
+// maintenanceMarketSource is a market source in scheduled maintenance.
+type maintenanceMarketSource struct {
+	mockMarketSource
+	window model.MaintenanceWindow
+}
+
+func (m *maintenanceMarketSource) Maintenance(t time.Time) (model.MaintenanceWindow, bool) {
+	ts := t.UnixMicro()
+	return m.window, ts >= m.window.Start && ts < m.window.End
+}
+
+func TestPoller_PollAll_Maintenance(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "", nil, api.WithRetries(0, time.Millisecond))
+	now := time.Now()
+	markets := &maintenanceMarketSource{
+		mockMarketSource: mockMarketSource{markets: []model.Market{{Ticker: "MARKET-1"}}},
+		window:           model.MaintenanceWindow{Start: now.Add(-time.Minute).UnixMicro(), End: now.Add(time.Hour).UnixMicro()},
+	}
+
+	p := New(DefaultConfig(), client, markets, nil, nil)
+	p.ctx = context.Background()
+	p.pollAll()
+
+	if got := requests.Load(); got != 0 {
+		t.Errorf("requests = %d during maintenance, want 0", got)
+	}
+
+	// Outside the window the cycle runs
+	markets.window.End = now.Add(-time.Second).UnixMicro()
+	p.pollAll()
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d after maintenance, want 1", got)
+	}
+}
+
 func TestPoller_PollAll_NilHandler(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp := map[string]any{
@@ -197,7 +240,7 @@ func TestPoller_PollAll_NilHandler(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "")
+	client := api.NewClient(server.URL, "", nil)
 
 	markets := &mockMarketSource{
 		markets: []model.Market{
@@ -231,7 +274,7 @@ func TestPoller_PollAll_HandlerError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "")
+	client := api.NewClient(server.URL, "", nil)
 
 	markets := &mockMarketSource{
 		markets: []model.Market{
@@ -273,7 +316,7 @@ func TestPoller_PollAll_APIError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "")
+	client := api.NewClient(server.URL, "", nil)
 
 	markets := &mockMarketSource{
 		markets: []model.Market{
@@ -321,7 +364,7 @@ func TestPoller_PollAll_ContextCanceled(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "")
+	client := api.NewClient(server.URL, "", nil)
 
 	// Create many markets.
 	var marketList []model.Market
@@ -374,7 +417,7 @@ func TestPoller_StartStop(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "")
+	client := api.NewClient(server.URL, "", nil)
 
 	markets := &mockMarketSource{
 		markets: []model.Market{
@@ -421,7 +464,7 @@ func TestPoller_StartStop(t *testing.T) {
 // through other tests and the implementation is straightforward.
 
 func TestPoller_Stop_NilCancel(t *testing.T) {
-	client := api.NewClient("http://localhost", "")
+	client := api.NewClient("http://localhost", "", nil)
 	markets := &mockMarketSource{}
 	handler := SnapshotHandlerFunc(func(s model.OrderbookSnapshot) error { return nil })
 	cfg := DefaultConfig()
@@ -468,7 +511,7 @@ func TestPoller_Concurrency(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "")
+	client := api.NewClient(server.URL, "", nil)
 
 	// Create 20 markets.
 	var marketList []model.Market
@@ -515,7 +558,7 @@ func TestPoller_SnapshotFields(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "")
+	client := api.NewClient(server.URL, "", nil)
 
 	markets := &mockMarketSource{
 		markets: []model.Market{
@@ -563,7 +606,7 @@ func TestPoller_MultiplePollCycles(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := api.NewClient(server.URL, "")
+	client := api.NewClient(server.URL, "", nil)
 
 	markets := &mockMarketSource{
 		markets: []model.Market{
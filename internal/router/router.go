@@ -29,6 +29,11 @@ type RouterBuffers struct {
 	Orderbook *GrowableBuffer[OrderbookMsg]
 	Trade     *GrowableBuffer[TradeMsg]
 	Ticker    *GrowableBuffer[TickerMsg]
+
+	// Book receives the same messages as Orderbook, for consumers that
+	// must not compete with the Orderbook Writer. Nil unless
+	// RouterConfig.BookBufferSize > 0.
+	Book *GrowableBuffer[OrderbookMsg]
 }
 
 // RouterStats contains runtime statistics.
@@ -40,6 +45,7 @@ type RouterStats struct {
 	OrderbookBuffer  BufferStats
 	TradeBuffer      BufferStats
 	TickerBuffer     BufferStats
+	BookBuffer       BufferStats // Zero when the book buffer is disabled
 }
 
 // router is the internal implementation.
@@ -52,6 +58,7 @@ type router struct {
 
 	// Output to Writers (growable buffers)
 	orderbookBuf *GrowableBuffer[OrderbookMsg]
+	bookBuf      *GrowableBuffer[OrderbookMsg] // nil if disabled
 	tradeBuf     *GrowableBuffer[TradeMsg]
 	tickerBuf    *GrowableBuffer[TickerMsg]
 
@@ -74,7 +81,7 @@ func NewRouter(cfg RouterConfig, input <-chan connection.RawMessage, logger *slo
 		logger = slog.Default()
 	}
 
-	return &router{
+	r := &router{
 		cfg:          cfg,
 		logger:       logger,
 		input:        input,
@@ -82,6 +89,11 @@ func NewRouter(cfg RouterConfig, input <-chan connection.RawMessage, logger *slo
 		tradeBuf:     NewGrowableBuffer[TradeMsg](cfg.TradeBufferSize),
 		tickerBuf:    NewGrowableBuffer[TickerMsg](cfg.TickerBufferSize),
 	}
+	if cfg.BookBufferSize > 0 {
+		r.bookBuf = NewGrowableBuffer[OrderbookMsg](cfg.BookBufferSize)
+	}
+
+	return r
 }
 
 // Start begins routing messages.
@@ -126,6 +138,9 @@ func (r *router) Stop(ctx context.Context) error {
 	r.orderbookBuf.Close()
 	r.tradeBuf.Close()
 	r.tickerBuf.Close()
+	if r.bookBuf != nil {
+		r.bookBuf.Close()
+	}
 
 	return nil
 }
@@ -136,6 +151,7 @@ func (r *router) Buffers() RouterBuffers {
 		Orderbook: r.orderbookBuf,
 		Trade:     r.tradeBuf,
 		Ticker:    r.tickerBuf,
+		Book:      r.bookBuf,
 	}
 }
 
@@ -144,6 +160,11 @@ func (r *router) Stats() RouterStats {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	var bookStats BufferStats
+	if r.bookBuf != nil {
+		bookStats = r.bookBuf.Stats()
+	}
+
 	return RouterStats{
 		MessagesReceived: r.received,
 		MessagesRouted:   r.routed,
@@ -152,6 +173,7 @@ func (r *router) Stats() RouterStats {
 		OrderbookBuffer:  r.orderbookBuf.Stats(),
 		TradeBuffer:      r.tradeBuf.Stats(),
 		TickerBuffer:     r.tickerBuf.Stats(),
+		BookBuffer:       bookStats,
 	}
 }
 
@@ -201,7 +223,7 @@ func (r *router) route(raw connection.RawMessage) {
 			r.mu.Unlock()
 			return
 		}
-		sent = r.orderbookBuf.Send(msg)
+		sent = r.sendOrderbook(msg)
 
 	case "orderbook_delta":
 		msg, err := r.parseOrderbookDelta(raw)
@@ -212,7 +234,7 @@ func (r *router) route(raw connection.RawMessage) {
 			r.mu.Unlock()
 			return
 		}
-		sent = r.orderbookBuf.Send(msg)
+		sent = r.sendOrderbook(msg)
 
 	case "trade":
 		msg, err := r.parseTrade(raw)
@@ -251,6 +273,15 @@ func (r *router) route(raw connection.RawMessage) {
 	}
 }
 
+// sendOrderbook sends an orderbook message to the writer buffer and, if
+// enabled, the book buffer.
+func (r *router) sendOrderbook(msg OrderbookMsg) bool {
+	if r.bookBuf != nil {
+		r.bookBuf.Send(msg)
+	}
+	return r.orderbookBuf.Send(msg)
+}
+
 // extractType extracts the message type without full JSON parse.
 func (r *router) extractType(data []byte) (string, error) {
 	var envelope messageEnvelope
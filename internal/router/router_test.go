@@ -114,6 +114,60 @@ func TestRouter_ParseOrderbookDelta(t *testing.T) {
 	}
 }
 
+func TestRouter_BookBuffer(t *testing.T) {
+	tests := []struct {
+		name     string
+		bookSize int
+		wantBook bool
+	}{
+		{"disabled", 0, false},
+		{"enabled", 100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := make(chan connection.RawMessage, 10)
+			cfg := DefaultRouterConfig()
+			cfg.BookBufferSize = tt.bookSize
+			r := NewRouter(cfg, input, slog.Default())
+
+			ctx := context.Background()
+			if err := r.Start(ctx); err != nil {
+				t.Fatalf("Start failed: %v", err)
+			}
+			defer r.Stop(ctx)
+
+			data := []byte(`{"type":"orderbook_delta","sid":1,"seq":2,"msg":{"market_ticker":"TEST","price_dollars":"0.52","delta":5,"side":"yes","ts":1705328200}}`)
+			input <- connection.RawMessage{Data: data, ReceivedAt: time.Now()}
+
+			time.Sleep(50 * time.Millisecond)
+
+			buffers := r.Buffers()
+			if _, ok := buffers.Orderbook.TryReceive(); !ok {
+				t.Error("expected orderbook message")
+			}
+
+			if (buffers.Book != nil) != tt.wantBook {
+				t.Fatalf("Book buffer present = %v, want %v", buffers.Book != nil, tt.wantBook)
+			}
+			if !tt.wantBook {
+				return
+			}
+
+			msg, ok := buffers.Book.TryReceive()
+			if !ok {
+				t.Fatal("expected book message")
+			}
+			if msg.Ticker != "TEST" || msg.Seq != 2 {
+				t.Errorf("book msg = %s/%d, want TEST/2", msg.Ticker, msg.Seq)
+			}
+			if got := r.Stats().BookBuffer.TotalReceived; got != 1 {
+				t.Errorf("BookBuffer.TotalReceived = %d, want 1", got)
+			}
+		})
+	}
+}
+
 func TestRouter_ParseOrderbookSnapshot(t *testing.T) {
 	input := make(chan connection.RawMessage, 10)
 	cfg := DefaultRouterConfig()
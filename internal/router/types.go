@@ -37,6 +37,10 @@ type RouterConfig struct {
 	OrderbookBufferSize int // Default: 5000
 	TradeBufferSize     int // Default: 1000
 	TickerBufferSize    int // Default: 1000
+
+	// BookBufferSize enables a second copy of the orderbook stream for the
+	// in-memory book engine (RouterBuffers.Book). 0 disables it.
+	BookBufferSize int // Default: 0
 }
 
 // DefaultRouterConfig returns default configuration.
@@ -0,0 +1,250 @@
+package writer
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/rickgao/kalshi-data/internal/model"
+)
+
+// CandleWriter receives exchange candlesticks from the candle fetcher and
+// upserts them into the candles table. It implements poller.CandleHandler.
+//
+// The fetcher's lookback overlaps earlier fetches, so most candles arrive
+// more than once. A candle still open at its last fetch is updated when it
+// arrives closed; unchanged candles count as conflicts.
+type CandleWriter struct {
+	cfg    WriterConfig
+	logger *slog.Logger
+
+	// Database
+	db *pgxpool.Pool
+
+	// Batching
+	batch       []candleRow
+	batchMu     sync.Mutex
+	flushTicker *time.Ticker
+
+	// Retries and dead-letter queue for failed flushes
+	retry *retrier[candleRow]
+
+	// Lifecycle
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// Metrics
+	metrics WriterMetrics
+}
+
+// NewCandleWriter creates a new CandleWriter.
+func NewCandleWriter(
+	cfg WriterConfig,
+	db *pgxpool.Pool,
+	logger *slog.Logger,
+) *CandleWriter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	w := &CandleWriter{
+		cfg:    cfg,
+		db:     db,
+		logger: logger,
+		batch:  make([]candleRow, 0, cfg.BatchSize),
+	}
+	w.retry = newRetrier("candle", cfg.Retry, w.insert, w.recordWrite, logger)
+	return w
+}
+
+// Start begins periodic flushing.
+func (w *CandleWriter) Start(ctx context.Context) error {
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	w.flushTicker = time.NewTicker(w.cfg.FlushInterval)
+	w.retry.open()
+
+	w.wg.Add(1)
+	go w.flushLoop()
+
+	w.wg.Add(1)
+	go w.retry.replayLoop(w.ctx, &w.wg)
+
+	w.logger.Info("candle writer started",
+		"batch_size", w.cfg.BatchSize,
+		"flush_interval", w.cfg.FlushInterval,
+	)
+	return nil
+}
+
+// Stop gracefully shuts down the writer and flushes any pending candles.
+// Stop the candle fetcher first so no candles arrive after the final flush.
+func (w *CandleWriter) Stop(ctx context.Context) error {
+	w.logger.Info("stopping candle writer")
+
+	if w.cancel != nil {
+		w.cancel()
+	}
+
+	if w.flushTicker != nil {
+		w.flushTicker.Stop()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.logger.Info("candle writer stopped")
+	case <-ctx.Done():
+		w.logger.Warn("candle writer stop timed out")
+	}
+
+	// Final flush and queued retries use the caller's context; w.ctx is already canceled.
+	w.flush(ctx)
+	w.retry.close(ctx)
+
+	return nil
+}
+
+// Stats returns current metrics.
+func (w *CandleWriter) Stats() WriterMetrics {
+	w.batchMu.Lock()
+	m := w.metrics
+	w.batchMu.Unlock()
+	m.Retry = w.retry.stats()
+	return m
+}
+
+// HandleCandles adds candles to the batch, flushing when full.
+func (w *CandleWriter) HandleCandles(candles []model.Candle) error {
+	receivedAt := time.Now().UnixMicro()
+
+	w.batchMu.Lock()
+	for _, c := range candles {
+		w.batch = append(w.batch, w.transform(c, receivedAt))
+	}
+	shouldFlush := len(w.batch) >= w.cfg.BatchSize
+	w.batchMu.Unlock()
+
+	if shouldFlush {
+		w.flush(w.ctx)
+	}
+	return nil
+}
+
+// flushLoop periodically flushes the batch.
+func (w *CandleWriter) flushLoop() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-w.flushTicker.C:
+			w.flush(w.ctx)
+		}
+	}
+}
+
+// transform converts a model.Candle to candleRow.
+func (w *CandleWriter) transform(c model.Candle, receivedAt int64) candleRow {
+	return candleRow{
+		CloseTs:       c.CloseTS,
+		Ticker:        c.Ticker,
+		PeriodMinutes: c.PeriodMinutes,
+		OpenTs:        c.OpenTS,
+		Open:          c.Open,
+		High:          c.High,
+		Low:           c.Low,
+		Close:         c.Close,
+		Volume:        c.Volume,
+		OpenInterest:  c.OpenInterest,
+		ReceivedAt:    receivedAt,
+	}
+}
+
+// flush writes the current batch to the database, retrying and spilling
+// to the dead-letter queue on failure.
+func (w *CandleWriter) flush(ctx context.Context) {
+	w.batchMu.Lock()
+	if len(w.batch) == 0 {
+		w.batchMu.Unlock()
+		return
+	}
+
+	// Take ownership of current batch
+	batch := w.batch
+	w.batch = make([]candleRow, 0, w.cfg.BatchSize)
+	w.batchMu.Unlock()
+
+	start := time.Now()
+
+	err := w.retry.write(ctx, batch)
+	observeFlush(w.cfg, "candle", len(batch), start)
+	if err != nil {
+		w.logger.Error("candle batch insert failed", "error", err, "count", len(batch))
+		w.batchMu.Lock()
+		w.metrics.Errors++
+		w.batchMu.Unlock()
+		return
+	}
+
+	w.logger.Debug("flushed candles",
+		"count", len(batch),
+		"duration", time.Since(start),
+	)
+}
+
+// recordWrite counts a batch written to the database, directly or by
+// dead-letter replay.
+func (w *CandleWriter) recordWrite(rows, conflicts int) {
+	w.batchMu.Lock()
+	w.metrics.Inserts += int64(rows - conflicts)
+	w.metrics.Conflicts += int64(conflicts)
+	w.metrics.Flushes++
+	w.batchMu.Unlock()
+}
+
+// insert upserts candle rows. A candle whose values have not changed is
+// left alone and counted as a conflict.
+func (w *CandleWriter) insert(ctx context.Context, rows []candleRow) (conflicts int, err error) {
+	batch := &pgx.Batch{}
+	for _, r := range rows {
+		batch.Queue(`
+			INSERT INTO candles (close_ts, ticker, period_minutes, open_ts, open, high, low, close, volume, open_interest, received_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (close_ts, ticker, period_minutes) DO UPDATE SET
+				open = EXCLUDED.open,
+				high = EXCLUDED.high,
+				low = EXCLUDED.low,
+				close = EXCLUDED.close,
+				volume = EXCLUDED.volume,
+				open_interest = EXCLUDED.open_interest,
+				received_at = EXCLUDED.received_at
+			WHERE (candles.open, candles.high, candles.low, candles.close, candles.volume, candles.open_interest)
+				IS DISTINCT FROM (EXCLUDED.open, EXCLUDED.high, EXCLUDED.low, EXCLUDED.close, EXCLUDED.volume, EXCLUDED.open_interest)
+		`, r.CloseTs, r.Ticker, r.PeriodMinutes, r.OpenTs, r.Open, r.High, r.Low, r.Close, r.Volume, r.OpenInterest, r.ReceivedAt)
+	}
+
+	results := w.db.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range rows {
+		ct, err := results.Exec()
+		if err != nil {
+			return 0, err
+		}
+		if ct.RowsAffected() == 0 {
+			conflicts++
+		}
+	}
+
+	return conflicts, nil
+}
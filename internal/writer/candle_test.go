@@ -0,0 +1,65 @@
+package writer
+
+import (
+	"testing"
+
+	"github.com/rickgao/kalshi-data/internal/model"
+	"github.com/rickgao/kalshi-data/internal/poller"
+)
+
+var _ poller.CandleHandler = (*CandleWriter)(nil)
+
+func TestCandleWriter_Transform(t *testing.T) {
+	w := NewCandleWriter(DefaultWriterConfig(), nil, nil)
+
+	c := model.Candle{
+		Ticker:        "KXBTC-25JAN-B100000",
+		PeriodMinutes: 60,
+		OpenTS:        1705320000000000,
+		CloseTS:       1705323600000000,
+		Open:          52500,
+		High:          56000,
+		Low:           50000,
+		Close:         55250,
+		Volume:        1200,
+		OpenInterest:  5000,
+	}
+
+	row := w.transform(c, 1705323601000000)
+
+	if row.CloseTs != 1705323600000000 || row.OpenTs != 1705320000000000 {
+		t.Errorf("CloseTs, OpenTs = %d, %d", row.CloseTs, row.OpenTs)
+	}
+	if row.Ticker != "KXBTC-25JAN-B100000" || row.PeriodMinutes != 60 {
+		t.Errorf("Ticker, PeriodMinutes = %q, %d", row.Ticker, row.PeriodMinutes)
+	}
+	if row.Open != 52500 || row.High != 56000 || row.Low != 50000 || row.Close != 55250 {
+		t.Errorf("OHLC = %d/%d/%d/%d, want 52500/56000/50000/55250", row.Open, row.High, row.Low, row.Close)
+	}
+	if row.Volume != 1200 || row.OpenInterest != 5000 {
+		t.Errorf("Volume, OpenInterest = %d, %d, want 1200, 5000", row.Volume, row.OpenInterest)
+	}
+	if row.ReceivedAt != 1705323601000000 {
+		t.Errorf("ReceivedAt = %d, want 1705323601000000", row.ReceivedAt)
+	}
+}
+
+func TestCandleWriter_HandleCandlesBatches(t *testing.T) {
+	cfg := DefaultWriterConfig()
+	cfg.BatchSize = 10
+	w := NewCandleWriter(cfg, nil, nil)
+
+	if err := w.HandleCandles([]model.Candle{{Ticker: "A"}, {Ticker: "B"}}); err != nil {
+		t.Fatalf("HandleCandles() error = %v", err)
+	}
+
+	w.batchMu.Lock()
+	n := len(w.batch)
+	w.batchMu.Unlock()
+	if n != 2 {
+		t.Errorf("batch = %d rows, want 2", n)
+	}
+	if got := w.Stats(); got.Inserts != 0 || got.Flushes != 0 {
+		t.Errorf("Stats() = %+v, want nothing written below batch size", got)
+	}
+}
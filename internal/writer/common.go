@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"math"
 	"strconv"
+	"time"
 
+	"github.com/rickgao/kalshi-data/internal/model"
 	"github.com/rickgao/kalshi-data/internal/router"
 )
 
@@ -62,6 +64,33 @@ func deriveAsksFromBids(bids []router.PriceLevel) []byte {
 	return data
 }
 
+// modelLevelsToJSONB converts model.PriceLevel slice (already in internal
+// units) to the same JSONB layout as priceLevelsToJSONB.
+func modelLevelsToJSONB(levels []model.PriceLevel) []byte {
+	result := make([]priceLevelJSON, len(levels))
+	for i, level := range levels {
+		result[i] = priceLevelJSON{
+			Price: level.Price,
+			Size:  level.Size,
+		}
+	}
+	data, _ := json.Marshal(result)
+	return data
+}
+
+// deriveAsksFromModelBids converts model bids to asks on the opposite side.
+// YES bid at X = NO ask at (100000 - X)
+func deriveAsksFromModelBids(bids []model.PriceLevel) []model.PriceLevel {
+	asks := make([]model.PriceLevel, len(bids))
+	for i, bid := range bids {
+		asks[i] = model.PriceLevel{
+			Price: 100000 - bid.Price,
+			Size:  bid.Size,
+		}
+	}
+	return asks
+}
+
 // extractBestPrice returns the best price from price levels (first level).
 func extractBestPrice(levels []router.PriceLevel) int {
 	if len(levels) == 0 {
@@ -78,3 +107,10 @@ func extractBestAskFromBids(bids []router.PriceLevel) int {
 	// Best bid on opposite side = best ask
 	return 100000 - dollarsToInternal(bids[0].Dollars)
 }
+
+// observeFlush reports a batch insert to the configured observer, if any.
+func observeFlush(cfg WriterConfig, writer string, rows int, start time.Time) {
+	if cfg.Observer != nil {
+		cfg.Observer.ObserveFlush(writer, rows, time.Since(start))
+	}
+}
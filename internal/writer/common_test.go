@@ -3,6 +3,7 @@ package writer
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/rickgao/kalshi-data/internal/router"
 )
@@ -177,3 +178,32 @@ func TestExtractBestAskFromBids(t *testing.T) {
 		})
 	}
 }
+
+type recordingObserver struct {
+	writer string
+	rows   int
+	calls  int
+}
+
+func (o *recordingObserver) ObserveFlush(writer string, rows int, duration time.Duration) {
+	o.writer = writer
+	o.rows = rows
+	o.calls++
+}
+
+func TestObserveFlush(t *testing.T) {
+	obs := &recordingObserver{}
+	cfg := WriterConfig{Observer: obs}
+
+	observeFlush(cfg, "trade", 250, time.Now())
+
+	if obs.calls != 1 {
+		t.Fatalf("calls = %d, want 1", obs.calls)
+	}
+	if obs.writer != "trade" || obs.rows != 250 {
+		t.Errorf("ObserveFlush(%q, %d), want (\"trade\", 250)", obs.writer, obs.rows)
+	}
+
+	// No observer configured is a no-op.
+	observeFlush(WriterConfig{}, "trade", 1, time.Now())
+}
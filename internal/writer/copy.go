@@ -0,0 +1,124 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Insert modes for WriterConfig.InsertMode.
+const (
+	// InsertBatch queues one INSERT ... ON CONFLICT DO NOTHING per row in a
+	// pgx.Batch.
+	InsertBatch = "batch"
+
+	// InsertCopy COPYs the batch into a temporary staging table and moves it
+	// with a single INSERT ... SELECT ... ON CONFLICT DO NOTHING. Batches
+	// smaller than copyMinRows, and batches whose COPY fails, use
+	// InsertBatch instead.
+	InsertCopy = "copy"
+)
+
+// copyMinRows is the smallest batch worth the staging table round trips.
+const copyMinRows = 32
+
+// copyTarget describes a table written through a staging COPY.
+type copyTarget struct {
+	table   string
+	columns []string
+}
+
+var (
+	tradeCopy = copyTarget{
+		table:   "trades",
+		columns: []string{"trade_id", "exchange_ts", "received_at", "ticker", "price", "size", "taker_side", "sid"},
+	}
+	tickerCopy = copyTarget{
+		table:   "tickers",
+		columns: []string{"exchange_ts", "received_at", "ticker", "yes_bid", "yes_ask", "last_price", "volume", "open_interest", "dollar_volume", "dollar_open_interest", "sid"},
+	}
+	deltaCopy = copyTarget{
+		table:   "orderbook_deltas",
+		columns: []string{"exchange_ts", "received_at", "ticker", "side", "price", "size_delta", "seq", "ordinal", "sid"},
+	}
+)
+
+// useCopy reports whether a batch of n rows should go through COPY.
+func useCopy(cfg WriterConfig, n int) bool {
+	return cfg.InsertMode == InsertCopy && n >= copyMinRows
+}
+
+// copyInsert writes rows to t.table through a per-connection temporary
+// staging table, in one transaction. Rows already present (or duplicated
+// within rows) are skipped by ON CONFLICT DO NOTHING and returned as
+// conflicts.
+func copyInsert(ctx context.Context, db *pgxpool.Pool, t copyTarget, rows [][]any) (conflicts int, err error) {
+	staging := "staging_" + t.table
+	columns := strings.Join(t.columns, ", ")
+
+	err = pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		// Temp tables live as long as the pooled connection; ON COMMIT
+		// DELETE ROWS empties it after every flush.
+		if _, err := tx.Exec(ctx, fmt.Sprintf(
+			"CREATE TEMP TABLE IF NOT EXISTS %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DELETE ROWS",
+			staging, t.table,
+		)); err != nil {
+			return fmt.Errorf("create staging table: %w", err)
+		}
+
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{staging}, t.columns, pgx.CopyFromRows(rows)); err != nil {
+			return fmt.Errorf("copy: %w", err)
+		}
+
+		ct, err := tx.Exec(ctx, fmt.Sprintf(
+			"INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT DO NOTHING",
+			t.table, columns, columns, staging,
+		))
+		if err != nil {
+			return fmt.Errorf("insert from staging: %w", err)
+		}
+
+		conflicts = len(rows) - int(ct.RowsAffected())
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return conflicts, nil
+}
+
+// tradeCopyRows converts trade rows to COPY values. trade_id is parsed
+// client-side because binary COPY cannot encode a string as uuid.
+func tradeCopyRows(rows []tradeRow) ([][]any, error) {
+	values := make([][]any, len(rows))
+	for i, r := range rows {
+		var id pgtype.UUID
+		if err := id.Scan(r.TradeID); err != nil {
+			return nil, fmt.Errorf("trade_id %q: %w", r.TradeID, err)
+		}
+		values[i] = []any{id, r.ExchangeTs, r.ReceivedAt, r.Ticker, r.Price, r.Size, r.TakerSide, r.SID}
+	}
+	return values, nil
+}
+
+// tickerCopyRows converts ticker rows to COPY values.
+func tickerCopyRows(rows []tickerRow) [][]any {
+	values := make([][]any, len(rows))
+	for i, r := range rows {
+		values[i] = []any{r.ExchangeTs, r.ReceivedAt, r.Ticker, r.YesBid, r.YesAsk, r.LastPrice, r.Volume, r.OpenInterest, r.DollarVolume, r.DollarOpenInterest, r.SID}
+	}
+	return values
+}
+
+// deltaCopyRows converts delta rows to COPY values.
+func deltaCopyRows(rows []orderbookDeltaRow) [][]any {
+	values := make([][]any, len(rows))
+	for i, r := range rows {
+		values[i] = []any{r.ExchangeTs, r.ReceivedAt, r.Ticker, r.Side, r.Price, r.SizeDelta, r.Seq, r.Ordinal, r.SID}
+	}
+	return values
+}
@@ -0,0 +1,88 @@
+package writer
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestUseCopy(t *testing.T) {
+	tests := []struct {
+		name string
+		mode string
+		rows int
+		want bool
+	}{
+		{"copy mode, large batch", InsertCopy, copyMinRows, true},
+		{"copy mode, small batch", InsertCopy, copyMinRows - 1, false},
+		{"batch mode", InsertBatch, 1000, false},
+		{"unset mode", "", 1000, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := WriterConfig{InsertMode: tt.mode}
+			if got := useCopy(cfg, tt.rows); got != tt.want {
+				t.Errorf("useCopy(%q, %d) = %v, want %v", tt.mode, tt.rows, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCopyRows_Encode checks every COPY value encodes in binary format as
+// its column's type, which is what CopyFrom requires.
+func TestCopyRows_Encode(t *testing.T) {
+	trades, err := tradeCopyRows([]tradeRow{{
+		TradeID: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", ExchangeTs: 1, ReceivedAt: 2,
+		Ticker: "T", Price: 52000, Size: 3, TakerSide: true, SID: 4,
+	}})
+	if err != nil {
+		t.Fatalf("tradeCopyRows() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		target copyTarget
+		values []any
+		oids   []uint32
+	}{
+		{
+			name:   "trades",
+			target: tradeCopy,
+			values: trades[0],
+			oids:   []uint32{pgtype.UUIDOID, pgtype.Int8OID, pgtype.Int8OID, pgtype.TextOID, pgtype.Int4OID, pgtype.Int4OID, pgtype.BoolOID, pgtype.Int8OID},
+		},
+		{
+			name:   "tickers",
+			target: tickerCopy,
+			values: tickerCopyRows([]tickerRow{{ExchangeTs: 1, ReceivedAt: 2, Ticker: "T", YesBid: 50000, Volume: 10, SID: 4}})[0],
+			oids:   []uint32{pgtype.Int8OID, pgtype.Int8OID, pgtype.TextOID, pgtype.Int4OID, pgtype.Int4OID, pgtype.Int4OID, pgtype.Int8OID, pgtype.Int8OID, pgtype.Int8OID, pgtype.Int8OID, pgtype.Int8OID},
+		},
+		{
+			name:   "orderbook_deltas",
+			target: deltaCopy,
+			values: deltaCopyRows([]orderbookDeltaRow{{ExchangeTs: 1, ReceivedAt: 2, Ticker: "T", Side: true, Price: 52000, SizeDelta: -5, Seq: 9, Ordinal: 1, SID: 4}})[0],
+			oids:   []uint32{pgtype.Int8OID, pgtype.Int8OID, pgtype.TextOID, pgtype.BoolOID, pgtype.Int4OID, pgtype.Int4OID, pgtype.Int8OID, pgtype.Int4OID, pgtype.Int8OID},
+		},
+	}
+
+	m := pgtype.NewMap()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.values) != len(tt.target.columns) || len(tt.oids) != len(tt.target.columns) {
+				t.Fatalf("%d values, %d oids for %d columns", len(tt.values), len(tt.oids), len(tt.target.columns))
+			}
+			for i, v := range tt.values {
+				if _, err := m.Encode(tt.oids[i], pgtype.BinaryFormatCode, v, nil); err != nil {
+					t.Errorf("column %s: %v", tt.target.columns[i], err)
+				}
+			}
+		})
+	}
+}
+
+func TestTradeCopyRows_InvalidID(t *testing.T) {
+	if _, err := tradeCopyRows([]tradeRow{{TradeID: "trade-123"}}); err == nil {
+		t.Error("tradeCopyRows() error = nil, want error for non-UUID trade_id")
+	}
+}
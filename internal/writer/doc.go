@@ -5,8 +5,10 @@
 //   - Trade writer (TimescaleDB)
 //   - Ticker writer (TimescaleDB)
 //   - Snapshot writer (TimescaleDB)
-//   - Market/event writer (PostgreSQL)
+//   - Metadata writer: markets, events and series (TimescaleDB, plain tables)
 //
-// All writers use append-only semantics (never update, only insert).
+// Time-series writers use append-only semantics (never update, only insert).
+// The metadata writer upserts the latest copy of each row and appends
+// market status transitions to market_status_history.
 // Prices are stored as integer hundred-thousandths (0-100,000 = $0.00-$1.00) for 5-digit sub-penny precision.
 package writer
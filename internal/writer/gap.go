@@ -0,0 +1,255 @@
+package writer
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/rickgao/kalshi-data/internal/connection"
+)
+
+// GapWriter consumes gap events from the Connection Manager and writes them
+// to the gap_events table.
+type GapWriter struct {
+	cfg    WriterConfig
+	logger *slog.Logger
+
+	// Input from Connection Manager
+	input <-chan connection.GapEvent
+
+	// Database
+	db *pgxpool.Pool
+
+	// Batching
+	batch       []gapEventRow
+	batchMu     sync.Mutex
+	flushTicker *time.Ticker
+
+	// Retries and dead-letter queue for failed flushes
+	retry *retrier[gapEventRow]
+
+	// Lifecycle
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// Metrics
+	metrics WriterMetrics
+}
+
+// NewGapWriter creates a new GapWriter.
+func NewGapWriter(
+	cfg WriterConfig,
+	input <-chan connection.GapEvent,
+	db *pgxpool.Pool,
+	logger *slog.Logger,
+) *GapWriter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	w := &GapWriter{
+		cfg:    cfg,
+		input:  input,
+		db:     db,
+		logger: logger,
+		batch:  make([]gapEventRow, 0, cfg.BatchSize),
+	}
+	w.retry = newRetrier("gap", cfg.Retry, w.batchInsert, w.recordWrite, logger)
+	return w
+}
+
+// Start begins consuming gap events and writing to the database.
+func (w *GapWriter) Start(ctx context.Context) error {
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	w.flushTicker = time.NewTicker(w.cfg.FlushInterval)
+	w.retry.open()
+
+	w.wg.Add(1)
+	go w.consumeLoop()
+
+	w.wg.Add(1)
+	go w.flushLoop()
+
+	w.wg.Add(1)
+	go w.retry.replayLoop(w.ctx, &w.wg)
+
+	w.logger.Info("gap writer started",
+		"batch_size", w.cfg.BatchSize,
+		"flush_interval", w.cfg.FlushInterval,
+	)
+	return nil
+}
+
+// Stop gracefully shuts down the writer and flushes any pending events.
+func (w *GapWriter) Stop(ctx context.Context) error {
+	w.logger.Info("stopping gap writer")
+
+	if w.cancel != nil {
+		w.cancel()
+	}
+
+	if w.flushTicker != nil {
+		w.flushTicker.Stop()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.logger.Info("gap writer stopped")
+	case <-ctx.Done():
+		w.logger.Warn("gap writer stop timed out")
+	}
+
+	// Final flush and queued retries use the caller's context; w.ctx is already canceled.
+	w.flush(ctx)
+	w.retry.close(ctx)
+
+	return nil
+}
+
+// Stats returns current metrics.
+func (w *GapWriter) Stats() WriterMetrics {
+	w.batchMu.Lock()
+	m := w.metrics
+	w.batchMu.Unlock()
+	m.Retry = w.retry.stats()
+	return m
+}
+
+// consumeLoop reads gap events until the input closes or the writer stops.
+func (w *GapWriter) consumeLoop() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case event, ok := <-w.input:
+			if !ok {
+				return
+			}
+			w.add(event)
+		}
+	}
+}
+
+// add appends an event to the batch, flushing when full.
+func (w *GapWriter) add(event connection.GapEvent) {
+	row := w.transform(event)
+
+	w.batchMu.Lock()
+	w.batch = append(w.batch, row)
+	shouldFlush := len(w.batch) >= w.cfg.BatchSize
+	w.batchMu.Unlock()
+
+	if shouldFlush {
+		w.flush(w.ctx)
+	}
+}
+
+// flushLoop periodically flushes the batch.
+func (w *GapWriter) flushLoop() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-w.flushTicker.C:
+			w.flush(w.ctx)
+		}
+	}
+}
+
+// transform converts a connection.GapEvent to gapEventRow.
+func (w *GapWriter) transform(e connection.GapEvent) gapEventRow {
+	return gapEventRow{
+		DetectedAt:  e.DetectedAt.UnixMicro(),
+		Ticker:      e.Ticker,
+		SID:         e.SID,
+		ConnID:      e.ConnID,
+		ExpectedSeq: e.ExpectedSeq,
+		ReceivedSeq: e.ReceivedSeq,
+		GapSize:     e.GapSize,
+		Action:      string(e.Action),
+		Error:       e.Error,
+	}
+}
+
+// flush writes the current batch to the database, retrying and spilling
+// to the dead-letter queue on failure.
+func (w *GapWriter) flush(ctx context.Context) {
+	w.batchMu.Lock()
+	if len(w.batch) == 0 {
+		w.batchMu.Unlock()
+		return
+	}
+
+	// Take ownership of current batch
+	batch := w.batch
+	w.batch = make([]gapEventRow, 0, w.cfg.BatchSize)
+	w.batchMu.Unlock()
+
+	start := time.Now()
+
+	err := w.retry.write(ctx, batch)
+	observeFlush(w.cfg, "gap", len(batch), start)
+	if err != nil {
+		w.logger.Error("gap event batch insert failed", "error", err, "count", len(batch))
+		w.batchMu.Lock()
+		w.metrics.Errors++
+		w.batchMu.Unlock()
+		return
+	}
+
+	w.logger.Debug("flushed gap events",
+		"count", len(batch),
+		"duration", time.Since(start),
+	)
+}
+
+// recordWrite counts a batch written to the database, directly or by
+// dead-letter replay.
+func (w *GapWriter) recordWrite(rows, conflicts int) {
+	w.batchMu.Lock()
+	w.metrics.Inserts += int64(rows - conflicts)
+	w.metrics.Conflicts += int64(conflicts)
+	w.metrics.Flushes++
+	w.batchMu.Unlock()
+}
+
+// batchInsert inserts gap event rows with ON CONFLICT DO NOTHING.
+func (w *GapWriter) batchInsert(ctx context.Context, rows []gapEventRow) (conflicts int, err error) {
+	batch := &pgx.Batch{}
+	for _, r := range rows {
+		batch.Queue(`
+			INSERT INTO gap_events (detected_at, ticker, sid, conn_id, expected_seq, received_seq, gap_size, action, error)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''))
+			ON CONFLICT (detected_at, sid) DO NOTHING
+		`, r.DetectedAt, r.Ticker, r.SID, r.ConnID, r.ExpectedSeq, r.ReceivedSeq, r.GapSize, r.Action, r.Error)
+	}
+
+	results := w.db.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range rows {
+		ct, err := results.Exec()
+		if err != nil {
+			return 0, err
+		}
+		if ct.RowsAffected() == 0 {
+			conflicts++
+		}
+	}
+
+	return conflicts, nil
+}
@@ -0,0 +1,84 @@
+package writer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/connection"
+)
+
+func TestGapWriter_Transform(t *testing.T) {
+	w := NewGapWriter(DefaultWriterConfig(), nil, nil, nil)
+
+	row := w.transform(connection.GapEvent{
+		DetectedAt:  time.UnixMicro(1705320000123456),
+		Ticker:      "AAPL-JAN-100",
+		SID:         42,
+		ConnID:      9,
+		ExpectedSeq: 101,
+		ReceivedSeq: 105,
+		GapSize:     4,
+		Action:      connection.GapFailed,
+		Error:       "resubscribe: timeout",
+	})
+
+	want := gapEventRow{
+		DetectedAt:  1705320000123456,
+		Ticker:      "AAPL-JAN-100",
+		SID:         42,
+		ConnID:      9,
+		ExpectedSeq: 101,
+		ReceivedSeq: 105,
+		GapSize:     4,
+		Action:      "failed",
+		Error:       "resubscribe: timeout",
+	}
+	if row != want {
+		t.Errorf("transform() = %+v, want %+v", row, want)
+	}
+}
+
+func TestGapWriter_ConsumesEvents(t *testing.T) {
+	cfg := WriterConfig{
+		BatchSize:     100, // Large batch so no auto-flush
+		FlushInterval: time.Hour,
+	}
+	input := make(chan connection.GapEvent, 2)
+	w := NewGapWriter(cfg, input, nil, nil)
+
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	input <- connection.GapEvent{SID: 1, Action: connection.GapResubscribed}
+	input <- connection.GapEvent{SID: 2, Action: connection.GapRateLimited}
+	close(input)
+
+	// consumeLoop exits when the input closes
+	deadline := time.Now().Add(time.Second)
+	for {
+		w.batchMu.Lock()
+		batchLen := len(w.batch)
+		w.batchMu.Unlock()
+		if batchLen == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("batch length = %d, want 2", batchLen)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Drop the batch so Stop's final flush does not need a database
+	w.batchMu.Lock()
+	w.batch = w.batch[:0]
+	w.batchMu.Unlock()
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := w.Stop(stopCtx); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+}
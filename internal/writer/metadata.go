@@ -0,0 +1,541 @@
+package writer
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/rickgao/kalshi-data/internal/api"
+	"github.com/rickgao/kalshi-data/internal/market"
+	"github.com/rickgao/kalshi-data/internal/model"
+)
+
+// MetadataSource fetches event and series details. *api.Client implements it.
+type MetadataSource interface {
+	GetEvent(ctx context.Context, eventTicker string) (*api.APIEvent, error)
+	GetSeries(ctx context.Context, seriesTicker string) (*api.APISeries, error)
+}
+
+// MetadataWriter consumes metadata updates from the Market Registry and
+// upserts them into the markets, events and series tables, recording
+// status transitions in market_status_history, outcomes in
+// market_settlements and exchange announcements in exchange_announcements.
+//
+// Markets are written as soon as they arrive. Events and series are fetched
+// from REST the first time one of their markets is seen, by resolveLoop so
+// that slow lookups never hold up the updates behind them.
+type MetadataWriter struct {
+	cfg    WriterConfig
+	logger *slog.Logger
+
+	// Input from Market Registry
+	input <-chan market.MetadataUpdate
+
+	// REST lookups for events and series
+	source MetadataSource
+
+	// Database
+	db *pgxpool.Pool
+
+	// Retries and dead-letter queue for failed flushes
+	retry *retrier[metadataRow]
+
+	// Event lookups handed from consumeLoop to resolveLoop, guarded by
+	// lookupMu. queuedEvents holds events waiting or in flight, so each is
+	// fetched once; knownEvents holds events already written.
+	lookupMu      sync.Mutex
+	pendingEvents []string
+	queuedEvents  map[string]struct{}
+	knownEvents   map[string]struct{}
+	lookupReady   chan struct{}
+
+	// Series already written. Only used by resolveLoop.
+	knownSeries map[string]struct{}
+
+	// Lifecycle
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// Metrics
+	metricsMu sync.Mutex
+	metrics   WriterMetrics
+}
+
+// NewMetadataWriter creates a new MetadataWriter.
+func NewMetadataWriter(
+	cfg WriterConfig,
+	input <-chan market.MetadataUpdate,
+	source MetadataSource,
+	db *pgxpool.Pool,
+	logger *slog.Logger,
+) *MetadataWriter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	w := &MetadataWriter{
+		cfg:          cfg,
+		input:        input,
+		source:       source,
+		db:           db,
+		logger:       logger,
+		queuedEvents: make(map[string]struct{}),
+		knownEvents:  make(map[string]struct{}),
+		lookupReady:  make(chan struct{}, 1),
+		knownSeries:  make(map[string]struct{}),
+	}
+	w.retry = newRetrier("metadata", cfg.Retry, w.batchInsert, w.recordWrite, logger)
+	return w
+}
+
+// Start begins consuming metadata updates and writing to the database.
+func (w *MetadataWriter) Start(ctx context.Context) error {
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	w.retry.open()
+
+	w.wg.Add(1)
+	go w.consumeLoop()
+
+	w.wg.Add(1)
+	go w.resolveLoop()
+
+	w.wg.Add(1)
+	go w.retry.replayLoop(w.ctx, &w.wg)
+
+	w.logger.Info("metadata writer started", "batch_size", w.cfg.BatchSize)
+	return nil
+}
+
+// Stop gracefully shuts down the writer. Updates are written as they
+// arrive, so there is nothing left to flush.
+func (w *MetadataWriter) Stop(ctx context.Context) error {
+	w.logger.Info("stopping metadata writer")
+
+	if w.cancel != nil {
+		w.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.logger.Info("metadata writer stopped")
+	case <-ctx.Done():
+		w.logger.Warn("metadata writer stop timed out")
+	}
+
+	// Retry anything still queued with the caller's context; w.ctx is already canceled.
+	w.retry.close(ctx)
+
+	return nil
+}
+
+// Stats returns current metrics.
+func (w *MetadataWriter) Stats() WriterMetrics {
+	w.metricsMu.Lock()
+	m := w.metrics
+	w.metricsMu.Unlock()
+	m.Retry = w.retry.stats()
+	return m
+}
+
+// consumeLoop reads metadata updates until the input closes or the writer stops.
+func (w *MetadataWriter) consumeLoop() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case update, ok := <-w.input:
+			if !ok {
+				return
+			}
+			w.handle(w.ctx, update)
+		}
+	}
+}
+
+// handle writes the announcements and markets in an update, then queues
+// lookups for any events not written before.
+func (w *MetadataWriter) handle(ctx context.Context, update market.MetadataUpdate) {
+	observedAt := update.ObservedAt.UnixMicro()
+
+	if len(update.Announcements) > 0 {
+		rows := make([]metadataRow, 0, len(update.Announcements))
+		for _, a := range update.Announcements {
+			row := transformAnnouncement(a, observedAt)
+			rows = append(rows, metadataRow{Announcement: &row})
+		}
+		w.write(ctx, rows)
+	}
+
+	rows := make([]metadataRow, 0, len(update.Markets))
+	for i := range update.Markets {
+		row := transformMarket(&update.Markets[i], observedAt, update.Source)
+		rows = append(rows, metadataRow{Market: &row})
+	}
+	w.write(ctx, rows)
+
+	w.queueLookups(update.Markets)
+}
+
+// queueLookups hands the events of markets to resolveLoop, skipping events
+// already written or queued.
+func (w *MetadataWriter) queueLookups(markets []model.Market) {
+	w.lookupMu.Lock()
+	queued := false
+	for _, m := range markets {
+		if m.EventTicker == "" {
+			continue
+		}
+		if _, ok := w.knownEvents[m.EventTicker]; ok {
+			continue
+		}
+		if _, ok := w.queuedEvents[m.EventTicker]; ok {
+			continue
+		}
+		w.queuedEvents[m.EventTicker] = struct{}{}
+		w.pendingEvents = append(w.pendingEvents, m.EventTicker)
+		queued = true
+	}
+	w.lookupMu.Unlock()
+
+	if queued {
+		select {
+		case w.lookupReady <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// resolveLoop fetches and writes queued events until the writer stops.
+func (w *MetadataWriter) resolveLoop() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-w.lookupReady:
+			w.resolveQueued(w.ctx)
+		}
+	}
+}
+
+// resolveQueued fetches the queued events, and their series, and writes
+// them. Events whose lookup or write fails are dropped from the queue and
+// fetched again next time one of their markets is seen.
+func (w *MetadataWriter) resolveQueued(ctx context.Context) {
+	w.lookupMu.Lock()
+	eventTickers := w.pendingEvents
+	w.pendingEvents = nil
+	w.lookupMu.Unlock()
+
+	if len(eventTickers) == 0 {
+		return
+	}
+
+	rows := w.resolve(ctx, eventTickers)
+	written := len(rows) > 0 && w.write(ctx, rows)
+
+	w.lookupMu.Lock()
+	for _, ticker := range eventTickers {
+		delete(w.queuedEvents, ticker)
+	}
+	if written {
+		for _, row := range rows {
+			if row.Event != nil {
+				w.knownEvents[row.Event.EventTicker] = struct{}{}
+			}
+		}
+	}
+	w.lookupMu.Unlock()
+
+	if written {
+		for _, row := range rows {
+			if row.Series != nil {
+				w.knownSeries[row.Series.Ticker] = struct{}{}
+			}
+		}
+	}
+}
+
+// resolve fetches events, and their series if not written yet. Failed
+// lookups are logged and skipped.
+func (w *MetadataWriter) resolve(ctx context.Context, eventTickers []string) []metadataRow {
+	var rows []metadataRow
+	seenSeries := make(map[string]struct{})
+
+	for _, eventTicker := range eventTickers {
+		if ctx.Err() != nil {
+			return rows
+		}
+
+		apiEvent, err := w.source.GetEvent(ctx, eventTicker)
+		if err != nil {
+			w.logger.Warn("failed to fetch event", "event_ticker", eventTicker, "error", err)
+			continue
+		}
+		event := transformEvent(apiEvent.ToModel(), time.Now().UnixMicro())
+		rows = append(rows, metadataRow{Event: &event})
+
+		seriesTicker := event.SeriesTicker
+		if seriesTicker == "" {
+			continue
+		}
+		if _, ok := w.knownSeries[seriesTicker]; ok {
+			continue
+		}
+		if _, ok := seenSeries[seriesTicker]; ok {
+			continue
+		}
+		seenSeries[seriesTicker] = struct{}{}
+
+		apiSeries, err := w.source.GetSeries(ctx, seriesTicker)
+		if err != nil {
+			w.logger.Warn("failed to fetch series", "series_ticker", seriesTicker, "error", err)
+			continue
+		}
+		series := transformSeries(apiSeries.ToModel(), time.Now().UnixMicro())
+		rows = append(rows, metadataRow{Series: &series})
+	}
+
+	return rows
+}
+
+// write flushes rows in chunks of BatchSize and reports whether all were
+// written or spilled.
+func (w *MetadataWriter) write(ctx context.Context, rows []metadataRow) bool {
+	size := max(w.cfg.BatchSize, 1)
+	ok := true
+	for start := 0; start < len(rows); start += size {
+		end := min(start+size, len(rows))
+		if err := w.flush(ctx, rows[start:end]); err != nil {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// flush writes one batch, retrying and spilling to the dead-letter queue
+// on failure.
+func (w *MetadataWriter) flush(ctx context.Context, batch []metadataRow) error {
+	start := time.Now()
+
+	err := w.retry.write(ctx, batch)
+	observeFlush(w.cfg, "metadata", len(batch), start)
+	if err != nil {
+		w.logger.Error("metadata batch upsert failed", "error", err, "count", len(batch))
+		w.metricsMu.Lock()
+		w.metrics.Errors++
+		w.metricsMu.Unlock()
+		return err
+	}
+
+	w.logger.Debug("flushed metadata",
+		"count", len(batch),
+		"duration", time.Since(start),
+	)
+	return nil
+}
+
+// recordWrite counts a batch written to the database, directly or by
+// dead-letter replay.
+func (w *MetadataWriter) recordWrite(rows, conflicts int) {
+	w.metricsMu.Lock()
+	w.metrics.Inserts += int64(rows - conflicts)
+	w.metrics.Conflicts += int64(conflicts)
+	w.metrics.Flushes++
+	w.metricsMu.Unlock()
+}
+
+// transformMarket converts a model.Market to marketRow.
+func transformMarket(m *model.Market, observedAt int64, source string) marketRow {
+	return marketRow{
+		Ticker:        m.Ticker,
+		EventTicker:   m.EventTicker,
+		Title:         m.Title,
+		Subtitle:      m.Subtitle,
+		MarketStatus:  m.MarketStatus,
+		TradingStatus: m.TradingStatus,
+		MarketType:    m.MarketType,
+		Result:        m.Result,
+		Volume:        m.Volume,
+		Volume24h:     m.Volume24h,
+		OpenInterest:  m.OpenInterest,
+		OpenTS:        m.OpenTS,
+		CloseTS:       m.CloseTS,
+		ExpirationTS:  m.ExpirationTS,
+		CreatedTS:     m.CreatedTS,
+		ObservedAt:    observedAt,
+		Source:        source,
+
+		SettlementValue: m.SettlementValue,
+		SettledTS:       m.SettledTS,
+	}
+}
+
+// transformEvent converts a model.Event to eventRow.
+func transformEvent(e model.Event, observedAt int64) eventRow {
+	return eventRow{
+		EventTicker:  e.EventTicker,
+		SeriesTicker: e.SeriesTicker,
+		Title:        e.Title,
+		SubTitle:     e.SubTitle,
+		Category:     e.Category,
+		ObservedAt:   observedAt,
+	}
+}
+
+// transformSeries converts a model.Series to seriesRow.
+func transformSeries(s model.Series, observedAt int64) seriesRow {
+	tags, _ := json.Marshal(s.Tags)
+	sources, _ := json.Marshal(s.SettlementSources)
+	return seriesRow{
+		Ticker:            s.Ticker,
+		Title:             s.Title,
+		Category:          s.Category,
+		Frequency:         s.Frequency,
+		Tags:              tags,
+		SettlementSources: sources,
+		ObservedAt:        observedAt,
+	}
+}
+
+// transformAnnouncement converts a model.Announcement to announcementRow.
+func transformAnnouncement(a model.Announcement, observedAt int64) announcementRow {
+	return announcementRow{
+		ID:         a.ID,
+		Title:      a.Title,
+		Message:    a.Message,
+		Type:       a.Type,
+		Status:     a.Status,
+		CreatedTS:  a.CreatedTS,
+		DeliveryTS: a.DeliveryTS,
+		ObservedAt: observedAt,
+	}
+}
+
+// batchInsert upserts metadata rows in one batch, which Postgres runs as a
+// single implicit transaction. For each market the history row is inserted
+// first, comparing against the stored row before it is overwritten.
+func (w *MetadataWriter) batchInsert(ctx context.Context, rows []metadataRow) (conflicts int, err error) {
+	batch := &pgx.Batch{}
+	for _, r := range rows {
+		switch {
+		case r.Market != nil:
+			m := r.Market
+			batch.Queue(`
+				INSERT INTO market_status_history (ticker, changed_at, old_status, new_status, result, source)
+				SELECT $1::text, $2::bigint, m.market_status, $3::text, NULLIF($4::text, ''), $5::text
+				FROM (SELECT 1) AS one
+				LEFT JOIN markets m ON m.ticker = $1::text
+				WHERE m.market_status IS DISTINCT FROM $3::text
+				   OR m.result IS DISTINCT FROM NULLIF($4::text, '')
+				ON CONFLICT (ticker, changed_at, new_status) DO NOTHING
+			`, m.Ticker, m.ObservedAt, m.MarketStatus, m.Result, m.Source)
+			batch.Queue(`
+				INSERT INTO markets (ticker, event_ticker, title, subtitle, market_status, trading_status, market_type, result,
+					volume, volume_24h, open_interest, open_ts, close_ts, expiration_ts, created_ts, updated_at)
+				VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''),
+					$9, $10, $11, NULLIF($12, 0), NULLIF($13, 0), NULLIF($14, 0), NULLIF($15, 0), $16)
+				ON CONFLICT (ticker) DO UPDATE SET
+					event_ticker = EXCLUDED.event_ticker,
+					title = EXCLUDED.title,
+					subtitle = EXCLUDED.subtitle,
+					market_status = EXCLUDED.market_status,
+					trading_status = EXCLUDED.trading_status,
+					market_type = EXCLUDED.market_type,
+					result = EXCLUDED.result,
+					volume = EXCLUDED.volume,
+					volume_24h = EXCLUDED.volume_24h,
+					open_interest = EXCLUDED.open_interest,
+					open_ts = EXCLUDED.open_ts,
+					close_ts = EXCLUDED.close_ts,
+					expiration_ts = EXCLUDED.expiration_ts,
+					created_ts = EXCLUDED.created_ts,
+					updated_at = EXCLUDED.updated_at
+			`, m.Ticker, m.EventTicker, m.Title, m.Subtitle, m.MarketStatus, m.TradingStatus, m.MarketType, m.Result,
+				m.Volume, m.Volume24h, m.OpenInterest, m.OpenTS, m.CloseTS, m.ExpirationTS, m.CreatedTS, m.ObservedAt)
+
+			if m.Result == "" {
+				continue
+			}
+			// Known values are never replaced by unknown ones
+			batch.Queue(`
+				INSERT INTO market_settlements (ticker, result, settlement_value, settled_ts, final_volume, final_open_interest, received_at)
+				VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6, $7)
+				ON CONFLICT (ticker) DO UPDATE SET
+					result = EXCLUDED.result,
+					settlement_value = COALESCE(EXCLUDED.settlement_value, market_settlements.settlement_value),
+					settled_ts = COALESCE(EXCLUDED.settled_ts, market_settlements.settled_ts),
+					final_volume = EXCLUDED.final_volume,
+					final_open_interest = EXCLUDED.final_open_interest,
+					received_at = EXCLUDED.received_at
+			`, m.Ticker, m.Result, m.SettlementValue, m.SettledTS, m.Volume, m.OpenInterest, m.ObservedAt)
+
+		case r.Event != nil:
+			e := r.Event
+			batch.Queue(`
+				INSERT INTO events (event_ticker, series_ticker, title, sub_title, category, updated_at)
+				VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), NULLIF($5, ''), $6)
+				ON CONFLICT (event_ticker) DO UPDATE SET
+					series_ticker = EXCLUDED.series_ticker,
+					title = EXCLUDED.title,
+					sub_title = EXCLUDED.sub_title,
+					category = EXCLUDED.category,
+					updated_at = EXCLUDED.updated_at
+			`, e.EventTicker, e.SeriesTicker, e.Title, e.SubTitle, e.Category, e.ObservedAt)
+
+		case r.Series != nil:
+			s := r.Series
+			batch.Queue(`
+				INSERT INTO series (ticker, title, category, frequency, tags, settlement_sources, updated_at)
+				VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7)
+				ON CONFLICT (ticker) DO UPDATE SET
+					title = EXCLUDED.title,
+					category = EXCLUDED.category,
+					frequency = EXCLUDED.frequency,
+					tags = EXCLUDED.tags,
+					settlement_sources = EXCLUDED.settlement_sources,
+					updated_at = EXCLUDED.updated_at
+			`, s.Ticker, s.Title, s.Category, s.Frequency, s.Tags, s.SettlementSources, s.ObservedAt)
+
+		case r.Announcement != nil:
+			a := r.Announcement
+			batch.Queue(`
+				INSERT INTO exchange_announcements (id, title, message, type, status, created_ts, delivery_ts, updated_at)
+				VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, 0), NULLIF($7, 0), $8)
+				ON CONFLICT (id) DO UPDATE SET
+					title = EXCLUDED.title,
+					message = EXCLUDED.message,
+					type = EXCLUDED.type,
+					status = EXCLUDED.status,
+					created_ts = EXCLUDED.created_ts,
+					delivery_ts = EXCLUDED.delivery_ts,
+					updated_at = EXCLUDED.updated_at
+			`, a.ID, a.Title, a.Message, a.Type, a.Status, a.CreatedTS, a.DeliveryTS, a.ObservedAt)
+		}
+	}
+
+	results := w.db.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range batch.Len() {
+		if _, err := results.Exec(); err != nil {
+			return 0, err
+		}
+	}
+
+	return 0, nil
+}
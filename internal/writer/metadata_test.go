@@ -0,0 +1,268 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rickgao/kalshi-data/internal/api"
+	"github.com/rickgao/kalshi-data/internal/market"
+	"github.com/rickgao/kalshi-data/internal/model"
+)
+
+// fakeMetadataSource serves events and series from maps, counting lookups.
+type fakeMetadataSource struct {
+	events      map[string]api.APIEvent
+	series      map[string]api.APISeries
+	eventCalls  int
+	seriesCalls int
+}
+
+func (f *fakeMetadataSource) GetEvent(_ context.Context, ticker string) (*api.APIEvent, error) {
+	f.eventCalls++
+	e, ok := f.events[ticker]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &e, nil
+}
+
+func (f *fakeMetadataSource) GetSeries(_ context.Context, ticker string) (*api.APISeries, error) {
+	f.seriesCalls++
+	s, ok := f.series[ticker]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &s, nil
+}
+
+// newTestMetadataWriter returns a writer whose batches are captured instead
+// of sent to a database.
+func newTestMetadataWriter(source MetadataSource, batchSize int) (*MetadataWriter, *[][]metadataRow) {
+	cfg := DefaultWriterConfig()
+	cfg.BatchSize = batchSize
+	w := NewMetadataWriter(cfg, nil, source, nil, nil)
+
+	var written [][]metadataRow
+	insert := func(_ context.Context, rows []metadataRow) (int, error) {
+		written = append(written, rows)
+		return 0, nil
+	}
+	w.retry = newRetrier("metadata", cfg.Retry, insert, w.recordWrite, nil)
+	return w, &written
+}
+
+func TestMetadataWriter_Handle(t *testing.T) {
+	source := &fakeMetadataSource{
+		events: map[string]api.APIEvent{
+			"PRES-2024": {EventTicker: "PRES-2024", SeriesTicker: "PRES", Title: "Presidential Election", Category: "Politics"},
+		},
+		series: map[string]api.APISeries{
+			"PRES": {Ticker: "PRES", Title: "Presidential", Tags: []string{"us"}},
+		},
+	}
+	w, written := newTestMetadataWriter(source, 100)
+
+	observed := time.UnixMicro(1705320000000000)
+	w.handle(context.Background(), market.MetadataUpdate{
+		Markets: []model.Market{
+			{Ticker: "PRES-2024-DEM", EventTicker: "PRES-2024", MarketStatus: "open"},
+			{Ticker: "PRES-2024-REP", EventTicker: "PRES-2024", MarketStatus: "open"},
+		},
+		Source:     market.SourceSync,
+		ObservedAt: observed,
+	})
+	w.resolveQueued(context.Background())
+
+	if len(*written) != 2 {
+		t.Fatalf("batches = %d, want 2 (markets, then event and series)", len(*written))
+	}
+
+	markets := (*written)[0]
+	if len(markets) != 2 || markets[0].Market == nil {
+		t.Fatalf("first batch = %+v, want 2 markets", markets)
+	}
+	if got := markets[0].Market; got.ObservedAt != 1705320000000000 || got.Source != "sync" {
+		t.Errorf("ObservedAt, Source = %d, %q, want 1705320000000000, sync", got.ObservedAt, got.Source)
+	}
+
+	resolved := (*written)[1]
+	if len(resolved) != 2 || resolved[0].Event == nil || resolved[1].Series == nil {
+		t.Fatalf("second batch = %+v, want event then series", resolved)
+	}
+	if got := resolved[0].Event; got.Title != "Presidential Election" || got.SeriesTicker != "PRES" {
+		t.Errorf("event = %+v", got)
+	}
+	if got := string(resolved[1].Series.Tags); got != `{"us":"true"}` {
+		t.Errorf("series tags = %s, want {\"us\":\"true\"}", got)
+	}
+	if source.eventCalls != 1 || source.seriesCalls != 1 {
+		t.Errorf("lookups = %d events, %d series, want 1, 1", source.eventCalls, source.seriesCalls)
+	}
+
+	// Known events are not fetched again
+	w.handle(context.Background(), market.MetadataUpdate{
+		Markets:    []model.Market{{Ticker: "PRES-2024-DEM", EventTicker: "PRES-2024", MarketStatus: "settled", Result: "yes"}},
+		Source:     market.SourceLifecycle,
+		ObservedAt: observed,
+	})
+	w.resolveQueued(context.Background())
+	if source.eventCalls != 1 {
+		t.Errorf("eventCalls = %d, want 1", source.eventCalls)
+	}
+	if len(*written) != 3 {
+		t.Errorf("batches = %d, want 3", len(*written))
+	}
+	if got := w.Stats(); got.Inserts != 5 || got.Flushes != 3 {
+		t.Errorf("Inserts = %d, Flushes = %d, want 5, 3", got.Inserts, got.Flushes)
+	}
+}
+
+func TestMetadataWriter_FailedLookupRetried(t *testing.T) {
+	source := &fakeMetadataSource{}
+	w, written := newTestMetadataWriter(source, 100)
+
+	update := market.MetadataUpdate{
+		Markets:    []model.Market{{Ticker: "A-1", EventTicker: "A"}, {Ticker: "A-2", EventTicker: "A"}},
+		Source:     market.SourceReconcile,
+		ObservedAt: time.Now(),
+	}
+	w.handle(context.Background(), update)
+	w.resolveQueued(context.Background())
+
+	if len(*written) != 1 {
+		t.Errorf("batches = %d, want 1 (markets only)", len(*written))
+	}
+	if source.eventCalls != 1 {
+		t.Errorf("eventCalls = %d, want 1", source.eventCalls)
+	}
+
+	// The event is looked up again on the next update
+	source.events = map[string]api.APIEvent{"A": {EventTicker: "A"}}
+	w.handle(context.Background(), update)
+	w.resolveQueued(context.Background())
+
+	if source.eventCalls != 2 {
+		t.Errorf("eventCalls = %d, want 2", source.eventCalls)
+	}
+	if source.seriesCalls != 0 {
+		t.Errorf("seriesCalls = %d, want 0 (event has no series)", source.seriesCalls)
+	}
+}
+
+func TestMetadataWriter_HandleQueuesLookups(t *testing.T) {
+	source := &fakeMetadataSource{
+		events: map[string]api.APIEvent{"A": {EventTicker: "A"}},
+	}
+	w, written := newTestMetadataWriter(source, 100)
+
+	update := market.MetadataUpdate{
+		Markets:    []model.Market{{Ticker: "A-1", EventTicker: "A"}},
+		Source:     market.SourceLifecycle,
+		ObservedAt: time.Now(),
+	}
+	w.handle(context.Background(), update)
+	w.handle(context.Background(), update)
+
+	// Markets are written without waiting on REST
+	if source.eventCalls != 0 {
+		t.Errorf("eventCalls = %d, want 0 before resolving", source.eventCalls)
+	}
+	if len(*written) != 2 {
+		t.Fatalf("batches = %d, want 2 (markets only)", len(*written))
+	}
+
+	// The event was queued once
+	w.resolveQueued(context.Background())
+	if source.eventCalls != 1 {
+		t.Errorf("eventCalls = %d, want 1", source.eventCalls)
+	}
+	if len(*written) != 3 || (*written)[2][0].Event == nil {
+		t.Errorf("batches = %+v, want event written third", *written)
+	}
+}
+
+func TestMetadataWriter_HandleAnnouncements(t *testing.T) {
+	source := &fakeMetadataSource{}
+	w, written := newTestMetadataWriter(source, 100)
+
+	w.handle(context.Background(), market.MetadataUpdate{
+		Announcements: []model.Announcement{
+			{ID: "ann-1", Type: "maintenance", Status: "active", DeliveryTS: 1705320000000000},
+		},
+		Source:     market.SourceAnnouncements,
+		ObservedAt: time.UnixMicro(1705320001000000),
+	})
+
+	if len(*written) != 1 || len((*written)[0]) != 1 {
+		t.Fatalf("batches = %+v, want one announcement", *written)
+	}
+	got := (*written)[0][0].Announcement
+	if got == nil {
+		t.Fatal("row is not an announcement")
+	}
+	want := announcementRow{ID: "ann-1", Type: "maintenance", Status: "active", DeliveryTS: 1705320000000000, ObservedAt: 1705320001000000}
+	if *got != want {
+		t.Errorf("announcement = %+v, want %+v", *got, want)
+	}
+	if source.eventCalls != 0 {
+		t.Errorf("eventCalls = %d, want 0", source.eventCalls)
+	}
+}
+
+func TestMetadataWriter_WriteChunks(t *testing.T) {
+	w, written := newTestMetadataWriter(&fakeMetadataSource{}, 2)
+
+	rows := make([]metadataRow, 5)
+	if !w.write(context.Background(), rows) {
+		t.Fatal("write() = false, want true")
+	}
+
+	var sizes []int
+	for _, batch := range *written {
+		sizes = append(sizes, len(batch))
+	}
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[1] != 2 || sizes[2] != 1 {
+		t.Errorf("batch sizes = %v, want [2 2 1]", sizes)
+	}
+}
+
+func TestTransformMarket(t *testing.T) {
+	settled := 100000
+	m := model.Market{
+		Ticker:        "PRES-2024-DEM",
+		EventTicker:   "PRES-2024",
+		Title:         "Democrat wins",
+		MarketStatus:  "settled",
+		TradingStatus: "settled",
+		MarketType:    "binary",
+		Result:        "yes",
+		Volume:        1000,
+		CloseTS:       1705320000000000,
+
+		SettlementValue: &settled,
+		SettledTS:       1705328200000000,
+	}
+
+	got := transformMarket(&m, 42, market.SourceLifecycle)
+	want := marketRow{
+		Ticker:        "PRES-2024-DEM",
+		EventTicker:   "PRES-2024",
+		Title:         "Democrat wins",
+		MarketStatus:  "settled",
+		TradingStatus: "settled",
+		MarketType:    "binary",
+		Result:        "yes",
+		Volume:        1000,
+		CloseTS:       1705320000000000,
+		ObservedAt:    42,
+		Source:        "lifecycle",
+
+		SettlementValue: &settled,
+		SettledTS:       1705328200000000,
+	}
+	if got != want {
+		t.Errorf("transformMarket() = %+v, want %+v", got, want)
+	}
+}
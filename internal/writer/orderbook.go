@@ -30,6 +30,15 @@ type OrderbookWriter struct {
 	batchMu       sync.Mutex
 	flushTicker   *time.Ticker
 
+	// Per-ticker snapshot SIDs and delta ordinals, guarded by batchMu.
+	// Tickers idle for tickerStateTTL are evicted on flush, so settled and
+	// unsubscribed markets do not accumulate.
+	tickers map[string]*tickerState
+
+	// Retries and dead-letter queues for failed flushes
+	deltaRetry    *retrier[orderbookDeltaRow]
+	snapshotRetry *retrier[orderbookSnapshotRow]
+
 	// Lifecycle
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -41,13 +50,66 @@ type OrderbookWriter struct {
 
 // OrderbookWriterMetrics extends WriterMetrics with delta/snapshot breakdown.
 type OrderbookWriterMetrics struct {
-	DeltaInserts    int64
-	DeltaConflicts  int64
-	DeltaErrors     int64
-	SnapshotInserts int64
-	SnapshotErrors  int64
-	SeqGaps         int64
-	Flushes         int64
+	DeltaInserts      int64
+	DeltaConflicts    int64
+	DeltaErrors       int64
+	SnapshotInserts   int64
+	SnapshotConflicts int64
+	SnapshotErrors    int64
+	SeqGaps           int64
+	Flushes           int64
+
+	// StaleDeltas counts deltas dropped because a newer snapshot for the
+	// ticker arrived under another SID.
+	StaleDeltas int64
+
+	// DeltaCopyFallbacks counts delta COPY flushes retried as batch inserts.
+	DeltaCopyFallbacks int64
+
+	// DeltaRetry and SnapshotRetry report retries and dead-letter activity
+	// for each table.
+	DeltaRetry    RetryMetrics
+	SnapshotRetry RetryMetrics
+}
+
+// tickerStateTTL is how long a ticker's state outlives its last message.
+// It is far longer than a market takes to move between connections, and a
+// delta after it starts a new exchange second anyway.
+const tickerStateTTL = 10 * time.Minute
+
+// tickerState is what the writer remembers about a ticker between messages.
+type tickerState struct {
+	// snapshotSID is the SID of the ticker's latest snapshot (0 if none).
+	// While a market moves between connections both SIDs carry it; deltas
+	// from the old one after the new snapshot are dropped.
+	snapshotSID int64
+
+	ordinals deltaOrdinals
+	lastSeen time.Time // ReceivedAt of the latest message
+}
+
+// deltaOrdinals numbers a ticker's deltas within its current exchange
+// second. exchange_ts has one-second resolution, so several deltas can share
+// (exchange_ts, ticker, side, price); the ordinal tells them apart.
+//
+// The counts restart only when a delta starts a new exchange second. A
+// snapshot does not restart them: WS snapshots carry no exchange timestamp,
+// and a resubscribe often lands mid-second, so restarting would hand a
+// later delta at the same level an ordinal already written and drop it.
+// Between snapshots a gatherer sees every delta in seq order, so gatherers
+// agree on the ordinals of every exchange second in which neither received
+// a snapshot. In the second a snapshot arrives (first subscribe,
+// resubscribe after a gap, restart), a gatherer may have missed or counted
+// deltas the others did not, so those ordinals can differ between gatherers.
+type deltaOrdinals struct {
+	exchangeTs int64
+	counts     map[deltaLevel]int
+}
+
+// deltaLevel is one side of one price level.
+type deltaLevel struct {
+	side  bool
+	price int
 }
 
 // NewOrderbookWriter creates a new OrderbookWriter.
@@ -60,20 +122,26 @@ func NewOrderbookWriter(
 	if logger == nil {
 		logger = slog.Default()
 	}
-	return &OrderbookWriter{
+	w := &OrderbookWriter{
 		cfg:           cfg,
 		input:         input,
 		db:            db,
 		logger:        logger,
 		deltaBatch:    make([]orderbookDeltaRow, 0, cfg.BatchSize),
 		snapshotBatch: make([]orderbookSnapshotRow, 0, 100), // Snapshots are less frequent
+		tickers:       make(map[string]*tickerState),
 	}
+	w.deltaRetry = newRetrier("orderbook", cfg.Retry, w.insertDeltas, w.recordDeltas, logger)
+	w.snapshotRetry = newRetrier("orderbook_snapshot", cfg.Retry, w.insertSnapshots, w.recordSnapshots, logger)
+	return w
 }
 
 // Start begins consuming messages and writing to the database.
 func (w *OrderbookWriter) Start(ctx context.Context) error {
 	w.ctx, w.cancel = context.WithCancel(ctx)
 	w.flushTicker = time.NewTicker(w.cfg.FlushInterval)
+	w.deltaRetry.open()
+	w.snapshotRetry.open()
 
 	// Consumer goroutine
 	w.wg.Add(1)
@@ -83,6 +151,11 @@ func (w *OrderbookWriter) Start(ctx context.Context) error {
 	w.wg.Add(1)
 	go w.flushLoop()
 
+	// Dead-letter replay goroutines
+	w.wg.Add(2)
+	go w.deltaRetry.replayLoop(w.ctx, &w.wg)
+	go w.snapshotRetry.replayLoop(w.ctx, &w.wg)
+
 	w.logger.Info("orderbook writer started",
 		"batch_size", w.cfg.BatchSize,
 		"flush_interval", w.cfg.FlushInterval,
@@ -116,8 +189,10 @@ func (w *OrderbookWriter) Stop(ctx context.Context) error {
 		w.logger.Warn("orderbook writer stop timed out")
 	}
 
-	// Final flush
-	w.flush()
+	// Final flush and queued retries use the caller's context; w.ctx is already canceled.
+	w.flush(ctx)
+	w.deltaRetry.close(ctx)
+	w.snapshotRetry.close(ctx)
 
 	return nil
 }
@@ -125,8 +200,11 @@ func (w *OrderbookWriter) Stop(ctx context.Context) error {
 // Stats returns current metrics.
 func (w *OrderbookWriter) Stats() OrderbookWriterMetrics {
 	w.batchMu.Lock()
-	defer w.batchMu.Unlock()
-	return w.metrics
+	m := w.metrics
+	w.batchMu.Unlock()
+	m.DeltaRetry = w.deltaRetry.stats()
+	m.SnapshotRetry = w.snapshotRetry.stats()
+	return m
 }
 
 // consumeLoop reads from the input buffer and accumulates batches.
@@ -162,7 +240,7 @@ func (w *OrderbookWriter) flushLoop() {
 		case <-w.ctx.Done():
 			return
 		case <-w.flushTicker.C:
-			w.flush()
+			w.flush(w.ctx)
 		}
 	}
 }
@@ -184,16 +262,27 @@ func (w *OrderbookWriter) handleMessage(msg router.OrderbookMsg) {
 	case "snapshot":
 		row := w.transformSnapshot(msg)
 		w.batchMu.Lock()
+		state := w.tickerState(msg)
+		if msg.SID != 0 {
+			state.snapshotSID = msg.SID
+		}
 		w.snapshotBatch = append(w.snapshotBatch, row)
 		w.batchMu.Unlock()
 	case "delta":
 		row := w.transformDelta(msg)
 		w.batchMu.Lock()
+		state := w.tickerState(msg)
+		if state.snapshotSID != 0 && state.snapshotSID != msg.SID {
+			w.metrics.StaleDeltas++
+			w.batchMu.Unlock()
+			return
+		}
+		row.Ordinal = state.ordinals.next(row)
 		w.deltaBatch = append(w.deltaBatch, row)
 		shouldFlush := len(w.deltaBatch) >= w.cfg.BatchSize
 		w.batchMu.Unlock()
 		if shouldFlush {
-			w.flush()
+			w.flush(w.ctx)
 		}
 	default:
 		w.logger.Warn("unknown orderbook message type", "type", msg.Type)
@@ -214,6 +303,43 @@ func (w *OrderbookWriter) transformDelta(msg router.OrderbookMsg) orderbookDelta
 	}
 }
 
+// tickerState returns msg's ticker state, creating it if needed, and marks
+// the ticker as seen. Callers hold batchMu.
+func (w *OrderbookWriter) tickerState(msg router.OrderbookMsg) *tickerState {
+	state, ok := w.tickers[msg.Ticker]
+	if !ok {
+		state = &tickerState{}
+		w.tickers[msg.Ticker] = state
+	}
+	state.lastSeen = msg.ReceivedAt
+	return state
+}
+
+// evictIdleTickers forgets tickers with no message since before cutoff.
+// Callers hold batchMu.
+func (w *OrderbookWriter) evictIdleTickers(cutoff time.Time) {
+	for ticker, state := range w.tickers {
+		if state.lastSeen.Before(cutoff) {
+			delete(w.tickers, ticker)
+		}
+	}
+}
+
+// next returns the ordinal for a delta: the number of earlier deltas at
+// the same side and price in the same exchange second of its ticker. See
+// deltaOrdinals for when gatherers agree.
+func (o *deltaOrdinals) next(row orderbookDeltaRow) int {
+	if o.counts == nil || o.exchangeTs != row.ExchangeTs {
+		o.exchangeTs = row.ExchangeTs
+		o.counts = make(map[deltaLevel]int)
+	}
+
+	level := deltaLevel{side: row.Side, price: row.Price}
+	ordinal := o.counts[level]
+	o.counts[level]++
+	return ordinal
+}
+
 // transformSnapshot converts an OrderbookMsg (snapshot) to orderbookSnapshotRow.
 func (w *OrderbookWriter) transformSnapshot(msg router.OrderbookMsg) orderbookSnapshotRow {
 	yesBids := priceLevelsToJSONB(msg.Yes)
@@ -248,13 +374,15 @@ func (w *OrderbookWriter) transformSnapshot(msg router.OrderbookMsg) orderbookSn
 	}
 }
 
-// flush writes both batches to the database.
-func (w *OrderbookWriter) flush() {
+// flush writes both batches to the database, retrying and spilling to the
+// dead-letter queues on failure.
+func (w *OrderbookWriter) flush(ctx context.Context) {
 	w.batchMu.Lock()
 	deltaBatch := w.deltaBatch
 	snapshotBatch := w.snapshotBatch
 	w.deltaBatch = make([]orderbookDeltaRow, 0, w.cfg.BatchSize)
 	w.snapshotBatch = make([]orderbookSnapshotRow, 0, 100)
+	w.evictIdleTickers(time.Now().Add(-tickerStateTTL))
 	w.batchMu.Unlock()
 
 	if len(deltaBatch) == 0 && len(snapshotBatch) == 0 {
@@ -265,32 +393,27 @@ func (w *OrderbookWriter) flush() {
 
 	// Flush deltas
 	if len(deltaBatch) > 0 {
-		conflicts, err := w.batchInsertDeltas(deltaBatch)
+		deltaStart := time.Now()
+		err := w.deltaRetry.write(ctx, deltaBatch)
+		observeFlush(w.cfg, "orderbook", len(deltaBatch), deltaStart)
 		if err != nil {
 			w.logger.Error("delta batch insert failed", "error", err, "count", len(deltaBatch))
 			w.batchMu.Lock()
 			w.metrics.DeltaErrors++
 			w.batchMu.Unlock()
-		} else {
-			w.batchMu.Lock()
-			w.metrics.DeltaInserts += int64(len(deltaBatch) - conflicts)
-			w.metrics.DeltaConflicts += int64(conflicts)
-			w.batchMu.Unlock()
 		}
 	}
 
 	// Flush snapshots
 	if len(snapshotBatch) > 0 {
-		err := w.batchInsertSnapshots(snapshotBatch)
+		snapshotStart := time.Now()
+		err := w.snapshotRetry.write(ctx, snapshotBatch)
+		observeFlush(w.cfg, "orderbook_snapshot", len(snapshotBatch), snapshotStart)
 		if err != nil {
 			w.logger.Error("snapshot batch insert failed", "error", err, "count", len(snapshotBatch))
 			w.batchMu.Lock()
 			w.metrics.SnapshotErrors++
 			w.batchMu.Unlock()
-		} else {
-			w.batchMu.Lock()
-			w.metrics.SnapshotInserts += int64(len(snapshotBatch))
-			w.batchMu.Unlock()
 		}
 	}
 
@@ -305,18 +428,52 @@ func (w *OrderbookWriter) flush() {
 	)
 }
 
+// recordDeltas counts a delta batch written to the database, directly or by
+// dead-letter replay.
+func (w *OrderbookWriter) recordDeltas(rows, conflicts int) {
+	w.batchMu.Lock()
+	w.metrics.DeltaInserts += int64(rows - conflicts)
+	w.metrics.DeltaConflicts += int64(conflicts)
+	w.batchMu.Unlock()
+}
+
+// recordSnapshots counts a snapshot batch written to the database, directly
+// or by dead-letter replay.
+func (w *OrderbookWriter) recordSnapshots(rows, conflicts int) {
+	w.batchMu.Lock()
+	w.metrics.SnapshotInserts += int64(rows - conflicts)
+	w.metrics.SnapshotConflicts += int64(conflicts)
+	w.batchMu.Unlock()
+}
+
+// insertDeltas writes rows with COPY when configured and the batch is large
+// enough, falling back to batchInsertDeltas otherwise or if COPY fails.
+func (w *OrderbookWriter) insertDeltas(ctx context.Context, rows []orderbookDeltaRow) (conflicts int, err error) {
+	if useCopy(w.cfg, len(rows)) {
+		conflicts, err := copyInsert(ctx, w.db, deltaCopy, deltaCopyRows(rows))
+		if err == nil {
+			return conflicts, nil
+		}
+		w.logger.Warn("copy insert failed, falling back to batch insert", "error", err, "count", len(rows))
+		w.batchMu.Lock()
+		w.metrics.DeltaCopyFallbacks++
+		w.batchMu.Unlock()
+	}
+	return w.batchInsertDeltas(ctx, rows)
+}
+
 // batchInsertDeltas inserts delta rows with ON CONFLICT DO NOTHING.
-func (w *OrderbookWriter) batchInsertDeltas(rows []orderbookDeltaRow) (conflicts int, err error) {
+func (w *OrderbookWriter) batchInsertDeltas(ctx context.Context, rows []orderbookDeltaRow) (conflicts int, err error) {
 	batch := &pgx.Batch{}
 	for _, r := range rows {
 		batch.Queue(`
-			INSERT INTO orderbook_deltas (exchange_ts, received_at, ticker, side, price, size_delta, seq, sid)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-			ON CONFLICT (exchange_ts, ticker, price, side) DO NOTHING
-		`, r.ExchangeTs, r.ReceivedAt, r.Ticker, r.Side, r.Price, r.SizeDelta, r.Seq, r.SID)
+			INSERT INTO orderbook_deltas (exchange_ts, received_at, ticker, side, price, size_delta, seq, ordinal, sid)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (exchange_ts, ticker, price, side, ordinal) DO NOTHING
+		`, r.ExchangeTs, r.ReceivedAt, r.Ticker, r.Side, r.Price, r.SizeDelta, r.Seq, r.Ordinal, r.SID)
 	}
 
-	results := w.db.SendBatch(w.ctx, batch)
+	results := w.db.SendBatch(ctx, batch)
 	defer results.Close()
 
 	for range rows {
@@ -332,25 +489,7 @@ func (w *OrderbookWriter) batchInsertDeltas(rows []orderbookDeltaRow) (conflicts
 	return conflicts, nil
 }
 
-// batchInsertSnapshots inserts snapshot rows with ON CONFLICT DO NOTHING.
-func (w *OrderbookWriter) batchInsertSnapshots(rows []orderbookSnapshotRow) error {
-	batch := &pgx.Batch{}
-	for _, r := range rows {
-		batch.Queue(`
-			INSERT INTO orderbook_snapshots (snapshot_ts, exchange_ts, ticker, source, yes_bids, yes_asks, no_bids, no_asks, best_yes_bid, best_yes_ask, spread, sid)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-			ON CONFLICT (snapshot_ts, ticker, source) DO NOTHING
-		`, r.SnapshotTs, r.ExchangeTs, r.Ticker, r.Source, r.YesBids, r.YesAsks, r.NoBids, r.NoAsks, r.BestYesBid, r.BestYesAsk, r.Spread, r.SID)
-	}
-
-	results := w.db.SendBatch(w.ctx, batch)
-	defer results.Close()
-
-	for range rows {
-		if _, err := results.Exec(); err != nil {
-			return err
-		}
-	}
-
-	return nil
+// insertSnapshots writes WS snapshot rows.
+func (w *OrderbookWriter) insertSnapshots(ctx context.Context, rows []orderbookSnapshotRow) (conflicts int, err error) {
+	return insertSnapshots(ctx, w.db, rows)
 }
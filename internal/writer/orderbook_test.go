@@ -233,6 +233,93 @@ func TestOrderbookWriter_HandleMessage_Delta(t *testing.T) {
 	}
 }
 
+func TestOrderbookWriter_HandleMessage_Ordinal(t *testing.T) {
+	cfg := WriterConfig{
+		BatchSize:     100,
+		FlushInterval: time.Hour,
+	}
+	input := router.NewGrowableBuffer[router.OrderbookMsg](10)
+	w := NewOrderbookWriter(cfg, input, nil, nil)
+
+	delta := func(ticker string, ts int64, side, price string) router.OrderbookMsg {
+		return router.OrderbookMsg{
+			Type:         "delta",
+			Ticker:       ticker,
+			ExchangeTs:   ts,
+			PriceDollars: price,
+			Delta:        1,
+			Side:         side,
+			ReceivedAt:   time.Now(),
+		}
+	}
+
+	msgs := []router.OrderbookMsg{
+		delta("A", 1_000_000, "yes", "0.50"), // 0
+		delta("A", 1_000_000, "yes", "0.50"), // same level and second: 1
+		delta("A", 1_000_000, "no", "0.50"),  // other side: 0
+		delta("A", 1_000_000, "yes", "0.51"), // other price: 0
+		delta("B", 1_000_000, "yes", "0.50"), // other ticker: 0
+		delta("A", 1_000_000, "yes", "0.50"), // 2
+		delta("A", 2_000_000, "yes", "0.50"), // next second resets: 0
+		delta("A", 2_000_000, "yes", "0.50"), // 1
+		delta("B", 1_000_000, "yes", "0.50"), // other ticker unaffected: 1
+	}
+	want := []int{0, 1, 0, 0, 0, 2, 0, 1, 1}
+
+	for _, msg := range msgs {
+		w.handleMessage(msg)
+	}
+
+	w.batchMu.Lock()
+	defer w.batchMu.Unlock()
+
+	if len(w.deltaBatch) != len(want) {
+		t.Fatalf("deltaBatch length = %d, want %d", len(w.deltaBatch), len(want))
+	}
+	for i, row := range w.deltaBatch {
+		if row.Ordinal != want[i] {
+			t.Errorf("deltaBatch[%d].Ordinal = %d, want %d", i, row.Ordinal, want[i])
+		}
+	}
+}
+
+func TestOrderbookWriter_HandleMessage_OrdinalAcrossSnapshot(t *testing.T) {
+	cfg := WriterConfig{
+		BatchSize:     100,
+		FlushInterval: time.Hour,
+	}
+	input := router.NewGrowableBuffer[router.OrderbookMsg](10)
+	w := NewOrderbookWriter(cfg, input, nil, nil)
+
+	delta := router.OrderbookMsg{
+		Type:         "delta",
+		Ticker:       "A",
+		ExchangeTs:   1_000_000,
+		PriceDollars: "0.50",
+		Delta:        1,
+		Side:         "yes",
+		SID:          1,
+		ReceivedAt:   time.Now(),
+	}
+
+	// A resubscribe lands mid-second: delta, snapshot, delta in one second
+	// at one level
+	w.handleMessage(delta)
+	w.handleMessage(router.OrderbookMsg{Type: "snapshot", Ticker: "A", SID: 2, ReceivedAt: time.Now()})
+	delta.SID = 2
+	w.handleMessage(delta)
+
+	w.batchMu.Lock()
+	defer w.batchMu.Unlock()
+
+	if len(w.deltaBatch) != 2 {
+		t.Fatalf("deltaBatch length = %d, want 2", len(w.deltaBatch))
+	}
+	if a, b := w.deltaBatch[0].Ordinal, w.deltaBatch[1].Ordinal; a == b {
+		t.Errorf("ordinals = %d, %d, want distinct", a, b)
+	}
+}
+
 func TestOrderbookWriter_HandleMessage_Snapshot(t *testing.T) {
 	cfg := WriterConfig{
 		BatchSize:     100,
@@ -298,3 +385,73 @@ func TestOrderbookWriter_Stats(t *testing.T) {
 		t.Errorf("initial SnapshotInserts = %d, want 0", stats.SnapshotInserts)
 	}
 }
+
+func TestOrderbookWriter_HandleMessage_StaleSID(t *testing.T) {
+	cfg := WriterConfig{
+		BatchSize:     100,
+		FlushInterval: time.Hour,
+	}
+	input := router.NewGrowableBuffer[router.OrderbookMsg](10)
+	w := NewOrderbookWriter(cfg, input, nil, nil)
+
+	msg := func(typ string, sid int64) router.OrderbookMsg {
+		return router.OrderbookMsg{
+			Type:         typ,
+			Ticker:       "TEST",
+			SID:          sid,
+			PriceDollars: "0.50",
+			Delta:        1,
+			Side:         "yes",
+			ReceivedAt:   time.Now(),
+		}
+	}
+
+	// The market moves from SID 1 to SID 2: both deliver it until SID 1
+	// is removed
+	w.handleMessage(msg("snapshot", 1))
+	w.handleMessage(msg("delta", 1))
+	w.handleMessage(msg("snapshot", 2))
+	w.handleMessage(msg("delta", 1)) // Stale
+	w.handleMessage(msg("delta", 2))
+
+	w.batchMu.Lock()
+	var sids []int64
+	for _, row := range w.deltaBatch {
+		sids = append(sids, row.SID)
+	}
+	w.batchMu.Unlock()
+
+	if len(sids) != 2 || sids[0] != 1 || sids[1] != 2 {
+		t.Errorf("delta SIDs = %v, want [1 2]", sids)
+	}
+	if got := w.Stats().StaleDeltas; got != 1 {
+		t.Errorf("StaleDeltas = %d, want 1", got)
+	}
+}
+
+func TestOrderbookWriter_EvictIdleTickers(t *testing.T) {
+	cfg := WriterConfig{
+		BatchSize:     100,
+		FlushInterval: time.Hour,
+	}
+	input := router.NewGrowableBuffer[router.OrderbookMsg](10)
+	w := NewOrderbookWriter(cfg, input, nil, nil)
+
+	now := time.Now()
+	w.handleMessage(router.OrderbookMsg{Type: "snapshot", Ticker: "SETTLED", SID: 1, ReceivedAt: now.Add(-time.Hour)})
+	w.handleMessage(router.OrderbookMsg{Type: "snapshot", Ticker: "LIVE", SID: 2, ReceivedAt: now.Add(-time.Hour)})
+	w.handleMessage(router.OrderbookMsg{Type: "delta", Ticker: "LIVE", SID: 2, PriceDollars: "0.50", Side: "yes", ReceivedAt: now})
+
+	w.batchMu.Lock()
+	w.evictIdleTickers(now.Add(-tickerStateTTL))
+	_, settled := w.tickers["SETTLED"]
+	live, ok := w.tickers["LIVE"]
+	w.batchMu.Unlock()
+
+	if settled {
+		t.Error("idle ticker SETTLED not evicted")
+	}
+	if !ok || live.snapshotSID != 2 {
+		t.Errorf("LIVE state = %+v, want kept with snapshot SID 2", live)
+	}
+}
@@ -0,0 +1,523 @@
+package writer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// RetryConfig controls how failed flushes are retried and, when the
+// database stays down, spilled to a local dead-letter queue.
+type RetryConfig struct {
+	// MaxAttempts is the number of insert attempts per batch, including
+	// the first. Values below 1 mean a single attempt.
+	MaxAttempts int
+
+	// BaseDelay is the wait after the first failed attempt, doubling up to
+	// MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// DeadLetterDir, if set, receives batches that still fail after
+	// MaxAttempts, one subdirectory per writer. Empty drops them.
+	DeadLetterDir string
+
+	// ReplayInterval is how often spilled batches are retried.
+	ReplayInterval time.Duration
+}
+
+// DefaultRetryConfig returns sensible defaults with spilling disabled.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:    3,
+		BaseDelay:      500 * time.Millisecond,
+		MaxDelay:       10 * time.Second,
+		ReplayInterval: 10 * time.Second,
+	}
+}
+
+// RetryMetrics holds retry and dead-letter counters for one table.
+type RetryMetrics struct {
+	Retries  int64 // Insert attempts after the first
+	Spilled  int64 // Batches written to the dead-letter queue
+	Replayed int64 // Spilled batches later written to the database
+	Rejected int64 // Batches the database refused as invalid (kept in rejected/)
+	Dropped  int64 // Batches that ran out of attempts with spilling disabled
+	Pending  int64 // Spilled batches awaiting replay
+	Retrying int64 // Batches awaiting a background retry
+}
+
+// rejectedDir holds batches the database refused, for manual inspection.
+const rejectedDir = "rejected"
+
+// retrier writes batches through insert, retrying with backoff and spilling
+// to disk when the database stays down. A batch is inserted once on the
+// caller's goroutine; if that fails it is handed to replayLoop, which waits
+// out the backoff, so writers keep consuming during an outage. Batches
+// written while others await a retry or replay queue behind them, so rows
+// reach the database in write order.
+type retrier[T any] struct {
+	name   string
+	cfg    RetryConfig
+	insert func(ctx context.Context, rows []T) (conflicts int, err error)
+	record func(rows, conflicts int)
+	logger *slog.Logger
+
+	// mu serialises inserts, spills and replay so batches stay in order.
+	// It is never held while waiting out a backoff.
+	mu      sync.Mutex
+	dir     string // "" when spilling is disabled
+	seq     int64
+	backlog []retryBatch[T] // Batches awaiting a background retry, oldest first
+
+	// backlogReady wakes replayLoop when a batch joins the backlog.
+	backlogReady chan struct{}
+
+	metricsMu sync.Mutex
+	metrics   RetryMetrics
+}
+
+// newRetrier creates a retrier for the named writer. record is called for
+// every batch written, directly or by replay.
+func newRetrier[T any](
+	name string,
+	cfg RetryConfig,
+	insert func(ctx context.Context, rows []T) (int, error),
+	record func(rows, conflicts int),
+	logger *slog.Logger,
+) *retrier[T] {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &retrier[T]{
+		name:         name,
+		cfg:          cfg,
+		insert:       insert,
+		record:       record,
+		logger:       logger,
+		backlogReady: make(chan struct{}, 1),
+	}
+}
+
+// retryBatch is a batch awaiting a background retry.
+type retryBatch[T any] struct {
+	rows     []T
+	attempts int   // Failed attempts so far; 0 if queued behind another batch
+	err      error // Last insert error
+}
+
+// open prepares the dead-letter directory and counts batches left by a
+// previous run. Spilling is disabled if the directory cannot be created.
+func (r *retrier[T]) open() {
+	if r.cfg.DeadLetterDir == "" {
+		return
+	}
+
+	dir := filepath.Join(r.cfg.DeadLetterDir, r.name)
+	if err := os.MkdirAll(filepath.Join(dir, rejectedDir), 0o755); err != nil {
+		r.logger.Error("dead-letter queue disabled", "writer", r.name, "error", err)
+		return
+	}
+
+	r.mu.Lock()
+	r.dir = dir
+	pending, err := r.list()
+	r.mu.Unlock()
+	if err != nil {
+		r.logger.Error("failed to list dead-letter queue", "writer", r.name, "error", err)
+	}
+
+	r.metricsMu.Lock()
+	r.metrics.Pending = int64(len(pending))
+	r.metricsMu.Unlock()
+
+	if len(pending) > 0 {
+		r.logger.Warn("dead-letter queue has batches from a previous run",
+			"writer", r.name,
+			"pending", len(pending),
+		)
+	}
+}
+
+// stats returns current retry metrics.
+func (r *retrier[T]) stats() RetryMetrics {
+	r.metricsMu.Lock()
+	defer r.metricsMu.Unlock()
+	return r.metrics
+}
+
+// write inserts rows once. On a transient failure the batch is handed to
+// replayLoop for the remaining attempts, so write never waits out a
+// backoff. It returns nil if the rows were written, queued or spilled, and
+// an error if they were dropped or rejected by the database.
+func (r *retrier[T]) write(ctx context.Context, rows []T) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stats().Pending > 0 {
+		return r.spill(rows, nil)
+	}
+	if len(r.backlog) > 0 {
+		r.enqueue(retryBatch[T]{rows: rows})
+		return nil
+	}
+
+	conflicts, err := r.insert(ctx, rows)
+	if err == nil {
+		r.record(len(rows), conflicts)
+		return nil
+	}
+	if permanent(err) {
+		return r.reject(rows, err)
+	}
+	if r.cfg.MaxAttempts <= 1 || ctx.Err() != nil {
+		return r.giveUp(rows, err)
+	}
+
+	r.logger.Warn("flush failed, retrying in background",
+		"writer", r.name,
+		"error", err,
+		"rows", len(rows),
+	)
+	r.enqueue(retryBatch[T]{rows: rows, attempts: 1, err: err})
+	return nil
+}
+
+// enqueue adds a batch to the backlog and wakes replayLoop. Callers hold r.mu.
+func (r *retrier[T]) enqueue(b retryBatch[T]) {
+	r.backlog = append(r.backlog, b)
+	r.count(func(m *RetryMetrics) { m.Retrying++ })
+
+	select {
+	case r.backlogReady <- struct{}{}:
+	default:
+	}
+}
+
+// retryBacklog retries backlog batches in order until the backlog is empty
+// or ctx is done. The backoff before each retry is waited out without r.mu.
+func (r *retrier[T]) retryBacklog(ctx context.Context) {
+	attempts := max(r.cfg.MaxAttempts, 1)
+
+	for {
+		r.mu.Lock()
+		if len(r.backlog) == 0 {
+			r.mu.Unlock()
+			return
+		}
+		head := r.backlog[0]
+		r.mu.Unlock()
+
+		if head.attempts > 0 {
+			select {
+			case <-time.After(r.backoff(head.attempts)):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		r.mu.Lock()
+		if r.stats().Pending > 0 {
+			// An earlier batch spilled; keep order behind it
+			r.popBacklog()
+			if err := r.spill(head.rows, nil); err != nil {
+				r.logger.Error("batch dropped", "writer", r.name, "error", err, "rows", len(head.rows))
+			}
+			r.mu.Unlock()
+			continue
+		}
+
+		if head.attempts > 0 {
+			r.count(func(m *RetryMetrics) { m.Retries++ })
+		}
+		conflicts, err := r.insert(ctx, head.rows)
+		switch {
+		case err == nil:
+			r.popBacklog()
+			r.record(len(head.rows), conflicts)
+		case permanent(err):
+			r.popBacklog()
+			r.logger.Error("batch rejected by database", "writer", r.name, "error", r.reject(head.rows, err))
+		case head.attempts+1 >= attempts || ctx.Err() != nil:
+			r.popBacklog()
+			if err := r.giveUp(head.rows, err); err != nil {
+				r.logger.Error("batch dropped after retries", "writer", r.name, "error", err, "rows", len(head.rows))
+			}
+		default:
+			r.backlog[0].attempts++
+			r.backlog[0].err = err
+			r.logger.Warn("flush failed, retrying",
+				"writer", r.name,
+				"error", err,
+				"attempt", head.attempts+1,
+				"rows", len(head.rows),
+			)
+		}
+		r.mu.Unlock()
+	}
+}
+
+// popBacklog removes the oldest backlog batch. Callers hold r.mu.
+func (r *retrier[T]) popBacklog() {
+	r.backlog[0] = retryBatch[T]{}
+	r.backlog = r.backlog[1:]
+	r.count(func(m *RetryMetrics) { m.Retrying-- })
+}
+
+// giveUp spills rows that ran out of attempts, or drops them and returns
+// err when spilling is disabled. Callers hold r.mu.
+func (r *retrier[T]) giveUp(rows []T, err error) error {
+	if r.dir == "" {
+		r.count(func(m *RetryMetrics) { m.Dropped++ })
+		return err
+	}
+	return r.spill(rows, err)
+}
+
+// close retries the backlog with ctx, then spills or drops whatever is left
+// if ctx ends first. Writers call it after their final flush in Stop, once
+// replayLoop has exited.
+func (r *retrier[T]) close(ctx context.Context) {
+	r.retryBacklog(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for len(r.backlog) > 0 {
+		head := r.backlog[0]
+		r.popBacklog()
+		cause := head.err
+		if cause == nil {
+			cause = ctx.Err()
+		}
+		if err := r.giveUp(head.rows, cause); err != nil {
+			r.logger.Error("batch dropped at shutdown", "writer", r.name, "error", err, "rows", len(head.rows))
+		}
+	}
+}
+
+// backoff returns the wait after the given failed attempt.
+func (r *retrier[T]) backoff(attempt int) time.Duration {
+	delay := r.cfg.BaseDelay
+	for i := 1; i < attempt && delay < r.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if r.cfg.MaxDelay > 0 && delay > r.cfg.MaxDelay {
+		delay = r.cfg.MaxDelay
+	}
+	return delay
+}
+
+// spill writes rows to the dead-letter queue. cause is the insert error,
+// or nil when spilling behind already pending batches.
+func (r *retrier[T]) spill(rows []T, cause error) error {
+	if _, err := r.save(r.dir, rows); err != nil {
+		if cause != nil {
+			return fmt.Errorf("%w (dead-letter spill failed: %v)", cause, err)
+		}
+		return fmt.Errorf("dead-letter spill: %w", err)
+	}
+
+	r.count(func(m *RetryMetrics) {
+		m.Spilled++
+		m.Pending++
+	})
+	if cause != nil {
+		r.logger.Warn("flush failed, spilled to dead-letter queue",
+			"writer", r.name,
+			"error", cause,
+			"rows", len(rows),
+		)
+	}
+	return nil
+}
+
+// reject keeps rows the database refused under rejected/ and returns err.
+func (r *retrier[T]) reject(rows []T, err error) error {
+	r.count(func(m *RetryMetrics) { m.Rejected++ })
+	if r.dir == "" {
+		return err
+	}
+
+	path, saveErr := r.save(filepath.Join(r.dir, rejectedDir), rows)
+	if saveErr != nil {
+		return fmt.Errorf("%w (saving rejected batch failed: %v)", err, saveErr)
+	}
+	return fmt.Errorf("%w (batch saved to %s)", err, path)
+}
+
+// save writes rows as JSON to a new file in dir. Names sort in write order.
+func (r *retrier[T]) save(dir string, rows []T) (string, error) {
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return "", err
+	}
+
+	r.seq++
+	name := fmt.Sprintf("%019d-%06d.json", time.Now().UnixNano(), r.seq%1000000)
+	path := filepath.Join(dir, name)
+
+	// Write then rename so replay never sees a partial batch
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return path, nil
+}
+
+// list returns pending batch files, oldest first. Callers hold r.mu.
+func (r *retrier[T]) list() ([]string, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && strings.HasSuffix(e.Name(), ".json") {
+			paths = append(paths, filepath.Join(r.dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// replay writes spilled batches to the database in order, stopping at the
+// first transient failure.
+func (r *retrier[T]) replay(ctx context.Context) {
+	for ctx.Err() == nil {
+		if !r.replayOne(ctx) {
+			return
+		}
+	}
+}
+
+// replayOne writes the oldest spilled batch and reports whether to continue.
+func (r *retrier[T]) replayOne(ctx context.Context) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	paths, err := r.list()
+	if err != nil {
+		r.logger.Error("failed to list dead-letter queue", "writer", r.name, "error", err)
+		return false
+	}
+	r.count(func(m *RetryMetrics) { m.Pending = int64(len(paths)) })
+	if len(paths) == 0 {
+		return false
+	}
+	path := paths[0]
+
+	var rows []T
+	data, err := os.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(data, &rows)
+	}
+	if err != nil {
+		r.logger.Error("unreadable dead-letter batch, moving to rejected", "writer", r.name, "path", path, "error", err)
+		r.discard(path)
+		return true
+	}
+
+	conflicts, err := r.insert(ctx, rows)
+	if err != nil && permanent(err) {
+		r.logger.Error("dead-letter batch rejected by database, moving to rejected", "writer", r.name, "path", path, "error", err)
+		r.discard(path)
+		return true
+	}
+	if err != nil {
+		r.logger.Debug("dead-letter replay failed, database still unavailable", "writer", r.name, "error", err)
+		return false
+	}
+
+	if err := os.Remove(path); err != nil {
+		// Leaving it would replay the batch again; ON CONFLICT makes that harmless
+		r.logger.Error("failed to remove replayed batch", "writer", r.name, "path", path, "error", err)
+	}
+	r.count(func(m *RetryMetrics) {
+		m.Replayed++
+		m.Pending--
+	})
+	r.record(len(rows), conflicts)
+
+	r.logger.Info("replayed dead-letter batch",
+		"writer", r.name,
+		"rows", len(rows),
+		"conflicts", conflicts,
+		"pending", r.stats().Pending,
+	)
+	return true
+}
+
+// discard moves a pending batch to rejected/.
+func (r *retrier[T]) discard(path string) {
+	if err := os.Rename(path, filepath.Join(r.dir, rejectedDir, filepath.Base(path))); err != nil {
+		r.logger.Error("failed to move batch to rejected", "writer", r.name, "path", path, "error", err)
+		return
+	}
+	r.count(func(m *RetryMetrics) {
+		m.Rejected++
+		m.Pending--
+	})
+}
+
+// replayLoop retries backlog batches as they arrive and replays spilled
+// batches every ReplayInterval, until ctx is done.
+func (r *retrier[T]) replayLoop(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	var tick <-chan time.Time
+	if r.dir != "" && r.cfg.ReplayInterval > 0 {
+		ticker := time.NewTicker(r.cfg.ReplayInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.backlogReady:
+			r.retryBacklog(ctx)
+		case <-tick:
+			if r.stats().Pending > 0 {
+				r.replay(ctx)
+			}
+		}
+	}
+}
+
+// count applies fn to the metrics under their lock.
+func (r *retrier[T]) count(fn func(m *RetryMetrics)) {
+	r.metricsMu.Lock()
+	fn(&r.metrics)
+	r.metricsMu.Unlock()
+}
+
+// permanent reports whether err is the database refusing the data itself
+// (bad values, constraint violations), which retrying will not fix.
+func permanent(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || len(pgErr.Code) < 2 {
+		return false
+	}
+	switch pgErr.Code[:2] {
+	case "22", "23": // data_exception, integrity_constraint_violation
+		return true
+	}
+	return false
+}
@@ -0,0 +1,312 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeDB is an insert func whose failures are scripted per call.
+type fakeDB struct {
+	errs    []error // returned by successive calls; nil once exhausted
+	calls   int
+	written [][]int
+}
+
+func (f *fakeDB) insert(_ context.Context, rows []int) (int, error) {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		if err != nil {
+			return 0, err
+		}
+	}
+	f.written = append(f.written, rows)
+	return 0, nil
+}
+
+var errDown = errors.New("connection refused")
+
+func newTestRetrier(t *testing.T, db *fakeDB, dir string) (*retrier[int], *int) {
+	t.Helper()
+	recorded := new(int)
+	cfg := RetryConfig{
+		MaxAttempts:    3,
+		BaseDelay:      time.Millisecond,
+		MaxDelay:       2 * time.Millisecond,
+		DeadLetterDir:  dir,
+		ReplayInterval: time.Hour,
+	}
+	r := newRetrier("test", cfg, db.insert, func(rows, _ int) { *recorded += rows }, nil)
+	r.open()
+	return r, recorded
+}
+
+func TestRetrier_RetriesThenSucceeds(t *testing.T) {
+	db := &fakeDB{errs: []error{errDown, errDown}}
+	r, recorded := newTestRetrier(t, db, "")
+	ctx := context.Background()
+
+	if err := r.write(ctx, []int{1, 2}); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	if db.calls != 1 {
+		t.Errorf("calls after write = %d, want 1", db.calls)
+	}
+	if got := r.stats().Retrying; got != 1 {
+		t.Errorf("Retrying = %d, want 1", got)
+	}
+
+	r.retryBacklog(ctx)
+
+	if db.calls != 3 {
+		t.Errorf("calls = %d, want 3", db.calls)
+	}
+	if *recorded != 2 {
+		t.Errorf("recorded = %d, want 2", *recorded)
+	}
+	if got := r.stats(); got.Retries != 2 || got.Retrying != 0 {
+		t.Errorf("Retries = %d, Retrying = %d, want 2, 0", got.Retries, got.Retrying)
+	}
+}
+
+func TestRetrier_WriteDoesNotWaitOutBackoff(t *testing.T) {
+	db := &fakeDB{errs: []error{errDown}}
+	r, recorded := newTestRetrier(t, db, "")
+	r.cfg.BaseDelay = time.Hour
+	r.cfg.MaxDelay = time.Hour
+	ctx := context.Background()
+
+	start := time.Now()
+	for _, rows := range [][]int{{1}, {2}} {
+		if err := r.write(ctx, rows); err != nil {
+			t.Fatalf("write(%v) error = %v", rows, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("write() took %v, want no backoff wait", elapsed)
+	}
+
+	// The second batch queues behind the first without an insert
+	if db.calls != 1 {
+		t.Errorf("calls = %d, want 1", db.calls)
+	}
+	if got := r.stats().Retrying; got != 2 {
+		t.Errorf("Retrying = %d, want 2", got)
+	}
+
+	// Shutting down with the backoff still running retries nothing and,
+	// with no dead-letter directory, drops both batches.
+	closeCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	r.close(closeCtx)
+
+	if got := r.stats(); got.Dropped != 2 || got.Retrying != 0 {
+		t.Errorf("Dropped = %d, Retrying = %d, want 2, 0", got.Dropped, got.Retrying)
+	}
+	if *recorded != 0 {
+		t.Errorf("recorded = %d, want 0", *recorded)
+	}
+}
+
+func TestRetrier_CloseRetriesBacklog(t *testing.T) {
+	db := &fakeDB{errs: []error{errDown}}
+	r, recorded := newTestRetrier(t, db, "")
+	ctx := context.Background()
+
+	r.write(ctx, []int{1})
+	r.write(ctx, []int{2, 3})
+	r.close(ctx)
+
+	want := [][]int{{1}, {2, 3}}
+	if !reflect.DeepEqual(db.written, want) {
+		t.Errorf("written = %v, want %v", db.written, want)
+	}
+	if *recorded != 3 {
+		t.Errorf("recorded = %d, want 3", *recorded)
+	}
+}
+
+func TestRetrier_NoDeadLetterDir(t *testing.T) {
+	db := &fakeDB{errs: []error{errDown, errDown, errDown}}
+	r, _ := newTestRetrier(t, db, "")
+
+	if err := r.write(context.Background(), []int{1}); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	r.retryBacklog(context.Background())
+
+	if got := r.stats(); got.Spilled != 0 || got.Pending != 0 || got.Dropped != 1 {
+		t.Errorf("stats = %+v, want nothing spilled and 1 dropped", got)
+	}
+}
+
+func TestRetrier_SpillAndReplayInOrder(t *testing.T) {
+	dir := t.TempDir()
+	db := &fakeDB{errs: []error{errDown, errDown, errDown}}
+	r, recorded := newTestRetrier(t, db, dir)
+	ctx := context.Background()
+
+	// First batch exhausts its attempts and spills; later batches queue
+	// behind it without touching the database.
+	for _, rows := range [][]int{{1}, {2, 3}, {4}} {
+		if err := r.write(ctx, rows); err != nil {
+			t.Fatalf("write(%v) error = %v", rows, err)
+		}
+	}
+	r.retryBacklog(ctx)
+
+	if db.calls != 3 {
+		t.Errorf("calls = %d, want 3", db.calls)
+	}
+	if got := r.stats(); got.Spilled != 3 || got.Pending != 3 {
+		t.Errorf("Spilled = %d, Pending = %d, want 3, 3", got.Spilled, got.Pending)
+	}
+
+	r.replay(ctx)
+
+	want := [][]int{{1}, {2, 3}, {4}}
+	if !reflect.DeepEqual(db.written, want) {
+		t.Errorf("written = %v, want %v", db.written, want)
+	}
+	if got := r.stats(); got.Replayed != 3 || got.Pending != 0 {
+		t.Errorf("Replayed = %d, Pending = %d, want 3, 0", got.Replayed, got.Pending)
+	}
+	if *recorded != 4 {
+		t.Errorf("recorded = %d, want 4", *recorded)
+	}
+
+	// Queue drained: writes go straight to the database again
+	if err := r.write(ctx, []int{5}); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	if got := r.stats().Spilled; got != 3 {
+		t.Errorf("Spilled = %d, want 3", got)
+	}
+}
+
+func TestRetrier_ReplayStopsWhileDown(t *testing.T) {
+	dir := t.TempDir()
+	db := &fakeDB{errs: []error{errDown, errDown, errDown}}
+	r, _ := newTestRetrier(t, db, dir)
+	ctx := context.Background()
+
+	r.write(ctx, []int{1})
+	r.write(ctx, []int{2})
+	r.retryBacklog(ctx)
+
+	db.errs = []error{errDown}
+	r.replay(ctx)
+
+	if db.calls != 4 {
+		t.Errorf("calls = %d, want 4", db.calls)
+	}
+	if got := r.stats().Pending; got != 2 {
+		t.Errorf("Pending = %d, want 2", got)
+	}
+}
+
+func TestRetrier_PermanentError(t *testing.T) {
+	dir := t.TempDir()
+	db := &fakeDB{errs: []error{&pgconn.PgError{Code: "23502"}}}
+	r, _ := newTestRetrier(t, db, dir)
+
+	if err := r.write(context.Background(), []int{1}); err == nil {
+		t.Fatal("write() error = nil, want error")
+	}
+	if db.calls != 1 {
+		t.Errorf("calls = %d, want 1 (no retries)", db.calls)
+	}
+	if got := r.stats(); got.Rejected != 1 || got.Pending != 0 {
+		t.Errorf("Rejected = %d, Pending = %d, want 1, 0", got.Rejected, got.Pending)
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(dir, "test", rejectedDir))
+	if len(entries) != 1 {
+		t.Errorf("rejected files = %d, want 1", len(entries))
+	}
+}
+
+func TestRetrier_CanceledContextSpills(t *testing.T) {
+	dir := t.TempDir()
+	db := &fakeDB{errs: []error{context.Canceled}}
+	r, _ := newTestRetrier(t, db, dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := r.write(ctx, []int{1}); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	if db.calls != 1 {
+		t.Errorf("calls = %d, want 1", db.calls)
+	}
+	if got := r.stats().Pending; got != 1 {
+		t.Errorf("Pending = %d, want 1", got)
+	}
+}
+
+func TestRetrier_OpenCountsPending(t *testing.T) {
+	dir := t.TempDir()
+	db := &fakeDB{errs: []error{errDown, errDown, errDown}}
+	first, _ := newTestRetrier(t, db, dir)
+	first.write(context.Background(), []int{1})
+	first.write(context.Background(), []int{2})
+	first.retryBacklog(context.Background())
+
+	// A new retrier over the same directory picks up the previous run's queue
+	second, _ := newTestRetrier(t, &fakeDB{}, dir)
+	if got := second.stats().Pending; got != 2 {
+		t.Errorf("Pending = %d, want 2", got)
+	}
+}
+
+func TestRetrier_Backoff(t *testing.T) {
+	r := newRetrier[int]("test", RetryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}, nil, nil, nil)
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{80, time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := r.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection error", errDown, false},
+		{"not null violation", &pgconn.PgError{Code: "23502"}, true},
+		{"invalid text", &pgconn.PgError{Code: "22P02"}, true},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, false},
+		{"wrapped", errors.Join(errDown, &pgconn.PgError{Code: "23505"}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := permanent(tt.err); got != tt.want {
+				t.Errorf("permanent(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
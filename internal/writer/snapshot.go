@@ -0,0 +1,253 @@
+package writer
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/rickgao/kalshi-data/internal/model"
+)
+
+// SnapshotWriter receives REST orderbook snapshots from the Snapshot Poller
+// and writes them to the orderbook_snapshots table. It implements
+// poller.SnapshotHandler.
+type SnapshotWriter struct {
+	cfg    WriterConfig
+	logger *slog.Logger
+
+	// Database
+	db *pgxpool.Pool
+
+	// Batching
+	batch       []orderbookSnapshotRow
+	batchMu     sync.Mutex
+	flushTicker *time.Ticker
+
+	// Retries and dead-letter queue for failed flushes
+	retry *retrier[orderbookSnapshotRow]
+
+	// Lifecycle
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// Metrics
+	metrics WriterMetrics
+}
+
+// NewSnapshotWriter creates a new SnapshotWriter.
+func NewSnapshotWriter(
+	cfg WriterConfig,
+	db *pgxpool.Pool,
+	logger *slog.Logger,
+) *SnapshotWriter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	w := &SnapshotWriter{
+		cfg:    cfg,
+		db:     db,
+		logger: logger,
+		batch:  make([]orderbookSnapshotRow, 0, cfg.BatchSize),
+	}
+	w.retry = newRetrier("snapshot", cfg.Retry, w.insert, w.recordWrite, logger)
+	return w
+}
+
+// Start begins periodic flushing.
+func (w *SnapshotWriter) Start(ctx context.Context) error {
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	w.flushTicker = time.NewTicker(w.cfg.FlushInterval)
+	w.retry.open()
+
+	w.wg.Add(1)
+	go w.flushLoop()
+
+	w.wg.Add(1)
+	go w.retry.replayLoop(w.ctx, &w.wg)
+
+	w.logger.Info("snapshot writer started",
+		"batch_size", w.cfg.BatchSize,
+		"flush_interval", w.cfg.FlushInterval,
+	)
+	return nil
+}
+
+// Stop gracefully shuts down the writer and flushes any pending snapshots.
+// Stop the Snapshot Poller first so no snapshots arrive after the final flush.
+func (w *SnapshotWriter) Stop(ctx context.Context) error {
+	w.logger.Info("stopping snapshot writer")
+
+	if w.cancel != nil {
+		w.cancel()
+	}
+
+	if w.flushTicker != nil {
+		w.flushTicker.Stop()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.logger.Info("snapshot writer stopped")
+	case <-ctx.Done():
+		w.logger.Warn("snapshot writer stop timed out")
+	}
+
+	// Final flush and queued retries use the caller's context; w.ctx is already canceled.
+	w.flush(ctx)
+	w.retry.close(ctx)
+
+	return nil
+}
+
+// Stats returns current metrics.
+func (w *SnapshotWriter) Stats() WriterMetrics {
+	w.batchMu.Lock()
+	m := w.metrics
+	w.batchMu.Unlock()
+	m.Retry = w.retry.stats()
+	return m
+}
+
+// HandleSnapshot adds a REST snapshot to the batch, flushing when full.
+// Safe for concurrent use by poller goroutines.
+func (w *SnapshotWriter) HandleSnapshot(snapshot model.OrderbookSnapshot) error {
+	row := w.transform(snapshot)
+
+	w.batchMu.Lock()
+	w.batch = append(w.batch, row)
+	shouldFlush := len(w.batch) >= w.cfg.BatchSize
+	w.batchMu.Unlock()
+
+	if shouldFlush {
+		w.flush(w.ctx)
+	}
+	return nil
+}
+
+// flushLoop periodically flushes the batch.
+func (w *SnapshotWriter) flushLoop() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-w.flushTicker.C:
+			w.flush(w.ctx)
+		}
+	}
+}
+
+// transform converts a model.OrderbookSnapshot to orderbookSnapshotRow.
+// REST responses only carry bids, so asks are derived from opposite-side bids.
+func (w *SnapshotWriter) transform(s model.OrderbookSnapshot) orderbookSnapshotRow {
+	yesAsks := s.YesAsks
+	if len(yesAsks) == 0 {
+		yesAsks = deriveAsksFromModelBids(s.NoBids) // NO bids → YES asks
+	}
+	noAsks := s.NoAsks
+	if len(noAsks) == 0 {
+		noAsks = deriveAsksFromModelBids(s.YesBids) // YES bids → NO asks
+	}
+
+	return orderbookSnapshotRow{
+		SnapshotTs: s.SnapshotTS,
+		ExchangeTs: s.ExchangeTS,
+		Ticker:     s.Ticker,
+		Source:     s.Source,
+		YesBids:    modelLevelsToJSONB(s.YesBids),
+		YesAsks:    modelLevelsToJSONB(yesAsks),
+		NoBids:     modelLevelsToJSONB(s.NoBids),
+		NoAsks:     modelLevelsToJSONB(noAsks),
+		BestYesBid: s.BestYesBid,
+		BestYesAsk: s.BestYesAsk,
+		Spread:     s.Spread,
+		SID:        0, // REST snapshots have no subscription
+	}
+}
+
+// flush writes the current batch to the database, retrying and spilling
+// to the dead-letter queue on failure.
+func (w *SnapshotWriter) flush(ctx context.Context) {
+	w.batchMu.Lock()
+	if len(w.batch) == 0 {
+		w.batchMu.Unlock()
+		return
+	}
+
+	// Take ownership of current batch
+	batch := w.batch
+	w.batch = make([]orderbookSnapshotRow, 0, w.cfg.BatchSize)
+	w.batchMu.Unlock()
+
+	start := time.Now()
+
+	err := w.retry.write(ctx, batch)
+	observeFlush(w.cfg, "snapshot", len(batch), start)
+	if err != nil {
+		w.logger.Error("snapshot batch insert failed", "error", err, "count", len(batch))
+		w.batchMu.Lock()
+		w.metrics.Errors++
+		w.batchMu.Unlock()
+		return
+	}
+
+	w.logger.Debug("flushed snapshots",
+		"count", len(batch),
+		"duration", time.Since(start),
+	)
+}
+
+// recordWrite counts a batch written to the database, directly or by
+// dead-letter replay.
+func (w *SnapshotWriter) recordWrite(rows, conflicts int) {
+	w.batchMu.Lock()
+	w.metrics.Inserts += int64(rows - conflicts)
+	w.metrics.Conflicts += int64(conflicts)
+	w.metrics.Flushes++
+	w.batchMu.Unlock()
+}
+
+// insert writes REST snapshot rows.
+func (w *SnapshotWriter) insert(ctx context.Context, rows []orderbookSnapshotRow) (conflicts int, err error) {
+	return insertSnapshots(ctx, w.db, rows)
+}
+
+// insertSnapshots inserts snapshot rows with ON CONFLICT DO NOTHING.
+// Shared by OrderbookWriter (WS snapshots) and SnapshotWriter (REST snapshots).
+func insertSnapshots(ctx context.Context, db *pgxpool.Pool, rows []orderbookSnapshotRow) (conflicts int, err error) {
+	batch := &pgx.Batch{}
+	for _, r := range rows {
+		batch.Queue(`
+			INSERT INTO orderbook_snapshots (snapshot_ts, exchange_ts, ticker, source, yes_bids, yes_asks, no_bids, no_asks, best_yes_bid, best_yes_ask, spread, sid)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT (snapshot_ts, ticker, source) DO NOTHING
+		`, r.SnapshotTs, r.ExchangeTs, r.Ticker, r.Source, r.YesBids, r.YesAsks, r.NoBids, r.NoAsks, r.BestYesBid, r.BestYesAsk, r.Spread, r.SID)
+	}
+
+	results := db.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range rows {
+		ct, err := results.Exec()
+		if err != nil {
+			return 0, err
+		}
+		if ct.RowsAffected() == 0 {
+			conflicts++
+		}
+	}
+
+	return conflicts, nil
+}
@@ -28,6 +28,9 @@ type TickerWriter struct {
 	batchMu     sync.Mutex
 	flushTicker *time.Ticker
 
+	// Retries and dead-letter queue for failed flushes
+	retry *retrier[tickerRow]
+
 	// Lifecycle
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -47,19 +50,22 @@ func NewTickerWriter(
 	if logger == nil {
 		logger = slog.Default()
 	}
-	return &TickerWriter{
+	w := &TickerWriter{
 		cfg:    cfg,
 		input:  input,
 		db:     db,
 		logger: logger,
 		batch:  make([]tickerRow, 0, cfg.BatchSize),
 	}
+	w.retry = newRetrier("ticker", cfg.Retry, w.insert, w.recordWrite, logger)
+	return w
 }
 
 // Start begins consuming messages and writing to the database.
 func (w *TickerWriter) Start(ctx context.Context) error {
 	w.ctx, w.cancel = context.WithCancel(ctx)
 	w.flushTicker = time.NewTicker(w.cfg.FlushInterval)
+	w.retry.open()
 
 	// Consumer goroutine
 	w.wg.Add(1)
@@ -69,6 +75,10 @@ func (w *TickerWriter) Start(ctx context.Context) error {
 	w.wg.Add(1)
 	go w.flushLoop()
 
+	// Dead-letter replay goroutine
+	w.wg.Add(1)
+	go w.retry.replayLoop(w.ctx, &w.wg)
+
 	w.logger.Info("ticker writer started",
 		"batch_size", w.cfg.BatchSize,
 		"flush_interval", w.cfg.FlushInterval,
@@ -102,8 +112,9 @@ func (w *TickerWriter) Stop(ctx context.Context) error {
 		w.logger.Warn("ticker writer stop timed out")
 	}
 
-	// Final flush
-	w.flush()
+	// Final flush and queued retries use the caller's context; w.ctx is already canceled.
+	w.flush(ctx)
+	w.retry.close(ctx)
 
 	return nil
 }
@@ -111,8 +122,10 @@ func (w *TickerWriter) Stop(ctx context.Context) error {
 // Stats returns current metrics.
 func (w *TickerWriter) Stats() WriterMetrics {
 	w.batchMu.Lock()
-	defer w.batchMu.Unlock()
-	return w.metrics
+	m := w.metrics
+	w.batchMu.Unlock()
+	m.Retry = w.retry.stats()
+	return m
 }
 
 // consumeLoop reads from the input buffer and accumulates batches.
@@ -148,7 +161,7 @@ func (w *TickerWriter) flushLoop() {
 		case <-w.ctx.Done():
 			return
 		case <-w.flushTicker.C:
-			w.flush()
+			w.flush(w.ctx)
 		}
 	}
 }
@@ -163,7 +176,7 @@ func (w *TickerWriter) handleMessage(msg router.TickerMsg) {
 	w.batchMu.Unlock()
 
 	if shouldFlush {
-		w.flush()
+		w.flush(w.ctx)
 	}
 }
 
@@ -184,8 +197,9 @@ func (w *TickerWriter) transform(msg router.TickerMsg) tickerRow {
 	}
 }
 
-// flush writes the current batch to the database.
-func (w *TickerWriter) flush() {
+// flush writes the current batch to the database, retrying and spilling
+// to the dead-letter queue on failure.
+func (w *TickerWriter) flush(ctx context.Context) {
 	w.batchMu.Lock()
 	if len(w.batch) == 0 {
 		w.batchMu.Unlock()
@@ -199,7 +213,8 @@ func (w *TickerWriter) flush() {
 
 	start := time.Now()
 
-	conflicts, err := w.batchInsert(batch)
+	err := w.retry.write(ctx, batch)
+	observeFlush(w.cfg, "ticker", len(batch), start)
 	if err != nil {
 		w.logger.Error("batch insert failed", "error", err, "count", len(batch))
 		w.batchMu.Lock()
@@ -208,21 +223,40 @@ func (w *TickerWriter) flush() {
 		return
 	}
 
+	w.logger.Debug("flushed tickers",
+		"count", len(batch),
+		"duration", time.Since(start),
+	)
+}
+
+// recordWrite counts a batch written to the database, directly or by
+// dead-letter replay.
+func (w *TickerWriter) recordWrite(rows, conflicts int) {
 	w.batchMu.Lock()
-	w.metrics.Inserts += int64(len(batch) - conflicts)
+	w.metrics.Inserts += int64(rows - conflicts)
 	w.metrics.Conflicts += int64(conflicts)
 	w.metrics.Flushes++
 	w.batchMu.Unlock()
+}
 
-	w.logger.Debug("flushed tickers",
-		"count", len(batch),
-		"conflicts", conflicts,
-		"duration", time.Since(start),
-	)
+// insert writes rows with COPY when configured and the batch is large
+// enough, falling back to batchInsert otherwise or if COPY fails.
+func (w *TickerWriter) insert(ctx context.Context, rows []tickerRow) (conflicts int, err error) {
+	if useCopy(w.cfg, len(rows)) {
+		conflicts, err := copyInsert(ctx, w.db, tickerCopy, tickerCopyRows(rows))
+		if err == nil {
+			return conflicts, nil
+		}
+		w.logger.Warn("copy insert failed, falling back to batch insert", "error", err, "count", len(rows))
+		w.batchMu.Lock()
+		w.metrics.CopyFallbacks++
+		w.batchMu.Unlock()
+	}
+	return w.batchInsert(ctx, rows)
 }
 
 // batchInsert inserts rows using pgx.Batch with ON CONFLICT DO NOTHING.
-func (w *TickerWriter) batchInsert(rows []tickerRow) (conflicts int, err error) {
+func (w *TickerWriter) batchInsert(ctx context.Context, rows []tickerRow) (conflicts int, err error) {
 	batch := &pgx.Batch{}
 	for _, r := range rows {
 		batch.Queue(`
@@ -232,7 +266,7 @@ func (w *TickerWriter) batchInsert(rows []tickerRow) (conflicts int, err error)
 		`, r.ExchangeTs, r.ReceivedAt, r.Ticker, r.YesBid, r.YesAsk, r.LastPrice, r.Volume, r.OpenInterest, r.DollarVolume, r.DollarOpenInterest, r.SID)
 	}
 
-	results := w.db.SendBatch(w.ctx, batch)
+	results := w.db.SendBatch(ctx, batch)
 	defer results.Close()
 
 	for range rows {
@@ -28,6 +28,9 @@ type TradeWriter struct {
 	batchMu     sync.Mutex
 	flushTicker *time.Ticker
 
+	// Retries and dead-letter queue for failed flushes
+	retry *retrier[tradeRow]
+
 	// Lifecycle
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -47,19 +50,22 @@ func NewTradeWriter(
 	if logger == nil {
 		logger = slog.Default()
 	}
-	return &TradeWriter{
+	w := &TradeWriter{
 		cfg:    cfg,
 		input:  input,
 		db:     db,
 		logger: logger,
 		batch:  make([]tradeRow, 0, cfg.BatchSize),
 	}
+	w.retry = newRetrier("trade", cfg.Retry, w.insert, w.recordWrite, logger)
+	return w
 }
 
 // Start begins consuming messages and writing to the database.
 func (w *TradeWriter) Start(ctx context.Context) error {
 	w.ctx, w.cancel = context.WithCancel(ctx)
 	w.flushTicker = time.NewTicker(w.cfg.FlushInterval)
+	w.retry.open()
 
 	// Consumer goroutine
 	w.wg.Add(1)
@@ -69,6 +75,10 @@ func (w *TradeWriter) Start(ctx context.Context) error {
 	w.wg.Add(1)
 	go w.flushLoop()
 
+	// Dead-letter replay goroutine
+	w.wg.Add(1)
+	go w.retry.replayLoop(w.ctx, &w.wg)
+
 	w.logger.Info("trade writer started",
 		"batch_size", w.cfg.BatchSize,
 		"flush_interval", w.cfg.FlushInterval,
@@ -102,8 +112,9 @@ func (w *TradeWriter) Stop(ctx context.Context) error {
 		w.logger.Warn("trade writer stop timed out")
 	}
 
-	// Final flush
-	w.flush()
+	// Final flush and queued retries use the caller's context; w.ctx is already canceled.
+	w.flush(ctx)
+	w.retry.close(ctx)
 
 	return nil
 }
@@ -111,8 +122,10 @@ func (w *TradeWriter) Stop(ctx context.Context) error {
 // Stats returns current metrics.
 func (w *TradeWriter) Stats() WriterMetrics {
 	w.batchMu.Lock()
-	defer w.batchMu.Unlock()
-	return w.metrics
+	m := w.metrics
+	w.batchMu.Unlock()
+	m.Retry = w.retry.stats()
+	return m
 }
 
 // consumeLoop reads from the input buffer and accumulates batches.
@@ -150,7 +163,7 @@ func (w *TradeWriter) flushLoop() {
 		case <-w.ctx.Done():
 			return
 		case <-w.flushTicker.C:
-			w.flush()
+			w.flush(w.ctx)
 		}
 	}
 }
@@ -165,7 +178,7 @@ func (w *TradeWriter) handleMessage(msg router.TradeMsg) {
 	w.batchMu.Unlock()
 
 	if shouldFlush {
-		w.flush()
+		w.flush(w.ctx)
 	}
 }
 
@@ -183,8 +196,9 @@ func (w *TradeWriter) transform(msg router.TradeMsg) tradeRow {
 	}
 }
 
-// flush writes the current batch to the database.
-func (w *TradeWriter) flush() {
+// flush writes the current batch to the database, retrying and spilling
+// to the dead-letter queue on failure.
+func (w *TradeWriter) flush(ctx context.Context) {
 	w.batchMu.Lock()
 	if len(w.batch) == 0 {
 		w.batchMu.Unlock()
@@ -198,7 +212,8 @@ func (w *TradeWriter) flush() {
 
 	start := time.Now()
 
-	conflicts, err := w.batchInsert(batch)
+	err := w.retry.write(ctx, batch)
+	observeFlush(w.cfg, "trade", len(batch), start)
 	if err != nil {
 		w.logger.Error("batch insert failed", "error", err, "count", len(batch))
 		w.batchMu.Lock()
@@ -207,31 +222,53 @@ func (w *TradeWriter) flush() {
 		return
 	}
 
+	w.logger.Debug("flushed trades",
+		"count", len(batch),
+		"duration", time.Since(start),
+	)
+}
+
+// recordWrite counts a batch written to the database, directly or by
+// dead-letter replay.
+func (w *TradeWriter) recordWrite(rows, conflicts int) {
 	w.batchMu.Lock()
-	w.metrics.Inserts += int64(len(batch) - conflicts)
+	w.metrics.Inserts += int64(rows - conflicts)
 	w.metrics.Conflicts += int64(conflicts)
 	w.metrics.Flushes++
 	w.batchMu.Unlock()
+}
 
-	w.logger.Debug("flushed trades",
-		"count", len(batch),
-		"conflicts", conflicts,
-		"duration", time.Since(start),
-	)
+// insert writes rows with COPY when configured and the batch is large
+// enough, falling back to batchInsert otherwise or if COPY fails.
+func (w *TradeWriter) insert(ctx context.Context, rows []tradeRow) (conflicts int, err error) {
+	if useCopy(w.cfg, len(rows)) {
+		values, err := tradeCopyRows(rows)
+		if err == nil {
+			conflicts, err = copyInsert(ctx, w.db, tradeCopy, values)
+		}
+		if err == nil {
+			return conflicts, nil
+		}
+		w.logger.Warn("copy insert failed, falling back to batch insert", "error", err, "count", len(rows))
+		w.batchMu.Lock()
+		w.metrics.CopyFallbacks++
+		w.batchMu.Unlock()
+	}
+	return w.batchInsert(ctx, rows)
 }
 
 // batchInsert inserts rows using pgx.Batch with ON CONFLICT DO NOTHING.
-func (w *TradeWriter) batchInsert(rows []tradeRow) (conflicts int, err error) {
+func (w *TradeWriter) batchInsert(ctx context.Context, rows []tradeRow) (conflicts int, err error) {
 	batch := &pgx.Batch{}
 	for _, r := range rows {
 		batch.Queue(`
 			INSERT INTO trades (trade_id, exchange_ts, received_at, ticker, price, size, taker_side, sid)
 			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-			ON CONFLICT (trade_id) DO NOTHING
+			ON CONFLICT (trade_id, exchange_ts) DO NOTHING
 		`, r.TradeID, r.ExchangeTs, r.ReceivedAt, r.Ticker, r.Price, r.Size, r.TakerSide, r.SID)
 	}
 
-	results := w.db.SendBatch(w.ctx, batch)
+	results := w.db.SendBatch(ctx, batch)
 	defer results.Close()
 
 	for range rows {
@@ -11,6 +11,21 @@ type WriterConfig struct {
 
 	// FlushInterval is the maximum time between flushes.
 	FlushInterval time.Duration
+
+	// InsertMode is InsertCopy or InsertBatch. Empty means InsertBatch.
+	InsertMode string
+
+	// Retry controls retries and the dead-letter queue for failed flushes.
+	Retry RetryConfig
+
+	// Observer, if set, is notified after every batch insert attempt.
+	Observer FlushObserver
+}
+
+// FlushObserver receives per-batch flush measurements.
+// Implementations must be safe for concurrent use.
+type FlushObserver interface {
+	ObserveFlush(writer string, rows int, duration time.Duration)
 }
 
 // DefaultWriterConfig returns sensible defaults.
@@ -18,6 +33,8 @@ func DefaultWriterConfig() WriterConfig {
 	return WriterConfig{
 		BatchSize:     1000,
 		FlushInterval: 5 * time.Second,
+		InsertMode:    InsertCopy,
+		Retry:         DefaultRetryConfig(),
 	}
 }
 
@@ -42,6 +59,7 @@ type orderbookDeltaRow struct {
 	Side       bool // TRUE = yes, FALSE = no
 	Price      int  // Hundred-thousandths
 	SizeDelta  int  // Positive = add, negative = remove
+	Ordinal    int  // Occurrence of (ExchangeTs, Ticker, Side, Price), in seq order
 	SID        int64
 }
 
@@ -61,6 +79,21 @@ type orderbookSnapshotRow struct {
 	SID        int64
 }
 
+// candleRow represents a row for the candles table.
+type candleRow struct {
+	CloseTs       int64 // Microseconds
+	Ticker        string
+	PeriodMinutes int
+	OpenTs        int64 // Microseconds
+	Open          int   // Hundred-thousandths
+	High          int
+	Low           int
+	Close         int
+	Volume        int64
+	OpenInterest  int64
+	ReceivedAt    int64 // Microseconds
+}
+
 // tickerRow represents a row for the tickers table.
 type tickerRow struct {
 	ExchangeTs         int64
@@ -76,6 +109,86 @@ type tickerRow struct {
 	SID                int64
 }
 
+// gapEventRow represents a row for the gap_events table.
+type gapEventRow struct {
+	DetectedAt  int64 // Microseconds
+	Ticker      string
+	SID         int64
+	ConnID      int
+	ExpectedSeq int64
+	ReceivedSeq int64
+	GapSize     int
+	Action      string // "resubscribed", "rate_limited", "failed", "skipped"
+	Error       string
+}
+
+// marketRow represents a row for the markets table. ObservedAt and Source
+// also fill the market_status_history row.
+type marketRow struct {
+	Ticker        string
+	EventTicker   string
+	Title         string
+	Subtitle      string
+	MarketStatus  string
+	TradingStatus string
+	MarketType    string
+	Result        string // "" until settled
+	Volume        int64
+	Volume24h     int64
+	OpenInterest  int64
+	OpenTS        int64 // Microseconds, 0 if unknown
+	CloseTS       int64
+	ExpirationTS  int64
+	CreatedTS     int64
+	ObservedAt    int64  // Microseconds
+	Source        string // "sync", "reconcile" or "lifecycle"
+
+	// Settlement, written to market_settlements once Result is set
+	SettlementValue *int  // Hundred-thousandths, nil if unknown
+	SettledTS       int64 // Microseconds, 0 if unknown
+}
+
+// eventRow represents a row for the events table.
+type eventRow struct {
+	EventTicker  string
+	SeriesTicker string
+	Title        string
+	SubTitle     string
+	Category     string
+	ObservedAt   int64 // Microseconds
+}
+
+// seriesRow represents a row for the series table.
+type seriesRow struct {
+	Ticker            string
+	Title             string
+	Category          string
+	Frequency         string
+	Tags              []byte // JSONB
+	SettlementSources []byte // JSONB
+	ObservedAt        int64  // Microseconds
+}
+
+// announcementRow represents a row for the exchange_announcements table.
+type announcementRow struct {
+	ID         string
+	Title      string
+	Message    string
+	Type       string
+	Status     string
+	CreatedTS  int64 // Microseconds, 0 if unknown
+	DeliveryTS int64
+	ObservedAt int64 // Microseconds
+}
+
+// metadataRow is one upsert for the metadata writer. Exactly one field is set.
+type metadataRow struct {
+	Market       *marketRow       `json:",omitempty"`
+	Event        *eventRow        `json:",omitempty"`
+	Series       *seriesRow       `json:",omitempty"`
+	Announcement *announcementRow `json:",omitempty"`
+}
+
 // WriterMetrics holds metrics for a writer.
 type WriterMetrics struct {
 	Inserts   int64
@@ -83,4 +196,11 @@ type WriterMetrics struct {
 	Errors    int64
 	Flushes   int64
 	SeqGaps   int64
+
+	// CopyFallbacks counts COPY flushes that failed and were retried as
+	// batch inserts.
+	CopyFallbacks int64
+
+	// Retry reports retries and dead-letter activity.
+	Retry RetryMetrics
 }